@@ -0,0 +1,84 @@
+package clipboard
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+var ErrUnavailable = errors.New("clipboard is not available")
+
+type tool struct {
+	name string
+	args []string
+}
+
+// System reads the system clipboard with the clipboard tool available on the platform,
+// e.g. pbpaste on macOS or wl-paste, xclip and xsel on Linux.
+type System struct {
+	lookPath func(file string) (string, error)
+	run      func(name string, args ...string) ([]byte, error)
+	getenv   func(key string) string
+	goos     string
+}
+
+// New creates a new System clipboard reader for the current platform.
+// It returns a pointer to the created System.
+func New() *System {
+	return &System{
+		lookPath: exec.LookPath,
+		run: func(name string, args ...string) ([]byte, error) {
+			return exec.Command(name, args...).Output()
+		},
+		getenv: os.Getenv,
+		goos:   runtime.GOOS,
+	}
+}
+
+// Read returns the text content of the system clipboard.
+// It returns ErrUnavailable if there is no display or no clipboard tool is installed, e.g. on headless systems,
+// and an error if the clipboard tool fails.
+func (s *System) Read() (string, error) {
+	for _, t := range s.tools() {
+		path, err := s.lookPath(t.name)
+		if err != nil {
+			continue
+		}
+
+		out, err := s.run(path, t.args...)
+		if err != nil {
+			return "", fmt.Errorf("fail to read clipboard with %s: %w", t.name, err)
+		}
+
+		return string(out), nil
+	}
+
+	return "", ErrUnavailable
+}
+
+// tools returns the clipboard tools that can be used on the platform in the order of preference.
+func (s *System) tools() []tool {
+	switch s.goos {
+	case "darwin":
+		return []tool{{name: "pbpaste"}}
+	case "windows":
+		return []tool{{name: "powershell", args: []string{"-NoProfile", "-Command", "Get-Clipboard"}}}
+	}
+
+	var tools []tool
+
+	if s.getenv("WAYLAND_DISPLAY") != "" {
+		tools = append(tools, tool{name: "wl-paste", args: []string{"--no-newline"}})
+	}
+
+	if s.getenv("DISPLAY") != "" {
+		tools = append(tools,
+			tool{name: "xclip", args: []string{"-selection", "clipboard", "-o"}},
+			tool{name: "xsel", args: []string{"--clipboard", "--output"}},
+		)
+	}
+
+	return tools
+}
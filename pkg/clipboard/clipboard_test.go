@@ -0,0 +1,95 @@
+package clipboard
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newStubSystem(goos string, env, installed map[string]string) *System {
+	return &System{
+		goos:   goos,
+		getenv: func(key string) string { return env[key] },
+		lookPath: func(file string) (string, error) {
+			if _, ok := installed[file]; ok {
+				return "/usr/bin/" + file, nil
+			}
+
+			return "", exec.ErrNotFound
+		},
+		run: func(name string, _ ...string) ([]byte, error) {
+			for tool, out := range installed {
+				if name == "/usr/bin/"+tool {
+					return []byte(out), nil
+				}
+			}
+
+			return nil, errors.New("unexpected tool")
+		},
+	}
+}
+
+func TestSystem_Read(t *testing.T) {
+	tests := []struct {
+		env       map[string]string
+		installed map[string]string
+		wantErr   error
+		name      string
+		goos      string
+		want      string
+	}{
+		{
+			name:      "macOS",
+			goos:      "darwin",
+			installed: map[string]string{"pbpaste": `{"ping":1}`},
+			want:      `{"ping":1}`,
+		},
+		{
+			name:      "Wayland",
+			goos:      "linux",
+			env:       map[string]string{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"},
+			installed: map[string]string{"wl-paste": "wayland", "xclip": "x11"},
+			want:      "wayland",
+		},
+		{
+			name:      "X11 falls back to xsel",
+			goos:      "linux",
+			env:       map[string]string{"DISPLAY": ":0"},
+			installed: map[string]string{"xsel": "x11"},
+			want:      "x11",
+		},
+		{
+			name:      "Headless",
+			goos:      "linux",
+			installed: map[string]string{"xclip": "x11"},
+			wantErr:   ErrUnavailable,
+		},
+		{
+			name:    "No tool installed",
+			goos:    "darwin",
+			wantErr: ErrUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStubSystem(tt.goos, tt.env, tt.installed)
+
+			got, err := s.Read()
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSystem_Read_ToolFails(t *testing.T) {
+	s := newStubSystem("darwin", nil, map[string]string{"pbpaste": ""})
+	s.run = func(string, ...string) ([]byte, error) { return nil, errors.New("exit status 1") }
+
+	_, err := s.Read()
+
+	assert.EqualError(t, err, "fail to read clipboard with pbpaste: exit status 1")
+}
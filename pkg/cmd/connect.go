@@ -1,19 +1,25 @@
 package cmd
 
 import (
+	"cmp"
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/user"
 	"path/filepath"
+	"regexp"
 	"time"
 
+	"github.com/ksysoev/wsget/pkg/clipboard"
 	"github.com/ksysoev/wsget/pkg/core"
 	command2 "github.com/ksysoev/wsget/pkg/core/command"
 	"github.com/ksysoev/wsget/pkg/core/edit"
 	"github.com/ksysoev/wsget/pkg/core/formater"
 	"github.com/ksysoev/wsget/pkg/input"
+	"github.com/ksysoev/wsget/pkg/output"
 	"github.com/ksysoev/wsget/pkg/repo/history"
 	"github.com/ksysoev/wsget/pkg/repo/macro"
 	"github.com/ksysoev/wsget/pkg/ws"
@@ -27,16 +33,31 @@ const (
 	historyCmdFilename = "cmd_history"
 	configDirMode      = 0o755
 	defaultConfigDir   = ".wsget"
+	envelopeFields     = 2
 )
 
 // createConnectRunner creates a runner function for the connect command.
 // It takes a single parameter args of type *flags.
 // It returns a function that takes a *cobra.Command and a slice of strings, and returns an error.
-// The returned function calls runConnectCmd with the provided command, args, and unnamedArgs.
-// It returns an error if runConnectCmd encounters any issues.
+// The returned function merges the project config into args and calls runConnectCmd with the resolved URL.
+// It returns an error if the project config can't be loaded or runConnectCmd encounters any issues.
 func createConnectRunner(args *flags) func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, unnamedArgs []string) error {
-		return runConnectCmd(cmd.Context(), args, unnamedArgs)
+		wsURL, err := loadProjectConfig(cmd.Flags().Changed, args, unnamedArgs)
+		if err != nil {
+			return err
+		}
+
+		err = runConnectCmd(cmd.Context(), args, []string{wsURL})
+		if errors.As(err, &command2.ErrSequenceFailed{}) || (args.connectOnly && args.outputJSON) {
+			// The failures are already reported, only the exit code is left to set.
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+		} else if args.connectOnly {
+			cmd.SilenceUsage = true
+		}
+
+		return err
 	}
 }
 
@@ -44,6 +65,7 @@ func createConnectRunner(args *flags) func(cmd *cobra.Command, args []string) er
 // It takes ctx of type context.Context, args of type *flags, and unnamedArgs of type []string.
 // It returns an error if the WebSocket connection cannot be established, the CLI cannot be started, or the client fails to run.
 // It returns nil if the client is interrupted gracefully.
+// Failures of sequences run with --continue are returned, so the tool exits with a non-zero code.
 func runConnectCmd(ctx context.Context, args *flags, unnamedArgs []string) error {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -54,14 +76,71 @@ func runConnectCmd(ctx context.Context, args *flags, unnamedArgs []string) error
 		return err
 	}
 
+	framing, err := createFraming(args)
+	if err != nil {
+		return err
+	}
+
+	format := formater.NewFormat()
+	if err := format.SetUTF8Mode(cmp.Or(args.utf8Mode, formater.UTF8Replace)); err != nil {
+		return err
+	}
+
+	if err := format.SetContentType(cmp.Or(args.contentType, formater.ContentTypeAuto)); err != nil {
+		return err
+	}
+
+	if err := format.SetFileFormat(cmp.Or(args.fileFormat, formater.FileFormatCompact)); err != nil {
+		return err
+	}
+
+	format.SetStrictJSON(args.strictJSON)
+	format.SetUnwrapJSON(args.unwrapJSON)
+	format.SetShowNewlines(args.showNewlines)
+	format.SetSummary(args.summary)
+	format.SetTable(args.table)
+
+	if len(args.envelope) == envelopeFields {
+		format.SetEnvelope(args.envelope[0], args.envelope[1])
+	}
+
+	var (
+		out    = output.New(os.Stdout, args.forceColor)
+		events *core.EventWriter
+	)
+
+	if args.outputJSON {
+		out = io.Discard
+		events = core.NewEventWriter(os.Stdout)
+	}
+
 	wsOpts := ws.Options{
 		SkipSSLVerification: args.insecure,
+		TLSResume:           args.tlsResume,
 		Headers:             args.headers,
+		HeaderPresets:       args.headerPresets,
+		BearerTokenCommand:  args.bearerTokenCmd,
+		ExecOnClose:         args.execOnClose,
+		ServerName:          args.serverName,
+		MaxRedirects:        redirectLimit(args.maxRedirects),
+		RedirectAuth:        args.redirectAuth,
+		Extensions:          args.extensions,
+		Subprotocols:        args.subprotocols,
 		MaxMessageSize:      args.maxMsgSize,
+		Framing:             framing,
+		ReconnectAttempts:   args.reconnect,
+		ReconnectDelay:      args.reconnectDelay,
+		ReconnectJitter:     args.reconnectJitter,
+		HeartbeatMessage:    args.heartbeat,
+		HeartbeatInterval:   args.heartbeatInterval,
+		WriteTimeout:        args.writeTimeout,
+		ConnectMessage:      args.connectMessage,
+		ConnectAck:          args.connectAck,
+		ConnectAckTimeout:   args.connectAckTimeout,
 	}
 
 	if args.verbose {
-		wsOpts.Output = os.Stdout
+		wsOpts.Output = out
 	}
 
 	wsConn, err := ws.New(wsURL, wsOpts)
@@ -71,6 +150,10 @@ func runConnectCmd(ctx context.Context, args *flags, unnamedArgs []string) error
 
 	defer func() { _ = wsConn.Close() }()
 
+	if args.connectOnly {
+		return runConnectOnly(ctx, wsConn, args.connectOnlyWait, events)
+	}
+
 	if args.configDir == "" {
 		currentUser, err := user.Current()
 		if err != nil {
@@ -80,8 +163,16 @@ func runConnectCmd(ctx context.Context, args *flags, unnamedArgs []string) error
 		args.configDir = filepath.Join(currentUser.HomeDir, defaultConfigDir)
 	}
 
-	if err = os.MkdirAll(filepath.Join(args.configDir, macroDir), configDirMode); err != nil {
-		return fmt.Errorf("fail to get current user: %s", err)
+	macroPaths := filepath.SplitList(args.macroDir)
+
+	if len(macroPaths) == 0 {
+		macroPath := filepath.Join(args.configDir, macroDir)
+
+		if err = os.MkdirAll(macroPath, configDirMode); err != nil {
+			return fmt.Errorf("fail to get current user: %s", err)
+		}
+
+		macroPaths = []string{macroPath}
 	}
 
 	reqHistory, err := history.LoadFromFile(filepath.Join(args.configDir, historyFilename))
@@ -98,7 +189,7 @@ func runConnectCmd(ctx context.Context, args *flags, unnamedArgs []string) error
 
 	defer func() { _ = cmdHistory.Close() }()
 
-	macroRepo, err := macro.LoadMacroForDomain(filepath.Join(args.configDir, macroDir), wsConn.Hostname())
+	macroRepo, err := macro.LoadMacroForDomain(macroPaths, wsConn.Hostname())
 	if err != nil {
 		return fmt.Errorf("fail to load macro: %s", err)
 	}
@@ -112,9 +203,28 @@ func runConnectCmd(ctx context.Context, args *flags, unnamedArgs []string) error
 		cmdFactory = command2.NewFactory(nil)
 	}
 
-	editor := edit.NewMultiMode(os.Stdout, reqHistory, cmdHistory)
+	cmdFactory.SetClipboard(clipboard.New())
 
-	client := core.NewCLI(cmdFactory, wsConn, os.Stdout, editor, formater.NewFormat())
+	editor := edit.NewMultiMode(out, reqHistory, cmdHistory)
+	editor.SetPrettifyPaste(args.prettifyPaste)
+	editor.SetJSONIndicator(output.IsTerminal(os.Stdout))
+
+	client := core.NewCLI(cmdFactory, wsConn, out, editor, format)
+	client.SetRecentSize(args.recentSize)
+	client.SetDedupWindow(args.dedupWindow)
+	client.SetSkipBanner(args.skipBanner)
+
+	if args.controlPattern != "" {
+		pattern, err := regexp.Compile(args.controlPattern)
+		if err != nil {
+			return fmt.Errorf("invalid control pattern: %w", err)
+		}
+
+		client.SetControlPattern(pattern, args.dataBuffer)
+	}
+	client.SetEventWriter(events)
+
+	wsConn.SetHeaderExpander(client.ExpandVariables)
 
 	keyboard := input.NewKeyboard(client)
 	defer keyboard.Close()
@@ -124,6 +234,16 @@ func runConnectCmd(ctx context.Context, args *flags, unnamedArgs []string) error
 		return err
 	}
 
+	if closer, ok := opts.OutputFile.(io.Closer); ok {
+		defer func() { _ = closer.Close() }()
+	}
+
+	for _, w := range opts.Sinks {
+		if closer, ok := w.(io.Closer); ok {
+			defer func() { _ = closer.Close() }()
+		}
+	}
+
 	eg, ctx := errgroup.WithContext(ctx)
 
 	eg.Go(func() error {
@@ -134,6 +254,14 @@ func runConnectCmd(ctx context.Context, args *flags, unnamedArgs []string) error
 		return wsConn.Connect(ctx)
 	})
 
+	if args.controlPipe != "" {
+		pipe := input.NewPipe(args.controlPipe, client)
+
+		eg.Go(func() error {
+			return pipe.Run(ctx)
+		})
+	}
+
 	eg.Go(func() error {
 		select {
 		case <-ctx.Done():
@@ -144,22 +272,56 @@ func runConnectCmd(ctx context.Context, args *flags, unnamedArgs []string) error
 		return client.Run(ctx, *opts)
 	})
 
-	err = eg.Wait()
+	err = sessionError(eg.Wait())
 
-	if errors.Is(err, context.Canceled) || errors.Is(err, core.ErrInterrupted) {
+	if args.reportFile != "" {
+		if reportErr := core.WriteReport(args.reportFile, client.Report(err)); reportErr != nil {
+			return reportErr
+		}
+	}
+
+	if err == nil {
 		return nil
 	}
 
-	fmt.Println("Error:", err)
+	if events != nil {
+		events.Error(err)
+	} else {
+		fmt.Println("Error:", err)
+	}
+
+	if errors.As(err, &command2.ErrSequenceFailed{}) {
+		return err
+	}
 
 	return nil
 }
 
+// sessionError returns the error the session ended with, or nil if it was closed by the user.
+func sessionError(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, core.ErrInterrupted) {
+		return nil
+	}
+
+	return err
+}
+
+// redirectLimit converts the redirect count of the flag to the limit of ws.Options,
+// where 0 disables following redirects on the command line, while ws.Options treats it as the default limit.
+func redirectLimit(count int) int {
+	if count == 0 {
+		return -1
+	}
+
+	return count
+}
+
 // validateArgs checks the validity of the provided WebSocket URL and flags.
 // It takes wsURL of type string and args of type *flags.
 // It returns an error if the wsURL is empty or if the single response timeout is set without a request.
 // If wsURL is an empty string, it returns an error indicating that the URL is required.
 // If args.waitResponse is non-negative and args.request is an empty string, it returns an error indicating that the single response timeout can only be used with a request.
+// It returns an error if the JSON output is combined with forced colors.
 func validateArgs(wsURL string, args *flags) error {
 	if wsURL == "" {
 		return fmt.Errorf("url is required")
@@ -169,15 +331,81 @@ func validateArgs(wsURL string, args *flags) error {
 		return fmt.Errorf("single response timeout could be used only with request")
 	}
 
+	if args.outputJSON && args.forceColor {
+		return fmt.Errorf("json output can't be used with colored output")
+	}
+
+	if len(args.envelope) > 0 && (len(args.envelope) != envelopeFields || args.envelope[0] == "" || args.envelope[1] == "") {
+		return fmt.Errorf("envelope requires the content type and the body field names, e.g. contentType,body")
+	}
+
+	switch args.newline {
+	case "", core.NewlineKeep, core.NewlineLF, core.NewlineCRLF:
+	default:
+		return fmt.Errorf("invalid newline mode: %s, expected keep, lf or crlf", args.newline)
+	}
+
+	if args.skipBanner < 0 {
+		return fmt.Errorf("skip banner count must not be negative")
+	}
+
+	if args.maxDuration < 0 {
+		return fmt.Errorf("max duration must not be negative")
+	}
+
+	if args.maxRedirects < 0 {
+		return fmt.Errorf("redirect count must not be negative")
+	}
+
+	switch args.trim {
+	case "", core.TrimNone, core.TrimSpace, core.TrimNewline:
+	default:
+		return fmt.Errorf("invalid trim mode: %s, expected none, space or newline", args.trim)
+	}
+
 	return nil
 }
 
+// createFraming creates the binary frame decoder requested by the length prefix flags.
+// It takes args of type *flags with the prefix size and byte order.
+// It returns nil if splitting is disabled, or an error if the prefix size or byte order is invalid.
+func createFraming(args *flags) (*ws.LengthPrefixFraming, error) {
+	if args.lengthPrefix == 0 {
+		return nil, nil
+	}
+
+	var order binary.ByteOrder
+
+	switch args.lengthPrefixOrder {
+	case "big":
+		order = binary.BigEndian
+	case "little":
+		order = binary.LittleEndian
+	default:
+		return nil, fmt.Errorf("invalid length prefix order: %s", args.lengthPrefixOrder)
+	}
+
+	return ws.NewLengthPrefixFraming(args.lengthPrefix, order)
+}
+
 // initRunOptions initializes and returns a RunOptions struct based on the provided flags.
 // It takes a single parameter args of type *flags which contains the command-line arguments.
 // It returns a pointer to cli.RunOptions and an error.
-// It returns an error if it fails to open the specified output file.
+// It returns an error if it fails to open the specified output file or to connect to syslog.
 func initRunOptions(args *flags) (opts *core.RunOptions, err error) {
-	opts = &core.RunOptions{}
+	opts = &core.RunOptions{
+		Prompt:             args.prompt,
+		Newline:            args.newline,
+		Trim:               args.trim,
+		AutoCloseAfterIdle: args.idleClose,
+		MaxDuration:        args.maxDuration,
+		InitialSendDelay:   args.initialSendDelay,
+		OnlyRequests:       args.onlyRequests,
+		OnlyResponses:      args.onlyResponses,
+		ShowRaw:            args.showRaw,
+		ContinueOnError:    args.continueOnError,
+		EchoInput:          args.echoInput,
+	}
 
 	if args.outputFile != "" {
 		if opts.OutputFile, err = os.Create(args.outputFile); err != nil {
@@ -185,6 +413,19 @@ func initRunOptions(args *flags) (opts *core.RunOptions, err error) {
 		}
 	}
 
+	if args.syslog != "" {
+		w, err := output.NewSyslog(args.syslog, args.syslogAddr)
+		if err != nil {
+			return nil, err
+		}
+
+		opts.Sinks = append(opts.Sinks, w)
+	}
+
+	if args.correlate != "" {
+		opts.Correlator = core.NewJSONFieldCorrelator(args.correlate)
+	}
+
 	opts.Commands = createCommands(args)
 
 	return opts, nil
@@ -194,7 +435,7 @@ func initRunOptions(args *flags) (opts *core.RunOptions, err error) {
 // It takes a single parameter args of type *flags, which contains the command-line arguments.
 // It returns a slice of core.Executer, which represents the sequence of commands to be executed.
 // If args.request is not empty, it creates a Send command and optionally adds WaitForResp and Exit commands if args.waitResponse is non-negative.
-// If args.inputFile is not empty, it creates an InputFileCommand.
+// If args.inputFile is not empty, it creates an InputFileCommand, or a ReplayLoop if the input file is replayed more than once.
 // If neither args.request nor args.inputFile is provided, it defaults to creating an Edit command.
 func createCommands(args *flags) []core.Executer {
 	var executers []core.Executer
@@ -211,7 +452,11 @@ func createCommands(args *flags) []core.Executer {
 			)
 		}
 	case args.inputFile != "":
-		executers = []core.Executer{command2.NewInputFileCommand(args.inputFile)}
+		if args.replayLoop != 1 {
+			executers = []core.Executer{command2.NewReplayLoop(args.inputFile, args.replayLoop, args.replayResetSeq)}
+		} else {
+			executers = []core.Executer{command2.NewInputFileCommand(args.inputFile)}
+		}
 	default:
 		executers = []core.Executer{command2.NewEdit("")}
 	}
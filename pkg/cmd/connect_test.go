@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"io"
 	"net/http"
@@ -12,7 +13,11 @@ import (
 	"github.com/coder/websocket"
 	"github.com/ksysoev/wsget/pkg/core"
 	"github.com/ksysoev/wsget/pkg/core/command"
+	"github.com/ksysoev/wsget/pkg/core/formater"
+	"github.com/ksysoev/wsget/pkg/ws"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func createEchoWSHandler() http.HandlerFunc {
@@ -85,12 +90,23 @@ func TestCreateCommands(t *testing.T) {
 		{
 			name: "InputFile",
 			args: &flags{
-				inputFile: tmpDir + "/testfile.txt",
+				inputFile:  tmpDir + "/testfile.txt",
+				replayLoop: 1,
 			},
 			expected: []core.Executer{
 				command.NewInputFileCommand(tmpDir + "/testfile.txt"),
 			},
 		},
+		{
+			name: "InputFile replayed in a loop",
+			args: &flags{
+				inputFile:      tmpDir + "/testfile.txt",
+				replayResetSeq: true,
+			},
+			expected: []core.Executer{
+				command.NewReplayLoop(tmpDir+"/testfile.txt", 0, true),
+			},
+		},
 		{
 			name: "Default Edit",
 			args: &flags{},
@@ -161,7 +177,8 @@ func TestInitRunOptions(t *testing.T) {
 		{
 			name: "InputFile",
 			args: &flags{
-				inputFile: tmpDir + "/testfile.txt",
+				inputFile:  tmpDir + "/testfile.txt",
+				replayLoop: 1,
 			},
 			expected: &core.RunOptions{
 				Commands: []core.Executer{
@@ -170,6 +187,56 @@ func TestInitRunOptions(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "Idle close and prompt",
+			args: &flags{
+				idleClose:   5 * time.Second,
+				maxDuration: time.Minute,
+				prompt:      "{{.Host}}>",
+			},
+			expected: &core.RunOptions{
+				Commands: []core.Executer{
+					command.NewEdit(""),
+				},
+				Prompt:             "{{.Host}}>",
+				AutoCloseAfterIdle: 5 * time.Second,
+				MaxDuration:        time.Minute,
+			},
+			expectError: false,
+		},
+		{
+			name: "Only responses",
+			args: &flags{
+				onlyResponses: true,
+			},
+			expected: &core.RunOptions{
+				Commands: []core.Executer{
+					command.NewEdit(""),
+				},
+				OnlyResponses: true,
+			},
+			expectError: false,
+		},
+		{
+			name: "Initial send delay",
+			args: &flags{
+				initialSendDelay: 500 * time.Millisecond,
+			},
+			expected: &core.RunOptions{
+				Commands: []core.Executer{
+					command.NewEdit(""),
+				},
+				InitialSendDelay: 500 * time.Millisecond,
+			},
+			expectError: false,
+		},
+		{
+			name: "Invalid syslog priority",
+			args: &flags{
+				syslog: "local0",
+			},
+			expectError: true,
+		},
 		{
 			name: "Default Edit",
 			args: &flags{},
@@ -190,6 +257,11 @@ func TestInitRunOptions(t *testing.T) {
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.expected.Commands, opts.Commands)
+				assert.Equal(t, tt.expected.AutoCloseAfterIdle, opts.AutoCloseAfterIdle)
+				assert.Equal(t, tt.expected.Prompt, opts.Prompt)
+				assert.Equal(t, tt.expected.OnlyRequests, opts.OnlyRequests)
+				assert.Equal(t, tt.expected.OnlyResponses, opts.OnlyResponses)
+				assert.Equal(t, tt.expected.InitialSendDelay, opts.InitialSendDelay)
 
 				if tt.expected.OutputFile != nil {
 					assert.NotNil(t, opts.OutputFile)
@@ -221,6 +293,79 @@ func TestValidateArgs(t *testing.T) {
 			},
 			expectedErr: "single response timeout could be used only with request",
 		},
+		{
+			name:  "JSON output with colors",
+			wsURL: "ws://example.com",
+			args: &flags{
+				waitResponse: -1,
+				outputJSON:   true,
+				forceColor:   true,
+			},
+			expectedErr: "json output can't be used with colored output",
+		},
+		{
+			name:  "Envelope with a single field",
+			wsURL: "ws://example.com",
+			args: &flags{
+				waitResponse: -1,
+				envelope:     []string{"contentType"},
+			},
+			expectedErr: "envelope requires the content type and the body field names, e.g. contentType,body",
+		},
+		{
+			name:  "Invalid newline mode",
+			wsURL: "ws://example.com",
+			args: &flags{
+				waitResponse: -1,
+				newline:      "cr",
+			},
+			expectedErr: "invalid newline mode: cr, expected keep, lf or crlf",
+		},
+		{
+			name:  "Negative skip banner",
+			wsURL: "ws://example.com",
+			args: &flags{
+				waitResponse: -1,
+				skipBanner:   -1,
+			},
+			expectedErr: "skip banner count must not be negative",
+		},
+		{
+			name:  "Negative max duration",
+			wsURL: "ws://example.com",
+			args: &flags{
+				waitResponse: -1,
+				maxDuration:  -time.Second,
+			},
+			expectedErr: "max duration must not be negative",
+		},
+		{
+			name:  "Negative redirect count",
+			wsURL: "ws://example.com",
+			args: &flags{
+				waitResponse: -1,
+				maxRedirects: -1,
+			},
+			expectedErr: "redirect count must not be negative",
+		},
+		{
+			name:  "Invalid trim mode",
+			wsURL: "ws://example.com",
+			args: &flags{
+				waitResponse: -1,
+				trim:         "all",
+			},
+			expectedErr: "invalid trim mode: all, expected none, space or newline",
+		},
+		{
+			name:  "Envelope fields",
+			wsURL: "ws://example.com",
+			args: &flags{
+				waitResponse: -1,
+				envelope:     []string{"contentType", "body"},
+			},
+			expectedErr: "",
+		},
 		{
 			name:  "Valid Arguments",
 			wsURL: "ws://example.com",
@@ -294,3 +439,126 @@ func TestRunConnectCmd_SuccessConnect(t *testing.T) {
 		assert.NoError(t, err)
 	}
 }
+
+func TestCreateFraming(t *testing.T) {
+	tests := []struct {
+		args        *flags
+		name        string
+		expectedErr string
+		wantFraming bool
+	}{
+		{
+			name: "Disabled",
+			args: &flags{},
+		},
+		{
+			name:        "Big endian",
+			args:        &flags{lengthPrefix: 4, lengthPrefixOrder: "big"},
+			wantFraming: true,
+		},
+		{
+			name:        "Little endian",
+			args:        &flags{lengthPrefix: 2, lengthPrefixOrder: "little"},
+			wantFraming: true,
+		},
+		{
+			name:        "Invalid order",
+			args:        &flags{lengthPrefix: 4, lengthPrefixOrder: "middle"},
+			expectedErr: "invalid length prefix order: middle",
+		},
+		{
+			name:        "Invalid size",
+			args:        &flags{lengthPrefix: 3, lengthPrefixOrder: "big"},
+			expectedErr: "invalid length prefix size: 3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			framing, err := createFraming(tt.args)
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantFraming, framing != nil)
+		})
+	}
+}
+
+func TestRedirectLimit(t *testing.T) {
+	assert.Equal(t, -1, redirectLimit(0))
+	assert.Equal(t, 3, redirectLimit(3))
+	assert.Equal(t, 10, redirectLimit(10))
+}
+
+// receiveBinaryFrame runs a session against a server sending the binary frame and formats it with the content type.
+// It returns the content of the output file.
+func receiveBinaryFrame(t *testing.T, frame []byte, contentType string) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = c.CloseNow() }()
+
+		if err := c.Write(r.Context(), websocket.MessageBinary, frame); err != nil {
+			return
+		}
+
+		_, _, _ = c.Read(r.Context())
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := ws.New("ws://"+server.Listener.Addr().String(), ws.Options{})
+	require.NoError(t, err)
+
+	format := formater.NewFormat()
+	require.NoError(t, format.SetContentType(contentType))
+
+	editor := core.NewMockEditor(t)
+	editor.EXPECT().SetInput(mock.Anything)
+
+	cli := core.NewCLI(command.NewFactory(nil), conn, &bytes.Buffer{}, editor, format)
+
+	go func() { _ = conn.Connect(ctx) }()
+
+	select {
+	case <-conn.Ready():
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for connection")
+	}
+
+	file := &bytes.Buffer{}
+
+	err = cli.Run(ctx, core.RunOptions{
+		OutputFile: file,
+		Commands:   []core.Executer{command.NewWaitForResp(2 * time.Second), command.NewExit()},
+	})
+	assert.ErrorIs(t, err, core.ErrInterrupted)
+
+	return file.String()
+}
+
+func TestBinaryFrame_ShownAsBase64(t *testing.T) {
+	out := receiveBinaryFrame(t, []byte{0x00, 0x01, 0x02, 0xff}, formater.ContentTypeBase64)
+
+	assert.Contains(t, out, "b64:AAEC/w==")
+}
+
+func TestBinaryFrame_DecodedAsCBOR(t *testing.T) {
+	// {"id": 7, "ok": true}
+	frame := []byte{0xa2, 0x62, 'i', 'd', 0x07, 0x62, 'o', 'k', 0xf5}
+
+	out := receiveBinaryFrame(t, frame, formater.ContentTypeCBOR)
+
+	assert.Contains(t, out, `{"id":7,"ok":true}`)
+}
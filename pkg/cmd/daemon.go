@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/ksysoev/wsget/pkg/daemon"
+	"github.com/spf13/cobra"
+)
+
+const (
+	socketName    = "wsget.sock"
+	socketDirMode = 0o700
+	socketMode    = 0o600
+)
+
+// daemonFlags holds the options of the daemon and send commands.
+type daemonFlags struct {
+	socket   string
+	headers  []string
+	timeout  time.Duration
+	insecure bool
+}
+
+// defaultSocket returns the path of the daemon socket used unless it's set with the --socket flag.
+// The socket is kept in a directory of the current user, as send passes the headers, often carrying credentials,
+// to whatever process listens on it: in XDG_RUNTIME_DIR if it's set, and in the config directory otherwise.
+func defaultSocket() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "wsget", socketName)
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, defaultConfigDir, "daemon", socketName)
+	}
+
+	return filepath.Join(os.TempDir(), "wsget-"+strconv.Itoa(os.Getuid()), socketName)
+}
+
+// runDaemonCommand listens on the daemon socket and keeps connections open for send commands until the context is canceled.
+// A socket file left by a daemon that is not running anymore is removed.
+// The directory of the socket is created accessible only to the current user, if it doesn't exist,
+// and the socket is accessible only to the current user.
+// It returns an error if another daemon is already listening on the socket or the socket can't be created.
+func runDaemonCommand(ctx context.Context, args *daemonFlags) error {
+	if err := os.MkdirAll(filepath.Dir(args.socket), socketDirMode); err != nil {
+		return fmt.Errorf("fail to create daemon socket directory: %w", err)
+	}
+
+	if _, err := os.Stat(args.socket); err == nil {
+		if conn, err := net.Dial("unix", args.socket); err == nil {
+			_ = conn.Close()
+			return fmt.Errorf("daemon is already running on %s", args.socket)
+		}
+
+		if err := os.Remove(args.socket); err != nil {
+			return fmt.Errorf("fail to remove stale daemon socket: %w", err)
+		}
+	}
+
+	ln, err := net.Listen("unix", args.socket)
+	if err != nil {
+		return fmt.Errorf("fail to listen on daemon socket: %w", err)
+	}
+
+	if err := os.Chmod(args.socket, socketMode); err != nil {
+		_ = ln.Close()
+		return fmt.Errorf("fail to restrict access to daemon socket: %w", err)
+	}
+
+	return daemon.NewServer().Serve(ctx, ln)
+}
+
+// runSendCommand sends the message through the daemon and prints the first message received after it.
+// It takes url and message of type string, the connection to the url is reused if the daemon already has it open.
+// It returns an error if the daemon is not running, the connection fails or no response is received in time.
+func runSendCommand(cmd *cobra.Command, args *daemonFlags, url, message string) error {
+	req := daemon.Request{
+		URL:      url,
+		Message:  message,
+		Headers:  args.headers,
+		Timeout:  args.timeout,
+		Insecure: args.insecure,
+	}
+
+	resp, err := daemon.Send(cmd.Context(), args.socket, req)
+	if err != nil {
+		return err
+	}
+
+	if resp.Error != "" {
+		return errors.New(resp.Error)
+	}
+
+	_, err = fmt.Fprintln(cmd.OutOrStdout(), resp.Data)
+
+	return err
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunDaemonCommand_Send(t *testing.T) {
+	server := httptest.NewServer(createEchoWSHandler())
+	defer server.Close()
+
+	socket := filepath.Join(t.TempDir(), "wsget.sock")
+
+	// A socket file left by a crashed daemon doesn't prevent starting a new one.
+	require.NoError(t, os.WriteFile(socket, nil, 0o600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() {
+		done <- runDaemonCommand(ctx, &daemonFlags{socket: socket})
+	}()
+
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("unix", socket)
+		if err == nil {
+			_ = conn.Close()
+		}
+
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	err := runDaemonCommand(context.Background(), &daemonFlags{socket: socket})
+	assert.ErrorContains(t, err, "daemon is already running")
+
+	cmd := initSendCommand()
+	out := &bytes.Buffer{}
+
+	cmd.SetOut(out)
+	cmd.SetArgs([]string{"--socket", socket, "ws://" + server.Listener.Addr().String(), "Hello"})
+
+	require.NoError(t, cmd.ExecuteContext(context.Background()))
+	assert.Equal(t, "Hello\n", out.String())
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestRunDaemonCommand_SocketPermissions(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "run", "wsget.sock")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() {
+		done <- runDaemonCommand(ctx, &daemonFlags{socket: socket})
+	}()
+
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(socket)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	dir, err := os.Stat(filepath.Dir(socket))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(socketDirMode), dir.Mode().Perm())
+
+	require.Eventually(t, func() bool {
+		info, err := os.Stat(socket)
+		return err == nil && info.Mode().Perm() == socketMode
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestDefaultSocket(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	assert.Equal(t, filepath.Join("/run/user/1000", "wsget", "wsget.sock"), defaultSocket())
+
+	home := t.TempDir()
+
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	t.Setenv("HOME", home)
+	assert.Equal(t, filepath.Join(home, ".wsget", "daemon", "wsget.sock"), defaultSocket())
+}
+
+func TestRunSendCommand_NoDaemon(t *testing.T) {
+	cmd := initSendCommand()
+	cmd.SetArgs([]string{"--socket", filepath.Join(t.TempDir(), "missing.sock"), "ws://localhost", "Hello"})
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+
+	err := cmd.ExecuteContext(context.Background())
+
+	assert.ErrorContains(t, err, "fail to connect to daemon")
+}
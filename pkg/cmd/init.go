@@ -3,7 +3,11 @@ package cmd
 import (
 	"cmp"
 	"os"
+	"time"
 
+	"github.com/ksysoev/wsget/pkg/core"
+	"github.com/ksysoev/wsget/pkg/core/formater"
+	"github.com/ksysoev/wsget/pkg/daemon"
 	"github.com/ksysoev/wsget/pkg/ws"
 	"github.com/spf13/cobra"
 )
@@ -31,15 +35,73 @@ In this request mode the tool will send the request to the server and print resp
 )
 
 type flags struct {
-	request      string
-	outputFile   string
-	inputFile    string
-	configDir    string
-	headers      []string
-	maxMsgSize   int64
-	waitResponse int
-	insecure     bool
-	verbose      bool
+	request           string
+	outputFile        string
+	inputFile         string
+	configDir         string
+	controlPipe       string
+	lengthPrefixOrder string
+	prompt            string
+	utf8Mode          string
+	fileFormat        string
+	heartbeat         string
+	macroDir          string
+	contentType       string
+	connectMessage    string
+	connectAck        string
+	bearerTokenCmd    string
+	execOnClose       string
+	reportFile        string
+	serverName        string
+	redirectAuth      string
+	reconnectJitter   string
+	correlate         string
+	newline           string
+	trim              string
+	controlPattern    string
+	syslog            string
+	syslogAddr        string
+	headers           []string
+	headerPresets     []string
+	extensions        []string
+	subprotocols      []string
+	envelope          []string
+	maxMsgSize        int64
+	idleClose         time.Duration
+	maxDuration       time.Duration
+	heartbeatInterval time.Duration
+	writeTimeout      time.Duration
+	initialSendDelay  time.Duration
+	reconnectDelay    time.Duration
+	connectAckTimeout time.Duration
+	connectOnlyWait   time.Duration
+	dedupWindow       time.Duration
+	waitResponse      int
+	reconnect         int
+	lengthPrefix      int
+	recentSize        int
+	dataBuffer        int
+	skipBanner        int
+	maxRedirects      int
+	replayLoop        int
+	insecure          bool
+	tlsResume         bool
+	strictJSON        bool
+	unwrapJSON        bool
+	showNewlines      bool
+	summary           bool
+	table             bool
+	echoInput         bool
+	verbose           bool
+	forceColor        bool
+	onlyRequests      bool
+	onlyResponses     bool
+	replayResetSeq    bool
+	showRaw           bool
+	prettifyPaste     bool
+	continueOnError   bool
+	outputJSON        bool
+	connectOnly       bool
 }
 
 // InitCommands initializes and returns a new cobra.Command for the wsget tool.
@@ -53,7 +115,7 @@ func InitCommands(version string) *cobra.Command {
 		Short:      "A command-line tool for interacting with WebSocket servers",
 		Long:       longDescription,
 		Example:    `wsget wss://ws.postman-echo.com/raw -r "Hello, world!"`,
-		Args:       cobra.ExactArgs(1),
+		Args:       cobra.MaximumNArgs(1),
 		ArgAliases: []string{"url"},
 		Version:    version,
 		RunE:       createConnectRunner(args),
@@ -64,15 +126,76 @@ func InitCommands(version string) *cobra.Command {
 	cmd.Flags().BoolVarP(&args.insecure, "insecure", "k", false, "Skip SSL certificate verification")
 	cmd.Flags().StringVarP(&args.request, "request", "r", "", "WebSocket request that will be sent to the server")
 	cmd.Flags().StringVarP(&args.outputFile, "output", "o", "", "Output file for saving all request and responses")
+	cmd.Flags().StringVar(&args.fileFormat, "format-file", formater.FileFormatCompact, "Layout of JSON and XML messages in the output file: compact or pretty")
+	cmd.Flags().BoolVar(&args.onlyRequests, "only-requests", false, "Save only requests to the output file, responses are still shown")
+	cmd.Flags().StringVar(&args.syslog, "syslog", "", "Record messages to syslog with the priority given as facility.severity, e.g. local0.info")
+	cmd.Flags().StringVar(&args.syslogAddr, "syslog-addr", "", "Address of a remote syslog daemon, e.g. udp://logs.example.com:514, the local daemon is used by default")
+	cmd.Flags().BoolVar(&args.outputJSON, "output-json", false, "Print connection events and messages as JSON objects, one per line, instead of the formatted output")
+	cmd.Flags().BoolVar(&args.continueOnError, "continue", false, "Keep running macros and input files after a failed step, all failures are reported at the end with a non-zero exit code")
+	cmd.Flags().BoolVar(&args.prettifyPaste, "prettify-paste", false, "Indent JSON pasted in the request editor, so minified JSON can be edited comfortably")
+	cmd.Flags().StringVar(&args.controlPattern, "control-pattern", "", "Regular expression matching control messages, e.g. heartbeats, which are handled before buffered data messages")
+	cmd.Flags().IntVar(&args.dataBuffer, "data-buffer", core.DataBufferSize, "Number of data messages buffered with --control-pattern, the oldest one is dropped when the buffer is full")
+	cmd.Flags().DurationVar(&args.dedupWindow, "dedup", 0, "Suppress received messages identical to the previous one that arrive within the window, e.g. 500ms, 0 disables it")
+	cmd.Flags().BoolVar(&args.showRaw, "show-raw", false, "Print the number of bytes and a hexdump of the raw data after every formatted message")
+	cmd.Flags().BoolVar(&args.onlyResponses, "only-responses", false, "Save only responses to the output file, requests are still shown")
 	cmd.Flags().IntVarP(&args.waitResponse, "wait-resp", "w", -1, "Timeout for single response in seconds, 0 means no timeout. If this option is set, the tool will exit after receiving the first response")
 	cmd.Flags().StringSliceVarP(&args.headers, "header", "H", []string{}, "HTTP headers to attach to the request")
+	cmd.Flags().StringSliceVar(&args.headerPresets, "header-preset", []string{}, "Authentication headers by preset: bearer:TOKEN, basic:USER:PASSWORD, apikey:KEY or token:TOKEN")
+	cmd.Flags().StringVar(&args.bearerTokenCmd, "bearer-token-cmd", "", "Command printing the bearer token sent in the Authorization header, run again on every reconnect, e.g. gcloud auth print-access-token")
+	cmd.Flags().StringVar(&args.reportFile, "report", "", "File to write a JSON summary of the session to on exit: message totals, response time percentiles and errors")
+	cmd.Flags().IntVar(&args.skipBanner, "skip-banner", 0, "Number of messages discarded after every connect and reconnect, e.g. a greeting banner of the server, 1 if the flag is set without a value")
+	cmd.Flags().Lookup("skip-banner").NoOptDefVal = "1"
+	cmd.Flags().StringVar(&args.execOnClose, "exec-on-close", "", "Command run once when the connection is first closed unexpectedly, the close code and reason are passed in WSGET_CLOSE_CODE and WSGET_CLOSE_REASON")
+	cmd.Flags().IntVar(&args.maxRedirects, "insecure-allow-redirects", ws.DefaultMaxRedirects, "Number of redirects followed during the handshake, 0 disables following them")
+	cmd.Flags().StringVar(&args.redirectAuth, "redirect-auth", ws.RedirectAuthCrossOrigin, "Credentials sent to the redirect target: cross-origin drops them for another origin, keep or drop")
+	cmd.Flags().BoolVar(&args.tlsResume, "tls-resume", false, "Keep TLS session tickets, so reconnects resume the TLS session instead of making a full handshake")
+	cmd.Flags().StringVar(&args.serverName, "sni", "", "Server name sent in the TLS handshake and used to verify the certificate instead of the URL host")
+	cmd.Flags().StringSliceVar(&args.extensions, "extension", []string{}, "WebSocket extensions with parameters to offer in Sec-WebSocket-Extensions header")
+	cmd.Flags().StringSliceVar(&args.subprotocols, "subprotocol", []string{}, "WebSocket subprotocols to offer in Sec-WebSocket-Protocol header, in the order of preference")
+	cmd.Flags().StringVar(&args.controlPipe, "control-pipe", "", "Named pipe to read commands from while the session is running, e.g. created with mkfifo")
 	cmd.Flags().StringVarP(&args.inputFile, "input", "i", "", "Input YAML file with list of requests to send to the server")
+	cmd.Flags().IntVar(&args.replayLoop, "replay-loop", 1, "Number of times to replay the input file from the top, 0 replays it until the tool is stopped")
+	cmd.Flags().BoolVar(&args.replayResetSeq, "replay-reset-seq", false, "Restart the ${seq} counter of replayed commands on every replay instead of continuing it")
+	cmd.Flags().StringVar(&args.correlate, "correlate", "", "JSON field with the request ID, e.g. id, the request command waits for the response with the same ID")
+	cmd.Flags().IntVar(&args.reconnect, "reconnect", 0, "Number of attempts to re-establish a dropped connection, 0 disables reconnecting")
+	cmd.Flags().DurationVar(&args.reconnectDelay, "reconnect-delay", time.Second, "Delay before the first reconnect attempt, doubled after every failed attempt")
+	cmd.Flags().StringVar(&args.reconnectJitter, "reconnect-jitter", ws.JitterNone, "Randomization of the reconnect delay: none, full for a delay between zero and the backoff or equal for a delay between half of the backoff and the backoff")
+	cmd.Flags().StringVar(&args.heartbeat, "heartbeat", "", "Message sent to the server on the heartbeat interval to keep the session alive")
+	cmd.Flags().DurationVar(&args.heartbeatInterval, "heartbeat-interval", 30*time.Second, "Interval between heartbeat messages")
+	cmd.Flags().DurationVar(&args.writeTimeout, "write-timeout", 0, "Maximum time to send a message to the server, the connection is closed if it's exceeded, 0 disables the timeout")
+	cmd.Flags().StringVar(&args.connectMessage, "connect-message", "", "Message sent as is right after the connection is established, e.g. a token expected by the server")
+	cmd.Flags().StringVar(&args.connectAck, "connect-ack", "", "Regular expression the server's acknowledgement message should match before the connection is ready, the connection fails otherwise")
+	cmd.Flags().DurationVar(&args.connectAckTimeout, "connect-ack-timeout", 10*time.Second, "Time to wait for the acknowledgement message")
+	cmd.Flags().BoolVar(&args.connectOnly, "connect-only", false, "Check that the connection can be established and exit, the exit code is non-zero on failure")
+	cmd.Flags().DurationVar(&args.connectOnlyWait, "connect-only-wait", 0, "Time to wait for the first message from the server in the connect-only mode, it's not awaited by default")
+	cmd.Flags().DurationVar(&args.initialSendDelay, "initial-send-delay", 0, "Delay before the first message sent after the connection is established or re-established")
+	cmd.Flags().DurationVar(&args.idleClose, "idle-close", 0, "Save the output and close the session after the provided period without sent or received messages, 0 disables it")
+	cmd.Flags().DurationVar(&args.maxDuration, "max-duration", 0, "Save the output and close the session after the provided period since the connection is established, regardless of activity, 0 disables it")
+	cmd.Flags().IntVar(&args.recentSize, "recent", core.DefaultRecentSize, "Number of the last sent and received messages kept in memory for the recent command, 0 disables it")
+	cmd.Flags().StringVar(&args.prompt, "prompt", core.DefaultPrompt, "Command mode prompt, Go template with .Host, .Status, .Sent and .Received fields")
 	cmd.Flags().BoolVarP(&args.verbose, "verbose", "v", false, "Verbose output")
-	cmd.Flags().Int64VarP(&args.maxMsgSize, "max-size", "s", ws.DefaultMaxMessageSize, "Maximum message size in bytes, non-positive value will be ignored and default value will be used")
+	cmd.Flags().BoolVar(&args.forceColor, "color", false, "Force colored output even if stdout is not a terminal")
+	cmd.Flags().IntVar(&args.lengthPrefix, "length-prefix", 0, "Size in bytes (1, 2, 4 or 8) of the length prefix used to split binary frames into messages, 0 disables splitting")
+	cmd.Flags().StringVar(&args.lengthPrefixOrder, "length-prefix-order", "big", "Byte order of the length prefix: big or little")
+	cmd.Flags().BoolVar(&args.strictJSON, "strict-json", false, "Fail on messages that look like JSON but can't be parsed instead of showing them as text")
+	cmd.Flags().BoolVar(&args.unwrapJSON, "unwrap-json", false, "Show JSON objects and arrays double-encoded as a JSON string as the inner JSON in the terminal")
+	cmd.Flags().StringSliceVar(&args.envelope, "envelope", []string{}, "Names of the content type and body fields of enveloped messages, e.g. contentType,body, the body is formatted according to the declared content type")
+	cmd.Flags().StringVar(&args.newline, "newline", core.NewlineKeep, "Line endings of sent messages: keep, lf or crlf")
+	cmd.Flags().StringVar(&args.trim, "trim", core.TrimNone, "Whitespace stripped from sent messages: none, space for leading and trailing whitespace or newline for trailing line endings")
+	cmd.Flags().BoolVar(&args.showNewlines, "show-newlines", false, "Show line endings of text messages as ␍ and ␊ in the terminal")
+	cmd.Flags().BoolVar(&args.echoInput, "echo-input", false, "Print every command from the input file, the command mode, hooks and the control pipe prefixed with a colon before it's executed, like set -x")
+	cmd.Flags().BoolVar(&args.summary, "summary", false, "Show every message in the terminal as a single line with its size and a truncated preview")
+	cmd.Flags().BoolVar(&args.table, "table", false, "Show JSON arrays of objects with the same keys in the terminal as an aligned table")
+	cmd.Flags().StringVar(&args.utf8Mode, "utf8", formater.UTF8Replace, "Handling of invalid UTF-8 in messages: replace, reject or escape to show invalid bytes as hex escapes")
+	cmd.Flags().Int64VarP(&args.maxMsgSize, "max-size", "s", ws.DefaultMaxMessageSize, "Maximum size of received messages in bytes, the connection is closed if the server sends a larger one, non-positive value will be ignored and default value will be used")
 
 	args.configDir = cmp.Or(args.configDir, os.Getenv("WSGET_CONFIG_DIR"))
 
 	cmd.AddCommand(initMacroDownloadCommand(args))
+	cmd.AddCommand(initMacroValidateCommand())
+	cmd.AddCommand(initDaemonCommand())
+	cmd.AddCommand(initSendCommand())
+	cmd.AddCommand(initLoadTestCommand())
 
 	return cmd
 }
@@ -95,3 +218,80 @@ func initMacroDownloadCommand(args *flags) *cobra.Command {
 
 	return cmd
 }
+
+// initMacroValidateCommand initializes a Cobra command for validating macro files before they are deployed.
+// It returns a pointer to a Cobra command that accepts one or more macro file paths.
+// It returns an error during execution if any of the provided files contains invalid macros.
+func initMacroValidateCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate <file>...",
+		Short: "Validate macro files and report all problems found",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  runMacroValidateCommand,
+	}
+}
+
+// initDaemonCommand initializes a Cobra command running the daemon that keeps connections open for the send command.
+// It returns a pointer to a Cobra command that runs until it's interrupted.
+// It returns an error during execution if the daemon socket can't be created.
+func initDaemonCommand() *cobra.Command {
+	args := &daemonFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Keep connections open for the send command, so repeated runs don't pay the handshake cost",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runDaemonCommand(cmd.Context(), args)
+		},
+	}
+
+	cmd.Flags().StringVar(&args.socket, "socket", defaultSocket(), "Path of the Unix socket the daemon listens on")
+
+	return cmd
+}
+
+// initSendCommand initializes a Cobra command sending a message through the daemon.
+// It returns a pointer to a Cobra command that accepts the URL and the message.
+// It returns an error during execution if the daemon is not running or no response is received.
+func initSendCommand() *cobra.Command {
+	args := &daemonFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "send [flags] <url> <message>",
+		Short: "Send a message over a connection kept open by the daemon and print the response",
+		Args:  cobra.ExactArgs(2), // url and message
+		RunE: func(cmd *cobra.Command, unnamedArgs []string) error {
+			return runSendCommand(cmd, args, unnamedArgs[0], unnamedArgs[1])
+		},
+	}
+
+	cmd.Flags().StringVar(&args.socket, "socket", defaultSocket(), "Path of the Unix socket the daemon listens on")
+	cmd.Flags().StringSliceVarP(&args.headers, "header", "H", []string{}, "HTTP headers to attach to the request, connections with other headers are not reused")
+	cmd.Flags().DurationVarP(&args.timeout, "timeout", "t", daemon.DefaultTimeout, "Time to wait for the response")
+	cmd.Flags().BoolVarP(&args.insecure, "insecure", "k", false, "Skip SSL certificate verification")
+
+	return cmd
+}
+
+// initLoadTestCommand initializes a Cobra command sending the same payload over many connections at a target rate.
+// It returns a pointer to a Cobra command that accepts the URL, the number of connections, the rate, the duration and the payload.
+// It returns an error during execution if the arguments are invalid or none of the connections could be established.
+func initLoadTestCommand() *cobra.Command {
+	args := &loadTestFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "loadtest [flags] <url> <connections> <rps> <duration> <payload>",
+		Short: "Send the payload over many connections at the target rate and report throughput and errors",
+		Args:  cobra.ExactArgs(5), // url, connections, rps, duration and payload
+		RunE: func(cmd *cobra.Command, unnamedArgs []string) error {
+			return runLoadTestCommand(cmd, args, unnamedArgs)
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&args.headers, "header", "H", []string{}, "HTTP headers to attach to the requests")
+	cmd.Flags().DurationVar(&args.ramp, "ramp", 0, "Time to open the connections evenly over before sending starts, all are opened at once by default")
+	cmd.Flags().BoolVarP(&args.insecure, "insecure", "k", false, "Skip SSL certificate verification")
+
+	return cmd
+}
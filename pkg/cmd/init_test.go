@@ -45,4 +45,58 @@ func TestWsgetInitCommands(t *testing.T) {
 	verboseFlag := cmd.Flags().Lookup("verbose")
 	assert.NotNil(t, verboseFlag)
 	assert.Equal(t, "false", verboseFlag.DefValue)
+
+	colorFlag := cmd.Flags().Lookup("color")
+	assert.NotNil(t, colorFlag)
+	assert.Equal(t, "false", colorFlag.DefValue)
+
+	idleCloseFlag := cmd.Flags().Lookup("idle-close")
+	assert.NotNil(t, idleCloseFlag)
+	assert.Equal(t, "0s", idleCloseFlag.DefValue)
+
+	for _, name := range []string{"only-requests", "only-responses"} {
+		filterFlag := cmd.Flags().Lookup(name)
+		assert.NotNil(t, filterFlag)
+		assert.Equal(t, "false", filterFlag.DefValue)
+	}
+
+	heartbeatFlag := cmd.Flags().Lookup("heartbeat-interval")
+	assert.NotNil(t, heartbeatFlag)
+	assert.Equal(t, "30s", heartbeatFlag.DefValue)
+
+	writeTimeoutFlag := cmd.Flags().Lookup("write-timeout")
+	assert.NotNil(t, writeTimeoutFlag)
+	assert.Equal(t, "0s", writeTimeoutFlag.DefValue)
+
+	sendDelayFlag := cmd.Flags().Lookup("initial-send-delay")
+	assert.NotNil(t, sendDelayFlag)
+	assert.Equal(t, "0s", sendDelayFlag.DefValue)
+
+	promptFlag := cmd.Flags().Lookup("prompt")
+	assert.NotNil(t, promptFlag)
+	assert.Equal(t, ":", promptFlag.DefValue)
+
+	reconnectFlag := cmd.Flags().Lookup("reconnect")
+	assert.NotNil(t, reconnectFlag)
+	assert.Equal(t, "0", reconnectFlag.DefValue)
+
+	reconnectDelayFlag := cmd.Flags().Lookup("reconnect-delay")
+	assert.NotNil(t, reconnectDelayFlag)
+	assert.Equal(t, "1s", reconnectDelayFlag.DefValue)
+
+	utf8Flag := cmd.Flags().Lookup("utf8")
+	assert.NotNil(t, utf8Flag)
+	assert.Equal(t, "replace", utf8Flag.DefValue)
+
+	strictJSONFlag := cmd.Flags().Lookup("strict-json")
+	assert.NotNil(t, strictJSONFlag)
+	assert.Equal(t, "false", strictJSONFlag.DefValue)
+
+	unwrapJSONFlag := cmd.Flags().Lookup("unwrap-json")
+	assert.NotNil(t, unwrapJSONFlag)
+	assert.Equal(t, "false", unwrapJSONFlag.DefValue)
+
+	showRawFlag := cmd.Flags().Lookup("show-raw")
+	assert.NotNil(t, showRawFlag)
+	assert.Equal(t, "false", showRawFlag.DefValue)
 }
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ksysoev/wsget/pkg/loadtest"
+	"github.com/spf13/cobra"
+)
+
+// loadTestFlags holds the options of the loadtest command.
+type loadTestFlags struct {
+	headers  []string
+	ramp     time.Duration
+	insecure bool
+}
+
+// runLoadTestCommand parses the positional arguments, runs the load test and prints the report.
+// It takes rawArgs of type []string, the URL, the number of connections, the rate, the duration and the payload.
+// It returns an error if the arguments are invalid or none of the connections could be established.
+func runLoadTestCommand(cmd *cobra.Command, args *loadTestFlags, rawArgs []string) error {
+	connections, err := strconv.Atoi(rawArgs[1])
+	if err != nil || connections <= 0 {
+		return fmt.Errorf("invalid number of connections: %s", rawArgs[1])
+	}
+
+	rate, err := strconv.Atoi(rawArgs[2])
+	if err != nil || rate <= 0 {
+		return fmt.Errorf("invalid rate: %s", rawArgs[2])
+	}
+
+	duration, err := time.ParseDuration(rawArgs[3])
+	if err != nil || duration <= 0 {
+		return fmt.Errorf("invalid duration: %s", rawArgs[3])
+	}
+
+	report, err := loadtest.Run(cmd.Context(), loadtest.Options{
+		URL:         rawArgs[0],
+		Payload:     rawArgs[4],
+		Headers:     args.headers,
+		Connections: connections,
+		Rate:        rate,
+		Duration:    duration,
+		Ramp:        args.ramp,
+		Insecure:    args.insecure,
+	})
+
+	if report.Connections > 0 || report.ConnectErrs > 0 {
+		_, _ = fmt.Fprint(cmd.OutOrStdout(), report.String())
+	}
+
+	return err
+}
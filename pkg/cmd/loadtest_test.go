@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunLoadTestCommand(t *testing.T) {
+	server := httptest.NewServer(createEchoWSHandler())
+	defer server.Close()
+
+	cmd := initLoadTestCommand()
+	out := &bytes.Buffer{}
+
+	cmd.SetOut(out)
+	cmd.SetArgs([]string{"ws://" + server.Listener.Addr().String(), "2", "50", "200ms", "Hello"})
+
+	require.NoError(t, cmd.ExecuteContext(context.Background()))
+	assert.Contains(t, out.String(), "connections: 2 open, 0 failed\n")
+	assert.Contains(t, out.String(), "send errors: 0 (0.00%)\n")
+}
+
+func TestRunLoadTestCommand_InvalidArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr string
+		args    []string
+	}{
+		{name: "connections", args: []string{"ws://localhost", "none", "10", "1s", "Hello"}, wantErr: "invalid number of connections: none"},
+		{name: "rate", args: []string{"ws://localhost", "1", "0", "1s", "Hello"}, wantErr: "invalid rate: 0"},
+		{name: "duration", args: []string{"ws://localhost", "1", "10", "soon", "Hello"}, wantErr: "invalid duration: soon"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := initLoadTestCommand()
+			cmd.SetArgs(tt.args)
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+
+			assert.EqualError(t, cmd.ExecuteContext(context.Background()), tt.wantErr)
+		})
+	}
+}
@@ -43,3 +43,48 @@ func runMacroDownloadCommand(_ context.Context, args *flags, name *string, unnam
 
 	return macro.Download(path, url)
 }
+
+// runMacroValidateCommand validates every macro file passed as an argument and prints the findings.
+// It takes cmd of type *cobra.Command, used for output, and unnamedArgs of type []string with the file paths.
+// It prints warnings and errors for all files before returning, so problems in one file don't hide the others.
+// It returns an error if at least one of the files is invalid.
+func runMacroValidateCommand(cmd *cobra.Command, unnamedArgs []string) error {
+	out := cmd.OutOrStdout()
+	failed := 0
+
+	for _, path := range unnamedArgs {
+		warnings, err := macro.Validate(path)
+
+		for _, warning := range warnings {
+			_, _ = fmt.Fprintln(out, "warning:", warning)
+		}
+
+		if err != nil {
+			failed++
+
+			for _, e := range unwrapJoined(err) {
+				_, _ = fmt.Fprintln(out, "error:", e)
+			}
+
+			continue
+		}
+
+		_, _ = fmt.Fprintln(out, "ok:", path)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d macro files are invalid", failed, len(unnamedArgs))
+	}
+
+	return nil
+}
+
+// unwrapJoined splits an error created with errors.Join back into its parts.
+// It takes err of type error and returns a slice with the joined errors or the error itself if it wasn't joined.
+func unwrapJoined(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+
+	return []error{err}
+}
@@ -1,11 +1,15 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRunMacroDownloadCommand_NoUrl(t *testing.T) {
@@ -43,3 +47,24 @@ func TestRunMacroDownloadCommand(t *testing.T) {
 	// Assert
 	assert.ErrorContains(t, err, "macro URL is required")
 }
+
+func TestRunMacroValidateCommand(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.yaml")
+	invalid := filepath.Join(dir, "invalid.yaml")
+
+	require.NoError(t, os.WriteFile(valid, []byte("version: \"1\"\ndomains: [example.com]\nmacro:\n  bye: [exit]\n"), 0o600))
+	require.NoError(t, os.WriteFile(invalid, []byte("version: \"1\"\ndomains: [example.com]\nmacro:\n  bad: [\"wait x\", unknown]\n"), 0o600))
+
+	var out bytes.Buffer
+
+	cmd := initMacroValidateCommand()
+	cmd.SetOut(&out)
+
+	err := cmd.RunE(cmd, []string{valid, invalid})
+
+	assert.EqualError(t, err, "1 of 2 macro files are invalid")
+	assert.Contains(t, out.String(), "ok: "+valid)
+	assert.Contains(t, out.String(), "error: "+invalid+`: macro "bad" step 1: invalid timeout: x`)
+	assert.Contains(t, out.String(), "error: "+invalid+`: macro "bad" step 2: unknown command: unknown`)
+}
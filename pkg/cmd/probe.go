@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ksysoev/wsget/pkg/core"
+	"github.com/ksysoev/wsget/pkg/ws"
+)
+
+// runConnectOnly checks that the connection can be established and closes it without starting a session.
+// It takes wsConn of type *ws.Connection, the application ack configured for it is awaited as a part of the handshake,
+// wait of type time.Duration, if it's positive the first message from the server is awaited up to this timeout,
+// and events of type *core.EventWriter, if it's not nil the result is written to it as a connect or an error event.
+// It returns an error if the handshake fails or no message is received in time, nothing is printed otherwise.
+func runConnectOnly(ctx context.Context, wsConn *ws.Connection, wait time.Duration, events *core.EventWriter) error {
+	err := probeConnection(ctx, wsConn, wait)
+	if err != nil {
+		events.Error(err)
+		return err
+	}
+
+	events.Connected(wsConn.Info().URL)
+
+	return nil
+}
+
+// probeConnection establishes the connection, waits for the first message if wait is positive and closes the connection.
+// It returns an error if the handshake fails, the context is canceled or no message is received in time.
+func probeConnection(ctx context.Context, wsConn *ws.Connection, wait time.Duration) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	received := make(chan struct{}, 1)
+
+	wsConn.SetOnMessage(func(context.Context, []byte) {
+		select {
+		case received <- struct{}{}:
+		default:
+		}
+	})
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- wsConn.Connect(ctx)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-done:
+		if err == nil {
+			err = fmt.Errorf("connection closed during the handshake")
+		}
+
+		return fmt.Errorf("unable to connect to the server: %w", err)
+	case <-wsConn.Ready():
+	}
+
+	defer func() {
+		_ = wsConn.Close()
+
+		cancel()
+		<-done
+	}()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-received:
+		return nil
+	case <-time.After(wait):
+		return fmt.Errorf("no message received in %s", wait)
+	}
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/ksysoev/wsget/pkg/core"
+	"github.com/ksysoev/wsget/pkg/ws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createGreetingWSHandler() http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = c.CloseNow() }()
+
+		if err := c.Write(r.Context(), websocket.MessageText, []byte("hello")); err != nil {
+			return
+		}
+
+		_, _, _ = c.Read(r.Context())
+	})
+}
+
+func TestConnectOnly_ExitCode(t *testing.T) {
+	server := httptest.NewServer(createEchoWSHandler())
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		url     string
+		wantErr string
+	}{
+		{
+			name: "successful handshake",
+			url:  "ws://" + server.Listener.Addr().String(),
+		},
+		{
+			name:    "failed handshake",
+			url:     "ws://127.0.0.1:0",
+			wantErr: "unable to connect to the server",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := InitCommands("test")
+			out := &bytes.Buffer{}
+
+			cmd.SetOut(out)
+			cmd.SetErr(out)
+			cmd.SetArgs([]string{"--connect-only", tt.url})
+
+			err := cmd.ExecuteContext(context.Background())
+
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				assert.Empty(t, out.String())
+
+				return
+			}
+
+			assert.ErrorContains(t, err, tt.wantErr)
+			assert.NotContains(t, out.String(), "Usage:")
+		})
+	}
+}
+
+func TestRunConnectOnly_Wait(t *testing.T) {
+	echo := httptest.NewServer(createEchoWSHandler())
+	defer echo.Close()
+
+	greeting := httptest.NewServer(createGreetingWSHandler())
+	defer greeting.Close()
+
+	wsConn, err := ws.New("ws://"+greeting.Listener.Addr().String(), ws.Options{})
+	require.NoError(t, err)
+
+	assert.NoError(t, runConnectOnly(context.Background(), wsConn, time.Second, nil))
+
+	wsConn, err = ws.New("ws://"+echo.Listener.Addr().String(), ws.Options{})
+	require.NoError(t, err)
+
+	err = runConnectOnly(context.Background(), wsConn, 50*time.Millisecond, nil)
+	assert.EqualError(t, err, "no message received in 50ms")
+}
+
+func TestRunConnectOnly_Events(t *testing.T) {
+	server := httptest.NewServer(createEchoWSHandler())
+	defer server.Close()
+
+	url := "ws://" + server.Listener.Addr().String()
+	out := &bytes.Buffer{}
+
+	wsConn, err := ws.New(url, ws.Options{})
+	require.NoError(t, err)
+
+	require.NoError(t, runConnectOnly(context.Background(), wsConn, 0, core.NewEventWriter(out)))
+	assert.Contains(t, out.String(), `"event":"connect","url":"`+url+`"`)
+
+	out.Reset()
+
+	wsConn, err = ws.New("ws://127.0.0.1:0", ws.Options{})
+	require.NoError(t, err)
+
+	assert.Error(t, runConnectOnly(context.Background(), wsConn, 0, core.NewEventWriter(out)))
+	assert.Contains(t, out.String(), `"event":"error"`)
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ksysoev/wsget/pkg/repo/config"
+)
+
+// loadProjectConfig discovers the project config from the working directory up to the home directory
+// and merges it into args, values set with command-line flags win.
+// It takes isSet of type func(string) bool, which reports whether the flag with the given name was set,
+// args of type *flags to update, and unnamedArgs of type []string with the positional arguments.
+// It returns the WebSocket URL from the arguments or from the config, and an error if the config can't be loaded.
+func loadProjectConfig(isSet func(name string) bool, args *flags, unnamedArgs []string) (string, error) {
+	var wsURL string
+	if len(unnamedArgs) > 0 {
+		wsURL = unnamedArgs[0]
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("fail to get working directory: %w", err)
+	}
+
+	home, _ := os.UserHomeDir()
+
+	cfg, err := config.Discover(wd, home)
+	if err != nil {
+		return "", fmt.Errorf("fail to load project config: %w", err)
+	}
+
+	if cfg == nil {
+		return wsURL, nil
+	}
+
+	mergeProjectConfig(cfg, isSet, args)
+
+	if wsURL == "" {
+		wsURL = cfg.URL
+	}
+
+	return wsURL, nil
+}
+
+// mergeProjectConfig copies values from the project config into args for options not set with flags.
+// Headers are merged by name, so a header from the config is sent unless a header with the same name is set with a flag.
+func mergeProjectConfig(cfg *config.Config, isSet func(name string) bool, args *flags) {
+	if cfg.MacroDir != "" {
+		args.macroDir = cfg.MacroDir
+	}
+
+	if cfg.Format != "" {
+		args.contentType = cfg.Format
+	}
+
+	if cfg.UTF8 != "" && !isSet("utf8") {
+		args.utf8Mode = cfg.UTF8
+	}
+
+	set := make(map[string]bool, len(args.headers))
+	for _, h := range args.headers {
+		set[headerName(h)] = true
+	}
+
+	for _, h := range cfg.Headers {
+		if !set[headerName(h)] {
+			args.headers = append(args.headers, h)
+		}
+	}
+}
+
+// headerName returns the canonical name of the header in "Name: value" form.
+func headerName(header string) string {
+	name, _, _ := strings.Cut(header, ":")
+	return strings.ToLower(strings.TrimSpace(name))
+}
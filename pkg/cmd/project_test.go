@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ksysoev/wsget/pkg/repo/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeProjectConfig(t *testing.T) {
+	cfg := &config.Config{
+		URL:      "wss://example.com",
+		MacroDir: "/project/macros",
+		Format:   "json",
+		UTF8:     "escape",
+		Headers:  []string{"Authorization: Bearer config", "X-Project: 1"},
+	}
+
+	tests := []struct {
+		args     *flags
+		expected *flags
+		name     string
+		setFlags []string
+	}{
+		{
+			name: "config fills defaults",
+			args: &flags{utf8Mode: "replace"},
+			expected: &flags{
+				utf8Mode:    "escape",
+				macroDir:    "/project/macros",
+				contentType: "json",
+				headers:     []string{"Authorization: Bearer config", "X-Project: 1"},
+			},
+		},
+		{
+			name:     "flags win",
+			args:     &flags{utf8Mode: "reject", headers: []string{"authorization: Bearer flag"}},
+			setFlags: []string{"utf8", "header"},
+			expected: &flags{
+				utf8Mode:    "reject",
+				macroDir:    "/project/macros",
+				contentType: "json",
+				headers:     []string{"authorization: Bearer flag", "X-Project: 1"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isSet := func(name string) bool {
+				for _, f := range tt.setFlags {
+					if f == name {
+						return true
+					}
+				}
+
+				return false
+			}
+
+			mergeProjectConfig(cfg, isSet, tt.args)
+
+			assert.Equal(t, tt.expected, tt.args)
+		})
+	}
+}
+
+func TestLoadProjectConfig(t *testing.T) {
+	home := t.TempDir()
+	project := filepath.Join(home, "project")
+	nested := filepath.Join(project, "nested")
+
+	require.NoError(t, os.MkdirAll(nested, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(project, config.FileName), []byte(`
+url: wss://project.example.com
+headers: ["X-Project: 1"]
+`), 0o600))
+
+	t.Setenv("HOME", home)
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	require.NoError(t, os.Chdir(nested))
+
+	defer func() { require.NoError(t, os.Chdir(wd)) }()
+
+	notSet := func(string) bool { return false }
+
+	args := &flags{}
+	wsURL, err := loadProjectConfig(notSet, args, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "wss://project.example.com", wsURL)
+	assert.Equal(t, []string{"X-Project: 1"}, args.headers)
+
+	wsURL, err = loadProjectConfig(notSet, &flags{}, []string{"wss://flag.example.com"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "wss://flag.example.com", wsURL)
+}
@@ -0,0 +1,37 @@
+package core
+
+import "sync/atomic"
+
+// banner discards the first messages received after every connect, e.g. a verbose greeting of the server.
+type banner struct {
+	left  atomic.Int64
+	count int64
+}
+
+// reset starts discarding the configured number of messages again, it's called when the connection is established.
+func (b *banner) reset() {
+	b.left.Store(b.count)
+}
+
+// skip reports whether the received message belongs to the banner and should be discarded.
+func (b *banner) skip() bool {
+	for {
+		left := b.left.Load()
+		if left <= 0 {
+			return false
+		}
+
+		if b.left.CompareAndSwap(left, left-1) {
+			return true
+		}
+	}
+}
+
+// SetSkipBanner discards the first messages received after every connect and reconnect,
+// they are not shown, recorded or counted.
+// It takes n of type int, the number of messages to discard, non-positive value disables discarding.
+// It should be called before the connection is established.
+func (c *CLI) SetSkipBanner(n int) {
+	c.banner.count = int64(max(n, 0))
+	c.banner.reset()
+}
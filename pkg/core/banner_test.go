@@ -0,0 +1,86 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBanner_Skip(t *testing.T) {
+	b := &banner{count: 2}
+
+	assert.False(t, b.skip(), "nothing is skipped before the connection is established")
+
+	b.reset()
+
+	assert.True(t, b.skip())
+	assert.True(t, b.skip())
+	assert.False(t, b.skip())
+
+	b.reset()
+
+	assert.True(t, b.skip())
+}
+
+func TestCLIRun_SkipBanner(t *testing.T) {
+	var (
+		onMessage      func(context.Context, []byte)
+		onStatusChange func(context.Context, string, error)
+	)
+
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().SetOnMessage(mock.Anything).Run(func(cb func(context.Context, []byte)) { onMessage = cb })
+	wsConn.EXPECT().SetOnStatusChange(mock.Anything).Run(func(cb func(context.Context, string, error)) { onStatusChange = cb })
+	wsConn.EXPECT().SetOnFrameError(mock.Anything)
+
+	editor := NewMockEditor(t)
+	editor.EXPECT().SetInput(mock.Anything)
+
+	exitCmd := NewMockExecuter(t)
+	exitCmd.EXPECT().Execute(mock.Anything).Return(nil, ErrInterrupted)
+
+	factory := NewMockCommandFactory(t)
+	factory.EXPECT().Create(mock.Anything).RunAndReturn(func(raw string) (Executer, error) {
+		if raw == "exit" {
+			return exitCmd, nil
+		}
+
+		printCmd := NewMockExecuter(t)
+		printCmd.EXPECT().Execute(mock.Anything).RunAndReturn(func(exCtx ExecutionContext) (Executer, error) {
+			return nil, exCtx.PrintToFile(raw)
+		})
+
+		return printCmd, nil
+	})
+
+	file := &bytes.Buffer{}
+	cli := NewCLI(factory, wsConn, &bytes.Buffer{}, editor, NewMockFormater(t))
+	cli.SetSkipBanner(2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		onStatusChange(ctx, StatusConnected, nil)
+		onMessage(ctx, []byte("banner 1"))
+		onMessage(ctx, []byte("banner 2"))
+		onMessage(ctx, []byte("first"))
+		onStatusChange(ctx, StatusReconnecting, assert.AnError)
+		onStatusChange(ctx, StatusConnected, nil)
+		onMessage(ctx, []byte("banner 1"))
+		onMessage(ctx, []byte("banner 2"))
+		onMessage(ctx, []byte("second"))
+		cli.OnKeyEvent(KeyEvent{Key: KeyCtrlC})
+	}()
+
+	err := cli.Run(ctx, RunOptions{OutputFile: file})
+	assert.ErrorIs(t, err, ErrInterrupted)
+
+	assert.NotContains(t, file.String(), "banner")
+	assert.Contains(t, file.String(), "print Response first")
+	assert.Contains(t, file.String(), "print Response second")
+	assert.Equal(t, int64(2), cli.received.Load())
+}
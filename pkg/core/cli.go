@@ -2,8 +2,13 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"text/template"
 	"time"
 
 	"github.com/fatih/color"
@@ -17,31 +22,103 @@ const (
 
 	ClearTerminal = "\u001B[H\u001B[2J"
 	WelcomMessage = "Use Enter to input request and send it, Ctrl+C to exit"
+
+	DefaultPrompt = ":"
+
+	StatusConnected    = "connected"
+	StatusReconnecting = "reconnecting"
+	StatusClosed       = "closed"
 )
 
 var (
 	ErrInterrupted = fmt.Errorf("interrupted")
+	// ErrAborted stops the running commands and returns to the prompt without closing the session.
+	ErrAborted = fmt.Errorf("aborted")
 )
 
 type CLI struct {
-	formater    Formater
-	wsConn      ConnectionHandler
-	editor      Editor
-	inputStream chan KeyEvent
-	messages    chan Message
-	output      io.Writer
-	commands    chan Executer
-	cmdFactory  CommandFactory
+	formater      Formater
+	wsConn        ConnectionHandler
+	editor        Editor
+	output        io.Writer
+	cmdFactory    CommandFactory
+	messages      chan Message
+	control       chan Message
+	inputStream   chan KeyEvent
+	commands      chan Executer
+	remote        chan string
+	recent        *recentMessages
+	dedup         *dedup
+	events        *EventWriter
+	startedAt     time.Time
+	responseTimes responseTimes
+	outbound      []Middleware
+	inbound       []Middleware
+	// carried are the markers pushed out of the full data buffer, see deliver.
+	carried []string
+	// controlPattern matches control messages delivered on the control path, nil disables it.
+	controlPattern *regexp.Regexp
+	lastResponse   atomic.Pointer[Message]
+	vars           variables
+	latency        latency
+	banner         banner
+	lastActivity   atomic.Int64
+	sent           atomic.Int64
+	received       atomic.Int64
+	dataDropped    atomic.Int64
+	carriedMu      sync.Mutex
+	reconnecting   atomic.Bool
+	// sendDelayPending is set when the connection is (re)established and cleared by the first send after it.
+	sendDelayPending atomic.Bool
 }
 
 type RunOptions struct {
-	OutputFile io.Writer
-	Commands   []Executer
+	OutputFile         io.Writer
+	Clock              Clock
+	Correlator         Correlator
+	Prompt             string
+	Newline            string
+	Trim               string
+	Commands           []Executer
+	Sinks              []io.Writer
+	AutoCloseAfterIdle time.Duration
+	InitialSendDelay   time.Duration
+	MaxDuration        time.Duration
+	OnlyRequests       bool
+	OnlyResponses      bool
+	ShowRaw            bool
+	ContinueOnError    bool
+	EchoInput          bool
+}
+
+// ConnectionInfo describes the last handshake of the connection.
+type ConnectionInfo struct {
+	ResponseHeaders map[string][]string
+	URL             string
+	RemoteAddr      string
+	TLSVersion      string
+	CipherSuite     string
+	Subprotocol     string
+	Subprotocols    []string
+	TLSResumed      bool
+}
+
+// PromptData holds the values available to the command prompt template.
+type PromptData struct {
+	Host     string
+	Status   string
+	Sent     int64
+	Received int64
 }
 
 type Formater interface {
 	FormatMessage(msgType string, msgData string) (string, error)
 	FormatForFile(msgType string, msgData string) (string, error)
+	SetContentType(contentType string) error
+	SetFocus(path string)
+	Summary() bool
+	Options() map[string]string
+	SetOption(name, value string) error
 }
 
 type CommandFactory interface {
@@ -51,17 +128,53 @@ type CommandFactory interface {
 type ExecutionContext interface {
 	Print(data string, attr ...color.Attribute) error
 	PrintToFile(data string) error
+	RecordMessage(msg Message) error
+	AddSink(path, format string) error
+	ShouldRecord(msgType MessageType) bool
+	ShowRaw() bool
+	SummaryMode() bool
+	ContinueOnError() bool
+	SetRecording(enabled bool) error
 	FormatMessage(msg Message, noColor bool) (string, error)
 	SendRequest(req string) error
+	SendBinary(data []byte) error
+	SetSkipSSLVerification(skip bool)
+	Reconnect() error
+	ConnectionInfo() ConnectionInfo
+	ResponseLatency() (time.Duration, bool)
+	SetThrottle(interval time.Duration)
+	SetMessageHook(command string)
+	SetResponseDelay(base, jitter time.Duration)
+	ThrottleResponse() (show bool, dropped int)
+	Pause() bool
+	Resume() (Executer, bool)
 	WaitForResponse(timeout time.Duration) (Message, error)
+	WaitForClose(timeout time.Duration) (msg Message, closed bool, err error)
+	WaitForInterrupt(timeout time.Duration) (bool, error)
 	EditorMode(initBuffer string) (string, error)
-	CommandMode(initBuffer string) (string, error)
+	CommandMode(prompt, initBuffer string) (string, error)
+	Prompt() (string, error)
 	CreateCommand(raw string) (Executer, error)
+	SetContentType(contentType string) error
+	SetFocus(path string)
+	FormatOptions() map[string]string
+	SetFormatOption(name, value string) error
+	LastRequest() (string, bool)
+	LastResponse() (Message, bool)
+	RecentMessages(n int) []Message
+	SetVariable(name, value string)
+	ExpandVariables(data string) string
+	NormalizeNewlines(data string) string
+	TrimPayload(data string) string
+	Clock() Clock
+	Correlator() Correlator
+	Context() context.Context
+	LimitTime(timeout time.Duration) (restore func())
 }
 
 type Editor interface {
 	Edit(ctx context.Context, initBuffer string) (string, error)
-	CommandMode(ctx context.Context, initBuffer string) (string, error)
+	CommandMode(ctx context.Context, prompt, initBuffer string) (string, error)
 	SetInput(input <-chan KeyEvent)
 }
 
@@ -71,7 +184,16 @@ type Executer interface {
 
 type ConnectionHandler interface {
 	SetOnMessage(func(context.Context, []byte))
+	SetOnStatusChange(func(ctx context.Context, status string, err error))
+	SetOnFrameError(func(ctx context.Context, err error))
 	Send(ctx context.Context, msg string) error
+	SendBinary(ctx context.Context, data []byte) error
+	SetSkipSSLVerification(skip bool)
+	Info() ConnectionInfo
+	Hostname() string
+	Status() string
+	Done() <-chan struct{}
+	Reconnect(ctx context.Context) error
 }
 
 // NewCLI creates a new CLI instance with the given wsConn, input, and output.
@@ -86,14 +208,25 @@ func NewCLI(cmdFactory CommandFactory, wsConn ConnectionHandler, output io.Write
 		messages:    make(chan Message),
 		output:      output,
 		commands:    make(chan Executer, CommandsLimit),
+		remote:      make(chan string),
+		recent:      newRecentMessages(DefaultRecentSize),
 		cmdFactory:  cmdFactory,
+		startedAt:   time.Now(),
 	}
 
+	c.touch()
+
 	wsConn.SetOnMessage(func(ctx context.Context, msg []byte) {
-		c.onMessage(ctx, Message{
-			Data: string(msg),
-			Type: Response,
-		})
+		if c.banner.skip() {
+			return
+		}
+
+		c.latency.schedule(ctx, msg, c.receive)
+	})
+
+	wsConn.SetOnStatusChange(c.onStatusChange)
+	wsConn.SetOnFrameError(func(ctx context.Context, err error) {
+		c.deliverMarker(ctx, fmt.Sprintf("--- dropped malformed frame: %v ---", err))
 	})
 
 	editor.SetInput(c.inputStream)
@@ -101,17 +234,132 @@ func NewCLI(cmdFactory CommandFactory, wsConn ConnectionHandler, output io.Write
 	return c
 }
 
+// receive handles a message received from the connection once its artificial delay, if any, elapsed.
+// The message is transformed by the inbound middlewares, checked for duplicates, recorded and passed to the session.
+func (c *CLI) receive(ctx context.Context, msg []byte) {
+	resp := Message{
+		Data: c.transformInbound(ctx, string(msg)),
+		Type: Response,
+	}
+
+	c.touch()
+	c.received.Add(1)
+
+	duplicate, suppressed := c.dedup.check(resp.Data, time.Now())
+	if duplicate {
+		return
+	}
+
+	if suppressed > 0 {
+		c.deliverMarker(ctx, fmt.Sprintf("--- %d duplicate messages suppressed ---", suppressed))
+	}
+
+	c.events.message(resp)
+	c.lastResponse.Store(&resp)
+	c.recent.add(resp)
+	c.onMessage(ctx, resp)
+}
+
+// SetRecentSize sets the number of the last sent and received messages kept in memory for the recent command.
+// It takes size of type int, 0 disables keeping messages, messages kept so far are dropped.
+// It should be called before the connection is established.
+func (c *CLI) SetRecentSize(size int) {
+	c.recent = newRecentMessages(size)
+}
+
+// SetDedupWindow enables suppressing consecutive identical received messages.
+// It takes window of type time.Duration, a message equal to the last shown one is suppressed if it arrives within
+// the window after it, the number of suppressed messages is reported before the next shown message.
+// Non-positive window disables suppressing. It should be called before the connection is established.
+func (c *CLI) SetDedupWindow(window time.Duration) {
+	c.dedup = &dedup{window: window}
+}
+
+// SetEventWriter enables the structured event stream.
+// It takes events of type *EventWriter, connection status changes, sent and received messages are written to it
+// as JSON objects, one per line. It should be called before the connection is established.
+func (c *CLI) SetEventWriter(events *EventWriter) {
+	c.events = events
+}
+
 func (c *CLI) OnKeyEvent(event KeyEvent) {
 	c.inputStream <- event
 }
 
-func (c *CLI) onMessage(ctx context.Context, msg Message) {
+// OnCommand queues a command received from outside of the terminal, e.g. from a control pipe.
+// It takes ctx of type context.Context and raw of type string, the command as it would be entered in command mode.
+// Commands are executed by the Run loop one at a time, so they don't interleave with commands entered in the terminal.
+// It blocks until the command is queued or the context is canceled.
+func (c *CLI) OnCommand(ctx context.Context, raw string) {
 	select {
-	case c.messages <- msg:
+	case c.remote <- raw:
 	case <-ctx.Done():
 	}
 }
 
+func (c *CLI) onMessage(ctx context.Context, msg Message) {
+	c.deliver(ctx, msg)
+}
+
+// transformInbound applies the inbound middlewares to the received data.
+// If a middleware fails, the error is reported with a marker and the data is returned as received.
+func (c *CLI) transformInbound(ctx context.Context, data string) string {
+	if len(c.inbound) == 0 {
+		return data
+	}
+
+	transformed, err := applyMiddleware(c.inbound, data)
+	if err == nil {
+		return transformed
+	}
+
+	c.deliverMarker(ctx, fmt.Sprintf("--- %v ---", err))
+
+	return data
+}
+
+// ExpandVariables replaces ${name} references in data with values from the session variable store.
+// It takes data of type string, references to variables that are not set are left unchanged.
+// It returns the data with all known variables expanded, so it can be used to resolve handshake headers on reconnect.
+func (c *CLI) ExpandVariables(data string) string {
+	return c.vars.Expand(data)
+}
+
+// onStatusChange records markers around connection drops, so the output shows where messages could have been missed.
+// It takes ctx of type context.Context, status of type string with the new connection state and err with the reason of the change.
+// A disconnect marker is queued when the connection starts reconnecting and a reconnect marker once it is connected again.
+// The banner of the server is discarded again after every connect.
+// Markers are delivered on the same path as messages, so they keep their order relative to received messages.
+// If the event stream is enabled, every change is written to it as well.
+func (c *CLI) onStatusChange(ctx context.Context, status string, err error) {
+	if c.events != nil {
+		c.events.status(status, c.wsConn.Info().URL, err)
+	}
+
+	var text string
+
+	switch status {
+	case StatusReconnecting:
+		c.reconnecting.Store(true)
+
+		text = fmt.Sprintf("--- disconnected at %s: %v ---", time.Now().Format(time.RFC3339Nano), err)
+	case StatusConnected:
+		c.banner.reset()
+
+		if !c.reconnecting.Swap(false) {
+			return
+		}
+
+		c.sendDelayPending.Store(true)
+
+		text = fmt.Sprintf("--- reconnected at %s ---", time.Now().Format(time.RFC3339Nano))
+	default:
+		return
+	}
+
+	c.deliverMarker(ctx, text)
+}
+
 // Run runs the CLI with the provided options.
 // It listens for user input and executes commands accordingly.
 func (c *CLI) Run(ctx context.Context, opts RunOptions) error {
@@ -128,15 +376,96 @@ func (c *CLI) Run(ctx context.Context, opts RunOptions) error {
 		c.commands <- cmd
 	}
 
+	prompt, err := newPromptTemplate(opts.Prompt)
+	if err != nil {
+		return err
+	}
+
 	exCtx := newExecutionContext(ctx, c, opts.OutputFile)
+	defer exCtx.closeSinks()
+
+	for _, w := range opts.Sinks {
+		exCtx.sinks = append(exCtx.sinks, &sink{w: w, format: SinkFormatText})
+	}
+
+	exCtx.prompt = prompt
+	exCtx.onlyRequests = opts.OnlyRequests
+	exCtx.onlyResponses = opts.OnlyResponses
+	exCtx.showRaw = opts.ShowRaw
+	exCtx.continueOnError = opts.ContinueOnError
+	exCtx.echoInput = opts.EchoInput
+	exCtx.clock = opts.Clock
+	exCtx.correlator = opts.Correlator
+	exCtx.newline = opts.Newline
+	exCtx.trim = opts.Trim
+	c.latency.setClock(exCtx.Clock())
+	exCtx.initialSendDelay = opts.InitialSendDelay
+
+	c.sendDelayPending.Store(true)
+
+	idle := newIdleTimer(opts.AutoCloseAfterIdle)
+	defer idle.Stop()
+
+	// The session is started once the connection is established, so the maximum duration is counted from the connect.
+	var deadline <-chan time.Time
+	if opts.MaxDuration > 0 {
+		deadline = exCtx.Clock().After(opts.MaxDuration)
+	}
 
 	for {
+		// Received messages are not taken while the pause buffer is full, so reading from the connection blocks.
+		messages, control := c.messages, c.control
+		if exCtx.pause.full() {
+			messages, control = nil, nil
+		}
+
+		// Control messages are handled before anything else, so they are not starved by data messages.
 		select {
+		case msg := <-control:
+			if err := c.handleMessage(exCtx, msg); err != nil {
+				return err
+			}
+
+			continue
+		default:
+		}
+
+		select {
+		case <-idle.C:
+			if left := opts.AutoCloseAfterIdle - time.Since(c.lastActive()); left > 0 {
+				idle.Reset(left)
+				continue
+			}
+
+			_, _ = fmt.Fprintf(c.output, "Session was idle for %s, closing\n", opts.AutoCloseAfterIdle)
+
+			cmd, err := c.cmdFactory.Create("exit")
+			if err != nil {
+				return fmt.Errorf("fail to create exit command: %w", err)
+			}
+
+			c.commands <- cmd
+		case <-deadline:
+			deadline = nil
+
+			_, _ = fmt.Fprintf(c.output, "Session reached the maximum duration of %s, closing\n", opts.MaxDuration)
+
+			cmd, err := c.cmdFactory.Create("exit")
+			if err != nil {
+				return fmt.Errorf("fail to create exit command: %w", err)
+			}
+
+			c.commands <- cmd
 		case cmd := <-c.commands:
 			var err error
 			for cmd != nil {
 				cmd, err = cmd.Execute(exCtx)
 
+				if errors.Is(err, ErrAborted) {
+					_ = exCtx.Print(err.Error()+"\n", color.FgYellow)
+					break
+				}
+
 				if err != nil {
 					return err
 				}
@@ -177,18 +506,24 @@ func (c *CLI) Run(ctx context.Context, opts RunOptions) error {
 				}
 			}
 
-		case msg, ok := <-c.messages:
+		case raw := <-c.remote:
+			c.commands <- &remoteCommand{raw: raw}
+		case msg := <-control:
+			if err := c.handleMessage(exCtx, msg); err != nil {
+				return err
+			}
+		case msg, ok := <-messages:
 			if !ok {
 				return nil
 			}
 
-			cmd, err := c.cmdFactory.Create(fmt.Sprintf("print %s %s", msg.Type.String(), msg.Data))
-
-			if err != nil {
-				return fmt.Errorf("fail to create print command: %w", err)
+			if m := c.pendingMarkers(); m != nil {
+				c.commands <- m
 			}
 
-			c.commands <- cmd
+			if err := c.handleMessage(exCtx, msg); err != nil {
+				return err
+			}
 
 		case <-ctx.Done():
 			return nil
@@ -196,6 +531,66 @@ func (c *CLI) Run(ctx context.Context, opts RunOptions) error {
 	}
 }
 
+// handleMessage queues the commands printing the received message and running the message hook,
+// unless the output is paused, in which case the message is held until it's resumed.
+// It returns an error if the commands can't be created.
+func (c *CLI) handleMessage(exCtx *executionContext, msg Message) error {
+	if exCtx.hold(msg) {
+		return nil
+	}
+
+	cmds, err := exCtx.messageCommands(msg)
+	if err != nil {
+		return err
+	}
+
+	for _, cmd := range cmds {
+		c.commands <- cmd
+	}
+
+	return nil
+}
+
+// newPromptTemplate parses the format of the command prompt.
+// It takes format of type string, a text/template rendered with PromptData, an empty format falls back to DefaultPrompt.
+// It returns the parsed template and an error if the format is not a valid template.
+func newPromptTemplate(format string) (*template.Template, error) {
+	if format == "" {
+		format = DefaultPrompt
+	}
+
+	tmpl, err := template.New("prompt").Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prompt format: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// touch records the current time as the moment of the last activity in the session.
+func (c *CLI) touch() {
+	c.lastActivity.Store(time.Now().UnixNano())
+}
+
+// lastActive returns the time of the last message sent or received in the session.
+func (c *CLI) lastActive() time.Time {
+	return time.Unix(0, c.lastActivity.Load())
+}
+
+// newIdleTimer creates a timer that fires after the provided idle period.
+// It takes d of type time.Duration, if d is not positive the returned timer never fires.
+// It returns a pointer to the created time.Timer.
+func newIdleTimer(d time.Duration) *time.Timer {
+	if d > 0 {
+		return time.NewTimer(d)
+	}
+
+	t := time.NewTimer(time.Hour)
+	t.Stop()
+
+	return t
+}
+
 // hideCursor hides the cursor in the terminal output.
 func (c *CLI) hideCursor() {
 	_, _ = fmt.Fprint(c.output, HideCursor)
@@ -225,6 +620,84 @@ func (mt MessageType) String() string {
 }
 
 type Message struct {
-	Data string      `json:"data"`
-	Type MessageType `json:"type"`
+	Data string `json:"data"`
+	// marker is the text of a marker delivered among received messages, see deliverMarker.
+	marker string
+	Type   MessageType `json:"type"`
+}
+
+// marker is a command that records a connection lifecycle event in the output and the output file.
+type marker struct {
+	text string
+}
+
+// Execute prints the marker text to the output and the output file.
+// It returns an error if printing to the output fails.
+func (m *marker) Execute(exCtx ExecutionContext) (Executer, error) {
+	if err := exCtx.Print(m.text+"\n", color.FgYellow); err != nil {
+		return nil, err
+	}
+
+	return nil, exCtx.PrintToFile(m.text + "\n")
+}
+
+// printMarker prints the marker delivered on the message path by a command waiting for messages,
+// so the marker keeps its place among the messages read by the command.
+// It returns false if msg is not a marker, and an error if printing fails.
+func (c *executionContext) printMarker(msg Message) (bool, error) {
+	if msg.marker == "" {
+		return false, nil
+	}
+
+	_, err := (&marker{text: msg.marker}).Execute(c)
+
+	return true, err
+}
+
+// printPendingMarkers prints the markers pushed out of the full data buffer and the number of dropped data messages,
+// if any, by a command waiting for messages, before the message it has just taken from the buffer.
+// It returns an error if printing fails.
+func (c *executionContext) printPendingMarkers() error {
+	m := c.cli.pendingMarkers()
+	if m == nil {
+		return nil
+	}
+
+	_, err := m.Execute(c)
+
+	return err
+}
+
+// echoCommand prints the raw form of a command before it's executed, like set -x of a shell,
+// so a transcript of a scripted session shows which command produced which output.
+type echoCommand struct {
+	cmd Executer
+	raw string
+}
+
+// Execute prints the command prefixed with the command mode colon and returns the command to be executed.
+// It returns an error if printing fails.
+func (e *echoCommand) Execute(exCtx ExecutionContext) (Executer, error) {
+	if err := exCtx.Print(": "+e.raw+"\n", color.Faint); err != nil {
+		return nil, err
+	}
+
+	return e.cmd, nil
+}
+
+// remoteCommand is a command received from outside of the terminal, it's created when it's its turn to be executed.
+type remoteCommand struct {
+	raw string
+}
+
+// Execute creates the command from its raw form and returns it to be executed.
+// If the command is invalid, it prints the reason instead of interrupting the session.
+// It returns an error only if printing fails.
+func (r *remoteCommand) Execute(exCtx ExecutionContext) (Executer, error) {
+	cmd, err := exCtx.CreateCommand(r.raw)
+	if err != nil {
+		return nil, exCtx.Print(fmt.Sprintf("Invalid command: %s\n", r.raw), color.FgRed)
+	}
+
+	return cmd, nil
 }
@@ -1,19 +1,27 @@
 package core
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"os"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewCLI(t *testing.T) {
 	wsConn := NewMockConnectionHandler(t)
 	wsConn.EXPECT().Send(context.Background(), mock.Anything).Return(nil)
 	wsConn.EXPECT().SetOnMessage(mock.Anything)
+	wsConn.EXPECT().SetOnStatusChange(mock.Anything)
+	wsConn.EXPECT().SetOnFrameError(mock.Anything)
 
 	factory := NewMockCommandFactory(t)
 
@@ -61,6 +69,8 @@ func TestNewCLI(t *testing.T) {
 func TestNewCLIRunWithCommands(t *testing.T) {
 	wsConn := NewMockConnectionHandler(t)
 	wsConn.EXPECT().SetOnMessage(mock.Anything)
+	wsConn.EXPECT().SetOnStatusChange(mock.Anything)
+	wsConn.EXPECT().SetOnFrameError(mock.Anything)
 
 	factory := NewMockCommandFactory(t)
 
@@ -83,3 +93,447 @@ func TestNewCLIRunWithCommands(t *testing.T) {
 		t.Errorf("Exit.Execute() error = %v, wantErr interupted", err)
 	}
 }
+
+func TestCLIRun_Abort(t *testing.T) {
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().SetOnMessage(mock.Anything)
+	wsConn.EXPECT().SetOnStatusChange(mock.Anything)
+	wsConn.EXPECT().SetOnFrameError(mock.Anything)
+
+	editor := NewMockEditor(t)
+	editor.EXPECT().SetInput(mock.Anything)
+
+	output := &bytes.Buffer{}
+	cli := NewCLI(NewMockCommandFactory(t), wsConn, output, editor, NewMockFormater(t))
+
+	skippedCmd := NewMockExecuter(t)
+
+	abortCmd := NewMockExecuter(t)
+	abortCmd.EXPECT().Execute(mock.Anything).Return(skippedCmd, fmt.Errorf("%w: no ack", ErrAborted))
+
+	exitCmd := NewMockExecuter(t)
+	exitCmd.EXPECT().Execute(mock.Anything).Return(nil, ErrInterrupted)
+
+	err := cli.Run(context.Background(), RunOptions{Commands: []Executer{abortCmd, exitCmd}})
+
+	assert.ErrorIs(t, err, ErrInterrupted)
+	assert.Contains(t, output.String(), "aborted: no ack")
+}
+
+func TestCLIRun_OutputFileWriteError(t *testing.T) {
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().SetOnMessage(mock.Anything)
+	wsConn.EXPECT().SetOnStatusChange(mock.Anything)
+	wsConn.EXPECT().SetOnFrameError(mock.Anything)
+
+	editor := NewMockEditor(t)
+	editor.EXPECT().SetInput(mock.Anything)
+
+	output := &bytes.Buffer{}
+	cli := NewCLI(NewMockCommandFactory(t), wsConn, output, editor, NewMockFormater(t))
+
+	writeCmd := NewMockExecuter(t)
+	writeCmd.EXPECT().Execute(mock.Anything).RunAndReturn(func(exCtx ExecutionContext) (Executer, error) {
+		return nil, exCtx.PrintToFile("data")
+	}).Twice()
+
+	exitCmd := NewMockExecuter(t)
+	exitCmd.EXPECT().Execute(mock.Anything).Return(nil, ErrInterrupted)
+
+	err := cli.Run(context.Background(), RunOptions{
+		OutputFile: &failingWriter{},
+		Commands:   []Executer{writeCmd, writeCmd, exitCmd},
+	})
+
+	assert.ErrorIs(t, err, ErrInterrupted)
+	assert.Contains(t, output.String(), "Fail to write to output file")
+}
+
+func TestCLIRun_AutoCloseAfterIdle(t *testing.T) {
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().SetOnMessage(mock.Anything)
+	wsConn.EXPECT().SetOnStatusChange(mock.Anything)
+	wsConn.EXPECT().SetOnFrameError(mock.Anything)
+
+	editor := NewMockEditor(t)
+	editor.EXPECT().SetInput(mock.Anything)
+
+	exitCmd := NewMockExecuter(t)
+	exitCmd.EXPECT().Execute(mock.Anything).Return(nil, ErrInterrupted)
+
+	factory := NewMockCommandFactory(t)
+	factory.EXPECT().Create("exit").Return(exitCmd, nil)
+
+	output := &bytes.Buffer{}
+	file := &bytes.Buffer{}
+	cli := NewCLI(factory, wsConn, output, editor, NewMockFormater(t))
+
+	saveCmd := NewMockExecuter(t)
+	saveCmd.EXPECT().Execute(mock.Anything).RunAndReturn(func(exCtx ExecutionContext) (Executer, error) {
+		return nil, exCtx.PrintToFile("transcript")
+	})
+
+	start := time.Now()
+
+	err := cli.Run(context.Background(), RunOptions{
+		OutputFile:         file,
+		Commands:           []Executer{saveCmd},
+		AutoCloseAfterIdle: 50 * time.Millisecond,
+	})
+
+	assert.ErrorIs(t, err, ErrInterrupted)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	assert.Contains(t, output.String(), "Session was idle for 50ms, closing")
+	assert.Equal(t, "transcript\n", file.String())
+}
+
+func TestCLIRun_MaxDuration(t *testing.T) {
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().SetOnMessage(mock.Anything)
+	wsConn.EXPECT().SetOnStatusChange(mock.Anything)
+	wsConn.EXPECT().SetOnFrameError(mock.Anything)
+
+	editor := NewMockEditor(t)
+	editor.EXPECT().SetInput(mock.Anything)
+
+	exitCmd := NewMockExecuter(t)
+	exitCmd.EXPECT().Execute(mock.Anything).Return(nil, ErrInterrupted)
+
+	factory := NewMockCommandFactory(t)
+	factory.EXPECT().Create("exit").Return(exitCmd, nil)
+
+	expired := make(chan time.Time, 1)
+	expired <- time.Now()
+
+	clock := NewMockClock(t)
+	clock.EXPECT().After(5 * time.Minute).Return(expired)
+
+	output := &bytes.Buffer{}
+	file := &bytes.Buffer{}
+	cli := NewCLI(factory, wsConn, output, editor, NewMockFormater(t))
+
+	saveCmd := NewMockExecuter(t)
+	saveCmd.EXPECT().Execute(mock.Anything).RunAndReturn(func(exCtx ExecutionContext) (Executer, error) {
+		return nil, exCtx.PrintToFile("transcript")
+	})
+
+	err := cli.Run(context.Background(), RunOptions{
+		OutputFile:         file,
+		Clock:              clock,
+		Commands:           []Executer{saveCmd},
+		MaxDuration:        5 * time.Minute,
+		AutoCloseAfterIdle: time.Hour,
+	})
+
+	assert.ErrorIs(t, err, ErrInterrupted)
+	assert.Contains(t, output.String(), "Session reached the maximum duration of 5m0s, closing")
+	assert.NotContains(t, output.String(), "idle")
+	assert.Equal(t, "transcript\n", file.String())
+}
+
+func TestCLIRun_AutoCloseAfterIdle_Activity(t *testing.T) {
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().SetOnMessage(mock.Anything)
+	wsConn.EXPECT().SetOnStatusChange(mock.Anything)
+	wsConn.EXPECT().SetOnFrameError(mock.Anything)
+	wsConn.EXPECT().Send(mock.Anything, "ping").Return(nil)
+
+	editor := NewMockEditor(t)
+	editor.EXPECT().SetInput(mock.Anything)
+
+	exitCmd := NewMockExecuter(t)
+	exitCmd.EXPECT().Execute(mock.Anything).Return(nil, ErrInterrupted)
+
+	factory := NewMockCommandFactory(t)
+	factory.EXPECT().Create("exit").Return(exitCmd, nil)
+
+	cli := NewCLI(factory, wsConn, &bytes.Buffer{}, editor, NewMockFormater(t))
+
+	sendCmd := NewMockExecuter(t)
+	sendCmd.EXPECT().Execute(mock.Anything).RunAndReturn(func(exCtx ExecutionContext) (Executer, error) {
+		time.Sleep(60 * time.Millisecond)
+
+		return nil, exCtx.SendRequest("ping")
+	})
+
+	start := time.Now()
+
+	err := cli.Run(context.Background(), RunOptions{
+		Commands:           []Executer{sendCmd},
+		AutoCloseAfterIdle: 100 * time.Millisecond,
+	})
+
+	assert.ErrorIs(t, err, ErrInterrupted)
+	assert.GreaterOrEqual(t, time.Since(start), 160*time.Millisecond)
+}
+
+func TestCLIRun_InvalidPrompt(t *testing.T) {
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().SetOnMessage(mock.Anything)
+	wsConn.EXPECT().SetOnStatusChange(mock.Anything)
+	wsConn.EXPECT().SetOnFrameError(mock.Anything)
+
+	editor := NewMockEditor(t)
+	editor.EXPECT().SetInput(mock.Anything)
+
+	cli := NewCLI(NewMockCommandFactory(t), wsConn, &bytes.Buffer{}, editor, NewMockFormater(t))
+
+	err := cli.Run(context.Background(), RunOptions{Prompt: "{{.Host"})
+
+	assert.ErrorContains(t, err, "invalid prompt format")
+}
+
+func TestCLIRun_ReconnectMarkers(t *testing.T) {
+	var (
+		onMessage      func(context.Context, []byte)
+		onStatusChange func(context.Context, string, error)
+	)
+
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().SetOnMessage(mock.Anything).Run(func(cb func(context.Context, []byte)) { onMessage = cb })
+	wsConn.EXPECT().SetOnStatusChange(mock.Anything).Run(func(cb func(context.Context, string, error)) { onStatusChange = cb })
+	wsConn.EXPECT().SetOnFrameError(mock.Anything)
+
+	editor := NewMockEditor(t)
+	editor.EXPECT().SetInput(mock.Anything)
+
+	exitCmd := NewMockExecuter(t)
+	exitCmd.EXPECT().Execute(mock.Anything).Return(nil, ErrInterrupted)
+
+	factory := NewMockCommandFactory(t)
+	factory.EXPECT().Create(mock.Anything).RunAndReturn(func(raw string) (Executer, error) {
+		if raw == "exit" {
+			return exitCmd, nil
+		}
+
+		printCmd := NewMockExecuter(t)
+		printCmd.EXPECT().Execute(mock.Anything).RunAndReturn(func(exCtx ExecutionContext) (Executer, error) {
+			return nil, exCtx.PrintToFile(raw)
+		})
+
+		return printCmd, nil
+	})
+
+	output := &bytes.Buffer{}
+	file := &bytes.Buffer{}
+	cli := NewCLI(factory, wsConn, output, editor, NewMockFormater(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		onStatusChange(ctx, StatusConnected, nil)
+		onMessage(ctx, []byte("before"))
+		onStatusChange(ctx, StatusReconnecting, assert.AnError)
+		onStatusChange(ctx, StatusConnected, nil)
+		onMessage(ctx, []byte("after"))
+		cli.OnKeyEvent(KeyEvent{Key: KeyCtrlC})
+	}()
+
+	err := cli.Run(ctx, RunOptions{OutputFile: file})
+	assert.ErrorIs(t, err, ErrInterrupted)
+
+	lines := strings.Split(strings.TrimSpace(file.String()), "\n")
+	require.Len(t, lines, 6)
+	assert.Equal(t, "print Response before", lines[0])
+	assert.Regexp(t, `^--- disconnected at \S+: `+assert.AnError.Error()+` ---$`, lines[1])
+	assert.Empty(t, lines[2])
+	assert.Regexp(t, `^--- reconnected at \S+ ---$`, lines[3])
+	assert.Empty(t, lines[4])
+	assert.Equal(t, "print Response after", lines[5])
+
+	assert.Contains(t, output.String(), "--- disconnected at ")
+	assert.Contains(t, output.String(), "--- reconnected at ")
+}
+
+func TestCLIRun_ReconnectMarkers_Buffered(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		var (
+			onMessage      func(context.Context, []byte)
+			onStatusChange func(context.Context, string, error)
+		)
+
+		wsConn := NewMockConnectionHandler(t)
+		wsConn.EXPECT().SetOnMessage(mock.Anything).Run(func(cb func(context.Context, []byte)) { onMessage = cb })
+		wsConn.EXPECT().SetOnStatusChange(mock.Anything).Run(func(cb func(context.Context, string, error)) { onStatusChange = cb })
+		wsConn.EXPECT().SetOnFrameError(mock.Anything)
+
+		editor := NewMockEditor(t)
+		editor.EXPECT().SetInput(mock.Anything)
+
+		factory := NewMockCommandFactory(t)
+		factory.EXPECT().Create(mock.Anything).RunAndReturn(func(raw string) (Executer, error) {
+			printCmd := NewMockExecuter(t)
+			printCmd.EXPECT().Execute(mock.Anything).RunAndReturn(func(exCtx ExecutionContext) (Executer, error) {
+				if err := exCtx.PrintToFile(raw + "\n"); err != nil || !strings.HasSuffix(raw, "after") {
+					return nil, err
+				}
+
+				// The last message ends the session, so every message and marker before it is handled.
+				return nil, ErrInterrupted
+			})
+
+			return printCmd, nil
+		})
+
+		file := &bytes.Buffer{}
+		cli := NewCLI(factory, wsConn, &bytes.Buffer{}, editor, NewMockFormater(t))
+
+		// Received messages are buffered, so they are queued before the Run loop reads them.
+		cli.SetControlPattern(regexp.MustCompile(`^control$`), 10)
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		go func() {
+			onMessage(ctx, []byte("first"))
+			onMessage(ctx, []byte("second"))
+			onStatusChange(ctx, StatusReconnecting, assert.AnError)
+			onStatusChange(ctx, StatusConnected, nil)
+			onMessage(ctx, []byte("after"))
+		}()
+
+		err := cli.Run(ctx, RunOptions{OutputFile: file})
+		assert.ErrorIs(t, err, ErrInterrupted)
+
+		cancel()
+
+		lines := strings.Fields(file.String())
+		var order []string
+
+		for _, line := range lines {
+			switch line {
+			case "first", "second", "after", "disconnected", "reconnected":
+				order = append(order, line)
+			}
+		}
+
+		require.Equal(t, []string{"first", "second", "disconnected", "reconnected", "after"}, order)
+	}
+}
+
+func TestCLI_ExpandVariables(t *testing.T) {
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().SetOnMessage(mock.Anything)
+	wsConn.EXPECT().SetOnStatusChange(mock.Anything)
+	wsConn.EXPECT().SetOnFrameError(mock.Anything)
+
+	editor := NewMockEditor(t)
+	editor.EXPECT().SetInput(mock.Anything)
+
+	cli := NewCLI(NewMockCommandFactory(t), wsConn, &bytes.Buffer{}, editor, NewMockFormater(t))
+	exCtx := newExecutionContext(context.Background(), cli, nil)
+
+	assert.Equal(t, "Bearer ${token}", cli.ExpandVariables("Bearer ${token}"))
+
+	exCtx.SetVariable("token", "abc")
+	assert.Equal(t, "Bearer abc", cli.ExpandVariables("Bearer ${token}"))
+
+	exCtx.SetVariable("token", "refreshed")
+	assert.Equal(t, "Bearer refreshed", cli.ExpandVariables("Bearer ${token}"))
+}
+
+func TestCLIRun_EchoInput(t *testing.T) {
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().SetOnMessage(mock.Anything)
+	wsConn.EXPECT().SetOnStatusChange(mock.Anything)
+	wsConn.EXPECT().SetOnFrameError(mock.Anything)
+
+	editor := NewMockEditor(t)
+	editor.EXPECT().SetInput(mock.Anything)
+
+	sendCmd := NewMockExecuter(t)
+	sendCmd.EXPECT().Execute(mock.Anything).RunAndReturn(func(exCtx ExecutionContext) (Executer, error) {
+		return nil, exCtx.Print("pong\n")
+	})
+
+	exitCmd := NewMockExecuter(t)
+	exitCmd.EXPECT().Execute(mock.Anything).Return(nil, ErrInterrupted)
+
+	factory := NewMockCommandFactory(t)
+	factory.EXPECT().Create("send ping").Return(sendCmd, nil)
+	factory.EXPECT().Create("exit").Return(exitCmd, nil)
+
+	output := &bytes.Buffer{}
+	cli := NewCLI(factory, wsConn, output, editor, NewMockFormater(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		cli.OnCommand(ctx, "send ping")
+		cli.OnCommand(ctx, "exit")
+	}()
+
+	err := cli.Run(ctx, RunOptions{EchoInput: true})
+
+	assert.ErrorIs(t, err, ErrInterrupted)
+	assert.Contains(t, output.String(), ": send ping\npong\n: exit\n")
+}
+
+func TestCLIRun_OnCommand(t *testing.T) {
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().SetOnMessage(mock.Anything)
+	wsConn.EXPECT().SetOnStatusChange(mock.Anything)
+	wsConn.EXPECT().SetOnFrameError(mock.Anything)
+
+	editor := NewMockEditor(t)
+	editor.EXPECT().SetInput(mock.Anything)
+
+	executed := make(chan string, 1)
+
+	sendCmd := NewMockExecuter(t)
+	sendCmd.EXPECT().Execute(mock.Anything).RunAndReturn(func(ExecutionContext) (Executer, error) {
+		executed <- "send ping"
+		return nil, nil
+	})
+
+	exitCmd := NewMockExecuter(t)
+	exitCmd.EXPECT().Execute(mock.Anything).Return(nil, ErrInterrupted)
+
+	factory := NewMockCommandFactory(t)
+	factory.EXPECT().Create("send ping").Return(sendCmd, nil)
+	factory.EXPECT().Create("unknown").Return(nil, assert.AnError)
+	factory.EXPECT().Create("exit").Return(exitCmd, nil)
+
+	output := &bytes.Buffer{}
+	cli := NewCLI(factory, wsConn, output, editor, NewMockFormater(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		cli.OnCommand(ctx, "send ping")
+		<-executed
+		cli.OnCommand(ctx, "unknown")
+		cli.OnCommand(ctx, "exit")
+	}()
+
+	err := cli.Run(ctx, RunOptions{})
+
+	assert.ErrorIs(t, err, ErrInterrupted)
+	assert.Contains(t, output.String(), "Invalid command: unknown")
+}
+
+func TestNewCLI_FrameErrorMarker(t *testing.T) {
+	var onFrameError func(context.Context, error)
+
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().SetOnMessage(mock.Anything)
+	wsConn.EXPECT().SetOnStatusChange(mock.Anything)
+	wsConn.EXPECT().SetOnFrameError(mock.Anything).Run(func(cb func(context.Context, error)) { onFrameError = cb })
+
+	editor := NewMockEditor(t)
+	editor.EXPECT().SetInput(mock.Anything)
+
+	cli := NewCLI(NewMockCommandFactory(t), wsConn, os.Stdout, editor, NewMockFormater(t))
+	cli.messages = make(chan Message, 1)
+
+	onFrameError(context.Background(), errors.New("fail to split binary frame: truncated length prefix"))
+
+	select {
+	case m := <-cli.messages:
+		assert.Equal(t, Message{marker: "--- dropped malformed frame: fail to split binary frame: truncated length prefix ---"}, m)
+	default:
+		t.Fatal("expected frame error marker")
+	}
+}
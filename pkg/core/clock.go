@@ -0,0 +1,28 @@
+package core
+
+import "time"
+
+// Clock provides the current time and timers to time-dependent commands,
+// so timeouts and sleeps can be tested without real waiting.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	Sleep(d time.Duration)
+}
+
+type realClock struct{}
+
+// Now returns the current local time.
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// After waits for the duration to elapse and then sends the current time on the returned channel.
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Sleep pauses the current goroutine for at least the duration d.
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
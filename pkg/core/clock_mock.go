@@ -0,0 +1,164 @@
+// Code generated by mockery v2.50.0. DO NOT EDIT.
+
+//go:build !compile
+
+package core
+
+import (
+	time "time"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockClock is an autogenerated mock type for the Clock type
+type MockClock struct {
+	mock.Mock
+}
+
+type MockClock_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockClock) EXPECT() *MockClock_Expecter {
+	return &MockClock_Expecter{mock: &_m.Mock}
+}
+
+// After provides a mock function with given fields: d
+func (_m *MockClock) After(d time.Duration) <-chan time.Time {
+	ret := _m.Called(d)
+
+	if len(ret) == 0 {
+		panic("no return value specified for After")
+	}
+
+	var r0 <-chan time.Time
+	if rf, ok := ret.Get(0).(func(time.Duration) <-chan time.Time); ok {
+		r0 = rf(d)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan time.Time)
+		}
+	}
+
+	return r0
+}
+
+// MockClock_After_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'After'
+type MockClock_After_Call struct {
+	*mock.Call
+}
+
+// After is a helper method to define mock.On call
+//   - d time.Duration
+func (_e *MockClock_Expecter) After(d interface{}) *MockClock_After_Call {
+	return &MockClock_After_Call{Call: _e.mock.On("After", d)}
+}
+
+func (_c *MockClock_After_Call) Run(run func(d time.Duration)) *MockClock_After_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockClock_After_Call) Return(_a0 <-chan time.Time) *MockClock_After_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockClock_After_Call) RunAndReturn(run func(time.Duration) <-chan time.Time) *MockClock_After_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Now provides a mock function with no fields
+func (_m *MockClock) Now() time.Time {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Now")
+	}
+
+	var r0 time.Time
+	if rf, ok := ret.Get(0).(func() time.Time); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(time.Time)
+	}
+
+	return r0
+}
+
+// MockClock_Now_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Now'
+type MockClock_Now_Call struct {
+	*mock.Call
+}
+
+// Now is a helper method to define mock.On call
+func (_e *MockClock_Expecter) Now() *MockClock_Now_Call {
+	return &MockClock_Now_Call{Call: _e.mock.On("Now")}
+}
+
+func (_c *MockClock_Now_Call) Run(run func()) *MockClock_Now_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockClock_Now_Call) Return(_a0 time.Time) *MockClock_Now_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockClock_Now_Call) RunAndReturn(run func() time.Time) *MockClock_Now_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Sleep provides a mock function with given fields: d
+func (_m *MockClock) Sleep(d time.Duration) {
+	_m.Called(d)
+}
+
+// MockClock_Sleep_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Sleep'
+type MockClock_Sleep_Call struct {
+	*mock.Call
+}
+
+// Sleep is a helper method to define mock.On call
+//   - d time.Duration
+func (_e *MockClock_Expecter) Sleep(d interface{}) *MockClock_Sleep_Call {
+	return &MockClock_Sleep_Call{Call: _e.mock.On("Sleep", d)}
+}
+
+func (_c *MockClock_Sleep_Call) Run(run func(d time.Duration)) *MockClock_Sleep_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockClock_Sleep_Call) Return() *MockClock_Sleep_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockClock_Sleep_Call) RunAndReturn(run func(time.Duration)) *MockClock_Sleep_Call {
+	_c.Run(run)
+	return _c
+}
+
+// NewMockClock creates a new instance of MockClock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockClock(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockClock {
+	mock := &MockClock{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,75 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+// cutArg cuts the first argument of the command from s, leading spaces are skipped.
+// An argument wrapped in single quotes keeps its spaces, \' and \\ inside the quotes stand for a quote and a backslash.
+// It returns the argument and the rest of s after the space following it,
+// or an error if the quote of the argument is not closed.
+func cutArg(s string) (arg, rest string, err error) {
+	s = strings.TrimLeft(s, " ")
+
+	if !strings.HasPrefix(s, "'") {
+		arg, rest, _ = strings.Cut(s, " ")
+		return arg, rest, nil
+	}
+
+	var b strings.Builder
+
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && (s[i+1] == '\'' || s[i+1] == '\\'):
+			i++
+			b.WriteByte(s[i])
+		case s[i] == '\'':
+			rest = s[i+1:]
+			if rest != "" && rest[0] != ' ' {
+				return "", "", fmt.Errorf("unexpected characters after quoted argument: %s", s)
+			}
+
+			return b.String(), strings.TrimPrefix(rest, " "), nil
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+
+	return "", "", fmt.Errorf("unterminated quoted argument: %s", s)
+}
+
+// unquoteArg returns the payload without the quotes if it's wrapped in single quotes as a whole, e.g. 'a b',
+// so payloads can be passed to nested commands unchanged.
+// Payloads that are not a single quoted argument, e.g. JSON or text with a leading apostrophe, are returned as is.
+func unquoteArg(s string) string {
+	if !strings.HasPrefix(s, "'") {
+		return s
+	}
+
+	arg, rest, err := cutArg(s)
+	if err != nil || rest != "" {
+		return s
+	}
+
+	return arg
+}
+
+// SplitArgs splits s into space separated arguments, arguments wrapped in single quotes keep their spaces,
+// e.g. `greet 'hello world'` has two arguments.
+// It returns the arguments or an error if the quote of an argument is not closed.
+func SplitArgs(s string) ([]string, error) {
+	var args []string
+
+	for s = strings.TrimLeft(s, " "); s != ""; s = strings.TrimLeft(s, " ") {
+		arg, rest, err := cutArg(s)
+		if err != nil {
+			return nil, err
+		}
+
+		args = append(args, arg)
+		s = rest
+	}
+
+	return args, nil
+}
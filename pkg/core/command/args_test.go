@@ -0,0 +1,85 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/ksysoev/wsget/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCutArg(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantArg  string
+		wantRest string
+		wantErr  bool
+	}{
+		{name: "plain", input: "3 send hi", wantArg: "3", wantRest: "send hi"},
+		{name: "leading spaces", input: "  3 send", wantArg: "3", wantRest: "send"},
+		{name: "single", input: "3", wantArg: "3"},
+		{name: "quoted", input: `'a b' c`, wantArg: "a b", wantRest: "c"},
+		{name: "escaped quote", input: `'it\'s \\ ok'`, wantArg: `it's \ ok`},
+		{name: "other backslashes are kept", input: `'{"a":"x\ny"}'`, wantArg: `{"a":"x\ny"}`},
+		{name: "unterminated", input: `'a b`, wantErr: true},
+		{name: "characters after quote", input: `'a'b`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			arg, rest, err := cutArg(tt.input)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantArg, arg)
+			assert.Equal(t, tt.wantRest, rest)
+		})
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{name: "empty", input: ""},
+		{name: "plain", input: "a  b c ", want: []string{"a", "b", "c"}},
+		{name: "quoted", input: `'hello world' 'it\'s' x`, want: []string{"hello world", "it's", "x"}},
+		{name: "empty quoted", input: `'' a`, want: []string{"", "a"}},
+		{name: "unterminated", input: `a 'b c`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := SplitArgs(tt.input)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, args)
+		})
+	}
+}
+
+func TestUnquoteArg(t *testing.T) {
+	assert.Equal(t, `{"k": "v with spaces"}`, unquoteArg(`'{"k": "v with spaces"}'`))
+	assert.Equal(t, `{"k": "v"}`, unquoteArg(`{"k": "v"}`))
+	assert.Equal(t, `'a' b`, unquoteArg(`'a' b`))
+	assert.Equal(t, `'unterminated`, unquoteArg(`'unterminated`))
+	assert.Equal(t, " 'a'", unquoteArg(" 'a'"))
+}
+
+func TestUnquoteArg_QuoteArg(t *testing.T) {
+	for _, data := range []string{"plain", "'quoted'", `'it\'s'`, `'\\'`, "'", "'a' b", ""} {
+		assert.Equal(t, data, unquoteArg(core.QuoteArg(data)), data)
+	}
+}
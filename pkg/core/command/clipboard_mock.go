@@ -0,0 +1,89 @@
+// Code generated by mockery v2.50.0. DO NOT EDIT.
+
+//go:build !compile
+
+package command
+
+import mock "github.com/stretchr/testify/mock"
+
+// MockClipboard is an autogenerated mock type for the Clipboard type
+type MockClipboard struct {
+	mock.Mock
+}
+
+type MockClipboard_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockClipboard) EXPECT() *MockClipboard_Expecter {
+	return &MockClipboard_Expecter{mock: &_m.Mock}
+}
+
+// Read provides a mock function with no fields
+func (_m *MockClipboard) Read() (string, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Read")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (string, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockClipboard_Read_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Read'
+type MockClipboard_Read_Call struct {
+	*mock.Call
+}
+
+// Read is a helper method to define mock.On call
+func (_e *MockClipboard_Expecter) Read() *MockClipboard_Read_Call {
+	return &MockClipboard_Read_Call{Call: _e.mock.On("Read")}
+}
+
+func (_c *MockClipboard_Read_Call) Run(run func()) *MockClipboard_Read_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockClipboard_Read_Call) Return(_a0 string, _a1 error) *MockClipboard_Read_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockClipboard_Read_Call) RunAndReturn(run func() (string, error)) *MockClipboard_Read_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockClipboard creates a new instance of MockClipboard. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockClipboard(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockClipboard {
+	mock := &MockClipboard{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
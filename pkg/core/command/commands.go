@@ -1,12 +1,25 @@
 package command
 
 import (
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/ksysoev/wsget/pkg/core"
+	"github.com/ksysoev/wsget/pkg/core/formater"
 	"gopkg.in/yaml.v3"
 )
 
@@ -16,6 +29,12 @@ const (
 	LineClear   = "\x1b[2K"
 	HideCursor  = "\x1b[?25l"
 	ShowCursor  = "\x1b[?25h"
+	ClearScreen = "\x1b[H\x1b[2J"
+
+	shapeExampleLength = 60
+
+	// SeqVariable is the session variable with the number of the command replayed from the input file.
+	SeqVariable = "seq"
 )
 
 type Edit struct {
@@ -55,14 +74,136 @@ func NewSend(request string) *Send {
 }
 
 // Execute sends the request using the WebSocket connection and returns a PrintMsg to print the response message.
+// Session variables referenced as ${name} are expanded, whitespace is trimmed and line endings are normalized
+// before the request is sent.
 // It implements the Execute method of the core.Executer interface.
 func (c *Send) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
-	err := exCtx.SendRequest(c.request)
+	req := exCtx.NormalizeNewlines(exCtx.TrimPayload(exCtx.ExpandVariables(c.request)))
+
+	err := exCtx.SendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPrintMsg(core.Message{Type: core.Request, Data: req}), nil
+}
+
+type SendClip struct {
+	clipboard Clipboard
+}
+
+// NewSendClip creates a new SendClip command that sends the content of the clipboard.
+// It takes clipboard of type Clipboard, which provides the content of the system clipboard.
+// It returns a pointer to a SendClip instance.
+func NewSendClip(clipboard Clipboard) *SendClip {
+	return &SendClip{clipboard: clipboard}
+}
+
+// Execute reads the clipboard and sends its content as is.
+// If the clipboard can't be read, e.g. on a headless system, it prints the reason instead of interrupting the session.
+// It returns a PrintMsg command to print the sent request, or an error if sending or printing fails.
+func (c *SendClip) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	if c.clipboard == nil {
+		return nil, exCtx.Print("Fail to read clipboard: clipboard is not available\n", color.FgRed)
+	}
+
+	req, err := c.clipboard.Read()
+	if err != nil {
+		return nil, exCtx.Print(fmt.Sprintf("Fail to read clipboard: %s\n", err), color.FgRed)
+	}
+
+	if req == "" {
+		return nil, exCtx.Print("Clipboard is empty, nothing to send\n", color.FgRed)
+	}
+
+	if err := exCtx.SendRequest(req); err != nil {
+		return nil, err
+	}
+
+	return NewPrintMsg(core.Message{Type: core.Request, Data: req}), nil
+}
+
+type SendBase64 struct {
+	payload string
+}
+
+// NewSendBase64 creates a new SendBase64 command that decodes the base64 payload and sends it as a binary frame.
+// It takes payload of type string, standard base64 with an optional b64: prefix.
+// It returns a pointer to a SendBase64 instance.
+func NewSendBase64(payload string) *SendBase64 {
+	return &SendBase64{payload: payload}
+}
+
+// Execute decodes the payload and sends it as a binary frame.
+// It returns a PrintMsg command to print the decoded payload, or an error if sending fails.
+// If the payload is not valid base64, it returns core.ErrAborted wrapped with the reason, so the running macro
+// or sequence stops, but the session is kept.
+func (c *SendBase64) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(c.payload, formater.Base64Prefix))
 	if err != nil {
+		return nil, fmt.Errorf("%w: invalid base64 payload: %w", core.ErrAborted, err)
+	}
+
+	if err := exCtx.SendBinary(data); err != nil {
 		return nil, err
 	}
 
-	return NewPrintMsg(core.Message{Type: core.Request, Data: c.request}), nil
+	return NewPrintMsg(core.Message{Type: core.Request, Data: string(data)}), nil
+}
+
+type SendGzip struct {
+	payload  string
+	filePath string
+}
+
+// NewSendGzip creates a new SendGzip command that compresses the payload with gzip and sends it as a binary frame.
+// It takes payload of type string, session variables referenced as ${name} are expanded before compression.
+// It returns a pointer to a SendGzip instance.
+func NewSendGzip(payload string) *SendGzip {
+	return &SendGzip{payload: payload}
+}
+
+// NewSendGzipFile creates a new SendGzip command that compresses the content of a file and sends it as a binary frame.
+// It takes filePath of type string, the path to the file with the payload.
+// It returns a pointer to a SendGzip instance.
+func NewSendGzipFile(filePath string) *SendGzip {
+	return &SendGzip{filePath: filePath}
+}
+
+// Execute compresses the payload with gzip and sends it as a binary frame.
+// It returns a PrintMsg command to print the uncompressed payload,
+// or an error if the file can't be read, the payload can't be compressed or sending fails.
+func (c *SendGzip) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	var payload string
+
+	if c.filePath != "" {
+		data, err := os.ReadFile(c.filePath)
+		if err != nil {
+			return nil, fmt.Errorf("fail to read payload file: %w", err)
+		}
+
+		payload = string(data)
+	} else {
+		payload = exCtx.ExpandVariables(c.payload)
+	}
+
+	var buf bytes.Buffer
+
+	zw := gzip.NewWriter(&buf)
+
+	if _, err := zw.Write([]byte(payload)); err != nil {
+		return nil, fmt.Errorf("fail to compress payload: %w", err)
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("fail to compress payload: %w", err)
+	}
+
+	if err := exCtx.SendBinary(buf.Bytes()); err != nil {
+		return nil, err
+	}
+
+	return NewPrintMsg(core.Message{Type: core.Request, Data: payload}), nil
 }
 
 type PrintMsg struct {
@@ -78,19 +219,47 @@ func NewPrintMsg(msg core.Message) *PrintMsg {
 
 // Execute executes the PrintMsg command and returns nil and error.
 // It formats the message and prints it to the output file.
-// If an output file is provided, it writes the formatted message to the file.
+// The first response after a request is annotated with the time elapsed since the request was sent,
+// the annotation is shown only in the terminal. In the summary mode the message follows its direction on the same line.
+// If an output file is provided, it writes the formatted message to the file,
+// unless messages of this direction are filtered out of the recording.
+// Responses dropped by the throttle are not shown in the terminal, but they are still written to the output file,
+// the number of dropped responses is shown before the next shown one.
 func (c *PrintMsg) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	if c.msg.Type == core.Response {
+		show, dropped := exCtx.ThrottleResponse()
+		if !show {
+			return nil, c.record(exCtx)
+		}
+
+		if dropped > 0 {
+			if err := exCtx.Print(fmt.Sprintf("... %d messages dropped\n", dropped), color.FgYellow); err != nil {
+				return nil, fmt.Errorf("fail to print message: %w", err)
+			}
+		}
+	}
+
 	output, err := exCtx.FormatMessage(c.msg, false)
 
 	if err != nil {
 		return nil, fmt.Errorf("fail to format message: %w", err)
 	}
 
+	separator := "\n"
+	if exCtx.SummaryMode() {
+		separator = " "
+	}
+
 	switch c.msg.Type {
 	case core.Request:
-		err = exCtx.Print("->\n", color.FgGreen)
+		err = exCtx.Print("->"+separator, color.FgGreen)
 	case core.Response:
-		err = exCtx.Print("<-\n", color.FgRed)
+		marker := "<-"
+		if elapsed, ok := exCtx.ResponseLatency(); ok {
+			marker += fmt.Sprintf(" (%s)", elapsed.Round(time.Millisecond))
+		}
+
+		err = exCtx.Print(marker+separator, color.FgRed)
 	default:
 		return nil, fmt.Errorf("unsupported message type: %s", c.msg.Type.String())
 	}
@@ -103,16 +272,31 @@ func (c *PrintMsg) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
 		return nil, fmt.Errorf("fail to print message: %w", err)
 	}
 
-	fileOutput, err := exCtx.FormatMessage(c.msg, true)
-	if err != nil {
-		return nil, fmt.Errorf("fail to format message for file: %w", err)
+	if exCtx.ShowRaw() {
+		if err := exCtx.Print(formatRaw(c.msg.Data), color.FgCyan); err != nil {
+			return nil, fmt.Errorf("fail to print message: %w", err)
+		}
+	}
+
+	return nil, c.record(exCtx)
+}
+
+// formatRaw returns the number of bytes of the message data followed by their hexdump.
+func formatRaw(data string) string {
+	return fmt.Sprintf("raw %d bytes\n", len(data)) + hex.Dump([]byte(data))
+}
+
+// record writes the message to the output file, unless messages of this direction are filtered out of the recording.
+func (c *PrintMsg) record(exCtx core.ExecutionContext) error {
+	if !exCtx.ShouldRecord(c.msg.Type) {
+		return nil
 	}
 
-	if err := exCtx.PrintToFile(fileOutput + "\n"); err != nil {
-		return nil, fmt.Errorf("fail to write to output file: %w", err)
+	if err := exCtx.RecordMessage(c.msg); err != nil {
+		return fmt.Errorf("fail to write to output file: %w", err)
 	}
 
-	return nil, nil
+	return nil
 }
 
 type Exit struct{}
@@ -129,6 +313,27 @@ func (c *Exit) Execute(_ core.ExecutionContext) (core.Executer, error) {
 	return nil, core.ErrInterrupted
 }
 
+type Abort struct {
+	message string
+}
+
+// NewAbort creates a new Abort command that stops the running sequence of commands.
+// It takes message of type string, the reason shown when the sequence is stopped, it can be empty.
+// It returns a pointer to an Abort instance.
+func NewAbort(message string) *Abort {
+	return &Abort{message}
+}
+
+// Execute stops the running sequence, macro or repeat and returns to the prompt, the session is not closed.
+// It returns core.ErrAborted wrapped with the message, if it's set.
+func (c *Abort) Execute(_ core.ExecutionContext) (core.Executer, error) {
+	if c.message == "" {
+		return nil, core.ErrAborted
+	}
+
+	return nil, fmt.Errorf("%w: %s", core.ErrAborted, c.message)
+}
+
 type WaitForResp struct {
 	timeout time.Duration
 }
@@ -153,6 +358,156 @@ func (c *WaitForResp) Execute(exCtx core.ExecutionContext) (core.Executer, error
 	return NewPrintMsg(msg), nil
 }
 
+type WaitIdle struct {
+	idle  time.Duration
+	quiet bool
+}
+
+// NewWaitIdle creates a new WaitIdle command that waits until the server stops sending messages.
+// It takes idle of type time.Duration, the period without messages to wait for,
+// and quiet of type bool, if true messages received in the meantime are dropped instead of being printed.
+// It returns a pointer to a WaitIdle instance.
+func NewWaitIdle(idle time.Duration, quiet bool) *WaitIdle {
+	return &WaitIdle{idle: idle, quiet: quiet}
+}
+
+// Execute waits for the idle period, every message received in the meantime restarts the wait.
+// It returns nil once no message arrived for the whole period, the received message and the command to keep waiting
+// if a message arrived, or an error if waiting fails for another reason.
+func (c *WaitIdle) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	msg, err := exCtx.WaitForResponse(c.idle)
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	case c.quiet:
+		return c, nil
+	default:
+		return NewSequence([]core.Executer{NewPrintMsg(msg), c}), nil
+	}
+}
+
+type WaitClose struct {
+	timeout time.Duration
+}
+
+// NewWaitClose creates a new WaitClose command that waits until the server closes the connection.
+// It takes timeout of type time.Duration, the maximum time to wait, 0 means no timeout.
+// It returns a pointer to a WaitClose instance.
+func NewWaitClose(timeout time.Duration) *WaitClose {
+	return &WaitClose{timeout}
+}
+
+// Execute waits for the server to close the connection.
+// Messages received while waiting are printed and the command keeps waiting for the rest of the timeout.
+// It returns nil once the connection is closed and an error if the connection is still open when the timeout elapses.
+func (c *WaitClose) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	clock := exCtx.Clock()
+	start := clock.Now()
+
+	msg, closed, err := exCtx.WaitForClose(c.timeout)
+	if err != nil {
+		return nil, fmt.Errorf("connection was not closed: %w", err)
+	}
+
+	if closed {
+		return nil, nil
+	}
+
+	timeout := c.timeout
+
+	if timeout > 0 {
+		if timeout -= clock.Now().Sub(start); timeout <= 0 {
+			return nil, fmt.Errorf("connection was not closed: %w", context.DeadlineExceeded)
+		}
+	}
+
+	return NewSequence([]core.Executer{NewPrintMsg(msg), NewWaitClose(timeout)}), nil
+}
+
+type Request struct {
+	request string
+	timeout time.Duration
+}
+
+// NewRequest creates a new Request command that sends a request and waits for the response.
+// It takes timeout of type time.Duration, the maximum time to wait for the response, and request of type string to be sent.
+// It returns a pointer to a Request instance.
+func NewRequest(timeout time.Duration, request string) *Request {
+	return &Request{request: request, timeout: timeout}
+}
+
+// Execute sends the request, prints it and waits for the response within the configured timeout.
+// Sending and waiting happen in a single command, so the response can't be handled by anything else in between.
+// Session variables referenced as ${name} are expanded, whitespace is trimmed and line endings are normalized
+// before the request is sent.
+// If the session has a correlator and the request has an ID, the response is the first message with the same ID,
+// other messages received in the meantime are printed as usual.
+// It returns a PrintMsg command with the received response or an error if sending, printing or waiting fails.
+func (c *Request) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	req := exCtx.NormalizeNewlines(exCtx.TrimPayload(exCtx.ExpandVariables(c.request)))
+
+	if err := exCtx.SendRequest(req); err != nil {
+		return nil, err
+	}
+
+	reqMsg := core.Message{Type: core.Request, Data: req}
+
+	if _, err := NewPrintMsg(reqMsg).Execute(exCtx); err != nil {
+		return nil, err
+	}
+
+	correlator := exCtx.Correlator()
+	if correlator == nil {
+		return c.waitResponse(exCtx)
+	}
+
+	id, ok := correlator.ID(reqMsg)
+	if !ok {
+		return c.waitResponse(exCtx)
+	}
+
+	var deadline time.Time
+	if c.timeout > 0 {
+		deadline = exCtx.Clock().Now().Add(c.timeout)
+	}
+
+	for {
+		timeout := c.timeout
+		if !deadline.IsZero() {
+			if timeout = deadline.Sub(exCtx.Clock().Now()); timeout <= 0 {
+				return nil, context.DeadlineExceeded
+			}
+		}
+
+		msg, err := exCtx.WaitForResponse(timeout)
+		if err != nil {
+			return nil, err
+		}
+
+		if respID, ok := correlator.ID(msg); ok && respID == id {
+			return NewPrintMsg(msg), nil
+		}
+
+		if _, err := NewPrintMsg(msg).Execute(exCtx); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// waitResponse waits for the next received message within the configured timeout.
+// It returns a PrintMsg command with the message or an error if waiting fails.
+func (c *Request) waitResponse(exCtx core.ExecutionContext) (core.Executer, error) {
+	msg, err := exCtx.WaitForResponse(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPrintMsg(msg), nil
+}
+
 type CmdEdit struct{}
 
 // NewCmdEdit initializes and returns a new instance of CmdEdit.
@@ -163,9 +518,14 @@ func NewCmdEdit() *CmdEdit {
 }
 
 // Execute executes the CmdEdit and returns a core.Executer and an error.
-// It prompts the user to edit a command and returns the corresponding Command object.
+// It renders the configured prompt, prompts the user to edit a command and returns the corresponding Command object.
 func (c *CmdEdit) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
-	rawCmd, err := exCtx.CommandMode("")
+	prompt, err := exCtx.Prompt()
+	if err != nil {
+		return nil, err
+	}
+
+	rawCmd, err := exCtx.CommandMode(prompt, "")
 	if err != nil {
 		return nil, err
 	}
@@ -197,19 +557,81 @@ func NewSequence(subCommands []core.Executer) *Sequence {
 
 // Execute executes the command sequence by iterating over all sub-commands and executing them recursively.
 // It takes a core.ExecutionContext as input and returns a core.Executer and an error.
+// The sequence stops at the first failed sub-command, e.g. at the abort command, and returns its error.
+// If the session continues on errors, failed sub-commands are reported and the rest of them are executed,
+// ErrSequenceFailed with all failures is returned at the end. Abort and exit still stop the sequence.
 func (c *Sequence) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
-	for _, cmd := range c.subCommands {
+	var failed []error
+
+	for i, cmd := range c.subCommands {
 		for cmd != nil {
 			var err error
-			if cmd, err = cmd.Execute(exCtx); err != nil {
+			if cmd, err = cmd.Execute(exCtx); err == nil {
+				continue
+			}
+
+			if errors.Is(err, core.ErrInterrupted) || errors.Is(err, core.ErrAborted) || !exCtx.ContinueOnError() {
+				return nil, err
+			}
+
+			failed = append(failed, err)
+
+			// Failures of a nested sequence are already reported by it.
+			if errors.As(err, &ErrSequenceFailed{}) {
+				break
+			}
+
+			if err := exCtx.Print(fmt.Sprintf("Step %d failed: %s\n", i+1, err), color.FgRed); err != nil {
 				return nil, err
 			}
 		}
 	}
 
+	if len(failed) > 0 {
+		return nil, ErrSequenceFailed{Errors: failed, Steps: len(c.subCommands)}
+	}
+
 	return nil, nil
 }
 
+type TimeLimit struct {
+	cmd     core.Executer
+	name    string
+	timeout time.Duration
+}
+
+// NewTimeLimit creates a new TimeLimit command that runs a macro within its timeout.
+// It takes name of type string, the name of the macro, timeout of type time.Duration and cmd of type core.Executer,
+// the executer of the macro.
+// It returns a pointer to a TimeLimit instance.
+func NewTimeLimit(name string, timeout time.Duration, cmd core.Executer) *TimeLimit {
+	return &TimeLimit{cmd: cmd, name: name, timeout: timeout}
+}
+
+// Execute runs the macro with all commands it returns, waiting, sleeping and sending commands fail once the timeout elapses.
+// It returns core.ErrAborted with the name of the macro and the timeout if the macro failed after the timeout elapsed,
+// so the session goes back to the prompt, or the error of the macro otherwise.
+func (c *TimeLimit) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	restore := exCtx.LimitTime(c.timeout)
+	defer restore()
+
+	limited := exCtx.Context()
+
+	var err error
+
+	for cmd := c.cmd; cmd != nil; {
+		if cmd, err = cmd.Execute(exCtx); err != nil {
+			break
+		}
+	}
+
+	if err != nil && errors.Is(limited.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("%w: macro %s exceeded its timeout of %s", core.ErrAborted, c.name, c.timeout)
+	}
+
+	return nil, err
+}
+
 type InputFileCommand struct {
 	filePath string
 }
@@ -224,16 +646,11 @@ func NewInputFileCommand(filePath string) *InputFileCommand {
 // Execute executes the InputFileCommand and returns a core.Executer and an error.
 // It reads the file and executes the commands in the file.
 func (c *InputFileCommand) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
-	data, err := os.ReadFile(c.filePath)
+	rawCommands, err := readInputFile(c.filePath)
 	if err != nil {
 		return nil, err
 	}
 
-	var rawCommands []string
-	if err := yaml.Unmarshal(data, &rawCommands); err != nil {
-		return nil, err
-	}
-
 	cmds := make([]core.Executer, 0, len(rawCommands))
 
 	for _, rawCommand := range rawCommands {
@@ -248,6 +665,81 @@ func (c *InputFileCommand) Execute(exCtx core.ExecutionContext) (core.Executer,
 	return NewSequence(cmds), nil
 }
 
+// readInputFile reads the YAML list of commands from the input file.
+func readInputFile(filePath string) ([]string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var rawCommands []string
+	if err := yaml.Unmarshal(data, &rawCommands); err != nil {
+		return nil, err
+	}
+
+	return rawCommands, nil
+}
+
+type ReplayLoop struct {
+	filePath string
+	loops    int
+	resetSeq bool
+}
+
+// NewReplayLoop creates a new ReplayLoop command that replays the commands of the input file in a loop.
+// It takes filePath of type string, the input file, loops of type int, the number of replays, 0 replays until stopped,
+// and resetSeq of type bool, if true the ${seq} counter restarts on every replay, otherwise it continues.
+// It returns a pointer to a ReplayLoop instance.
+func NewReplayLoop(filePath string, loops int, resetSeq bool) *ReplayLoop {
+	return &ReplayLoop{filePath: filePath, loops: loops, resetSeq: resetSeq}
+}
+
+// Execute replays the commands of the input file from the top after the last one, until the number of loops is reached.
+// Before every command the seq session variable is set to its number counted from 1, so requests can use ${seq}.
+// Commands are created anew for every replay, so they don't share state between replays.
+// The loop stops cleanly once the session context is canceled.
+// It returns an error if the file can't be read, a command is invalid or fails.
+func (c *ReplayLoop) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	rawCommands, err := readInputFile(c.filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, raw := range rawCommands {
+		if _, err := exCtx.CreateCommand(raw); err != nil {
+			return nil, err
+		}
+	}
+
+	seq := 0
+
+	for loop := 0; c.loops == 0 || loop < c.loops; loop++ {
+		if c.resetSeq {
+			seq = 0
+		}
+
+		for _, raw := range rawCommands {
+			if exCtx.Context().Err() != nil {
+				return nil, nil
+			}
+
+			seq++
+			exCtx.SetVariable(SeqVariable, strconv.Itoa(seq))
+
+			cmd, err := exCtx.CreateCommand(raw)
+			for err == nil && cmd != nil {
+				cmd, err = cmd.Execute(exCtx)
+			}
+
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return nil, nil
+}
+
 type RepeatCommand struct {
 	subCommand core.Executer
 	times      int
@@ -276,6 +768,66 @@ func (c *RepeatCommand) Execute(exCtx core.ExecutionContext) (core.Executer, err
 	return nil, nil
 }
 
+type Retry struct {
+	subCommand core.Executer
+	attempts   int
+	delay      time.Duration
+}
+
+// NewRetry creates a new Retry command that re-runs a sub-command until it succeeds.
+// It takes attempts of type int, the maximum number of runs, delay of type time.Duration, the pause between runs,
+// and subCommand of type core.Executer to run. It returns a pointer to a Retry instance.
+func NewRetry(attempts int, delay time.Duration, subCommand core.Executer) *Retry {
+	return &Retry{
+		subCommand: subCommand,
+		attempts:   attempts,
+		delay:      delay,
+	}
+}
+
+// Execute runs the sub-command until it completes without an error or the attempts are exhausted.
+// Interruptions and aborts are not retried, they stop the command right away.
+// It returns the error of the last attempt if all of them fail.
+func (c *Retry) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	var err error
+
+	for attempt := 1; attempt <= c.attempts; attempt++ {
+		if err = runChain(exCtx, c.subCommand); err == nil {
+			return nil, nil
+		}
+
+		if errors.Is(err, core.ErrInterrupted) || errors.Is(err, core.ErrAborted) || attempt == c.attempts {
+			break
+		}
+
+		msg := fmt.Sprintf("Attempt %d of %d failed: %s, retrying in %s\n", attempt, c.attempts, err, c.delay)
+		if printErr := exCtx.Print(msg, color.FgYellow); printErr != nil {
+			return nil, printErr
+		}
+
+		select {
+		case <-exCtx.Context().Done():
+			return nil, err
+		case <-exCtx.Clock().After(c.delay):
+		}
+	}
+
+	return nil, err
+}
+
+// runChain executes the command and the commands it returns until the chain ends.
+// It returns the first error of the chain.
+func runChain(exCtx core.ExecutionContext, cmd core.Executer) error {
+	for cmd != nil {
+		var err error
+		if cmd, err = cmd.Execute(exCtx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 type SleepCommand struct {
 	duration time.Duration
 }
@@ -288,9 +840,1015 @@ func NewSleepCommand(duration time.Duration) *SleepCommand {
 }
 
 // Execute executes the SleepCommand and returns a core.Executer and an error.
-// It sleeps for the specified duration.
-func (c *SleepCommand) Execute(_ core.ExecutionContext) (core.Executer, error) {
-	time.Sleep(c.duration)
+// It sleeps for the specified duration on the session clock.
+// It returns the error of the context if it's done before the duration elapses, e.g. the macro timeout is exceeded.
+func (c *SleepCommand) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	ctx := exCtx.Context()
 
-	return nil, nil
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-exCtx.Clock().After(c.duration):
+		return nil, nil
+	}
+}
+
+type Watch struct {
+	subCommand core.Executer
+	title      string
+	interval   time.Duration
+	runs       int
+	highlight  bool
+}
+
+// NewWatch creates a new Watch command that runs a sub-command repeatedly and redraws its output, like the Unix watch.
+// It takes interval of type time.Duration, the pause between runs, runs of type int, the number of runs,
+// non-positive value runs the sub-command until it's stopped, highlight of type bool, which marks lines changed
+// since the previous run, title of type string, the sub-command as entered, and subCommand of type core.Executer.
+// It returns a pointer to a Watch instance.
+func NewWatch(interval time.Duration, runs int, highlight bool, title string, subCommand core.Executer) *Watch {
+	return &Watch{
+		subCommand: subCommand,
+		title:      title,
+		interval:   interval,
+		runs:       runs,
+		highlight:  highlight,
+	}
+}
+
+// Execute runs the sub-command every interval until Esc, Ctrl+C or Ctrl+D is pressed, the number of runs is reached
+// or the session context is canceled.
+// The output of every run is collected and drawn on a cleared screen under a header with the interval and the sub-command.
+// It returns an error if the sub-command or printing fails.
+func (c *Watch) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	var previous []string
+
+	for run := 1; exCtx.Context().Err() == nil; run++ {
+		out := &watchOutput{ExecutionContext: exCtx}
+
+		cmd := c.subCommand
+		for cmd != nil {
+			var err error
+			if cmd, err = cmd.Execute(out); err != nil {
+				return nil, err
+			}
+		}
+
+		lines, err := c.redraw(exCtx, out.printed, previous)
+		if err != nil {
+			return nil, err
+		}
+
+		previous = lines
+
+		if run == c.runs {
+			return nil, nil
+		}
+
+		if interrupted, err := exCtx.WaitForInterrupt(c.interval); interrupted || err != nil {
+			return nil, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// redraw clears the screen and prints the output of the last run.
+// If highlighting is enabled, lines that differ from the previous run are printed in yellow.
+// It returns the lines of the output, so the next run can be compared with them, and an error if printing fails.
+func (c *Watch) redraw(exCtx core.ExecutionContext, printed []watchPrint, previous []string) ([]string, error) {
+	header := fmt.Sprintf("Every %s: %s\n\n", c.interval, c.title)
+	if err := exCtx.Print(ClearScreen + header); err != nil {
+		return nil, err
+	}
+
+	var text strings.Builder
+	for _, p := range printed {
+		text.WriteString(p.data)
+	}
+
+	lines := strings.SplitAfter(text.String(), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	if !c.highlight {
+		for _, p := range printed {
+			if err := exCtx.Print(p.data, p.attr...); err != nil {
+				return nil, err
+			}
+		}
+
+		return lines, nil
+	}
+
+	for i, line := range lines {
+		var attr []color.Attribute
+		if previous != nil && (i >= len(previous) || previous[i] != line) {
+			attr = []color.Attribute{color.FgYellow}
+		}
+
+		if err := exCtx.Print(line, attr...); err != nil {
+			return nil, err
+		}
+	}
+
+	return lines, nil
+}
+
+// watchPrint is the text printed by the watched sub-command with its color attributes.
+type watchPrint struct {
+	data string
+	attr []color.Attribute
+}
+
+// watchOutput is the execution context of the watched sub-command, it collects the printed text instead of printing it,
+// so the screen is redrawn at once after the run.
+type watchOutput struct {
+	core.ExecutionContext
+	printed []watchPrint
+}
+
+// Print collects the text printed by the sub-command.
+func (o *watchOutput) Print(data string, attr ...color.Attribute) error {
+	o.printed = append(o.printed, watchPrint{data: data, attr: attr})
+	return nil
+}
+
+type FormatAs struct {
+	contentType string
+}
+
+// NewFormatAs creates a new FormatAs command that forces the content type used for formatting messages.
+// It takes contentType of type string, which is one of json, xml, text, hex or auto.
+// It returns a pointer to a FormatAs instance.
+func NewFormatAs(contentType string) *FormatAs {
+	return &FormatAs{contentType}
+}
+
+// Execute sets the content type hint in the execution context, it stays active until changed.
+// It returns an error if the content type is not supported by the formater.
+func (c *FormatAs) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	return nil, exCtx.SetContentType(c.contentType)
+}
+
+type Focus struct {
+	path string
+}
+
+// NewFocus creates a new Focus command that limits the terminal output of JSON messages to a subtree.
+// It takes path of type string, a dot separated path to the value shown, an empty path shows messages as a whole.
+// It returns a pointer to a Focus instance.
+func NewFocus(path string) *Focus {
+	return &Focus{path}
+}
+
+// Execute sets the focus path in the execution context, it stays active until changed.
+// It returns nil, as changing the focus can't fail.
+func (c *Focus) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	exCtx.SetFocus(c.path)
+
+	return nil, nil
+}
+
+type FormatShow struct{}
+
+// NewFormatShow creates a new FormatShow command that prints the current formatter options.
+// It returns a pointer to a FormatShow instance.
+func NewFormatShow() *FormatShow {
+	return &FormatShow{}
+}
+
+// Execute prints the formatter options sorted by name, one per line as name: value.
+// It returns an error if printing fails.
+func (c *FormatShow) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	options := exCtx.FormatOptions()
+
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var out strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&out, "%s: %s\n", name, options[name])
+	}
+
+	return nil, exCtx.Print(out.String())
+}
+
+type FormatSet struct {
+	name  string
+	value string
+}
+
+// NewFormatSet creates a new FormatSet command that changes a formatter option for subsequent messages.
+// It takes name of type string, the name of the option as shown by fmt show, and value of type string.
+// It returns a pointer to a FormatSet instance.
+func NewFormatSet(name, value string) *FormatSet {
+	return &FormatSet{name: name, value: value}
+}
+
+// Execute sets the formatter option in the execution context, it stays active until changed.
+// It returns an error if the option is unknown or the value is not valid for it.
+func (c *FormatSet) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	return nil, exCtx.SetFormatOption(c.name, c.value)
+}
+
+type Record struct {
+	enabled bool
+}
+
+// NewRecord creates a new Record command that resumes or pauses writing messages to the output file.
+// It takes enabled of type bool, which resumes writing if true and pauses it otherwise.
+// It returns a pointer to a Record instance.
+func NewRecord(enabled bool) *Record {
+	return &Record{enabled}
+}
+
+// Execute resumes or pauses writing messages to the output file.
+// If the output file is not configured for the session, it prints the reason instead of interrupting the session.
+// It returns an error only if printing fails.
+func (c *Record) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	if err := exCtx.SetRecording(c.enabled); err != nil {
+		return nil, exCtx.Print(fmt.Sprintf("Fail to change recording: %s\n", err), color.FgRed)
+	}
+
+	return nil, nil
+}
+
+type Insecure struct {
+	skip bool
+}
+
+// NewInsecure creates a new Insecure command that toggles verification of the server certificate.
+// It takes skip of type bool, which disables verification if true and enables it otherwise.
+// It returns a pointer to an Insecure instance.
+func NewInsecure(skip bool) *Insecure {
+	return &Insecure{skip}
+}
+
+// Execute changes the certificate verification used for the next handshake, e.g. when the connection is re-established.
+// A warning is printed when verification is disabled.
+// It returns an error only if printing fails.
+func (c *Insecure) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	exCtx.SetSkipSSLVerification(c.skip)
+
+	if c.skip {
+		return nil, exCtx.Print(
+			"WARNING: TLS certificate verification is disabled for the next connection, the server identity is not checked\n",
+			color.FgRed, color.Bold,
+		)
+	}
+
+	return nil, exCtx.Print("TLS certificate verification is enabled for the next connection\n")
+}
+
+type Reconnect struct{}
+
+// NewReconnect creates a new Reconnect command that re-establishes the connection on demand.
+// It returns a pointer to a Reconnect instance.
+func NewReconnect() *Reconnect {
+	return &Reconnect{}
+}
+
+// Execute dials a new connection with the same options and replaces the current one with it.
+// If the handshake fails, the reason is printed and the current connection is kept, so the session continues.
+// It returns an error only if printing fails.
+func (c *Reconnect) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	if err := exCtx.Reconnect(); err != nil {
+		return nil, exCtx.Print(fmt.Sprintf("Fail to reconnect: %s, the current connection is kept\n", err), color.FgRed)
+	}
+
+	return nil, exCtx.Print(fmt.Sprintf("Reconnected to %s\n", exCtx.ConnectionInfo().URL), color.FgGreen)
+}
+
+type Info struct{}
+
+// NewInfo creates a new Info command that shows the details of the connection.
+// It returns a pointer to an Info instance.
+func NewInfo() *Info {
+	return &Info{}
+}
+
+// Execute prints the URL, the remote address, the TLS parameters and the subprotocols offered to and selected by the server.
+// It returns an error if printing fails.
+func (c *Info) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	info := exCtx.ConnectionInfo()
+
+	remoteAddr := cmp.Or(info.RemoteAddr, "not connected")
+
+	tlsInfo := "not used"
+	if info.TLSVersion != "" {
+		tlsInfo = info.TLSVersion + ", " + info.CipherSuite
+
+		if info.TLSResumed {
+			tlsInfo += ", resumed session"
+		}
+	}
+
+	subprotocols := "none offered"
+	if len(info.Subprotocols) > 0 {
+		subprotocols = fmt.Sprintf("offered %s, selected %s", strings.Join(info.Subprotocols, ", "), cmp.Or(info.Subprotocol, "none"))
+	}
+
+	return nil, exCtx.Print(fmt.Sprintf(
+		"URL:          %s\nRemote:       %s\nTLS:          %s\nSubprotocols: %s\n",
+		info.URL, remoteAddr, tlsInfo, subprotocols,
+	))
+}
+
+type RespHeaders struct{}
+
+// NewRespHeaders creates a new RespHeaders command that shows the headers of the handshake response.
+// It returns a pointer to a RespHeaders instance.
+func NewRespHeaders() *RespHeaders {
+	return &RespHeaders{}
+}
+
+// Execute prints the headers the server sent with the 101 response of the last handshake,
+// one "Name: value" line per value sorted by name, e.g. to read a session ID set by the server.
+// Values are shown in full, including credentials.
+// It returns an error if the connection is not established yet or printing fails.
+func (c *RespHeaders) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	info := exCtx.ConnectionInfo()
+	if info.RemoteAddr == "" {
+		return nil, fmt.Errorf("no handshake response, the connection is not established")
+	}
+
+	names := make([]string, 0, len(info.ResponseHeaders))
+	for name := range info.ResponseHeaders {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var out strings.Builder
+
+	for _, name := range names {
+		for _, value := range info.ResponseHeaders[name] {
+			fmt.Fprintf(&out, "%s: %s\n", name, value)
+		}
+	}
+
+	return nil, exCtx.Print(out.String())
+}
+
+type Throttle struct {
+	interval time.Duration
+}
+
+// NewThrottle creates a new Throttle command that limits how often received messages are shown in the terminal.
+// It takes interval of type time.Duration, at most one message is shown per interval, 0 shows every message.
+// It returns a pointer to a Throttle instance.
+func NewThrottle(interval time.Duration) *Throttle {
+	return &Throttle{interval}
+}
+
+// Execute sets the throttle interval for the following messages, the count of dropped messages is reset.
+// It returns nil, as changing the throttle can't fail.
+func (c *Throttle) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	exCtx.SetThrottle(c.interval)
+
+	return nil, nil
+}
+
+type Pause struct{}
+
+// NewPause creates a new Pause command that holds received messages instead of showing them.
+// It returns a pointer to a Pause instance.
+func NewPause() *Pause {
+	return &Pause{}
+}
+
+// Execute pauses the output, messages are still read from the connection and shown on resume.
+// It returns an error if printing the status fails.
+func (c *Pause) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	if !exCtx.Pause() {
+		return nil, exCtx.Print("Output is already paused\n", color.FgYellow)
+	}
+
+	return nil, exCtx.Print("Output is paused, received messages are held until resume\n", color.FgYellow)
+}
+
+type Resume struct{}
+
+// NewResume creates a new Resume command that shows the messages held by pause and continues showing received messages.
+// It returns a pointer to a Resume instance.
+func NewResume() *Resume {
+	return &Resume{}
+}
+
+// Execute resumes the output.
+// It returns the command showing the held messages, or an error if printing the status fails.
+func (c *Resume) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	held, ok := exCtx.Resume()
+	if !ok {
+		return nil, exCtx.Print("Output is not paused\n", color.FgYellow)
+	}
+
+	return held, nil
+}
+
+type DelayResponses struct {
+	base   time.Duration
+	jitter time.Duration
+}
+
+// NewDelayResponses creates a new DelayResponses command that holds received messages before they're delivered to commands.
+// It takes base and jitter of type time.Duration, each message is held for a random duration within base±jitter, 0 base disables the delay.
+// It returns a pointer to a DelayResponses instance.
+func NewDelayResponses(base, jitter time.Duration) *DelayResponses {
+	return &DelayResponses{base: base, jitter: jitter}
+}
+
+// Execute sets the artificial delay of the following received messages, sent messages are not affected.
+// It returns nil, as changing the delay can't fail.
+func (c *DelayResponses) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	exCtx.SetResponseDelay(c.base, c.jitter)
+
+	return nil, nil
+}
+
+type OnMessage struct {
+	command string
+}
+
+// NewOnMessage creates a new OnMessage command that registers the command run for every received message.
+// It takes command of type string, the data of the message is available in it as ${msg}, an empty command removes the hook.
+// It returns a pointer to an OnMessage instance.
+func NewOnMessage(command string) *OnMessage {
+	return &OnMessage{command}
+}
+
+// Execute registers the hook for the following messages, replacing the previous one.
+// It returns nil, as registering the hook can't fail.
+func (c *OnMessage) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	exCtx.SetMessageHook(c.command)
+
+	return nil, nil
+}
+
+type Recent struct {
+	n int
+}
+
+// NewRecent creates a new Recent command that prints the last messages kept in memory.
+// It takes n of type int, the number of messages to print, 0 prints all kept messages.
+// It returns a pointer to a Recent instance.
+func NewRecent(n int) *Recent {
+	return &Recent{n}
+}
+
+// Execute prints the last sent and received messages from the oldest to the newest, formatted as they were shown.
+// The messages are only printed, they are not written to the output file again.
+// It returns an error if a message can't be formatted or printed.
+func (c *Recent) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	msgs := exCtx.RecentMessages(c.n)
+	if len(msgs) == 0 {
+		return nil, exCtx.Print("No recent messages\n", color.FgYellow)
+	}
+
+	separator := "\n"
+	if exCtx.SummaryMode() {
+		separator = " "
+	}
+
+	for _, msg := range msgs {
+		output, err := exCtx.FormatMessage(msg, false)
+		if err != nil {
+			return nil, fmt.Errorf("fail to format message: %w", err)
+		}
+
+		marker, attr := "<-", color.FgRed
+		if msg.Type == core.Request {
+			marker, attr = "->", color.FgGreen
+		}
+
+		if err := exCtx.Print(marker+separator, attr); err != nil {
+			return nil, fmt.Errorf("fail to print message: %w", err)
+		}
+
+		if err := exCtx.Print(output + "\n"); err != nil {
+			return nil, fmt.Errorf("fail to print message: %w", err)
+		}
+	}
+
+	return nil, nil
+}
+
+type Sizes struct {
+	n int
+}
+
+// NewSizes creates a new Sizes command that reports the distribution of message sizes.
+// It takes n of type int, the number of the last kept messages to analyze, 0 analyzes all kept messages.
+// It returns a pointer to a Sizes instance.
+func NewSizes(n int) *Sizes {
+	return &Sizes{n}
+}
+
+// Execute prints the count, min, mean, median, 95th percentile and max payload size in bytes
+// of the last sent and received messages kept in memory, one line per direction with key=value pairs.
+// It returns an error if printing fails.
+func (c *Sizes) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	var sent, received []int
+
+	for _, msg := range exCtx.RecentMessages(c.n) {
+		if msg.Type == core.Request {
+			sent = append(sent, len(msg.Data))
+		} else {
+			received = append(received, len(msg.Data))
+		}
+	}
+
+	return nil, exCtx.Print(formatSizes("sent", sent) + formatSizes("received", received))
+}
+
+// formatSizes returns the line with the size statistics of the direction, sizes are sorted in place.
+func formatSizes(direction string, sizes []int) string {
+	if len(sizes) == 0 {
+		return fmt.Sprintf("%-8s count=0\n", direction)
+	}
+
+	sort.Ints(sizes)
+
+	total := 0
+	for _, size := range sizes {
+		total += size
+	}
+
+	n := len(sizes)
+	median := float64(sizes[(n-1)/2]+sizes[n/2]) / 2
+	// The 95th percentile is computed with the nearest-rank method, so it's always one of the sizes.
+	p95 := sizes[(n*95+99)/100-1]
+
+	return fmt.Sprintf(
+		"%-8s count=%d min=%d mean=%.1f median=%.1f p95=%d max=%d\n",
+		direction, n, sizes[0], float64(total)/float64(n), median, p95, sizes[n-1],
+	)
+}
+
+type Tee struct {
+	path   string
+	format string
+}
+
+// NewTee creates a new Tee command that records the following messages to an additional output file.
+// It takes path of type string, the file to write, and format of type string, text or jsonl.
+// It returns a pointer to a Tee instance.
+func NewTee(path, format string) *Tee {
+	return &Tee{path: path, format: format}
+}
+
+// Execute opens the output file and attaches it to the session next to the files that are already recorded.
+// If the file can't be opened, it prints the reason instead of interrupting the session.
+// It returns an error only if printing fails.
+func (c *Tee) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	if err := exCtx.AddSink(c.path, c.format); err != nil {
+		return nil, exCtx.Print(fmt.Sprintf("Fail to add output file: %s\n", err), color.FgRed)
+	}
+
+	return nil, nil
+}
+
+type ReplayLast struct {
+	edit bool
+}
+
+// NewReplayLast creates a new ReplayLast command that re-sends the most recently sent request.
+// It takes edit of type bool, if true the request is opened in the editor instead of being sent right away.
+// It returns a pointer to a ReplayLast instance.
+func NewReplayLast(edit bool) *ReplayLast {
+	return &ReplayLast{edit: edit}
+}
+
+// Execute looks up the last sent request in the execution context.
+// It returns a Send command with the request, or an Edit command seeded with it if editing is requested.
+// It returns an error if no request has been sent in the session yet.
+func (c *ReplayLast) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	req, ok := exCtx.LastRequest()
+	if !ok {
+		return nil, fmt.Errorf("no request to replay")
+	}
+
+	if c.edit {
+		return NewEdit(req), nil
+	}
+
+	return NewSend(req), nil
+}
+
+type ResendPatch struct {
+	path  string
+	value string
+}
+
+// NewResendPatch creates a new ResendPatch command that re-sends the most recently sent request with one field changed.
+// It takes path of type string, a dot separated path to the field, and value of type string,
+// which is used as JSON if it's a valid JSON value, e.g. a number, a boolean or a quoted string, and as a string otherwise.
+// It returns a pointer to a ResendPatch instance.
+func NewResendPatch(path, value string) *ResendPatch {
+	return &ResendPatch{path: path, value: value}
+}
+
+// Execute parses the last sent request as JSON, sets the field at the configured path and sends the result.
+// Missing keys of objects are added, array elements can only be replaced.
+// It returns a Send command with the patched request.
+// It returns an error if no request has been sent yet, the request is not a valid JSON or the path doesn't exist.
+func (c *ResendPatch) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	req, ok := exCtx.LastRequest()
+	if !ok {
+		return nil, fmt.Errorf("no request to patch")
+	}
+
+	var data any
+
+	dec := json.NewDecoder(strings.NewReader(req))
+	dec.UseNumber()
+
+	if err := dec.Decode(&data); err != nil {
+		return nil, fmt.Errorf("fail to parse last request as JSON: %w", err)
+	}
+
+	var value any = c.value
+	if json.Valid([]byte(c.value)) {
+		value = json.RawMessage(c.value)
+	}
+
+	if err := setPath(data, c.path, value); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(data); err != nil {
+		return nil, fmt.Errorf("fail to encode patched request: %w", err)
+	}
+
+	return NewSend(strings.TrimSuffix(buf.String(), "\n")), nil
+}
+
+type Capture struct {
+	path     string
+	variable string
+}
+
+// NewCapture creates a new Capture command that stores a value from the last response in a session variable.
+// It takes path of type string, a dot separated path to the value in the JSON response, and variable of type string.
+// It returns a pointer to a Capture instance.
+func NewCapture(path, variable string) *Capture {
+	return &Capture{path: path, variable: variable}
+}
+
+// Execute parses the last response as JSON, extracts the value at the configured path and stores it in the variable.
+// String values are stored as is, other values are stored as JSON.
+// It returns an error if there is no response yet, the response is not a valid JSON or the path doesn't exist.
+func (c *Capture) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	msg, ok := exCtx.LastResponse()
+	if !ok {
+		return nil, fmt.Errorf("no response to capture from")
+	}
+
+	var data any
+
+	// Numbers are kept as they are in the response, so large IDs aren't rounded to float64.
+	dec := json.NewDecoder(strings.NewReader(msg.Data))
+	dec.UseNumber()
+
+	if err := dec.Decode(&data); err != nil {
+		return nil, fmt.Errorf("fail to parse last response as JSON: %w", err)
+	}
+
+	value, err := lookupPath(data, c.path)
+	if err != nil {
+		return nil, err
+	}
+
+	if str, ok := value.(string); ok {
+		exCtx.SetVariable(c.variable, str)
+		return nil, nil
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("fail to encode captured value: %w", err)
+	}
+
+	exCtx.SetVariable(c.variable, string(raw))
+
+	return nil, nil
+}
+
+type CheckShape struct {
+	payload string
+}
+
+// NewCheckShape creates a new CheckShape command that compares the top-level keys of a payload
+// with the keys of the last response.
+// It takes payload of type string, a JSON object, variables in it are expanded before the check.
+// It returns a pointer to a CheckShape instance.
+func NewCheckShape(payload string) *CheckShape {
+	return &CheckShape{payload: payload}
+}
+
+// Execute parses the payload and the last response as JSON objects and prints the top-level keys
+// of the last response missing in the payload and the keys of the payload the last response doesn't have.
+// A mismatch is only reported as a warning, the payload isn't sent.
+// It returns an error if there is no response yet or the payload or the last response is not a JSON object.
+func (c *CheckShape) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	msg, ok := exCtx.LastResponse()
+	if !ok {
+		return nil, fmt.Errorf("no response to compare the shape with")
+	}
+
+	expected, err := objectKeys(msg.Data)
+	if err != nil {
+		return nil, fmt.Errorf("fail to parse last response as JSON object: %w", err)
+	}
+
+	actual, err := objectKeys(exCtx.ExpandVariables(c.payload))
+	if err != nil {
+		return nil, fmt.Errorf("fail to parse payload as JSON object: %w", err)
+	}
+
+	var missing, extra []string
+
+	for _, key := range expected {
+		if !slices.Contains(actual, key) {
+			missing = append(missing, key)
+		}
+	}
+
+	for _, key := range actual {
+		if !slices.Contains(expected, key) {
+			extra = append(extra, key)
+		}
+	}
+
+	if len(missing) == 0 && len(extra) == 0 {
+		return nil, exCtx.Print("Shape matches the last response\n", color.FgGreen)
+	}
+
+	var report strings.Builder
+
+	if len(missing) > 0 {
+		fmt.Fprintf(&report, "Missing keys: %s\n", strings.Join(missing, ", "))
+	}
+
+	if len(extra) > 0 {
+		fmt.Fprintf(&report, "Extra keys: %s\n", strings.Join(extra, ", "))
+	}
+
+	return nil, exCtx.Print(report.String(), color.FgYellow)
+}
+
+// objectKeys parses data as a JSON object and returns its top-level keys in sorted order.
+// It returns an error if data is not a JSON object.
+func objectKeys(data string) ([]string, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(data), &obj); err != nil {
+		return nil, err
+	}
+
+	if obj == nil {
+		return nil, fmt.Errorf("expected a JSON object, got null")
+	}
+
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	return keys, nil
+}
+
+type PromptVar struct {
+	variable string
+	message  string
+}
+
+// NewPromptVar creates a new PromptVar command that asks the user for the value of a session variable.
+// It takes variable of type string and message of type string, which is shown before the input.
+// It returns a pointer to a PromptVar instance.
+func NewPromptVar(variable, message string) *PromptVar {
+	return &PromptVar{variable: variable, message: message}
+}
+
+// Execute shows the message, reads a line in command mode and stores it in the variable,
+// so a macro can pause for a value and use it later as ${var}.
+// It returns an error if the input can't be read, e.g. the session has no keyboard input or it's interrupted.
+func (c *PromptVar) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	value, err := exCtx.CommandMode(c.message+" ", "")
+	if err != nil {
+		return nil, err
+	}
+
+	exCtx.SetVariable(c.variable, value)
+
+	return nil, nil
+}
+
+type ExportMacros struct {
+	macro MacroRepo
+	path  string
+}
+
+// NewExportMacros creates a new ExportMacros command that saves the macro set of the session to a file.
+// It takes path of type string, the destination YAML file, and macro of type MacroRepo with the macros to export.
+// It returns a pointer to an ExportMacros instance.
+func NewExportMacros(path string, macro MacroRepo) *ExportMacros {
+	return &ExportMacros{path: path, macro: macro}
+}
+
+// Execute writes the macros to the file in the macro config format, so it can be loaded back from the macro directory.
+// It returns an error if no macros are loaded in the session or the file can't be written.
+func (c *ExportMacros) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	if c.macro == nil || len(c.macro.GetNames()) == 0 {
+		return nil, fmt.Errorf("no macros to export")
+	}
+
+	if err := c.macro.Export(c.path); err != nil {
+		return nil, err
+	}
+
+	return nil, exCtx.Print(fmt.Sprintf("Macros are exported to %s\n", c.path))
+}
+
+type Shapes struct {
+	deadline time.Time
+	counts   map[string]int
+	examples map[string]string
+	window   time.Duration
+	total    int
+	other    int
+}
+
+// NewShapes creates a new Shapes command that groups received JSON messages by their shape.
+// It takes window of type time.Duration, the period to collect messages for.
+// It returns a pointer to a Shapes instance.
+func NewShapes(window time.Duration) *Shapes {
+	return &Shapes{
+		window:   window,
+		counts:   make(map[string]int),
+		examples: make(map[string]string),
+	}
+}
+
+// Execute collects messages received until the window elapses, every message is printed as usual.
+// Messages are grouped by the set of their keys, see formater.Shape.
+// It returns the received message and the command to keep collecting, nil once the window elapses and the summary
+// is printed, or an error if waiting fails.
+func (c *Shapes) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	now := exCtx.Clock().Now()
+
+	if c.deadline.IsZero() {
+		c.deadline = now.Add(c.window)
+	}
+
+	remaining := c.deadline.Sub(now)
+	if remaining <= 0 {
+		return nil, exCtx.Print(c.summary())
+	}
+
+	msg, err := exCtx.WaitForResponse(remaining)
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return nil, exCtx.Print(c.summary())
+	case err != nil:
+		return nil, err
+	}
+
+	c.add(msg.Data)
+
+	return NewSequence([]core.Executer{NewPrintMsg(msg), c}), nil
+}
+
+// add counts the message in the group of its shape, the first message of a group is kept as the example.
+func (c *Shapes) add(data string) {
+	c.total++
+
+	shape, ok := formater.Shape(data)
+	if !ok {
+		c.other++
+		return
+	}
+
+	if _, ok := c.examples[shape]; !ok {
+		c.examples[shape] = data
+	}
+
+	c.counts[shape]++
+}
+
+// summary returns the message counts per shape, the most frequent shapes first.
+func (c *Shapes) summary() string {
+	shapes := make([]string, 0, len(c.counts))
+	for shape := range c.counts {
+		shapes = append(shapes, shape)
+	}
+
+	sort.Slice(shapes, func(i, j int) bool {
+		if c.counts[shapes[i]] != c.counts[shapes[j]] {
+			return c.counts[shapes[i]] > c.counts[shapes[j]]
+		}
+
+		return shapes[i] < shapes[j]
+	})
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Received %d messages with %d shapes\n", c.total, len(shapes))
+
+	for _, shape := range shapes {
+		fmt.Fprintf(&b, "%6d  %s\n        e.g. %s\n", c.counts[shape], shape, truncate(c.examples[shape], shapeExampleLength))
+	}
+
+	if c.other > 0 {
+		fmt.Fprintf(&b, "%6d  not JSON\n", c.other)
+	}
+
+	return b.String()
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis.
+func truncate(s string, n int) string {
+	runes := []rune(strings.Join(strings.Fields(s), " "))
+	if len(runes) <= n {
+		return string(runes)
+	}
+
+	return string(runes[:n]) + "..."
+}
+
+// lookupPath walks the parsed JSON data following a dot separated path.
+// It takes data of type any, the decoded JSON value, and path of type string, where numeric segments index arrays.
+// It returns the value found at the path and an error if any segment of the path doesn't exist.
+func lookupPath(data any, path string) (any, error) {
+	current := data
+
+	for _, key := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]any:
+			value, ok := node[key]
+			if !ok {
+				return nil, fmt.Errorf("path not found: %s", path)
+			}
+
+			current = value
+		case []any:
+			idx, err := strconv.Atoi(key)
+			if err != nil || idx < 0 || idx >= len(node) {
+				return nil, fmt.Errorf("path not found: %s", path)
+			}
+
+			current = node[idx]
+		default:
+			return nil, fmt.Errorf("path not found: %s", path)
+		}
+	}
+
+	return current, nil
+}
+
+// setPath replaces the value at a dot separated path of the parsed JSON data in place.
+// It takes data of type any, the decoded JSON value, path of type string, where numeric segments index arrays,
+// and value of type any, the new value.
+// It returns an error if the parent of the field doesn't exist or the array index is out of range.
+func setPath(data any, path string, value any) error {
+	keys := strings.Split(path, ".")
+	parent := data
+
+	if len(keys) > 1 {
+		var err error
+		if parent, err = lookupPath(data, strings.Join(keys[:len(keys)-1], ".")); err != nil {
+			return fmt.Errorf("path not found: %s", path)
+		}
+	}
+
+	key := keys[len(keys)-1]
+
+	switch node := parent.(type) {
+	case map[string]any:
+		node[key] = value
+	case []any:
+		idx, err := strconv.Atoi(key)
+		if err != nil || idx < 0 || idx >= len(node) {
+			return fmt.Errorf("path not found: %s", path)
+		}
+
+		node[idx] = value
+	default:
+		return fmt.Errorf("path not found: %s", path)
+	}
+
+	return nil
 }
@@ -1,18 +1,149 @@
 package command
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/ksysoev/wsget/pkg/core"
+	"github.com/ksysoev/wsget/pkg/core/formater"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
+func TestPrintMsg_Execute_ResponseLatency(t *testing.T) {
+	msg := core.Message{Type: core.Response, Data: "pong"}
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().ThrottleResponse().Return(true, 0)
+	exCtx.EXPECT().FormatMessage(msg, false).Return("pong", nil)
+	exCtx.EXPECT().SummaryMode().Return(false)
+	exCtx.EXPECT().ResponseLatency().Return(123*time.Millisecond+400*time.Microsecond, true)
+	exCtx.EXPECT().Print("<- (123ms)\n", color.FgRed).Return(nil)
+	exCtx.EXPECT().Print("pong\n").Return(nil)
+	exCtx.EXPECT().ShowRaw().Return(false)
+	exCtx.EXPECT().ShouldRecord(core.Response).Return(true)
+	exCtx.EXPECT().RecordMessage(msg).Return(nil)
+
+	next, err := NewPrintMsg(msg).Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+}
+
+func TestPrintMsg_Execute_Summary(t *testing.T) {
+	msg := core.Message{Type: core.Response, Data: `{"type":"tick"}`}
+
+	var printed []string
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().ThrottleResponse().Return(true, 0)
+	exCtx.EXPECT().FormatMessage(msg, false).Return(`15B {type:"tick"}`, nil)
+	exCtx.EXPECT().SummaryMode().Return(true)
+	exCtx.EXPECT().ResponseLatency().Return(0, false)
+	exCtx.EXPECT().Print(mock.Anything, mock.Anything).RunAndReturn(func(data string, _ ...color.Attribute) error {
+		printed = append(printed, data)
+		return nil
+	})
+	exCtx.EXPECT().Print(mock.Anything).RunAndReturn(func(data string, _ ...color.Attribute) error {
+		printed = append(printed, data)
+		return nil
+	})
+	exCtx.EXPECT().ShowRaw().Return(false)
+	exCtx.EXPECT().ShouldRecord(core.Response).Return(false)
+
+	next, err := NewPrintMsg(msg).Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+	assert.Equal(t, "<- 15B {type:\"tick\"}\n", strings.Join(printed, ""))
+}
+
+func TestPrintMsg_Execute_Throttled(t *testing.T) {
+	msg := core.Message{Type: core.Response, Data: "tick"}
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().ThrottleResponse().Return(false, 0).Once()
+	exCtx.EXPECT().ShouldRecord(core.Response).Return(true)
+	exCtx.EXPECT().RecordMessage(msg).Return(nil)
+
+	next, err := NewPrintMsg(msg).Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+
+	exCtx.EXPECT().ThrottleResponse().Return(true, 41).Once()
+	exCtx.EXPECT().Print("... 41 messages dropped\n", color.FgYellow).Return(nil)
+	exCtx.EXPECT().FormatMessage(msg, false).Return("tick", nil)
+	exCtx.EXPECT().SummaryMode().Return(false)
+	exCtx.EXPECT().ResponseLatency().Return(0, false)
+	exCtx.EXPECT().Print("<-\n", color.FgRed).Return(nil)
+	exCtx.EXPECT().Print("tick\n").Return(nil)
+	exCtx.EXPECT().ShowRaw().Return(false)
+
+	next, err = NewPrintMsg(msg).Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+}
+
+func TestPrintMsg_Execute_ShowRaw(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected []string
+		showRaw  bool
+	}{
+		{
+			name:     "Enabled",
+			showRaw:  true,
+			expected: []string{"->\n", "{\"a\":1}\n", "raw 7 bytes\n00000000  7b 22 61 22 3a 31 7d                              |{\"a\":1}|\n"},
+		},
+		{
+			name:     "Disabled",
+			expected: []string{"->\n", "{\"a\":1}\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := core.Message{Type: core.Request, Data: `{"a":1}`}
+
+			var printed []string
+
+			exCtx := core.NewMockExecutionContext(t)
+			exCtx.EXPECT().FormatMessage(msg, false).Return(`{"a":1}`, nil)
+			exCtx.EXPECT().SummaryMode().Return(false)
+			exCtx.EXPECT().Print(mock.Anything, mock.Anything).RunAndReturn(func(data string, _ ...color.Attribute) error {
+				printed = append(printed, data)
+				return nil
+			})
+			exCtx.EXPECT().Print(mock.Anything).RunAndReturn(func(data string, _ ...color.Attribute) error {
+				printed = append(printed, data)
+				return nil
+			})
+			exCtx.EXPECT().ShowRaw().Return(tt.showRaw)
+			exCtx.EXPECT().ShouldRecord(core.Request).Return(false)
+
+			next, err := NewPrintMsg(msg).Execute(exCtx)
+
+			require.NoError(t, err)
+			assert.Nil(t, next)
+			assert.Equal(t, tt.expected, printed)
+		})
+	}
+}
+
 func TestExit_Execute(t *testing.T) {
 	c := NewExit()
 	_, err := c.Execute(nil)
@@ -26,6 +157,87 @@ func TestExit_Execute(t *testing.T) {
 	}
 }
 
+func TestAbort_Execute(t *testing.T) {
+	_, err := NewAbort("").Execute(nil)
+
+	assert.Equal(t, core.ErrAborted, err)
+
+	_, err = NewAbort("unexpected response").Execute(nil)
+
+	assert.ErrorIs(t, err, core.ErrAborted)
+	assert.EqualError(t, err, "aborted: unexpected response")
+}
+
+func TestTimeLimit_Execute(t *testing.T) {
+	expired, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+
+	tests := []struct {
+		ctx         context.Context
+		cmdErr      error
+		name        string
+		expectedErr string
+	}{
+		{name: "finished in time", ctx: context.Background()},
+		{name: "failed in time", ctx: context.Background(), cmdErr: assert.AnError, expectedErr: assert.AnError.Error()},
+		{
+			name:        "timeout exceeded",
+			ctx:         expired,
+			cmdErr:      context.DeadlineExceeded,
+			expectedErr: "aborted: macro slow exceeded its timeout of 2s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restored := false
+
+			exCtx := core.NewMockExecutionContext(t)
+			exCtx.EXPECT().LimitTime(2 * time.Second).Return(func() { restored = true })
+			exCtx.EXPECT().Context().Return(tt.ctx)
+
+			step := core.NewMockExecuter(t)
+			step.EXPECT().Execute(exCtx).Return(nil, tt.cmdErr)
+
+			first := core.NewMockExecuter(t)
+			first.EXPECT().Execute(exCtx).Return(step, nil)
+
+			next, err := NewTimeLimit("slow", 2*time.Second, first).Execute(exCtx)
+
+			assert.Nil(t, next)
+			assert.True(t, restored)
+
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.EqualError(t, err, tt.expectedErr)
+			assert.Equal(t, tt.ctx == expired, errors.Is(err, core.ErrAborted))
+		})
+	}
+}
+
+func TestSequence_Execute_Abort(t *testing.T) {
+	clock := core.NewMockClock(t)
+	clock.EXPECT().After(time.Millisecond).RunAndReturn(elapsed).Once()
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().Clock().Return(clock)
+	exCtx.EXPECT().Context().Return(context.Background())
+
+	seq := NewSequence([]core.Executer{
+		NewSleepCommand(time.Millisecond),
+		NewAbort("stop"),
+		NewSleepCommand(time.Millisecond),
+	})
+
+	next, err := seq.Execute(exCtx)
+
+	assert.Nil(t, next)
+	assert.ErrorIs(t, err, core.ErrAborted)
+}
+
 func TestPrintMsg_Execute(t *testing.T) {
 	t.Parallel()
 
@@ -36,6 +248,7 @@ func TestPrintMsg_Execute(t *testing.T) {
 		mockFormatOutput string
 		expectedErr      string
 		message          core.Message
+		notRecorded      bool
 	}{
 		{
 			name: "RequestMessage_Success",
@@ -59,6 +272,24 @@ func TestPrintMsg_Execute(t *testing.T) {
 			mockPrintError:   nil,
 			expectedErr:      "",
 		},
+		{
+			name: "RequestMessage_NotRecorded",
+			message: core.Message{
+				Type: core.Request,
+				Data: "test request",
+			},
+			mockFormatOutput: "formatted request",
+			notRecorded:      true,
+		},
+		{
+			name: "ResponseMessage_NotRecorded",
+			message: core.Message{
+				Type: core.Response,
+				Data: "test response",
+			},
+			mockFormatOutput: "formatted response",
+			notRecorded:      true,
+		},
 		{
 			name: "UnsupportedMessageType",
 			message: core.Message{
@@ -95,14 +326,17 @@ func TestPrintMsg_Execute(t *testing.T) {
 			t.Parallel()
 
 			exCtx := core.NewMockExecutionContext(t)
+			exCtx.EXPECT().
+				ThrottleResponse().
+				Return(true, 0).
+				Maybe()
 			exCtx.EXPECT().
 				FormatMessage(tt.message, false).
 				Return(tt.mockFormatOutput, tt.mockFormatError).
 				Maybe()
-
 			exCtx.EXPECT().
-				FormatMessage(tt.message, true).
-				Return(tt.mockFormatOutput, tt.mockFormatError).
+				SummaryMode().
+				Return(false).
 				Maybe()
 
 			if tt.mockFormatError == nil {
@@ -113,6 +347,10 @@ func TestPrintMsg_Execute(t *testing.T) {
 						Return(tt.mockPrintError).
 						Maybe()
 				case core.Response:
+					exCtx.EXPECT().
+						ResponseLatency().
+						Return(0, false).
+						Maybe()
 					exCtx.EXPECT().
 						Print("<-\n", color.FgRed).
 						Return(tt.mockPrintError).
@@ -126,9 +364,20 @@ func TestPrintMsg_Execute(t *testing.T) {
 					Return(tt.mockPrintError).
 					Maybe()
 				exCtx.EXPECT().
-					PrintToFile(tt.mockFormatOutput + "\n").
-					Return(tt.mockPrintError).
+					ShowRaw().
+					Return(false).
+					Maybe()
+				exCtx.EXPECT().
+					ShouldRecord(tt.message.Type).
+					Return(!tt.notRecorded).
 					Maybe()
+
+				if !tt.notRecorded {
+					exCtx.EXPECT().
+						RecordMessage(tt.message).
+						Return(nil).
+						Maybe()
+				}
 			}
 
 			cmd := NewPrintMsg(tt.message)
@@ -190,7 +439,8 @@ func TestCmdEdit_Execute(t *testing.T) {
 			t.Parallel()
 
 			exCtx := core.NewMockExecutionContext(t)
-			exCtx.EXPECT().CommandMode("").Return(tt.mockRawCommand, tt.mockCommandError).Maybe()
+			exCtx.EXPECT().Prompt().Return(":", nil)
+			exCtx.EXPECT().CommandMode(":", "").Return(tt.mockRawCommand, tt.mockCommandError).Maybe()
 			exCtx.EXPECT().CreateCommand(tt.mockRawCommand).Return(tt.mockCreateCmd, tt.mockCreateCmdErr).Maybe()
 			exCtx.EXPECT().Print("Invalid command: "+tt.mockRawCommand+"\n", color.FgRed).Return(nil).Maybe()
 
@@ -208,6 +458,16 @@ func TestCmdEdit_Execute(t *testing.T) {
 	}
 }
 
+func TestCmdEdit_Execute_PromptError(t *testing.T) {
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().Prompt().Return("", assert.AnError)
+
+	nextCmd, err := NewCmdEdit().Execute(exCtx)
+
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Nil(t, nextCmd)
+}
+
 func TestNewWaitForResp_Execute(t *testing.T) {
 	t.Parallel()
 
@@ -261,6 +521,98 @@ func TestNewWaitForResp_Execute(t *testing.T) {
 	}
 }
 
+func TestSequence_Execute_ContinueOnError(t *testing.T) {
+	tests := []struct {
+		name        string
+		wantErr     string
+		wantPrinted []string
+		wantRuns    int
+		continueOn  bool
+	}{
+		{
+			name:        "FailFast",
+			wantErr:     "first",
+			wantRuns:    1,
+			wantPrinted: nil,
+		},
+		{
+			name:        "Continue",
+			continueOn:  true,
+			wantErr:     "2 of 3 steps failed: first; third",
+			wantRuns:    3,
+			wantPrinted: []string{"Step 1 failed: first\n", "Step 3 failed: third\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				printed []string
+				runs    int
+			)
+
+			step := func(err error) core.Executer {
+				cmd := core.NewMockExecuter(t)
+				cmd.EXPECT().Execute(mock.Anything).RunAndReturn(func(core.ExecutionContext) (core.Executer, error) {
+					runs++
+					return nil, err
+				}).Maybe()
+
+				return cmd
+			}
+
+			exCtx := core.NewMockExecutionContext(t)
+			exCtx.EXPECT().ContinueOnError().Return(tt.continueOn)
+			exCtx.EXPECT().Print(mock.Anything, color.FgRed).RunAndReturn(func(data string, _ ...color.Attribute) error {
+				printed = append(printed, data)
+				return nil
+			}).Maybe()
+
+			seq := NewSequence([]core.Executer{step(errors.New("first")), step(nil), step(errors.New("third"))})
+
+			next, err := seq.Execute(exCtx)
+
+			assert.Nil(t, next)
+			assert.EqualError(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantRuns, runs)
+			assert.Equal(t, tt.wantPrinted, printed)
+
+			if tt.continueOn {
+				assert.ErrorAs(t, err, &ErrSequenceFailed{})
+			}
+		})
+	}
+}
+
+func TestSequence_Execute_ContinueOnErrorStopsOnAbort(t *testing.T) {
+	exCtx := core.NewMockExecutionContext(t)
+
+	seq := NewSequence([]core.Executer{NewAbort("stop"), NewExit()})
+
+	_, err := seq.Execute(exCtx)
+
+	assert.ErrorIs(t, err, core.ErrAborted)
+}
+
+func TestSequence_Execute_ContinueOnErrorNested(t *testing.T) {
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().ContinueOnError().Return(true)
+	exCtx.EXPECT().Print("Step 1 failed: empty request\n", color.FgRed).Return(nil).Once()
+
+	inner := NewSequence([]core.Executer{NewSend("")})
+	seq := NewSequence([]core.Executer{inner, NewSend("")})
+
+	exCtx.EXPECT().ExpandVariables("").Return("")
+	exCtx.EXPECT().TrimPayload("").Return("")
+	exCtx.EXPECT().NormalizeNewlines("").Return("")
+	exCtx.EXPECT().SendRequest("").Return(ErrEmptyRequest{})
+	exCtx.EXPECT().Print("Step 2 failed: empty request\n", color.FgRed).Return(nil).Once()
+
+	_, err := seq.Execute(exCtx)
+
+	assert.EqualError(t, err, "2 of 2 steps failed: 1 of 1 steps failed: empty request; empty request")
+}
+
 func TestSequence_Execute(t *testing.T) {
 	t.Parallel()
 
@@ -279,7 +631,14 @@ func TestSequence_Execute(t *testing.T) {
 			mockExecutionCtx: func(t *testing.T) core.ExecutionContext {
 				t.Helper()
 
-				return core.NewMockExecutionContext(t)
+				clock := core.NewMockClock(t)
+				clock.EXPECT().After(time.Millisecond).RunAndReturn(elapsed).Times(2)
+
+				exCtx := core.NewMockExecutionContext(t)
+				exCtx.EXPECT().Clock().Return(clock)
+				exCtx.EXPECT().Context().Return(context.Background())
+
+				return exCtx
 			},
 		},
 		{
@@ -293,9 +652,16 @@ func TestSequence_Execute(t *testing.T) {
 			mockExecutionCtx: func(t *testing.T) core.ExecutionContext {
 				t.Helper()
 
+				clock := core.NewMockClock(t)
+				clock.EXPECT().After(time.Millisecond).RunAndReturn(elapsed)
+				clock.EXPECT().After(time.Duration(0)).RunAndReturn(elapsed)
+
 				exCtx := core.NewMockExecutionContext(t)
 
-				exCtx.EXPECT().CommandMode("").Return("sleep 0", nil)
+				exCtx.EXPECT().Clock().Return(clock)
+				exCtx.EXPECT().Context().Return(context.Background())
+				exCtx.EXPECT().Prompt().Return(":", nil)
+				exCtx.EXPECT().CommandMode(":", "").Return("sleep 0", nil)
 				exCtx.EXPECT().CreateCommand("sleep 0").Return(NewSleepCommand(0), nil)
 
 				return exCtx
@@ -353,7 +719,14 @@ func TestRepeat_Execute(t *testing.T) {
 			mockExecutionContext: func(t *testing.T) core.ExecutionContext {
 				t.Helper()
 
-				return core.NewMockExecutionContext(t)
+				clock := core.NewMockClock(t)
+				clock.EXPECT().After(time.Millisecond).RunAndReturn(elapsed).Times(1)
+
+				exCtx := core.NewMockExecutionContext(t)
+				exCtx.EXPECT().Clock().Return(clock)
+				exCtx.EXPECT().Context().Return(context.Background())
+
+				return exCtx
 			},
 		},
 		{
@@ -364,7 +737,14 @@ func TestRepeat_Execute(t *testing.T) {
 			mockExecutionContext: func(t *testing.T) core.ExecutionContext {
 				t.Helper()
 
-				return core.NewMockExecutionContext(t)
+				clock := core.NewMockClock(t)
+				clock.EXPECT().After(time.Millisecond).RunAndReturn(elapsed).Times(3)
+
+				exCtx := core.NewMockExecutionContext(t)
+				exCtx.EXPECT().Clock().Return(clock)
+				exCtx.EXPECT().Context().Return(context.Background())
+
+				return exCtx
 			},
 		},
 		{
@@ -416,19 +796,115 @@ func TestRepeat_Execute(t *testing.T) {
 	}
 }
 
+func TestRetry_Execute_SucceedsAfterFailures(t *testing.T) {
+	sub := core.NewMockExecuter(t)
+	sub.EXPECT().Execute(mock.Anything).Return(nil, assert.AnError).Times(2)
+	sub.EXPECT().Execute(mock.Anything).Return(nil, nil).Once()
+
+	clock := core.NewMockClock(t)
+	clock.EXPECT().After(2 * time.Second).RunAndReturn(func(time.Duration) <-chan time.Time {
+		ch := make(chan time.Time, 1)
+		ch <- time.Now()
+
+		return ch
+	}).Times(2)
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().Clock().Return(clock)
+	exCtx.EXPECT().Context().Return(context.Background())
+	exCtx.EXPECT().Print("Attempt 1 of 3 failed: "+assert.AnError.Error()+", retrying in 2s\n", color.FgYellow).Return(nil).Once()
+	exCtx.EXPECT().Print("Attempt 2 of 3 failed: "+assert.AnError.Error()+", retrying in 2s\n", color.FgYellow).Return(nil).Once()
+
+	next, err := NewRetry(3, 2*time.Second, sub).Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+}
+
+func TestRetry_Execute_AlwaysFails(t *testing.T) {
+	lastErr := errors.New("last error")
+
+	sub := core.NewMockExecuter(t)
+	sub.EXPECT().Execute(mock.Anything).Return(nil, assert.AnError).Once()
+	sub.EXPECT().Execute(mock.Anything).Return(nil, lastErr).Once()
+
+	clock := core.NewMockClock(t)
+	clock.EXPECT().After(time.Duration(0)).RunAndReturn(func(time.Duration) <-chan time.Time {
+		ch := make(chan time.Time, 1)
+		ch <- time.Now()
+
+		return ch
+	}).Once()
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().Clock().Return(clock)
+	exCtx.EXPECT().Context().Return(context.Background())
+	exCtx.EXPECT().Print(mock.Anything, color.FgYellow).Return(nil).Once()
+
+	next, err := NewRetry(2, 0, sub).Execute(exCtx)
+
+	assert.ErrorIs(t, err, lastErr)
+	assert.Nil(t, next)
+}
+
+func TestRetry_Execute_Interrupted(t *testing.T) {
+	sub := core.NewMockExecuter(t)
+	sub.EXPECT().Execute(mock.Anything).Return(nil, core.ErrInterrupted).Once()
+
+	next, err := NewRetry(3, time.Second, sub).Execute(core.NewMockExecutionContext(t))
+
+	assert.ErrorIs(t, err, core.ErrInterrupted)
+	assert.Nil(t, next)
+}
+
+// elapsed returns a channel the clock has already sent the time on, as if the duration elapsed.
+func elapsed(time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	ch <- time.Now()
+
+	return ch
+}
+
 func TestSleep_Execute(t *testing.T) {
-	c := NewSleepCommand(1 * time.Millisecond)
+	clock := core.NewMockClock(t)
+	clock.EXPECT().After(time.Hour).RunAndReturn(elapsed)
 
-	start := time.Now()
-	_, err := c.Execute(nil)
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().Clock().Return(clock)
+	exCtx.EXPECT().Context().Return(context.Background())
 
-	elapsed := time.Since(start)
+	next, err := NewSleepCommand(time.Hour).Execute(exCtx)
 
 	assert.NoError(t, err)
+	assert.Nil(t, next)
+}
 
-	if elapsed < 1*time.Millisecond {
-		t.Errorf("Sleep.Execute() elapsed = %v, want >= 1ms", elapsed)
-	}
+func TestSleep_Execute_MacroTimeout(t *testing.T) {
+	clock := core.NewMockClock(t)
+	clock.EXPECT().After(time.Hour).Return(make(chan time.Time))
+
+	ctx := context.Background()
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().Clock().Return(clock)
+	exCtx.EXPECT().LimitTime(time.Second).RunAndReturn(func(time.Duration) func() {
+		parent := ctx
+
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithDeadline(parent, time.Time{})
+
+		return func() {
+			cancel()
+			ctx = parent
+		}
+	})
+	exCtx.EXPECT().Context().RunAndReturn(func() context.Context { return ctx })
+
+	next, err := NewTimeLimit("slow", time.Second, NewSleepCommand(time.Hour)).Execute(exCtx)
+
+	assert.EqualError(t, err, "aborted: macro slow exceeded its timeout of 1s")
+	assert.Nil(t, next)
 }
 
 func TestEdit_Execute(t *testing.T) {
@@ -511,10 +987,31 @@ func TestSend_Execute(t *testing.T) {
 				t.Helper()
 
 				exCtx := core.NewMockExecutionContext(t)
+				exCtx.EXPECT().ExpandVariables(mockRequest).Return(mockRequest)
+				exCtx.EXPECT().TrimPayload(mockRequest).Return(mockRequest)
+				exCtx.EXPECT().NormalizeNewlines(mockRequest).Return(mockRequest)
 				exCtx.EXPECT().SendRequest(mockRequest).Return(nil)
 				return exCtx
 			},
 		},
+		{
+			name:        "TrimmedExecution",
+			mockRequest: "test-request\n",
+			expectedErr: nil,
+			expectedNextCmd: NewPrintMsg(core.Message{
+				Type: core.Request, Data: "test-request",
+			}),
+			mockExecutionCtx: func(t *testing.T, mockRequest string) core.ExecutionContext {
+				t.Helper()
+
+				exCtx := core.NewMockExecutionContext(t)
+				exCtx.EXPECT().ExpandVariables(mockRequest).Return(mockRequest)
+				exCtx.EXPECT().TrimPayload(mockRequest).Return("test-request")
+				exCtx.EXPECT().NormalizeNewlines("test-request").Return("test-request")
+				exCtx.EXPECT().SendRequest("test-request").Return(nil)
+				return exCtx
+			},
+		},
 		{
 			name:            "SendRequestError",
 			mockRequest:     "error-request",
@@ -524,6 +1021,9 @@ func TestSend_Execute(t *testing.T) {
 				t.Helper()
 
 				exCtx := core.NewMockExecutionContext(t)
+				exCtx.EXPECT().ExpandVariables(mockRequest).Return(mockRequest)
+				exCtx.EXPECT().TrimPayload(mockRequest).Return(mockRequest)
+				exCtx.EXPECT().NormalizeNewlines(mockRequest).Return(mockRequest)
 				exCtx.EXPECT().SendRequest(mockRequest).Return(assert.AnError)
 				return exCtx
 			},
@@ -674,3 +1174,1732 @@ func TestInputFileCommand_Execute(t *testing.T) {
 		})
 	}
 }
+
+func TestWatch_Execute(t *testing.T) {
+	tests := []struct {
+		name      string
+		expected  []string
+		highlight bool
+	}{
+		{
+			name: "Redraw",
+			expected: []string{
+				ClearScreen + "Every 2s: count\n\n", "run 1\n", "same\n",
+				ClearScreen + "Every 2s: count\n\n", "run 2\n", "same\n",
+				ClearScreen + "Every 2s: count\n\n", "run 3\n", "same\n",
+			},
+		},
+		{
+			name:      "Highlight",
+			highlight: true,
+			expected: []string{
+				ClearScreen + "Every 2s: count\n\n", "run 1\n", "same\n",
+				ClearScreen + "Every 2s: count\n\n", "yellow:run 2\n", "same\n",
+				ClearScreen + "Every 2s: count\n\n", "yellow:run 3\n", "same\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var (
+				printed []string
+				runs    int
+			)
+
+			sub := core.NewMockExecuter(t)
+			sub.EXPECT().Execute(mock.Anything).RunAndReturn(func(exCtx core.ExecutionContext) (core.Executer, error) {
+				runs++
+				if runs == 3 {
+					cancel()
+				}
+
+				return nil, errors.Join(exCtx.Print(fmt.Sprintf("run %d\n", runs)), exCtx.Print("same\n"))
+			})
+
+			exCtx := core.NewMockExecutionContext(t)
+			exCtx.EXPECT().Context().Return(ctx)
+			exCtx.EXPECT().WaitForInterrupt(2*time.Second).Return(false, nil).Times(3)
+			exCtx.EXPECT().Print(mock.Anything, mock.Anything).RunAndReturn(func(data string, attr ...color.Attribute) error {
+				if len(attr) == 1 && attr[0] == color.FgYellow {
+					data = "yellow:" + data
+				}
+
+				printed = append(printed, data)
+
+				return nil
+			}).Maybe()
+			exCtx.EXPECT().Print(mock.Anything).RunAndReturn(func(data string, _ ...color.Attribute) error {
+				printed = append(printed, data)
+				return nil
+			})
+
+			next, err := NewWatch(2*time.Second, 0, tt.highlight, "count", sub).Execute(exCtx)
+
+			require.NoError(t, err)
+			assert.Nil(t, next)
+			assert.Equal(t, 3, runs)
+			assert.Equal(t, tt.expected, printed)
+		})
+	}
+}
+
+func TestWatch_Execute_Error(t *testing.T) {
+	sub := core.NewMockExecuter(t)
+	sub.EXPECT().Execute(mock.Anything).Return(nil, assert.AnError)
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().Context().Return(context.Background())
+
+	_, err := NewWatch(time.Second, 0, false, "fail", sub).Execute(exCtx)
+
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestWatch_Execute_Stop(t *testing.T) {
+	tests := []struct {
+		name        string
+		interrupted []bool
+		runs        int
+		expected    int
+	}{
+		{name: "KeyPressed", interrupted: []bool{false, true}, expected: 2},
+		{name: "RunsReached", interrupted: []bool{false, false}, runs: 3, expected: 3},
+		{name: "SingleRun", runs: 1, expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := core.NewMockExecuter(t)
+			sub.EXPECT().Execute(mock.Anything).Return(nil, nil).Times(tt.expected)
+
+			exCtx := core.NewMockExecutionContext(t)
+			exCtx.EXPECT().Context().Return(context.Background())
+			exCtx.EXPECT().Print(mock.Anything).Return(nil)
+
+			for _, interrupted := range tt.interrupted {
+				exCtx.EXPECT().WaitForInterrupt(time.Second).Return(interrupted, nil).Once()
+			}
+
+			next, err := NewWatch(time.Second, tt.runs, false, "noop", sub).Execute(exCtx)
+
+			assert.NoError(t, err)
+			assert.Nil(t, next)
+		})
+	}
+}
+
+func TestWatch_StopsOnKeyEvent(t *testing.T) {
+	wsConn := core.NewMockConnectionHandler(t)
+	wsConn.EXPECT().SetOnMessage(mock.Anything)
+	wsConn.EXPECT().SetOnStatusChange(mock.Anything)
+	wsConn.EXPECT().SetOnFrameError(mock.Anything)
+
+	editor := core.NewMockEditor(t)
+	editor.EXPECT().SetInput(mock.Anything)
+
+	cli := core.NewCLI(NewFactory(nil), wsConn, &bytes.Buffer{}, editor, core.NewMockFormater(t))
+
+	var runs atomic.Int32
+
+	sub := core.NewMockExecuter(t)
+	sub.EXPECT().Execute(mock.Anything).RunAndReturn(func(core.ExecutionContext) (core.Executer, error) {
+		if runs.Add(1) == 1 {
+			go cli.OnKeyEvent(core.KeyEvent{Key: core.KeyEsc})
+		}
+
+		return nil, nil
+	})
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- cli.Run(context.Background(), core.RunOptions{
+			Commands: []core.Executer{
+				NewSequence([]core.Executer{NewWatch(time.Hour, 0, false, "noop", sub), NewExit()}),
+			},
+		})
+	}()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, core.ErrInterrupted)
+	case <-time.After(2 * time.Second):
+		t.Fatal("watch didn't stop on Esc")
+	}
+
+	assert.Equal(t, int32(1), runs.Load())
+}
+
+func TestReplayLoop_Execute(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		expected []string
+		loops    int
+		resetSeq bool
+	}{
+		{
+			name:     "ContinueSeq",
+			loops:    2,
+			expected: []string{"ping 1", "pong 2", "ping 3", "pong 4"},
+		},
+		{
+			name:     "ResetSeq",
+			loops:    2,
+			resetSeq: true,
+			expected: []string{"ping 1", "pong 2", "ping 1", "pong 2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			filePath := filepath.Join(t.TempDir(), "capture.yaml")
+			require.NoError(t, os.WriteFile(filePath, []byte("- send ping ${seq}\n- send pong ${seq}\n"), 0o600))
+
+			var (
+				seq  string
+				sent []string
+			)
+
+			exCtx := core.NewMockExecutionContext(t)
+			exCtx.EXPECT().Context().Return(context.Background())
+			exCtx.EXPECT().CreateCommand(mock.Anything).RunAndReturn(func(raw string) (core.Executer, error) {
+				return NewSend(strings.TrimPrefix(raw, "send ")), nil
+			})
+			exCtx.EXPECT().SetVariable(SeqVariable, mock.Anything).Run(func(_, value string) { seq = value })
+			exCtx.EXPECT().ExpandVariables(mock.Anything).RunAndReturn(func(s string) string {
+				return strings.ReplaceAll(s, "${seq}", seq)
+			})
+			exCtx.EXPECT().TrimPayload(mock.Anything).RunAndReturn(func(s string) string { return s })
+			exCtx.EXPECT().NormalizeNewlines(mock.Anything).RunAndReturn(func(s string) string { return s })
+			exCtx.EXPECT().SendRequest(mock.Anything).RunAndReturn(func(req string) error {
+				sent = append(sent, req)
+				return nil
+			})
+			exCtx.EXPECT().FormatMessage(mock.Anything, false).Return("", nil)
+			exCtx.EXPECT().SummaryMode().Return(false)
+			exCtx.EXPECT().Print(mock.Anything, color.FgGreen).Return(nil)
+			exCtx.EXPECT().Print(mock.Anything).Return(nil)
+			exCtx.EXPECT().ShowRaw().Return(false)
+			exCtx.EXPECT().ShouldRecord(core.Request).Return(false)
+
+			next, err := NewReplayLoop(filePath, tt.loops, tt.resetSeq).Execute(exCtx)
+
+			require.NoError(t, err)
+			assert.Nil(t, next)
+			assert.Equal(t, tt.expected, sent)
+		})
+	}
+}
+
+func TestReplayLoop_Execute_Canceled(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "capture.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte("- ping\n"), 0o600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runs := 0
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().Context().Return(ctx)
+	exCtx.EXPECT().SetVariable(SeqVariable, mock.Anything)
+	exCtx.EXPECT().CreateCommand("ping").RunAndReturn(func(string) (core.Executer, error) {
+		if runs++; runs == 3 {
+			cancel()
+		}
+
+		return nil, nil
+	})
+
+	next, err := NewReplayLoop(filePath, 0, false).Execute(exCtx)
+
+	require.NoError(t, err)
+	assert.Nil(t, next)
+	assert.Equal(t, 3, runs)
+}
+
+func TestReplayLoop_Execute_InvalidCommand(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "capture.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte("- ping\n- bad\n"), 0o600))
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().CreateCommand("ping").Return(nil, nil)
+	exCtx.EXPECT().CreateCommand("bad").Return(nil, assert.AnError)
+
+	_, err := NewReplayLoop(filePath, 0, false).Execute(exCtx)
+
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestRequest_Execute(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		sendErr         error
+		waitErr         error
+		expectedErr     error
+		expectedNextCmd core.Executer
+		name            string
+		timeout         time.Duration
+	}{
+		{
+			name:            "SendAndWaitSuccessfully",
+			timeout:         5 * time.Second,
+			expectedNextCmd: NewPrintMsg(core.Message{Type: core.Response, Data: "test-response"}),
+		},
+		{
+			name:        "SendError",
+			timeout:     5 * time.Second,
+			sendErr:     assert.AnError,
+			expectedErr: assert.AnError,
+		},
+		{
+			name:        "WaitTimeout",
+			timeout:     time.Second,
+			waitErr:     context.DeadlineExceeded,
+			expectedErr: context.DeadlineExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			reqMsg := core.Message{Type: core.Request, Data: "test-request"}
+
+			exCtx := core.NewMockExecutionContext(t)
+			exCtx.EXPECT().ExpandVariables("test-request").Return("test-request")
+			exCtx.EXPECT().TrimPayload("test-request").Return("test-request")
+			exCtx.EXPECT().NormalizeNewlines("test-request").Return("test-request")
+			exCtx.EXPECT().SendRequest("test-request").Return(tt.sendErr)
+
+			if tt.sendErr == nil {
+				exCtx.EXPECT().FormatMessage(reqMsg, false).Return("test-request", nil)
+				exCtx.EXPECT().SummaryMode().Return(false)
+				exCtx.EXPECT().Print("->\n", color.FgGreen).Return(nil)
+				exCtx.EXPECT().Print("test-request\n").Return(nil)
+				exCtx.EXPECT().ShowRaw().Return(false)
+				exCtx.EXPECT().ShouldRecord(core.Request).Return(true)
+				exCtx.EXPECT().RecordMessage(reqMsg).Return(nil)
+				exCtx.EXPECT().Correlator().Return(nil)
+				exCtx.EXPECT().WaitForResponse(tt.timeout).Return(core.Message{Type: core.Response, Data: "test-response"}, tt.waitErr)
+			}
+
+			nextCmd, err := NewRequest(tt.timeout, "test-request").Execute(exCtx)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Nil(t, nextCmd)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedNextCmd, nextCmd)
+			}
+		})
+	}
+}
+
+// txnCorrelator correlates messages of a text protocol by the txn=<id>; prefix.
+type txnCorrelator struct{}
+
+func (txnCorrelator) ID(msg core.Message) (string, bool) {
+	rest, ok := strings.CutPrefix(msg.Data, "txn=")
+	if !ok {
+		return "", false
+	}
+
+	id, _, ok := strings.Cut(rest, ";")
+
+	return id, ok
+}
+
+func expectPrintedMessages(exCtx *core.MockExecutionContext, recorded *[]core.Message) {
+	exCtx.EXPECT().ThrottleResponse().Return(true, 0).Maybe()
+	exCtx.EXPECT().ResponseLatency().Return(0, false).Maybe()
+	exCtx.EXPECT().FormatMessage(mock.Anything, false).RunAndReturn(func(msg core.Message, _ bool) (string, error) { return msg.Data, nil })
+	exCtx.EXPECT().SummaryMode().Return(false)
+	exCtx.EXPECT().Print(mock.Anything).Return(nil)
+	exCtx.EXPECT().Print(mock.Anything, mock.Anything).Return(nil)
+	exCtx.EXPECT().ShowRaw().Return(false)
+	exCtx.EXPECT().ShouldRecord(mock.Anything).Return(true)
+	exCtx.EXPECT().RecordMessage(mock.Anything).RunAndReturn(func(msg core.Message) error {
+		*recorded = append(*recorded, msg)
+		return nil
+	})
+}
+
+func TestRequest_Execute_Correlated(t *testing.T) {
+	start := time.Now()
+	calls := 0
+
+	clock := core.NewMockClock(t)
+	clock.EXPECT().Now().RunAndReturn(func() time.Time {
+		calls++
+		return start.Add(time.Duration(calls-1) * time.Second)
+	})
+
+	var recorded []core.Message
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().ExpandVariables("txn=7;ping").Return("txn=7;ping")
+	exCtx.EXPECT().TrimPayload("txn=7;ping").Return("txn=7;ping")
+	exCtx.EXPECT().NormalizeNewlines("txn=7;ping").Return("txn=7;ping")
+	exCtx.EXPECT().SendRequest("txn=7;ping").Return(nil)
+	exCtx.EXPECT().Correlator().Return(txnCorrelator{})
+	exCtx.EXPECT().Clock().Return(clock)
+	expectPrintedMessages(exCtx, &recorded)
+
+	exCtx.EXPECT().WaitForResponse(4*time.Second).Return(core.Message{Type: core.Response, Data: "txn=3;other"}, nil).Once()
+	exCtx.EXPECT().WaitForResponse(3*time.Second).Return(core.Message{Type: core.Response, Data: "heartbeat"}, nil).Once()
+	exCtx.EXPECT().WaitForResponse(2*time.Second).Return(core.Message{Type: core.Response, Data: "txn=7;pong"}, nil).Once()
+
+	next, err := NewRequest(5*time.Second, "txn=7;ping").Execute(exCtx)
+
+	require.NoError(t, err)
+	assert.Equal(t, NewPrintMsg(core.Message{Type: core.Response, Data: "txn=7;pong"}), next)
+	assert.Equal(t, []core.Message{
+		{Type: core.Request, Data: "txn=7;ping"},
+		{Type: core.Response, Data: "txn=3;other"},
+		{Type: core.Response, Data: "heartbeat"},
+	}, recorded)
+}
+
+func TestRequest_Execute_CorrelatedTimeout(t *testing.T) {
+	start := time.Now()
+
+	clock := core.NewMockClock(t)
+	clock.EXPECT().Now().Return(start).Once()
+	clock.EXPECT().Now().Return(start.Add(time.Second)).Once()
+	clock.EXPECT().Now().Return(start.Add(2 * time.Second)).Once()
+
+	var recorded []core.Message
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().ExpandVariables("txn=7;ping").Return("txn=7;ping")
+	exCtx.EXPECT().TrimPayload("txn=7;ping").Return("txn=7;ping")
+	exCtx.EXPECT().NormalizeNewlines("txn=7;ping").Return("txn=7;ping")
+	exCtx.EXPECT().SendRequest("txn=7;ping").Return(nil)
+	exCtx.EXPECT().Correlator().Return(txnCorrelator{})
+	exCtx.EXPECT().Clock().Return(clock)
+	expectPrintedMessages(exCtx, &recorded)
+
+	exCtx.EXPECT().WaitForResponse(time.Second).Return(core.Message{Type: core.Response, Data: "txn=3;other"}, nil).Once()
+
+	next, err := NewRequest(2*time.Second, "txn=7;ping").Execute(exCtx)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, next)
+	assert.Len(t, recorded, 2)
+}
+
+func TestRequest_Execute_CorrelatorWithoutRequestID(t *testing.T) {
+	var recorded []core.Message
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().ExpandVariables("ping").Return("ping")
+	exCtx.EXPECT().TrimPayload("ping").Return("ping")
+	exCtx.EXPECT().NormalizeNewlines("ping").Return("ping")
+	exCtx.EXPECT().SendRequest("ping").Return(nil)
+	exCtx.EXPECT().Correlator().Return(txnCorrelator{})
+	expectPrintedMessages(exCtx, &recorded)
+	exCtx.EXPECT().WaitForResponse(time.Second).Return(core.Message{Type: core.Response, Data: "pong"}, nil).Once()
+
+	next, err := NewRequest(time.Second, "ping").Execute(exCtx)
+
+	require.NoError(t, err)
+	assert.Equal(t, NewPrintMsg(core.Message{Type: core.Response, Data: "pong"}), next)
+}
+
+func TestWaitClose_Execute(t *testing.T) {
+	t.Parallel()
+
+	msg := core.Message{Type: core.Response, Data: "bye"}
+
+	tests := []struct {
+		waitErr         error
+		expectedNextCmd core.Executer
+		name            string
+		msg             core.Message
+		timeout         time.Duration
+		closed          bool
+		expectErr       bool
+	}{
+		{
+			name:    "ClosedByServer",
+			timeout: 5 * time.Second,
+			closed:  true,
+		},
+		{
+			name:      "StaysOpen",
+			timeout:   time.Second,
+			waitErr:   context.DeadlineExceeded,
+			expectErr: true,
+		},
+		{
+			name:            "MessageBeforeClose",
+			timeout:         0,
+			msg:             msg,
+			expectedNextCmd: NewSequence([]core.Executer{NewPrintMsg(msg), NewWaitClose(0)}),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			now := time.Now()
+
+			clock := core.NewMockClock(t)
+			clock.EXPECT().Now().Return(now)
+
+			exCtx := core.NewMockExecutionContext(t)
+			exCtx.EXPECT().Clock().Return(clock)
+			exCtx.EXPECT().WaitForClose(tt.timeout).Return(tt.msg, tt.closed, tt.waitErr)
+
+			nextCmd, err := NewWaitClose(tt.timeout).Execute(exCtx)
+
+			if tt.expectErr {
+				assert.ErrorIs(t, err, tt.waitErr)
+				assert.ErrorContains(t, err, "connection was not closed")
+				assert.Nil(t, nextCmd)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedNextCmd, nextCmd)
+			}
+		})
+	}
+}
+
+func TestWaitClose_Execute_KeepsRemainingTimeout(t *testing.T) {
+	start := time.Now()
+
+	clock := core.NewMockClock(t)
+	clock.EXPECT().Now().Return(start).Once()
+	clock.EXPECT().Now().Return(start.Add(400 * time.Millisecond)).Once()
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().Clock().Return(clock)
+	exCtx.EXPECT().WaitForClose(time.Second).Return(core.Message{Type: core.Response, Data: "bye"}, false, nil)
+
+	nextCmd, err := NewWaitClose(time.Second).Execute(exCtx)
+	require.NoError(t, err)
+
+	seq, ok := nextCmd.(*Sequence)
+	require.True(t, ok)
+	require.Len(t, seq.subCommands, 2)
+
+	next, ok := seq.subCommands[1].(*WaitClose)
+	require.True(t, ok)
+	assert.Equal(t, 600*time.Millisecond, next.timeout)
+}
+
+func TestWaitClose_Execute_TimeoutElapsedWhileWaiting(t *testing.T) {
+	start := time.Now()
+
+	clock := core.NewMockClock(t)
+	clock.EXPECT().Now().Return(start).Once()
+	clock.EXPECT().Now().Return(start.Add(time.Second)).Once()
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().Clock().Return(clock)
+	exCtx.EXPECT().WaitForClose(time.Second).Return(core.Message{Type: core.Response, Data: "bye"}, false, nil)
+
+	nextCmd, err := NewWaitClose(time.Second).Execute(exCtx)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, nextCmd)
+}
+
+func TestFormatAs_Execute(t *testing.T) {
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().SetContentType("json").Return(nil)
+	exCtx.EXPECT().SetContentType("yaml").Return(assert.AnError)
+
+	next, err := NewFormatAs("json").Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+
+	_, err = NewFormatAs("yaml").Execute(exCtx)
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestRecord_Execute(t *testing.T) {
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().SetRecording(true).Return(nil)
+
+	next, err := NewRecord(true).Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+
+	exCtx = core.NewMockExecutionContext(t)
+	exCtx.EXPECT().SetRecording(false).Return(fmt.Errorf("output file is not set"))
+	exCtx.EXPECT().Print("Fail to change recording: output file is not set\n", color.FgRed).Return(nil)
+
+	next, err = NewRecord(false).Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+}
+
+func TestThrottle_Execute(t *testing.T) {
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().SetThrottle(time.Second).Once()
+
+	next, err := NewThrottle(time.Second).Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+}
+
+func TestFocus_Execute(t *testing.T) {
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().SetFocus("data.user").Once()
+
+	next, err := NewFocus("data.user").Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+}
+
+func TestFormatShow_Execute(t *testing.T) {
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().FormatOptions().Return(map[string]string{"utf8": "replace", "content-type": "auto", "focus": "off"})
+	exCtx.EXPECT().Print("content-type: auto\nfocus: off\nutf8: replace\n").Return(nil).Once()
+
+	next, err := NewFormatShow().Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+}
+
+func TestFormatSet_Execute(t *testing.T) {
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().SetFormatOption("strict-json", "on").Return(nil)
+	exCtx.EXPECT().SetFormatOption("indent", "4").Return(assert.AnError)
+
+	next, err := NewFormatSet("strict-json", "on").Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+
+	_, err = NewFormatSet("indent", "4").Execute(exCtx)
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestPause_Execute(t *testing.T) {
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().Pause().Return(true).Once()
+	exCtx.EXPECT().Print("Output is paused, received messages are held until resume\n", color.FgYellow).Return(nil).Once()
+
+	next, err := NewPause().Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+
+	exCtx.EXPECT().Pause().Return(false).Once()
+	exCtx.EXPECT().Print("Output is already paused\n", color.FgYellow).Return(nil).Once()
+
+	next, err = NewPause().Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+}
+
+func TestResume_Execute(t *testing.T) {
+	held := core.NewMockExecuter(t)
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().Resume().Return(held, true).Once()
+
+	next, err := NewResume().Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, held, next)
+
+	exCtx.EXPECT().Resume().Return(nil, false).Once()
+	exCtx.EXPECT().Print("Output is not paused\n", color.FgYellow).Return(nil).Once()
+
+	next, err = NewResume().Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+}
+
+func TestDelayResponses_Execute(t *testing.T) {
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().SetResponseDelay(200*time.Millisecond, 50*time.Millisecond).Once()
+
+	next, err := NewDelayResponses(200*time.Millisecond, 50*time.Millisecond).Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+}
+
+func TestOnMessage_Execute(t *testing.T) {
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().SetMessageHook("send ack").Once()
+
+	next, err := NewOnMessage("send ack").Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+}
+
+func TestSizes_Execute(t *testing.T) {
+	var msgs []core.Message
+
+	for _, size := range []int{30, 10, 20, 40} {
+		msgs = append(msgs, core.Message{Type: core.Request, Data: strings.Repeat("x", size)})
+	}
+
+	for size := 1; size <= 20; size++ {
+		msgs = append(msgs, core.Message{Type: core.Response, Data: strings.Repeat("y", size)})
+	}
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().RecentMessages(0).Return(msgs)
+	exCtx.EXPECT().Print(
+		"sent     count=4 min=10 mean=25.0 median=25.0 p95=40 max=40\n" +
+			"received count=20 min=1 mean=10.5 median=10.5 p95=19 max=20\n",
+	).Return(nil)
+
+	next, err := NewSizes(0).Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+}
+
+func TestSizes_Execute_NoMessages(t *testing.T) {
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().RecentMessages(5).Return([]core.Message{{Type: core.Response, Data: "abc"}})
+	exCtx.EXPECT().Print("sent     count=0\nreceived count=1 min=3 mean=3.0 median=3.0 p95=3 max=3\n").Return(nil)
+
+	_, err := NewSizes(5).Execute(exCtx)
+
+	assert.NoError(t, err)
+}
+
+func TestRecent_Execute(t *testing.T) {
+	msgs := []core.Message{
+		{Type: core.Request, Data: "ping"},
+		{Type: core.Response, Data: "pong"},
+	}
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().RecentMessages(2).Return(msgs)
+
+	var printed []string
+
+	for _, msg := range msgs {
+		exCtx.EXPECT().FormatMessage(msg, false).Return(msg.Data, nil)
+	}
+
+	exCtx.EXPECT().SummaryMode().Return(false)
+
+	exCtx.EXPECT().Print(mock.Anything, mock.Anything).RunAndReturn(func(data string, _ ...color.Attribute) error {
+		printed = append(printed, data)
+		return nil
+	})
+	exCtx.EXPECT().Print(mock.Anything).RunAndReturn(func(data string, _ ...color.Attribute) error {
+		printed = append(printed, data)
+		return nil
+	})
+
+	next, err := NewRecent(2).Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+	assert.Equal(t, []string{"->\n", "ping\n", "<-\n", "pong\n"}, printed)
+}
+
+func TestRecent_Execute_Empty(t *testing.T) {
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().RecentMessages(0).Return(nil)
+	exCtx.EXPECT().Print("No recent messages\n", color.FgYellow).Return(nil)
+
+	next, err := NewRecent(0).Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+}
+
+func TestInfo_Execute(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		info     core.ConnectionInfo
+	}{
+		{
+			name: "negotiated subprotocol",
+			info: core.ConnectionInfo{
+				URL:          "wss://example.com",
+				RemoteAddr:   "93.184.216.34:443",
+				TLSVersion:   "TLS 1.3",
+				CipherSuite:  "TLS_AES_128_GCM_SHA256",
+				Subprotocols: []string{"msgpack", "json"},
+				Subprotocol:  "json",
+			},
+			expected: "URL:          wss://example.com\n" +
+				"Remote:       93.184.216.34:443\n" +
+				"TLS:          TLS 1.3, TLS_AES_128_GCM_SHA256\n" +
+				"Subprotocols: offered msgpack, json, selected json\n",
+		},
+		{
+			name: "resumed TLS session",
+			info: core.ConnectionInfo{
+				URL:         "wss://example.com",
+				RemoteAddr:  "93.184.216.34:443",
+				TLSVersion:  "TLS 1.3",
+				CipherSuite: "TLS_AES_128_GCM_SHA256",
+				TLSResumed:  true,
+			},
+			expected: "URL:          wss://example.com\n" +
+				"Remote:       93.184.216.34:443\n" +
+				"TLS:          TLS 1.3, TLS_AES_128_GCM_SHA256, resumed session\n" +
+				"Subprotocols: none offered\n",
+		},
+		{
+			name: "no subprotocol selected",
+			info: core.ConnectionInfo{URL: "ws://localhost", RemoteAddr: "127.0.0.1:80", Subprotocols: []string{"json"}},
+			expected: "URL:          ws://localhost\n" +
+				"Remote:       127.0.0.1:80\n" +
+				"TLS:          not used\n" +
+				"Subprotocols: offered json, selected none\n",
+		},
+		{
+			name: "not connected",
+			info: core.ConnectionInfo{URL: "ws://localhost"},
+			expected: "URL:          ws://localhost\n" +
+				"Remote:       not connected\n" +
+				"TLS:          not used\n" +
+				"Subprotocols: none offered\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exCtx := core.NewMockExecutionContext(t)
+			exCtx.EXPECT().ConnectionInfo().Return(tt.info)
+			exCtx.EXPECT().Print(tt.expected).Return(nil)
+
+			next, err := NewInfo().Execute(exCtx)
+
+			assert.NoError(t, err)
+			assert.Nil(t, next)
+		})
+	}
+}
+
+func TestRespHeaders_Execute(t *testing.T) {
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().ConnectionInfo().Return(core.ConnectionInfo{
+		URL:        "ws://localhost",
+		RemoteAddr: "127.0.0.1:80",
+		ResponseHeaders: map[string][]string{
+			"Upgrade":      {"websocket"},
+			"Set-Cookie":   {"session=abc", "theme=dark"},
+			"X-Session-Id": {"abc123"},
+		},
+	})
+	exCtx.EXPECT().Print("Set-Cookie: session=abc\nSet-Cookie: theme=dark\nUpgrade: websocket\nX-Session-Id: abc123\n").Return(nil)
+
+	next, err := NewRespHeaders().Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+}
+
+func TestRespHeaders_Execute_NotConnected(t *testing.T) {
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().ConnectionInfo().Return(core.ConnectionInfo{URL: "ws://localhost"})
+
+	next, err := NewRespHeaders().Execute(exCtx)
+
+	assert.EqualError(t, err, "no handshake response, the connection is not established")
+	assert.Nil(t, next)
+}
+
+func TestReconnect_Execute(t *testing.T) {
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().Reconnect().Return(nil)
+	exCtx.EXPECT().ConnectionInfo().Return(core.ConnectionInfo{URL: "ws://localhost"})
+	exCtx.EXPECT().Print("Reconnected to ws://localhost\n", color.FgGreen).Return(nil)
+
+	next, err := NewReconnect().Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+
+	exCtx = core.NewMockExecutionContext(t)
+	exCtx.EXPECT().Reconnect().Return(errors.New("dial refused"))
+	exCtx.EXPECT().Print("Fail to reconnect: dial refused, the current connection is kept\n", color.FgRed).Return(nil)
+
+	next, err = NewReconnect().Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+}
+
+func TestReconnect_KeepsSessionState(t *testing.T) {
+	wsConn := core.NewMockConnectionHandler(t)
+	wsConn.EXPECT().SetOnMessage(mock.Anything)
+	wsConn.EXPECT().SetOnStatusChange(mock.Anything)
+	wsConn.EXPECT().SetOnFrameError(mock.Anything)
+	wsConn.EXPECT().Send(mock.Anything, `{"otp": "123456"}`).Return(nil).Times(3)
+	wsConn.EXPECT().Reconnect(mock.Anything).Return(nil).Once()
+	wsConn.EXPECT().Info().Return(core.ConnectionInfo{URL: "ws://localhost"})
+
+	editor := core.NewMockEditor(t)
+	editor.EXPECT().SetInput(mock.Anything)
+	editor.EXPECT().CommandMode(mock.Anything, "otp: ", "").Return("123456", nil)
+
+	formater := core.NewMockFormater(t)
+	formater.EXPECT().FormatMessage(mock.Anything, mock.Anything).RunAndReturn(func(_, data string) (string, error) { return data, nil })
+	formater.EXPECT().Summary().Return(false)
+	formater.EXPECT().FormatForFile(mock.Anything, mock.Anything).RunAndReturn(func(_, data string) (string, error) { return data, nil }).Maybe()
+
+	output := &bytes.Buffer{}
+	cli := core.NewCLI(NewFactory(nil), wsConn, output, editor, formater)
+
+	var cmds []core.Executer
+
+	for _, raw := range []string{"prompt otp", "reconnect", "replay-last"} {
+		cmd, err := NewFactory(nil).Create(raw)
+		require.NoError(t, err)
+
+		cmds = append(cmds, cmd)
+	}
+
+	err := cli.Run(context.Background(), core.RunOptions{
+		Commands: []core.Executer{
+			NewSequence([]core.Executer{
+				cmds[0],
+				NewSend(`{"otp": "${otp}"}`),
+				cmds[1],
+				cmds[2],
+				NewSend(`{"otp": "${otp}"}`),
+				NewExit(),
+			}),
+		},
+	})
+
+	assert.ErrorIs(t, err, core.ErrInterrupted)
+	assert.Contains(t, output.String(), "Reconnected to ws://localhost")
+}
+
+func TestInsecure_Execute(t *testing.T) {
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().SetSkipSSLVerification(true).Once()
+	exCtx.EXPECT().Print(
+		"WARNING: TLS certificate verification is disabled for the next connection, the server identity is not checked\n",
+		color.FgRed, color.Bold,
+	).Return(nil)
+
+	next, err := NewInsecure(true).Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+
+	exCtx = core.NewMockExecutionContext(t)
+	exCtx.EXPECT().SetSkipSSLVerification(false).Once()
+	exCtx.EXPECT().Print("TLS certificate verification is enabled for the next connection\n").Return(nil)
+
+	next, err = NewInsecure(false).Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+}
+
+func TestCapture_Execute(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		response      string
+		path          string
+		expectedValue string
+		expectedErr   string
+		noResponse    bool
+	}{
+		{
+			name:          "NestedField",
+			response:      `{"data": {"auth": {"token": "abc"}}}`,
+			path:          "data.auth.token",
+			expectedValue: "abc",
+		},
+		{
+			name:          "ArrayIndex",
+			response:      `{"items": [{"id": 1}, {"id": 2}]}`,
+			path:          "items.1.id",
+			expectedValue: "2",
+		},
+		{
+			name:          "ObjectValue",
+			response:      `{"data": {"user": {"id": 1}}}`,
+			path:          "data.user",
+			expectedValue: `{"id":1}`,
+		},
+		{
+			name:          "LargeInteger",
+			response:      `{"id": 12345678901234567890}`,
+			path:          "id",
+			expectedValue: "12345678901234567890",
+		},
+		{
+			name:          "LargeIntegerInObject",
+			response:      `{"user": {"id": 12345678901234567890, "score": 1.50}}`,
+			path:          "user",
+			expectedValue: `{"id":12345678901234567890,"score":1.50}`,
+		},
+		{
+			name:        "MissingPath",
+			response:    `{"data": {}}`,
+			path:        "data.token",
+			expectedErr: "path not found: data.token",
+		},
+		{
+			name:        "IndexOutOfRange",
+			response:    `{"items": []}`,
+			path:        "items.0",
+			expectedErr: "path not found: items.0",
+		},
+		{
+			name:        "InvalidJSON",
+			response:    `not json`,
+			path:        "data",
+			expectedErr: "fail to parse last response as JSON",
+		},
+		{
+			name:        "NoResponse",
+			noResponse:  true,
+			path:        "data",
+			expectedErr: "no response to capture from",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			exCtx := core.NewMockExecutionContext(t)
+			exCtx.EXPECT().LastResponse().Return(core.Message{Type: core.Response, Data: tt.response}, !tt.noResponse)
+
+			if tt.expectedErr == "" {
+				exCtx.EXPECT().SetVariable("token", tt.expectedValue)
+			}
+
+			nextCmd, err := NewCapture(tt.path, "token").Execute(exCtx)
+
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Nil(t, nextCmd)
+		})
+	}
+}
+
+func TestCheckShape_Execute(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		response    string
+		payload     string
+		expectedOut string
+		expectedErr string
+		noResponse  bool
+	}{
+		{
+			name:        "Matching",
+			response:    `{"id": 1, "method": "ping", "params": {"a": 1}}`,
+			payload:     `{"method": "pong", "params": {}, "id": 2}`,
+			expectedOut: "Shape matches the last response\n",
+		},
+		{
+			name:        "Mismatching",
+			response:    `{"id": 1, "method": "ping", "params": {}}`,
+			payload:     `{"id": 2, "metod": "ping", "extra": true}`,
+			expectedOut: "Missing keys: method, params\nExtra keys: extra, metod\n",
+		},
+		{
+			name:        "OnlyMissing",
+			response:    `{"id": 1, "method": "ping"}`,
+			payload:     `{"id": 2}`,
+			expectedOut: "Missing keys: method\n",
+		},
+		{
+			name:        "PayloadNotObject",
+			response:    `{"id": 1}`,
+			payload:     `[1, 2]`,
+			expectedErr: "fail to parse payload as JSON object",
+		},
+		{
+			name:        "ResponseNotJSON",
+			response:    `pong`,
+			payload:     `{"id": 1}`,
+			expectedErr: "fail to parse last response as JSON object",
+		},
+		{
+			name:        "NoResponse",
+			noResponse:  true,
+			payload:     `{"id": 1}`,
+			expectedErr: "no response to compare the shape with",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			exCtx := core.NewMockExecutionContext(t)
+			exCtx.EXPECT().LastResponse().Return(core.Message{Type: core.Response, Data: tt.response}, !tt.noResponse)
+
+			exCtx.EXPECT().ExpandVariables(tt.payload).Return(tt.payload).Maybe()
+
+			if tt.expectedOut != "" {
+				exCtx.EXPECT().Print(tt.expectedOut, mock.Anything).Return(nil)
+			}
+
+			nextCmd, err := NewCheckShape(tt.payload).Execute(exCtx)
+
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Nil(t, nextCmd)
+		})
+	}
+}
+
+func TestCapture_UsedInSubsequentSend(t *testing.T) {
+	var onMessage func(context.Context, []byte)
+
+	wsConn := core.NewMockConnectionHandler(t)
+	wsConn.EXPECT().SetOnMessage(mock.Anything).Run(func(cb func(context.Context, []byte)) { onMessage = cb })
+	wsConn.EXPECT().SetOnStatusChange(mock.Anything)
+	wsConn.EXPECT().SetOnFrameError(mock.Anything)
+	wsConn.EXPECT().Send(mock.Anything, `{"authorize": "abc"}`).Return(nil)
+
+	editor := core.NewMockEditor(t)
+	editor.EXPECT().SetInput(mock.Anything)
+
+	formater := core.NewMockFormater(t)
+	formater.EXPECT().FormatMessage(mock.Anything, mock.Anything).RunAndReturn(func(_, data string) (string, error) { return data, nil })
+	formater.EXPECT().Summary().Return(false)
+	formater.EXPECT().FormatForFile(mock.Anything, mock.Anything).RunAndReturn(func(_, data string) (string, error) { return data, nil }).Maybe()
+
+	cli := core.NewCLI(NewFactory(nil), wsConn, &bytes.Buffer{}, editor, formater)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go onMessage(ctx, []byte(`{"data": {"token": "abc"}}`))
+
+	err := cli.Run(ctx, core.RunOptions{
+		Commands: []core.Executer{
+			NewSequence([]core.Executer{
+				NewWaitForResp(time.Second),
+				NewCapture("data.token", "token"),
+				NewSend(`{"authorize": "${token}"}`),
+				NewExit(),
+			}),
+		},
+	})
+
+	assert.ErrorIs(t, err, core.ErrInterrupted)
+}
+
+func TestPromptVar_Execute(t *testing.T) {
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().CommandMode("One-time code: ", "").Return("123456", nil)
+	exCtx.EXPECT().SetVariable("otp", "123456")
+
+	next, err := NewPromptVar("otp", "One-time code:").Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+
+	exCtx = core.NewMockExecutionContext(t)
+	exCtx.EXPECT().CommandMode("One-time code: ", "").Return("", core.ErrInterrupted)
+
+	_, err = NewPromptVar("otp", "One-time code:").Execute(exCtx)
+
+	assert.ErrorIs(t, err, core.ErrInterrupted)
+}
+
+func TestPromptVar_UsedInSubsequentSend(t *testing.T) {
+	wsConn := core.NewMockConnectionHandler(t)
+	wsConn.EXPECT().SetOnMessage(mock.Anything)
+	wsConn.EXPECT().SetOnStatusChange(mock.Anything)
+	wsConn.EXPECT().SetOnFrameError(mock.Anything)
+	wsConn.EXPECT().Send(mock.Anything, `{"otp": "123456"}`).Return(nil)
+
+	editor := core.NewMockEditor(t)
+	editor.EXPECT().SetInput(mock.Anything)
+	editor.EXPECT().CommandMode(mock.Anything, "otp: ", "").Return("123456", nil)
+
+	formater := core.NewMockFormater(t)
+	formater.EXPECT().FormatMessage(mock.Anything, mock.Anything).RunAndReturn(func(_, data string) (string, error) { return data, nil })
+	formater.EXPECT().Summary().Return(false)
+	formater.EXPECT().FormatForFile(mock.Anything, mock.Anything).RunAndReturn(func(_, data string) (string, error) { return data, nil }).Maybe()
+
+	cli := core.NewCLI(NewFactory(nil), wsConn, &bytes.Buffer{}, editor, formater)
+
+	cmd, err := NewFactory(nil).Create("prompt otp")
+	require.NoError(t, err)
+
+	err = cli.Run(context.Background(), core.RunOptions{
+		Commands: []core.Executer{
+			NewSequence([]core.Executer{
+				cmd,
+				NewSend(`{"otp": "${otp}"}`),
+				NewExit(),
+			}),
+		},
+	})
+
+	assert.ErrorIs(t, err, core.ErrInterrupted)
+}
+
+func TestReplayLast_Execute(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		expectedNextCmd core.Executer
+		name            string
+		lastRequest     string
+		expectedErr     string
+		edit            bool
+	}{
+		{
+			name:            "ResendPrevious",
+			lastRequest:     `{"ping": 1}`,
+			expectedNextCmd: NewSend(`{"ping": 1}`),
+		},
+		{
+			name:            "EditPrevious",
+			lastRequest:     `{"ping": 1}`,
+			edit:            true,
+			expectedNextCmd: NewEdit(`{"ping": 1}`),
+		},
+		{
+			name:        "NoPreviousRequest",
+			expectedErr: "no request to replay",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			exCtx := core.NewMockExecutionContext(t)
+			exCtx.EXPECT().LastRequest().Return(tt.lastRequest, tt.lastRequest != "")
+
+			nextCmd, err := NewReplayLast(tt.edit).Execute(exCtx)
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				assert.Nil(t, nextCmd)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedNextCmd, nextCmd)
+			}
+		})
+	}
+}
+
+func TestResendPatch_Execute(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		lastRequest string
+		path        string
+		value       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:        "NestedString",
+			lastRequest: `{"method": "subscribe", "params": {"channel": "ticks", "limit": 5}}`,
+			path:        "params.channel",
+			value:       "trades",
+			expected:    `{"method":"subscribe","params":{"channel":"trades","limit":5}}`,
+		},
+		{
+			name:        "Number",
+			lastRequest: `{"params": {"limit": 5, "id": 12345678901234567890}}`,
+			path:        "params.limit",
+			value:       "10",
+			expected:    `{"params":{"id":12345678901234567890,"limit":10}}`,
+		},
+		{
+			name:        "QuotedNumber",
+			lastRequest: `{"limit": 5}`,
+			path:        "limit",
+			value:       `"10"`,
+			expected:    `{"limit":"10"}`,
+		},
+		{
+			name:        "BoolInArray",
+			lastRequest: `{"flags": [false, false]}`,
+			path:        "flags.1",
+			value:       "true",
+			expected:    `{"flags":[false,true]}`,
+		},
+		{
+			name:        "NewKey",
+			lastRequest: `{"ping": 1}`,
+			path:        "echo",
+			value:       "<hi>",
+			expected:    `{"echo":"<hi>","ping":1}`,
+		},
+		{
+			name:        "NoPreviousRequest",
+			path:        "ping",
+			value:       "2",
+			expectedErr: "no request to patch",
+		},
+		{
+			name:        "NotJSON",
+			lastRequest: "ping",
+			path:        "ping",
+			value:       "2",
+			expectedErr: "fail to parse last request as JSON: invalid character 'p' looking for beginning of value",
+		},
+		{
+			name:        "MissingParent",
+			lastRequest: `{"ping": 1}`,
+			path:        "params.limit",
+			value:       "2",
+			expectedErr: "path not found: params.limit",
+		},
+		{
+			name:        "IndexOutOfRange",
+			lastRequest: `{"flags": [false]}`,
+			path:        "flags.1",
+			value:       "true",
+			expectedErr: "path not found: flags.1",
+		},
+		{
+			name:        "ScalarParent",
+			lastRequest: `{"ping": 1}`,
+			path:        "ping.id",
+			value:       "2",
+			expectedErr: "path not found: ping.id",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			exCtx := core.NewMockExecutionContext(t)
+			exCtx.EXPECT().LastRequest().Return(tt.lastRequest, tt.lastRequest != "")
+
+			nextCmd, err := NewResendPatch(tt.path, tt.value).Execute(exCtx)
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				assert.Nil(t, nextCmd)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, NewSend(tt.expected), nextCmd)
+		})
+	}
+}
+
+func TestResendPatch_SendsPatchedRequest(t *testing.T) {
+	wsConn := core.NewMockConnectionHandler(t)
+	wsConn.EXPECT().SetOnMessage(mock.Anything)
+	wsConn.EXPECT().SetOnStatusChange(mock.Anything)
+	wsConn.EXPECT().SetOnFrameError(mock.Anything)
+	wsConn.EXPECT().Send(mock.Anything, `{"params": {"user": {"id": 1}}}`).Return(nil)
+	wsConn.EXPECT().Send(mock.Anything, `{"params":{"user":{"id":2}}}`).Return(nil)
+
+	editor := core.NewMockEditor(t)
+	editor.EXPECT().SetInput(mock.Anything)
+
+	formater := core.NewMockFormater(t)
+	formater.EXPECT().FormatMessage(mock.Anything, mock.Anything).RunAndReturn(func(_, data string) (string, error) { return data, nil })
+	formater.EXPECT().Summary().Return(false)
+	formater.EXPECT().FormatForFile(mock.Anything, mock.Anything).RunAndReturn(func(_, data string) (string, error) { return data, nil }).Maybe()
+
+	cli := core.NewCLI(NewFactory(nil), wsConn, &bytes.Buffer{}, editor, formater)
+
+	cmd, err := NewFactory(nil).Create("resend-patch params.user.id=2")
+	require.NoError(t, err)
+
+	err = cli.Run(context.Background(), core.RunOptions{
+		Commands: []core.Executer{
+			NewSequence([]core.Executer{
+				NewSend(`{"params": {"user": {"id": 1}}}`),
+				cmd,
+				NewExit(),
+			}),
+		},
+	})
+
+	assert.ErrorIs(t, err, core.ErrInterrupted)
+}
+
+func TestSendBase64_Execute_RoundTrip(t *testing.T) {
+	payload := []byte{0x00, 0x01, 0x02, 0xff}
+
+	format := formater.NewFormat()
+	require.NoError(t, format.SetContentType(formater.ContentTypeBase64))
+
+	encoded, err := format.FormatForFile("Response", string(payload))
+	require.NoError(t, err)
+
+	for _, raw := range []string{encoded, strings.TrimPrefix(encoded, formater.Base64Prefix)} {
+		exCtx := core.NewMockExecutionContext(t)
+		exCtx.EXPECT().SendBinary(payload).Return(nil)
+
+		next, err := NewSendBase64(raw).Execute(exCtx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, NewPrintMsg(core.Message{Type: core.Request, Data: string(payload)}), next)
+	}
+}
+
+func TestSendBase64_Execute_Invalid(t *testing.T) {
+	exCtx := core.NewMockExecutionContext(t)
+
+	next, err := NewSendBase64("AAEC!w==").Execute(exCtx)
+
+	assert.ErrorIs(t, err, core.ErrAborted)
+	assert.EqualError(t, err, "aborted: invalid base64 payload: illegal base64 data at input byte 4")
+	assert.Nil(t, next)
+}
+
+func TestSendGzip_Execute(t *testing.T) {
+	t.Parallel()
+
+	payloadFile := filepath.Join(t.TempDir(), "payload.json")
+	require.NoError(t, os.WriteFile(payloadFile, []byte(`{"from": "file"}`), 0o600))
+
+	tests := []struct {
+		sendErr     error
+		cmd         *SendGzip
+		name        string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "Payload",
+			cmd:      NewSendGzip(`{"ping": 1}`),
+			expected: `{"ping": 1}`,
+		},
+		{
+			name:     "File",
+			cmd:      NewSendGzipFile(payloadFile),
+			expected: `{"from": "file"}`,
+		},
+		{
+			name:        "MissingFile",
+			cmd:         NewSendGzipFile(filepath.Join(t.TempDir(), "missing.json")),
+			expectedErr: "fail to read payload file",
+		},
+		{
+			name:        "SendError",
+			cmd:         NewSendGzip(`{"ping": 1}`),
+			expected:    `{"ping": 1}`,
+			sendErr:     assert.AnError,
+			expectedErr: assert.AnError.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			exCtx := core.NewMockExecutionContext(t)
+			exCtx.EXPECT().ExpandVariables(mock.Anything).RunAndReturn(func(data string) string { return data }).Maybe()
+
+			if tt.expected != "" {
+				exCtx.EXPECT().SendBinary(mock.Anything).RunAndReturn(func(data []byte) error {
+					zr, err := gzip.NewReader(bytes.NewReader(data))
+					require.NoError(t, err)
+
+					decompressed, err := io.ReadAll(zr)
+					require.NoError(t, err)
+					assert.Equal(t, tt.expected, string(decompressed))
+
+					return tt.sendErr
+				})
+			}
+
+			nextCmd, err := tt.cmd.Execute(exCtx)
+
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				assert.Nil(t, nextCmd)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, NewPrintMsg(core.Message{Type: core.Request, Data: tt.expected}), nextCmd)
+		})
+	}
+}
+
+func TestExportMacros_Execute(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "macro.yaml")
+
+	macro := NewMockMacroRepo(t)
+	macro.EXPECT().GetNames().Return([]string{"ping"})
+	macro.EXPECT().Export(path).Return(nil)
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().Print("Macros are exported to " + path + "\n").Return(nil)
+
+	next, err := NewExportMacros(path, macro).Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+}
+
+func TestExportMacros_Execute_Errors(t *testing.T) {
+	_, err := NewExportMacros("macro.yaml", nil).Execute(core.NewMockExecutionContext(t))
+	assert.EqualError(t, err, "no macros to export")
+
+	empty := NewMockMacroRepo(t)
+	empty.EXPECT().GetNames().Return(nil)
+
+	_, err = NewExportMacros("macro.yaml", empty).Execute(core.NewMockExecutionContext(t))
+	assert.EqualError(t, err, "no macros to export")
+
+	failing := NewMockMacroRepo(t)
+	failing.EXPECT().GetNames().Return([]string{"ping"})
+	failing.EXPECT().Export("macro.yaml").Return(assert.AnError)
+
+	_, err = NewExportMacros("macro.yaml", failing).Execute(core.NewMockExecutionContext(t))
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestTee_Execute(t *testing.T) {
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().AddSink("log.jsonl", core.SinkFormatJSONL).Return(nil)
+
+	next, err := NewTee("log.jsonl", core.SinkFormatJSONL).Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+
+	exCtx = core.NewMockExecutionContext(t)
+	exCtx.EXPECT().AddSink("/invalid/log.txt", core.SinkFormatText).Return(assert.AnError)
+	exCtx.EXPECT().Print("Fail to add output file: "+assert.AnError.Error()+"\n", color.FgRed).Return(nil)
+
+	next, err = NewTee("/invalid/log.txt", core.SinkFormatText).Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+}
+
+func TestWaitIdle_Execute_BurstyThenQuiet(t *testing.T) {
+	burst := []core.Message{
+		{Type: core.Response, Data: "first"},
+		{Type: core.Response, Data: "second"},
+		{Type: core.Response, Data: "third"},
+	}
+
+	exCtx := core.NewMockExecutionContext(t)
+
+	for _, msg := range burst {
+		exCtx.EXPECT().WaitForResponse(time.Second).Return(msg, nil).Once()
+		exCtx.EXPECT().FormatMessage(msg, false).Return(msg.Data, nil).Once()
+		exCtx.EXPECT().SummaryMode().Return(false)
+		exCtx.EXPECT().Print(msg.Data + "\n").Return(nil).Once()
+		exCtx.EXPECT().RecordMessage(msg).Return(nil).Once()
+	}
+
+	exCtx.EXPECT().ThrottleResponse().Return(true, 0).Times(len(burst))
+	exCtx.EXPECT().ResponseLatency().Return(0, false).Times(len(burst))
+	exCtx.EXPECT().Print("<-\n", color.FgRed).Return(nil).Times(len(burst))
+	exCtx.EXPECT().ShowRaw().Return(false).Times(len(burst))
+	exCtx.EXPECT().ShouldRecord(core.Response).Return(true).Times(len(burst))
+	exCtx.EXPECT().WaitForResponse(time.Second).Return(core.Message{}, context.DeadlineExceeded).Once()
+
+	var (
+		cmd core.Executer = NewWaitIdle(time.Second, false)
+		err error
+	)
+
+	for cmd != nil {
+		cmd, err = cmd.Execute(exCtx)
+		require.NoError(t, err)
+	}
+}
+
+func TestWaitIdle_Execute_Quiet(t *testing.T) {
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().WaitForResponse(time.Second).Return(core.Message{Type: core.Response, Data: "dropped"}, nil).Twice()
+	exCtx.EXPECT().WaitForResponse(time.Second).Return(core.Message{}, context.DeadlineExceeded).Once()
+
+	var (
+		cmd   core.Executer = NewWaitIdle(time.Second, true)
+		err   error
+		steps int
+	)
+
+	for cmd != nil {
+		cmd, err = cmd.Execute(exCtx)
+		require.NoError(t, err)
+
+		steps++
+	}
+
+	assert.Equal(t, 3, steps)
+}
+
+func TestWaitIdle_Execute_Error(t *testing.T) {
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().WaitForResponse(time.Second).Return(core.Message{}, context.Canceled)
+
+	next, err := NewWaitIdle(time.Second, false).Execute(exCtx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, next)
+}
+
+func TestShapes_Execute(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	received := []core.Message{
+		{Type: core.Response, Data: `{"type":"tick","price":1}`},
+		{Type: core.Response, Data: `{"price":2,"type":"tick"}`},
+		{Type: core.Response, Data: `{"type":"error","error":{"code":1}}`},
+		{Type: core.Response, Data: `pong`},
+	}
+
+	clock := core.NewMockClock(t)
+	clock.EXPECT().Now().Return(start).Times(len(received))
+	clock.EXPECT().Now().Return(start.Add(10 * time.Second)).Once()
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().Clock().Return(clock)
+
+	for _, msg := range received {
+		exCtx.EXPECT().WaitForResponse(10*time.Second).Return(msg, nil).Once()
+	}
+
+	exCtx.EXPECT().Print("Received 4 messages with 2 shapes\n" +
+		"     2  {price,type}\n        e.g. {\"type\":\"tick\",\"price\":1}\n" +
+		"     1  {error:{code},type}\n        e.g. {\"type\":\"error\",\"error\":{\"code\":1}}\n" +
+		"     1  not JSON\n").Return(nil).Once()
+
+	var (
+		cmd  core.Executer = NewShapes(10 * time.Second)
+		err  error
+		msgs []core.Message
+	)
+
+	for cmd != nil {
+		cmd, err = cmd.Execute(exCtx)
+		require.NoError(t, err)
+
+		if seq, ok := cmd.(*Sequence); ok {
+			msgs = append(msgs, seq.subCommands[0].(*PrintMsg).msg)
+			cmd = seq.subCommands[1]
+		}
+	}
+
+	assert.Equal(t, received, msgs)
+}
+
+func TestShapes_Execute_Timeout(t *testing.T) {
+	clock := core.NewMockClock(t)
+	clock.EXPECT().Now().Return(time.Now())
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().Clock().Return(clock)
+	exCtx.EXPECT().WaitForResponse(time.Second).Return(core.Message{}, context.DeadlineExceeded)
+	exCtx.EXPECT().Print("Received 0 messages with 0 shapes\n").Return(nil)
+
+	next, err := NewShapes(time.Second).Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+}
+
+func TestSendClip_Execute(t *testing.T) {
+	clipboard := NewMockClipboard(t)
+	clipboard.EXPECT().Read().Return(`{"ping":1}`, nil)
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().SendRequest(`{"ping":1}`).Return(nil)
+
+	next, err := NewSendClip(clipboard).Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, NewPrintMsg(core.Message{Type: core.Request, Data: `{"ping":1}`}), next)
+}
+
+func TestSendClip_Execute_Unavailable(t *testing.T) {
+	tests := []struct {
+		clipboard func(t *testing.T) Clipboard
+		name      string
+		expected  string
+	}{
+		{
+			name:      "NoClipboard",
+			clipboard: func(*testing.T) Clipboard { return nil },
+			expected:  "Fail to read clipboard: clipboard is not available\n",
+		},
+		{
+			name: "ReadError",
+			clipboard: func(t *testing.T) Clipboard {
+				clipboard := NewMockClipboard(t)
+				clipboard.EXPECT().Read().Return("", errors.New("no display"))
+
+				return clipboard
+			},
+			expected: "Fail to read clipboard: no display\n",
+		},
+		{
+			name: "Empty",
+			clipboard: func(t *testing.T) Clipboard {
+				clipboard := NewMockClipboard(t)
+				clipboard.EXPECT().Read().Return("", nil)
+
+				return clipboard
+			},
+			expected: "Clipboard is empty, nothing to send\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exCtx := core.NewMockExecutionContext(t)
+			exCtx.EXPECT().Print(tt.expected, color.FgRed).Return(nil)
+
+			next, err := NewSendClip(tt.clipboard(t)).Execute(exCtx)
+
+			assert.NoError(t, err)
+			assert.Nil(t, next)
+		})
+	}
+}
@@ -1,5 +1,10 @@
 package command
 
+import (
+	"fmt"
+	"strings"
+)
+
 type ErrUnknownCommand struct {
 	Command string
 }
@@ -72,8 +77,34 @@ func (e ErrUnsupportedVersion) Error() string {
 	return "unsupported version: " + e.Version
 }
 
+type ErrMacroCycle struct {
+	Path []string
+}
+
+func (e ErrMacroCycle) Error() string {
+	return "macro recursion detected: " + strings.Join(e.Path, " -> ")
+}
+
 type ErrInvalidRepeatCommand struct{}
 
 func (e ErrInvalidRepeatCommand) Error() string {
 	return "invalid repeat command"
 }
+
+type ErrSequenceFailed struct {
+	Errors []error
+	Steps  int
+}
+
+func (e ErrSequenceFailed) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+
+	return fmt.Sprintf("%d of %d steps failed: %s", len(e.Errors), e.Steps, strings.Join(msgs, "; "))
+}
+
+func (e ErrSequenceFailed) Unwrap() []error {
+	return e.Errors
+}
@@ -1,6 +1,11 @@
 package command
 
-import "testing"
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
 
 func TestUnknownCommand_Error(t *testing.T) {
 	command := "test"
@@ -29,3 +34,21 @@ func TestTimeout_Error(t *testing.T) {
 		t.Errorf("Error() = %v, want %v", got, want)
 	}
 }
+
+func TestMacroCycle_Error(t *testing.T) {
+	err := ErrMacroCycle{Path: []string{"a", "b", "a"}}
+	want := "macro recursion detected: a -> b -> a"
+
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %v, want %v", got, want)
+	}
+}
+
+func TestSequenceFailed_Error(t *testing.T) {
+	errFirst := errors.New("first")
+	err := ErrSequenceFailed{Errors: []error{errFirst, ErrTimeout{}}, Steps: 5}
+
+	assert.EqualError(t, err, "2 of 5 steps failed: first; timeout")
+	assert.ErrorIs(t, err, errFirst)
+	assert.ErrorIs(t, err, ErrTimeout{})
+}
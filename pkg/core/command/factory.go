@@ -2,6 +2,7 @@ package command
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -11,16 +12,30 @@ import (
 
 type MacroRepo interface {
 	Get(name, argString string) (core.Executer, error)
+	GetNames() []string
+	Export(path string) error
+}
+
+// Clipboard provides the text content of the system clipboard.
+type Clipboard interface {
+	Read() (string, error)
 }
 
 type Factory struct {
-	macro MacroRepo
+	macro     MacroRepo
+	clipboard Clipboard
 }
 
 func NewFactory(macro MacroRepo) *Factory {
 	return &Factory{macro: macro}
 }
 
+// SetClipboard sets the clipboard used by the sendclip command.
+// It takes clipboard of type Clipboard, without it sendclip reports that the clipboard is not available.
+func (f *Factory) SetClipboard(clipboard Clipboard) {
+	f.clipboard = clipboard
+}
+
 func (f *Factory) Create(raw string) (core.Executer, error) {
 	if raw == "" {
 		return nil, &ErrEmptyCommand{}
@@ -32,6 +47,13 @@ func (f *Factory) Create(raw string) (core.Executer, error) {
 	switch cmd {
 	case "exit":
 		return NewExit(), nil
+	case "abort":
+		message := ""
+		if len(parts) > 1 {
+			message = strings.TrimSpace(parts[1])
+		}
+
+		return NewAbort(message), nil
 	case "edit":
 		content := ""
 		if len(parts) > 1 {
@@ -46,37 +68,59 @@ func (f *Factory) Create(raw string) (core.Executer, error) {
 			return nil, &ErrEmptyRequest{}
 		}
 
-		return NewSend(parts[1]), nil
-	case "print":
+		return NewSend(unquoteArg(parts[1])), nil
+	case "sendclip":
+		return NewSendClip(f.clipboard), nil
+	case "send-b64":
+		if len(parts) == 1 || strings.TrimSpace(parts[1]) == "" {
+			return nil, &ErrEmptyRequest{}
+		}
+
+		return NewSendBase64(strings.TrimSpace(parts[1])), nil
+	case "send-gzip":
 		if len(parts) == 1 {
 			return nil, &ErrEmptyRequest{}
 		}
 
-		args := strings.SplitN(parts[1], " ", PartsNumber)
+		return NewSendGzip(unquoteArg(parts[1])), nil
+	case "send-gzip-file":
+		if len(parts) == 1 || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("not enough arguments for send-gzip-file command: %s", raw)
+		}
 
-		if len(args) < PartsNumber {
+		return NewSendGzipFile(strings.TrimSpace(parts[1])), nil
+	case "print":
+		if len(parts) == 1 {
+			return nil, &ErrEmptyRequest{}
+		}
+
+		typeName, msg, found := strings.Cut(parts[1], " ")
+		if !found {
 			return nil, fmt.Errorf("not enough arguments for print command: %s", raw)
 		}
 
 		var msgType core.MessageType
 
-		switch args[0] {
+		switch typeName {
 		case "Request":
 			msgType = core.Request
 		case "Response":
 			msgType = core.Response
 		default:
-			return nil, fmt.Errorf("invalid message type: %s", parts[0])
+			return nil, fmt.Errorf("invalid message type: %s", typeName)
 		}
 
-		msg := args[1]
-
-		return NewPrintMsg(core.Message{Type: msgType, Data: msg}), nil
+		return NewPrintMsg(core.Message{Type: msgType, Data: unquoteArg(msg)}), nil
 	case "wait":
 		timeout := time.Duration(0)
 
 		if len(parts) > 1 {
-			sec, err := strconv.Atoi(parts[1])
+			arg, _, err := cutArg(parts[1])
+			if err != nil {
+				return nil, err
+			}
+
+			sec, err := strconv.Atoi(arg)
 			if err != nil || sec < 0 {
 				return nil, &ErrInvalidTimeout{parts[1]}
 			}
@@ -85,41 +129,461 @@ func (f *Factory) Create(raw string) (core.Executer, error) {
 		}
 
 		return NewWaitForResp(timeout), nil
+	case "wait-idle":
+		if len(parts) < PartsNumber {
+			return nil, fmt.Errorf("not enough arguments for wait-idle command: %s", raw)
+		}
 
-	case "repeat":
+		args := strings.Fields(parts[1])
+		if len(args) == 0 || len(args) > 2 || (len(args) == 2 && args[1] != "quiet") {
+			return nil, fmt.Errorf("invalid wait-idle command, expected wait-idle <duration> [quiet]: %s", raw)
+		}
+
+		idle, err := parseDuration(args[0])
+		if err != nil || idle <= 0 {
+			return nil, &ErrInvalidTimeout{args[0]}
+		}
+
+		return NewWaitIdle(idle, len(args) == 2), nil
+	case "shapes":
+		if len(parts) < PartsNumber || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("not enough arguments for shapes command: %s", raw)
+		}
+
+		window, err := parseDuration(strings.TrimSpace(parts[1]))
+		if err != nil || window <= 0 {
+			return nil, &ErrInvalidTimeout{parts[1]}
+		}
+
+		return NewShapes(window), nil
+	case "wait-close":
+		timeout := time.Duration(0)
+
+		if len(parts) > 1 {
+			arg, _, err := cutArg(parts[1])
+			if err != nil {
+				return nil, err
+			}
+
+			sec, err := strconv.Atoi(arg)
+			if err != nil || sec < 0 {
+				return nil, &ErrInvalidTimeout{parts[1]}
+			}
+
+			timeout = time.Duration(sec) * time.Second
+		}
+
+		return NewWaitClose(timeout), nil
+	case "request":
 		if len(parts) < PartsNumber {
-			return nil, fmt.Errorf("not enough arguments for repeat command: %s", raw)
+			return nil, &ErrEmptyRequest{}
+		}
+
+		timeout, payload, err := cutArg(parts[1])
+		if err != nil {
+			return nil, err
+		}
+
+		if payload == "" {
+			return nil, &ErrEmptyRequest{}
 		}
 
-		repeatParts := strings.SplitN(parts[1], " ", PartsNumber)
+		sec, err := strconv.Atoi(timeout)
+		if err != nil || sec < 0 {
+			return nil, &ErrInvalidTimeout{timeout}
+		}
 
+		return NewRequest(time.Duration(sec)*time.Second, unquoteArg(payload)), nil
+	case "repeat":
 		if len(parts) < PartsNumber {
 			return nil, fmt.Errorf("not enough arguments for repeat command: %s", raw)
 		}
 
-		times, err := strconv.Atoi(repeatParts[0])
+		timesArg, subRaw, err := cutArg(parts[1])
+		if err != nil {
+			return nil, err
+		}
+
+		times, err := strconv.Atoi(timesArg)
 		if err != nil || times <= 0 {
-			return nil, fmt.Errorf("invalid repeat times: %s", repeatParts[0])
+			return nil, fmt.Errorf("invalid repeat times: %s", timesArg)
 		}
 
-		subCommand, err := f.Create(repeatParts[1])
+		subCommand, err := f.Create(unquoteArg(subRaw))
 		if err != nil {
 			return nil, err
 		}
 
 		return NewRepeatCommand(times, subCommand), nil
+	case "retry":
+		if len(parts) < PartsNumber {
+			return nil, fmt.Errorf("not enough arguments for retry command: %s", raw)
+		}
+
+		attemptsArg, rest, err := cutArg(parts[1])
+		if err != nil {
+			return nil, err
+		}
+
+		attempts, err := strconv.Atoi(attemptsArg)
+		if err != nil || attempts <= 0 {
+			return nil, fmt.Errorf("invalid retry attempts: %s", attemptsArg)
+		}
+
+		delayArg, subRaw, err := cutArg(rest)
+		if err != nil {
+			return nil, err
+		}
+
+		delay, err := parseDuration(delayArg)
+		if err != nil || delay < 0 {
+			return nil, &ErrInvalidTimeout{delayArg}
+		}
+
+		subRaw = unquoteArg(subRaw)
+		if subRaw == "" {
+			return nil, fmt.Errorf("invalid retry command, expected retry <n> <delay> <command>: %s", raw)
+		}
+
+		subCommand, err := f.Create(subRaw)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewRetry(attempts, delay, subCommand), nil
+
+	case "watch":
+		if len(parts) < PartsNumber {
+			return nil, fmt.Errorf("not enough arguments for watch command: %s", raw)
+		}
+
+		rest := parts[1]
+		highlight := false
+		runs := 0
+
+		for {
+			if after, ok := strings.CutPrefix(rest, "-d "); ok {
+				highlight = true
+				rest = after
+
+				continue
+			}
 
+			after, ok := strings.CutPrefix(rest, "-n ")
+			if !ok {
+				break
+			}
+
+			countArg, after, err := cutArg(after)
+			if err != nil {
+				return nil, err
+			}
+
+			if runs, err = strconv.Atoi(countArg); err != nil || runs <= 0 {
+				return nil, fmt.Errorf("invalid number of runs for watch command: %s", countArg)
+			}
+
+			rest = after
+		}
+
+		intervalArg, subRaw, err := cutArg(rest)
+		if err != nil {
+			return nil, err
+		}
+
+		interval, err := parseDuration(intervalArg)
+		if err != nil || interval <= 0 {
+			return nil, &ErrInvalidTimeout{intervalArg}
+		}
+
+		subRaw = unquoteArg(subRaw)
+		if subRaw == "" {
+			return nil, fmt.Errorf("invalid watch command, expected watch [-d] [-n <count>] <interval> <command>: %s", raw)
+		}
+
+		subCommand, err := f.Create(subRaw)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewWatch(interval, runs, highlight, subRaw, subCommand), nil
 	case "sleep":
 		if len(parts) < PartsNumber {
 			return nil, fmt.Errorf("not enough arguments for sleep command: %s", raw)
 		}
 
-		sec, err := strconv.Atoi(parts[1])
-		if err != nil || sec < 0 {
+		arg, _, err := cutArg(parts[1])
+		if err != nil {
+			return nil, err
+		}
+
+		duration, err := parseDuration(arg)
+		if err != nil || duration < 0 {
 			return nil, fmt.Errorf("invalid sleep duration: %s", parts[1])
 		}
 
-		return NewSleepCommand(time.Duration(sec) * time.Second), nil
+		return NewSleepCommand(duration), nil
+	case "record":
+		if len(parts) == 1 {
+			return NewRecord(true), nil
+		}
+
+		switch parts[1] {
+		case "on":
+			return NewRecord(true), nil
+		case "off":
+			return NewRecord(false), nil
+		default:
+			return nil, fmt.Errorf("invalid record argument: %s", parts[1])
+		}
+	case "throttle":
+		if len(parts) < PartsNumber || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("not enough arguments for throttle command: %s", raw)
+		}
+
+		arg := strings.TrimSpace(parts[1])
+		if arg == "off" {
+			return NewThrottle(0), nil
+		}
+
+		interval, err := parseDuration(arg)
+		if err != nil || interval <= 0 {
+			return nil, &ErrInvalidTimeout{arg}
+		}
+
+		return NewThrottle(interval), nil
+	case "delay-responses":
+		if len(parts) < PartsNumber || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("not enough arguments for delay-responses command: %s", raw)
+		}
+
+		args := strings.Fields(parts[1])
+		if len(args) == 1 && args[0] == "off" {
+			return NewDelayResponses(0, 0), nil
+		}
+
+		if len(args) > 2 {
+			return nil, fmt.Errorf("invalid delay-responses command, expected delay-responses <base> [jitter]: %s", raw)
+		}
+
+		base, err := parseDuration(args[0])
+		if err != nil || base < 0 {
+			return nil, &ErrInvalidTimeout{args[0]}
+		}
+
+		var jitter time.Duration
+		if len(args) == 2 {
+			if jitter, err = parseDuration(args[1]); err != nil || jitter < 0 {
+				return nil, &ErrInvalidTimeout{args[1]}
+			}
+		}
+
+		return NewDelayResponses(base, jitter), nil
+	case "info":
+		return NewInfo(), nil
+	case "resp-headers":
+		return NewRespHeaders(), nil
+	case "reconnect":
+		return NewReconnect(), nil
+	case "pause":
+		return NewPause(), nil
+	case "resume":
+		return NewResume(), nil
+	case "on-message":
+		if len(parts) < PartsNumber || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("not enough arguments for on-message command: %s", raw)
+		}
+
+		hook := strings.TrimSpace(parts[1])
+		if hook == "off" {
+			return NewOnMessage(""), nil
+		}
+
+		if _, err := f.Create(hook); err != nil {
+			return nil, fmt.Errorf("invalid on-message command: %w", err)
+		}
+
+		return NewOnMessage(hook), nil
+	case "recent":
+		if len(parts) < PartsNumber {
+			return NewRecent(0), nil
+		}
+
+		n, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid number of messages: %s", parts[1])
+		}
+
+		return NewRecent(n), nil
+	case "sizes":
+		if len(parts) < PartsNumber {
+			return NewSizes(0), nil
+		}
+
+		n, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid number of messages: %s", parts[1])
+		}
+
+		return NewSizes(n), nil
+	case "insecure":
+		if len(parts) == 1 {
+			return nil, fmt.Errorf("not enough arguments for insecure command: %s", raw)
+		}
+
+		switch parts[1] {
+		case "on":
+			return NewInsecure(true), nil
+		case "off":
+			return NewInsecure(false), nil
+		default:
+			return nil, fmt.Errorf("invalid insecure argument: %s", parts[1])
+		}
+	case "tee":
+		if len(parts) < PartsNumber {
+			return nil, fmt.Errorf("not enough arguments for tee command: %s", raw)
+		}
+
+		args := strings.Fields(parts[1])
+
+		switch {
+		case len(args) == 1:
+			return NewTee(args[0], core.SinkFormatText), nil
+		case len(args) == 2 && (args[1] == core.SinkFormatText || args[1] == core.SinkFormatJSONL):
+			return NewTee(args[0], args[1]), nil
+		default:
+			return nil, fmt.Errorf("invalid tee command, expected tee <path> [text|jsonl]: %s", raw)
+		}
+	case "replay-last":
+		if len(parts) == 1 {
+			return NewReplayLast(false), nil
+		}
+
+		if parts[1] != "edit" {
+			return nil, fmt.Errorf("invalid replay-last argument: %s", parts[1])
+		}
+
+		return NewReplayLast(true), nil
+	case "resend-patch":
+		if len(parts) < PartsNumber {
+			return nil, fmt.Errorf("not enough arguments for resend-patch command: %s", raw)
+		}
+
+		path, value, ok := strings.Cut(parts[1], "=")
+		path = strings.TrimSpace(path)
+
+		if !ok || path == "" || strings.ContainsAny(path, " \t") {
+			return nil, fmt.Errorf("invalid resend-patch command, expected resend-patch <path>=<value>: %s", raw)
+		}
+
+		return NewResendPatch(path, strings.TrimSpace(value)), nil
+	case "help":
+		var macros []string
+		if f.macro != nil {
+			macros = f.macro.GetNames()
+		}
+
+		if len(parts) == 1 {
+			return NewHelp("", macros), nil
+		}
+
+		topic := strings.TrimSpace(parts[1])
+
+		if _, ok := lookupCommand(topic); !ok && !slices.Contains(macros, topic) {
+			return nil, &ErrUnknownCommand{topic}
+		}
+
+		return NewHelp(topic, macros), nil
+	case "capture":
+		if len(parts) < PartsNumber {
+			return nil, fmt.Errorf("not enough arguments for capture command: %s", raw)
+		}
+
+		args := strings.Fields(parts[1])
+		if len(args) != 3 || args[1] != "as" || args[0] == "" || !isVariableName(args[2]) {
+			return nil, fmt.Errorf("invalid capture command, expected capture <path> as <var>: %s", raw)
+		}
+
+		return NewCapture(args[0], args[2]), nil
+	case "check-shape":
+		if len(parts) == 1 || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("not enough arguments for check-shape command: %s", raw)
+		}
+
+		return NewCheckShape(unquoteArg(parts[1])), nil
+	case "prompt":
+		if len(parts) < PartsNumber {
+			return nil, fmt.Errorf("not enough arguments for prompt command: %s", raw)
+		}
+
+		variable, message, err := cutArg(parts[1])
+		if err != nil {
+			return nil, err
+		}
+
+		if !isVariableName(variable) {
+			return nil, fmt.Errorf("invalid prompt command, expected prompt <var> [message]: %s", raw)
+		}
+
+		message = unquoteArg(strings.TrimSpace(message))
+		if message == "" {
+			message = variable + ":"
+		}
+
+		return NewPromptVar(variable, message), nil
+	case "export-macros":
+		if len(parts) < PartsNumber || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("not enough arguments for export-macros command: %s", raw)
+		}
+
+		return NewExportMacros(strings.TrimSpace(parts[1]), f.macro), nil
+	case "focus":
+		if len(parts) < PartsNumber || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("not enough arguments for focus command: %s", raw)
+		}
+
+		path := strings.TrimSpace(parts[1])
+		if path == "off" {
+			return NewFocus(""), nil
+		}
+
+		return NewFocus(path), nil
+	case "fmt":
+		if len(parts) < PartsNumber {
+			return nil, fmt.Errorf("not enough arguments for fmt command: %s", raw)
+		}
+
+		sub, rest, _ := strings.Cut(strings.TrimSpace(parts[1]), " ")
+
+		switch sub {
+		case "show":
+			return NewFormatShow(), nil
+		case "set":
+			name, value, err := cutArg(rest)
+			if err != nil {
+				return nil, err
+			}
+
+			value = unquoteArg(value)
+			if name == "" || value == "" {
+				return nil, fmt.Errorf("invalid fmt command, expected fmt set <option> <value>: %s", raw)
+			}
+
+			return NewFormatSet(name, value), nil
+		default:
+			return nil, fmt.Errorf("invalid fmt command, expected fmt show or fmt set <option> <value>: %s", raw)
+		}
+	case "format-as":
+		if len(parts) < PartsNumber {
+			return nil, fmt.Errorf("not enough arguments for format-as command: %s", raw)
+		}
+
+		switch parts[1] {
+		case "auto", "json", "xml", "text", "hex", "base64", "socketio", "cbor":
+			return NewFormatAs(parts[1]), nil
+		default:
+			return nil, fmt.Errorf("invalid content type: %s", parts[1])
+		}
 	default:
 		args := ""
 		if len(parts) > 1 {
@@ -133,3 +597,30 @@ func (f *Factory) Create(raw string) (core.Executer, error) {
 		return nil, &ErrUnknownCommand{cmd}
 	}
 }
+
+// parseDuration parses a duration given in seconds, e.g. 5, or in Go duration format, e.g. 500ms.
+func parseDuration(raw string) (time.Duration, error) {
+	if sec, err := strconv.Atoi(raw); err == nil {
+		return time.Duration(sec) * time.Second, nil
+	}
+
+	return time.ParseDuration(raw)
+}
+
+// isVariableName checks if name can be referenced as ${name} in requests.
+func isVariableName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
@@ -13,6 +13,7 @@ import (
 func TestFactory_Create(t *testing.T) {
 	mockMacro := NewMockMacroRepo(t)
 	mockMacro.EXPECT().Get("macro", "").Return(nil, assert.AnError).Maybe()
+	mockMacro.EXPECT().GetNames().Return([]string{"ping"}).Maybe()
 
 	tests := []struct {
 		macro   MacroRepo
@@ -35,6 +36,20 @@ func TestFactory_Create(t *testing.T) {
 			want:    NewExit(),
 			wantErr: false,
 		},
+		{
+			name:    "abort command",
+			raw:     "abort",
+			macro:   nil,
+			want:    NewAbort(""),
+			wantErr: false,
+		},
+		{
+			name:    "abort command with message",
+			raw:     "abort no ack received",
+			macro:   nil,
+			want:    NewAbort("no ack received"),
+			wantErr: false,
+		},
 		{
 			name:    "edit command with content",
 			raw:     "edit some content",
@@ -84,6 +99,741 @@ func TestFactory_Create(t *testing.T) {
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name:    "wait-close command without timeout",
+			raw:     "wait-close",
+			macro:   nil,
+			want:    NewWaitClose(0),
+			wantErr: false,
+		},
+		{
+			name:    "wait-close command with timeout",
+			raw:     "wait-close 3",
+			macro:   nil,
+			want:    NewWaitClose(3 * time.Second),
+			wantErr: false,
+		},
+		{
+			name:    "wait-close command with invalid timeout",
+			raw:     "wait-close -1",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "replay-last command",
+			raw:     "replay-last",
+			macro:   nil,
+			want:    NewReplayLast(false),
+			wantErr: false,
+		},
+		{
+			name:    "replay-last edit command",
+			raw:     "replay-last edit",
+			macro:   nil,
+			want:    NewReplayLast(true),
+			wantErr: false,
+		},
+		{
+			name:    "replay-last command with invalid argument",
+			raw:     "replay-last now",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "resend-patch command",
+			raw:     "resend-patch params.name = \"Bob\"",
+			macro:   nil,
+			want:    NewResendPatch("params.name", `"Bob"`),
+			wantErr: false,
+		},
+		{
+			name:    "resend-patch command with empty value",
+			raw:     "resend-patch id=",
+			macro:   nil,
+			want:    NewResendPatch("id", ""),
+			wantErr: false,
+		},
+		{
+			name:    "resend-patch command without arguments",
+			raw:     "resend-patch",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "resend-patch command without value",
+			raw:     "resend-patch params.limit",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "resend-patch command without path",
+			raw:     "resend-patch =10",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "send-gzip command",
+			raw:     "send-gzip {\"ping\": 1}",
+			macro:   nil,
+			want:    NewSendGzip(`{"ping": 1}`),
+			wantErr: false,
+		},
+		{
+			name:    "send-gzip command without payload",
+			raw:     "send-gzip",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "send-gzip-file command",
+			raw:     "send-gzip-file payload.json",
+			macro:   nil,
+			want:    NewSendGzipFile("payload.json"),
+			wantErr: false,
+		},
+		{
+			name:    "send-gzip-file command without path",
+			raw:     "send-gzip-file",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "help command",
+			raw:     "help",
+			macro:   nil,
+			want:    NewHelp("", nil),
+			wantErr: false,
+		},
+		{
+			name:    "help command for built-in command",
+			raw:     "help send",
+			macro:   nil,
+			want:    NewHelp("send", nil),
+			wantErr: false,
+		},
+		{
+			name:    "help command for macro",
+			raw:     "help ping",
+			macro:   mockMacro,
+			want:    NewHelp("ping", []string{"ping"}),
+			wantErr: false,
+		},
+		{
+			name:    "help command for unknown command",
+			raw:     "help unknown",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "capture command",
+			raw:     "capture data.token as token",
+			macro:   nil,
+			want:    NewCapture("data.token", "token"),
+			wantErr: false,
+		},
+		{
+			name:    "check-shape command",
+			raw:     `check-shape {"id": 1}`,
+			macro:   nil,
+			want:    NewCheckShape(`{"id": 1}`),
+			wantErr: false,
+		},
+		{
+			name:    "prompt command",
+			raw:     "prompt otp 'One-time code:'",
+			macro:   nil,
+			want:    NewPromptVar("otp", "One-time code:"),
+			wantErr: false,
+		},
+		{
+			name:    "prompt command without message",
+			raw:     "prompt otp",
+			macro:   nil,
+			want:    NewPromptVar("otp", "otp:"),
+			wantErr: false,
+		},
+		{
+			name:    "sendclip command",
+			raw:     "sendclip",
+			macro:   nil,
+			want:    NewSendClip(nil),
+			wantErr: false,
+		},
+		{
+			name:    "wait-idle command in seconds",
+			raw:     "wait-idle 2",
+			macro:   nil,
+			want:    NewWaitIdle(2*time.Second, false),
+			wantErr: false,
+		},
+		{
+			name:    "wait-idle command with duration and quiet",
+			raw:     "wait-idle 500ms quiet",
+			macro:   nil,
+			want:    NewWaitIdle(500*time.Millisecond, true),
+			wantErr: false,
+		},
+		{
+			name:    "wait-idle command with invalid duration",
+			raw:     "wait-idle soon",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "wait-idle command with zero duration",
+			raw:     "wait-idle 0",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "wait-idle command with invalid option",
+			raw:     "wait-idle 1s loud",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "shapes command",
+			raw:     "shapes 30",
+			macro:   nil,
+			want:    NewShapes(30 * time.Second),
+			wantErr: false,
+		},
+		{
+			name:    "shapes command without duration",
+			raw:     "shapes",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "tee command",
+			raw:     "tee log.txt",
+			macro:   nil,
+			want:    NewTee("log.txt", core.SinkFormatText),
+			wantErr: false,
+		},
+		{
+			name:    "tee command with format",
+			raw:     "tee log.jsonl jsonl",
+			macro:   nil,
+			want:    NewTee("log.jsonl", core.SinkFormatJSONL),
+			wantErr: false,
+		},
+		{
+			name:    "tee command with invalid format",
+			raw:     "tee log.csv csv",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "tee command without path",
+			raw:     "tee",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "export-macros command",
+			raw:     "export-macros /tmp/macro.yaml",
+			macro:   nil,
+			want:    NewExportMacros("/tmp/macro.yaml", nil),
+			wantErr: false,
+		},
+		{
+			name:    "export-macros command without path",
+			raw:     "export-macros",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "capture command without variable",
+			raw:     "capture data.token",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "capture command with invalid variable",
+			raw:     "capture data.token as 1token",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "check-shape command without payload",
+			raw:     "check-shape ",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "prompt command without variable",
+			raw:     "prompt",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "prompt command with invalid variable",
+			raw:     "prompt 1otp code",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "request command",
+			raw:     "request 5 some request",
+			macro:   nil,
+			want:    NewRequest(5*time.Second, "some request"),
+			wantErr: false,
+		},
+		{
+			name:    "request command without payload",
+			raw:     "request 5",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "request command with invalid timeout",
+			raw:     "request abc some request",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "record command",
+			raw:     "record",
+			macro:   nil,
+			want:    NewRecord(true),
+			wantErr: false,
+		},
+		{
+			name:    "record off command",
+			raw:     "record off",
+			macro:   nil,
+			want:    NewRecord(false),
+			wantErr: false,
+		},
+		{
+			name:    "record command with invalid argument",
+			raw:     "record maybe",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "send-b64 command",
+			raw:     "send-b64 AAEC/w==",
+			macro:   nil,
+			want:    NewSendBase64("AAEC/w=="),
+			wantErr: false,
+		},
+		{
+			name:    "send-b64 command without payload",
+			raw:     "send-b64",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "throttle command",
+			raw:     "throttle 500ms",
+			macro:   nil,
+			want:    NewThrottle(500 * time.Millisecond),
+			wantErr: false,
+		},
+		{
+			name:    "throttle off command",
+			raw:     "throttle off",
+			macro:   nil,
+			want:    NewThrottle(0),
+			wantErr: false,
+		},
+		{
+			name:    "throttle command with invalid interval",
+			raw:     "throttle fast",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "delay-responses command",
+			raw:     "delay-responses 200ms 50ms",
+			macro:   nil,
+			want:    NewDelayResponses(200*time.Millisecond, 50*time.Millisecond),
+			wantErr: false,
+		},
+		{
+			name:    "delay-responses command without jitter",
+			raw:     "delay-responses 1",
+			macro:   nil,
+			want:    NewDelayResponses(time.Second, 0),
+			wantErr: false,
+		},
+		{
+			name:    "delay-responses off command",
+			raw:     "delay-responses off",
+			macro:   nil,
+			want:    NewDelayResponses(0, 0),
+			wantErr: false,
+		},
+		{
+			name:    "delay-responses command with invalid jitter",
+			raw:     "delay-responses 200ms often",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "delay-responses command with too many arguments",
+			raw:     "delay-responses 200ms 50ms 10ms",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "info command",
+			raw:     "info",
+			macro:   nil,
+			want:    NewInfo(),
+			wantErr: false,
+		},
+		{
+			name:    "resp-headers command",
+			raw:     "resp-headers",
+			macro:   nil,
+			want:    NewRespHeaders(),
+			wantErr: false,
+		},
+		{
+			name:    "reconnect command",
+			raw:     "reconnect",
+			macro:   nil,
+			want:    NewReconnect(),
+			wantErr: false,
+		},
+		{
+			name:    "pause command",
+			raw:     "pause",
+			macro:   nil,
+			want:    NewPause(),
+			wantErr: false,
+		},
+		{
+			name:    "resume command",
+			raw:     "resume",
+			macro:   nil,
+			want:    NewResume(),
+			wantErr: false,
+		},
+		{
+			name:    "focus command",
+			raw:     "focus data.user",
+			macro:   nil,
+			want:    NewFocus("data.user"),
+			wantErr: false,
+		},
+		{
+			name:    "focus off command",
+			raw:     "focus off",
+			macro:   nil,
+			want:    NewFocus(""),
+			wantErr: false,
+		},
+		{
+			name:    "focus command without path",
+			raw:     "focus",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "on-message command",
+			raw:     "on-message send {\"ack\":${msg}}",
+			macro:   nil,
+			want:    NewOnMessage("send {\"ack\":${msg}}"),
+			wantErr: false,
+		},
+		{
+			name:    "on-message command off",
+			raw:     "on-message off",
+			macro:   nil,
+			want:    NewOnMessage(""),
+			wantErr: false,
+		},
+		{
+			name:    "on-message command with invalid hook",
+			raw:     "on-message unknown",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "send command with quoted payload",
+			raw:     `send '{"k": "v with spaces"}'`,
+			macro:   nil,
+			want:    NewSend(`{"k": "v with spaces"}`),
+			wantErr: false,
+		},
+		{
+			name:    "print command with quoted payload",
+			raw:     `print Response 'it\'s  spaced'`,
+			macro:   nil,
+			want:    NewPrintMsg(core.Message{Type: core.Response, Data: "it's  spaced"}),
+			wantErr: false,
+		},
+		{
+			name:    "repeat command with quoted sub command",
+			raw:     `repeat 2 'repeat 3 \'send {"k": "v w"}\''`,
+			macro:   nil,
+			want:    NewRepeatCommand(2, NewRepeatCommand(3, NewSend(`{"k": "v w"}`))),
+			wantErr: false,
+		},
+		{
+			name:    "repeat command with unterminated quote",
+			raw:     `repeat '2 send x`,
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "retry command",
+			raw:     `retry 3 2s request 5 {"ping": 1}`,
+			macro:   nil,
+			want:    NewRetry(3, 2*time.Second, NewRequest(5*time.Second, `{"ping": 1}`)),
+			wantErr: false,
+		},
+		{
+			name:    "retry command with invalid attempts",
+			raw:     "retry 0 2s send ping",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "retry command with invalid delay",
+			raw:     "retry 3 soon send ping",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "retry command without sub command",
+			raw:     "retry 3 2s",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "watch command",
+			raw:     "watch 5s send ping",
+			macro:   nil,
+			want:    NewWatch(5*time.Second, 0, false, "send ping", NewSend("ping")),
+			wantErr: false,
+		},
+		{
+			name:    "watch command with highlight in seconds",
+			raw:     "watch -d 2 'send a b'",
+			macro:   nil,
+			want:    NewWatch(2*time.Second, 0, true, "send a b", NewSend("a b")),
+			wantErr: false,
+		},
+		{
+			name:    "watch command with number of runs",
+			raw:     "watch -n 3 -d 1s send ping",
+			macro:   nil,
+			want:    NewWatch(time.Second, 3, true, "send ping", NewSend("ping")),
+			wantErr: false,
+		},
+		{
+			name:    "watch command with invalid number of runs",
+			raw:     "watch -n 0 1s send ping",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "watch command without sub command",
+			raw:     "watch 5s",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "watch command with invalid interval",
+			raw:     "watch soon send ping",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "wait command with quoted timeout",
+			raw:     "wait '5'",
+			macro:   nil,
+			want:    NewWaitForResp(5 * time.Second),
+			wantErr: false,
+		},
+		{
+			name:    "wait-close command with quoted timeout",
+			raw:     "wait-close '3'",
+			macro:   nil,
+			want:    NewWaitClose(3 * time.Second),
+			wantErr: false,
+		},
+		{
+			name:    "sleep command with quoted duration",
+			raw:     "sleep '1s'",
+			macro:   nil,
+			want:    NewSleepCommand(time.Second),
+			wantErr: false,
+		},
+		{
+			name:    "sleep command with unterminated quote",
+			raw:     "sleep '1",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "request command with quoted payload",
+			raw:     `request 5 'a  b'`,
+			macro:   nil,
+			want:    NewRequest(5*time.Second, "a  b"),
+			wantErr: false,
+		},
+		{
+			name:    "sizes command",
+			raw:     "sizes 50",
+			macro:   nil,
+			want:    NewSizes(50),
+			wantErr: false,
+		},
+		{
+			name:    "sizes command with invalid number",
+			raw:     "sizes all",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "recent command",
+			raw:     "recent",
+			macro:   nil,
+			want:    NewRecent(0),
+			wantErr: false,
+		},
+		{
+			name:    "recent command with number",
+			raw:     "recent 5",
+			macro:   nil,
+			want:    NewRecent(5),
+			wantErr: false,
+		},
+		{
+			name:    "recent command with invalid number",
+			raw:     "recent -1",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "insecure on command",
+			raw:     "insecure on",
+			macro:   nil,
+			want:    NewInsecure(true),
+			wantErr: false,
+		},
+		{
+			name:    "insecure off command",
+			raw:     "insecure off",
+			macro:   nil,
+			want:    NewInsecure(false),
+			wantErr: false,
+		},
+		{
+			name:    "insecure command without argument",
+			raw:     "insecure",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "format-as command",
+			raw:     "format-as xml",
+			macro:   nil,
+			want:    NewFormatAs("xml"),
+			wantErr: false,
+		},
+		{
+			name:    "fmt show command",
+			raw:     "fmt show",
+			macro:   nil,
+			want:    NewFormatShow(),
+			wantErr: false,
+		},
+		{
+			name:    "fmt set command",
+			raw:     "fmt set envelope contentType,body",
+			macro:   nil,
+			want:    NewFormatSet("envelope", "contentType,body"),
+			wantErr: false,
+		},
+		{
+			name:    "fmt set command without value",
+			raw:     "fmt set focus",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "fmt command with unknown subcommand",
+			raw:     "fmt reset",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "fmt command without subcommand",
+			raw:     "fmt",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "format-as cbor command",
+			raw:     "format-as cbor",
+			macro:   nil,
+			want:    NewFormatAs("cbor"),
+			wantErr: false,
+		},
+		{
+			name:    "format-as command without content type",
+			raw:     "format-as",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "format-as command with invalid content type",
+			raw:     "format-as yaml",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
 		{
 			name:    "unknown command",
 			raw:     "unknown",
@@ -140,6 +890,15 @@ func TestFactory_Create(t *testing.T) {
 					if gotType.request != send.request {
 						t.Errorf("Factory() type %v, got = %v, want %v", gotType, got, tt.want)
 					}
+				case *Request:
+					request, ok := tt.want.(*Request)
+					if !ok {
+						t.Errorf("Factory() type %v, got = %v, want %v", gotType, got, tt.want)
+					}
+
+					if gotType.request != request.request || gotType.timeout != request.timeout {
+						t.Errorf("Factory() type %v, got = %v, want %v", gotType, got, tt.want)
+					}
 				case *WaitForResp:
 					wait, ok := tt.want.(*WaitForResp)
 					if !ok {
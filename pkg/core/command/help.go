@@ -0,0 +1,331 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ksysoev/wsget/pkg/core"
+)
+
+type commandInfo struct {
+	name        string
+	usage       string
+	description string
+	details     string
+}
+
+// registry describes the built-in commands available in command mode and macros.
+// It is the single source of the help output, so every new command should be added here.
+var registry = []commandInfo{
+	{
+		name:        "send",
+		usage:       "send <payload>",
+		description: "Send the payload to the server",
+		details:     "Session variables referenced as ${name} are expanded before the payload is sent.",
+	},
+	{
+		name:        "sendclip",
+		usage:       "sendclip",
+		description: "Send the content of the system clipboard",
+		details:     "Requires pbpaste on macOS or wl-paste, xclip or xsel on Linux.",
+	},
+	{
+		name:        "send-b64",
+		usage:       "send-b64 <base64>",
+		description: "Decode the base64 payload and send it as a binary frame",
+		details:     "The payload can have the b64: prefix, so messages shown with format-as base64 can be sent back as is.",
+	},
+	{
+		name:        "send-gzip",
+		usage:       "send-gzip <payload>",
+		description: "Compress the payload with gzip and send it as a binary frame",
+		details:     "Session variables referenced as ${name} are expanded before the payload is compressed.",
+	},
+	{
+		name:        "send-gzip-file",
+		usage:       "send-gzip-file <path>",
+		description: "Compress the content of the file with gzip and send it as a binary frame",
+	},
+	{
+		name:        "request",
+		usage:       "request <timeout> <payload>",
+		description: "Send the payload and wait for the response",
+		details:     "The timeout is in seconds, 0 waits without a time limit. Session variables referenced as ${name} are expanded. With --correlate, the response is the message with the same ID as the request.",
+	},
+	{
+		name:        "wait",
+		usage:       "wait [timeout]",
+		description: "Wait for a response from the server",
+		details:     "The timeout is in seconds, 0 or no timeout waits without a time limit. An error is returned if the timeout is reached.",
+	},
+	{
+		name:        "wait-idle",
+		usage:       "wait-idle <duration> [quiet]",
+		description: "Wait until the server sends no messages for the duration",
+		details:     "The duration is in seconds or in Go format, e.g. 500ms. Every received message restarts the wait, messages are printed unless quiet is set.",
+	},
+	{
+		name:        "shapes",
+		usage:       "shapes <duration>",
+		description: "Group messages received for the duration by their JSON shape",
+		details:     "The duration is in seconds or in Go format, e.g. 500ms. Messages are printed as usual, then the count of every set of keys is shown with an example message.",
+	},
+	{
+		name:        "wait-close",
+		usage:       "wait-close [timeout]",
+		description: "Wait until the server closes the connection",
+		details:     "Responses received in the meantime are printed. An error is returned if the connection is still open when the timeout is reached.",
+	},
+	{
+		name:        "edit",
+		usage:       "edit [payload]",
+		description: "Open the request editor",
+		details:     "The editor is seeded with the payload if provided.",
+	},
+	{
+		name:        "repeat",
+		usage:       "repeat <n> <command>",
+		description: "Repeat the command or macro n times",
+		details:     "Example: repeat 5 send {\"ping\": 1}. Wrap the command in single quotes to nest it, \\' stands for a quote inside them.",
+	},
+	{
+		name:        "retry",
+		usage:       "retry <n> <delay> <command>",
+		description: "Run the command or macro until it succeeds, up to n times with a delay between attempts",
+		details:     "Example: retry 3 2s request 5 {\"ping\": 1}. Unlike repeat, failed attempts are retried and the error of the last one is returned.",
+	},
+	{
+		name:        "watch",
+		usage:       "watch [-d] [-n <count>] <interval> <command>",
+		description: "Run the command every interval and redraw its output until Esc or Ctrl+C is pressed",
+		details:     "Example: watch 5s request 2 {\"time\":1}. Use -d to highlight lines changed since the previous run and -n to stop after the number of runs.",
+	},
+	{
+		name:        "sleep",
+		usage:       "sleep <duration>",
+		description: "Pause for the provided duration, in seconds or Go duration format, e.g. 500ms",
+	},
+	{
+		name:        "record",
+		usage:       "record [on|off]",
+		description: "Resume or pause writing messages to the output file",
+	},
+	{
+		name:        "throttle",
+		usage:       "throttle <interval>|off",
+		description: "Show at most one received message per interval in the terminal",
+		details:     "The interval is in seconds or in Go format, e.g. 500ms. Dropped messages are counted and still written to the output file.",
+	},
+	{
+		name:        "delay-responses",
+		usage:       "delay-responses <base> [jitter]|off",
+		description: "Hold every received message for base±jitter before it's delivered",
+		details:     "Durations are in seconds or in Go format, e.g. 500ms. Useful to test timeout handling of clients, sent messages are not delayed.",
+	},
+	{
+		name:        "on-message",
+		usage:       "on-message <command>|off",
+		description: "Run the command for every received message before it's printed",
+		details:     "The message is available as ${msg}, e.g. on-message send {\"ack\":${msg}}. The hook skips echoes of its own messages and is removed if it runs more than 100 times in a second.",
+	},
+	{
+		name:        "recent",
+		usage:       "recent [n]",
+		description: "Print the last sent and received messages kept in memory",
+		details:     "Without n all kept messages are printed, the number of kept messages is set with --recent.",
+	},
+	{
+		name:        "sizes",
+		usage:       "sizes [n]",
+		description: "Report payload sizes of the last sent and received messages",
+		details:     "Prints count, min, mean, median, p95 and max size in bytes per direction over the last n messages kept in memory, all kept messages without n.",
+	},
+	{
+		name:        "info",
+		usage:       "info",
+		description: "Show the details of the connection",
+		details:     "Shows the URL, the remote address, the TLS version and cipher suite, whether the TLS session was resumed, and the subprotocols offered to and selected by the server.",
+	},
+	{
+		name:        "resp-headers",
+		usage:       "resp-headers",
+		description: "Show the headers of the handshake response",
+		details:     "Shows the headers the server sent with the upgrade response of the last handshake, e.g. a session ID, values are not masked.",
+	},
+	{
+		name:        "pause",
+		usage:       "pause",
+		description: "Hold received messages instead of showing them",
+		details:     "Messages are still read from the connection until 1000 of them are held, then reading stops until resume, so no message is dropped. Commands waiting for a message, e.g. request, still receive and show it while paused.",
+	},
+	{
+		name:        "resume",
+		usage:       "resume",
+		description: "Show the messages held by pause and continue showing received messages",
+	},
+	{
+		name:        "insecure",
+		usage:       "insecure on|off",
+		description: "Disable or enable TLS certificate verification",
+		details:     "The setting is used for the next handshake, e.g. when the connection is re-established with --reconnect or reconnect.",
+	},
+	{
+		name:        "reconnect",
+		usage:       "reconnect",
+		description: "Re-establish the connection now",
+		details:     "The new connection uses the same URL, headers and options, session variables and the output are kept. If the handshake fails, the current connection is kept.",
+	},
+	{
+		name:        "export-macros",
+		usage:       "export-macros <path>",
+		description: "Save the macros of the session to a YAML file",
+		details:     "The file uses the macro config format, so it can be copied to the macro directory and loaded back.",
+	},
+	{
+		name:        "tee",
+		usage:       "tee <path> [text|jsonl]",
+		description: "Record the following messages to an additional output file",
+		details:     "text writes messages as in the output file, jsonl writes one JSON object with time, type and data per message.",
+	},
+	{
+		name:        "format-as",
+		usage:       "format-as <json|xml|text|hex|base64|socketio|cbor|auto>",
+		description: "Force the content type used to format messages",
+		details:     "socketio labels the packet type of Socket.IO frames, e.g. 42[...], cbor decodes binary CBOR messages as JSON, auto restores detection from the message content.",
+	},
+	{
+		name:        "fmt",
+		usage:       "fmt show|set <option> <value>",
+		description: "Show or change the formatter options",
+		details:     "Options: content-type, file-format, utf8, strict-json, unwrap-json, show-newlines, summary and table, which are on or off, focus and envelope, which are off or set as with their flags, e.g. fmt set envelope contentType,body.",
+	},
+	{
+		name:        "focus",
+		usage:       "focus <path>|off",
+		description: "Show only the value at the path of JSON messages",
+		details:     "The path is dot separated, numeric segments index arrays. The hidden keys are listed before the value, messages without the path are shown as a whole.",
+	},
+	{
+		name:        "capture",
+		usage:       "capture <path> as <var>",
+		description: "Store a value from the last JSON response in a session variable",
+		details:     "The path is dot separated, numeric segments index arrays. Example: capture data.token as token",
+	},
+	{
+		name:        "check-shape",
+		usage:       "check-shape <payload>",
+		description: "Compare the top-level keys of a JSON payload with the last response",
+		details:     "Missing and extra keys are reported, the payload isn't sent. Example: check-shape {\"id\": 1, \"method\": \"ping\"}",
+	},
+	{
+		name:        "prompt",
+		usage:       "prompt <var> [message]",
+		description: "Ask for the value of a session variable and wait for the input",
+		details:     "The message is shown before the input, <var>: by default. Example: prompt otp 'One-time code:'",
+	},
+	{
+		name:        "replay-last",
+		usage:       "replay-last [edit]",
+		description: "Resend the most recently sent request",
+		details:     "With edit, the request is opened in the request editor first.",
+	},
+	{
+		name:        "resend-patch",
+		usage:       "resend-patch <path>=<value>",
+		description: "Resend the most recently sent JSON request with one field changed",
+		details:     "The path is dot separated, numeric segments index arrays. The value is used as JSON if it's valid, e.g. 42, true or \"42\", and as a string otherwise. Example: resend-patch params.limit=10",
+	},
+	{
+		name:        "help",
+		usage:       "help [command]",
+		description: "Show available commands and macros",
+	},
+	{
+		name:        "exit",
+		usage:       "exit",
+		description: "Close the connection and exit",
+	},
+	{
+		name:        "abort",
+		usage:       "abort [message]",
+		description: "Stop the running macro or repeat and return to the prompt",
+		details:     "Unlike exit, the connection stays open. The message is shown as the reason of the abort.",
+	},
+}
+
+// lookupCommand finds the help entry of the built-in command with the given name.
+func lookupCommand(name string) (commandInfo, bool) {
+	for _, info := range registry {
+		if info.name == name {
+			return info, true
+		}
+	}
+
+	return commandInfo{}, false
+}
+
+type Help struct {
+	topic  string
+	macros []string
+}
+
+// NewHelp creates a new Help command that prints usage of the built-in commands and loaded macros.
+// It takes topic of type string, the name of the command to describe in detail, or empty to list everything,
+// and macros of type []string with the names of the loaded macros.
+// It returns a pointer to a Help instance.
+func NewHelp(topic string, macros []string) *Help {
+	sorted := append([]string(nil), macros...)
+	sort.Strings(sorted)
+
+	return &Help{topic: topic, macros: sorted}
+}
+
+// Execute prints the help text to the CLI output.
+// It returns an error if printing fails.
+func (c *Help) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	return nil, exCtx.Print(c.render())
+}
+
+// render generates the help text from the command registry.
+// It returns the list of commands and macros, or the detailed help of the topic if it is set.
+func (c *Help) render() string {
+	var b strings.Builder
+
+	if c.topic != "" {
+		if info, ok := lookupCommand(c.topic); ok {
+			fmt.Fprintf(&b, "Usage: %s\n\n%s.\n", info.usage, info.description)
+
+			if info.details != "" {
+				fmt.Fprintf(&b, "%s\n", info.details)
+			}
+		} else {
+			fmt.Fprintf(&b, "Usage: %s [args...]\n\nMacro loaded from the macro files.\n", c.topic)
+		}
+
+		return b.String()
+	}
+
+	width := 0
+	for _, info := range registry {
+		width = max(width, len(info.usage))
+	}
+
+	b.WriteString("Commands:\n")
+
+	for _, info := range registry {
+		fmt.Fprintf(&b, "  %-*s  %s\n", width, info.usage, info.description)
+	}
+
+	if len(c.macros) > 0 {
+		b.WriteString("\nMacros:\n")
+
+		for _, name := range c.macros {
+			fmt.Fprintf(&b, "  %s\n", name)
+		}
+	}
+
+	b.WriteString("\nUse help <command> for details.\n")
+
+	return b.String()
+}
@@ -0,0 +1,88 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/ksysoev/wsget/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHelp_Execute_ListsCommandsAndMacros(t *testing.T) {
+	var output string
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().Print(mock.Anything).RunAndReturn(func(data string, _ ...color.Attribute) error {
+		output = data
+		return nil
+	})
+
+	next, err := NewHelp("", []string{"ping", "auth"}).Execute(exCtx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+
+	for _, info := range registry {
+		assert.Contains(t, output, info.usage)
+		assert.Contains(t, output, info.description)
+	}
+
+	assert.Contains(t, output, "send <payload>")
+	assert.Contains(t, output, "wait [timeout]")
+	assert.Contains(t, output, "Macros:\n  auth\n  ping\n")
+}
+
+func TestHelp_Execute_NoMacros(t *testing.T) {
+	help := NewHelp("", nil)
+
+	assert.NotContains(t, help.render(), "Macros:")
+}
+
+func TestHelp_Execute_Topic(t *testing.T) {
+	tests := []struct {
+		name     string
+		topic    string
+		expected string
+	}{
+		{
+			name:     "Built-in command",
+			topic:    "capture",
+			expected: "Usage: capture <path> as <var>\n\nStore a value from the last JSON response in a session variable.\nThe path is dot separated",
+		},
+		{
+			name:     "Command without details",
+			topic:    "exit",
+			expected: "Usage: exit\n\nClose the connection and exit.\n",
+		},
+		{
+			name:     "Macro",
+			topic:    "ping",
+			expected: "Usage: ping [args...]\n\nMacro loaded from the macro files.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exCtx := core.NewMockExecutionContext(t)
+			exCtx.EXPECT().Print(mock.MatchedBy(func(data string) bool {
+				return assert.Contains(t, data, tt.expected)
+			})).Return(nil)
+
+			_, err := NewHelp(tt.topic, []string{"ping"}).Execute(exCtx)
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestHelp_RegistryCoversFactory(t *testing.T) {
+	factory := NewFactory(nil)
+
+	for _, info := range registry {
+		_, err := factory.Create(info.name)
+
+		var unknown *ErrUnknownCommand
+		assert.NotErrorAs(t, err, &unknown, "command %s from registry is not known by factory", info.name)
+	}
+}
@@ -2,13 +2,20 @@ package command
 
 import (
 	"bytes"
+	"fmt"
 	"text/template"
+	"time"
 
+	"github.com/fatih/color"
 	"github.com/ksysoev/wsget/pkg/core"
 )
 
 type Templates struct {
-	list []*template.Template
+	label    *template.Template
+	rawLabel string
+	list     []*template.Template
+	raw      []string
+	timeout  time.Duration
 }
 
 // NewMacro creates a new Templates instance by parsing a list of string templates.
@@ -16,7 +23,7 @@ type Templates struct {
 // It returns a pointer to a Templates instance populated with parsed templates.
 // It returns an error if any of the provided templates fail to parse.
 func NewMacro(rawTemplates []string) (*Templates, error) {
-	tmpls := &Templates{}
+	tmpls := &Templates{raw: append([]string(nil), rawTemplates...)}
 	tmpls.list = make([]*template.Template, len(rawTemplates))
 
 	for i, rawTempl := range rawTemplates {
@@ -31,16 +38,58 @@ func NewMacro(rawTemplates []string) (*Templates, error) {
 	return tmpls, nil
 }
 
+// Raw returns the raw command templates the macro was created from.
+func (t *Templates) Raw() []string {
+	return append([]string(nil), t.raw...)
+}
+
+// SetLabel sets the label printed before the steps of the macro run, so its output can be told apart from other macros.
+// It takes rawLabel of type string, a template with the same arguments as the steps, an empty label is not printed.
+// It returns an error if the label template fails to parse.
+func (t *Templates) SetLabel(rawLabel string) error {
+	if rawLabel == "" {
+		t.label, t.rawLabel = nil, ""
+		return nil
+	}
+
+	tmpl, err := template.New("label").Parse(rawLabel)
+	if err != nil {
+		return err
+	}
+
+	t.label, t.rawLabel = tmpl, rawLabel
+
+	return nil
+}
+
+// Label returns the raw label template of the macro, it's empty if the macro has no label.
+func (t *Templates) Label() string {
+	return t.rawLabel
+}
+
+// SetTimeout sets the time the macro run is allowed to take, 0 removes the limit.
+func (t *Templates) SetTimeout(timeout time.Duration) {
+	t.timeout = timeout
+}
+
+// Timeout returns the time the macro run is allowed to take, it's 0 if the macro has no timeout.
+func (t *Templates) Timeout() time.Duration {
+	return t.timeout
+}
+
 // GetExecuter generates an Executer based on the provided arguments and the templates in the Templates list.
-// It takes args of type []string, representing input arguments for template execution.
+// It takes args of type []string, representing input arguments for template execution,
+// and macro of type MacroRepo, used to build steps that call other macros, nil disables macro calls.
+// Steps calling other macros are expanded when the executer is built.
 // It returns a core.Executer initialized with the evaluated templates or an error if template execution fails.
 // It returns an error if a template execution fails or if command creation from the template output fails.
 // If a single template is evaluated, it returns the respective command; otherwise, returns a sequence of commands.
-func (t *Templates) GetExecuter(args []string) (core.Executer, error) {
+// If the macro has a label, the sequence starts with a MacroLabel command printing it.
+func (t *Templates) GetExecuter(args []string, macro MacroRepo) (core.Executer, error) {
 	data := struct {
 		Args []string
 	}{args}
-	cmds := make([]core.Executer, len(t.list))
+	cmds := make([]core.Executer, len(t.list), len(t.list)+1)
 
 	for i, tmpl := range t.list {
 		var output bytes.Buffer
@@ -48,7 +97,7 @@ func (t *Templates) GetExecuter(args []string) (core.Executer, error) {
 			return nil, err
 		}
 
-		cmd, err := NewFactory(nil).Create(output.String())
+		cmd, err := NewFactory(macro).Create(output.String())
 		if err != nil {
 			return nil, err
 		}
@@ -56,9 +105,41 @@ func (t *Templates) GetExecuter(args []string) (core.Executer, error) {
 		cmds[i] = cmd
 	}
 
+	if t.label != nil {
+		var label bytes.Buffer
+		if err := t.label.Execute(&label, data); err != nil {
+			return nil, err
+		}
+
+		cmds = append([]core.Executer{NewMacroLabel(label.String())}, cmds...)
+	}
+
 	if len(cmds) == 1 {
 		return cmds[0], nil
 	}
 
 	return NewSequence(cmds), nil
 }
+
+type MacroLabel struct {
+	text string
+}
+
+// NewMacroLabel creates a new MacroLabel command that marks the start of the output of a macro.
+// It takes text of type string, the label of the macro with its arguments already substituted.
+// It returns a pointer to a MacroLabel instance.
+func NewMacroLabel(text string) *MacroLabel {
+	return &MacroLabel{text}
+}
+
+// Execute prints the label to the terminal and the output file, so the output of macros run in a row is delineated.
+// It returns an error if printing fails.
+func (c *MacroLabel) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	line := fmt.Sprintf("--- %s ---\n", c.text)
+
+	if err := exCtx.Print(line, color.FgYellow); err != nil {
+		return nil, err
+	}
+
+	return nil, exCtx.PrintToFile(line)
+}
@@ -22,6 +22,52 @@ func (_m *MockMacroRepo) EXPECT() *MockMacroRepo_Expecter {
 	return &MockMacroRepo_Expecter{mock: &_m.Mock}
 }
 
+// Export provides a mock function with given fields: path
+func (_m *MockMacroRepo) Export(path string) error {
+	ret := _m.Called(path)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Export")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(path)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockMacroRepo_Export_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Export'
+type MockMacroRepo_Export_Call struct {
+	*mock.Call
+}
+
+// Export is a helper method to define mock.On call
+//   - path string
+func (_e *MockMacroRepo_Expecter) Export(path interface{}) *MockMacroRepo_Export_Call {
+	return &MockMacroRepo_Export_Call{Call: _e.mock.On("Export", path)}
+}
+
+func (_c *MockMacroRepo_Export_Call) Run(run func(path string)) *MockMacroRepo_Export_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockMacroRepo_Export_Call) Return(_a0 error) *MockMacroRepo_Export_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockMacroRepo_Export_Call) RunAndReturn(run func(string) error) *MockMacroRepo_Export_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Get provides a mock function with given fields: name, argString
 func (_m *MockMacroRepo) Get(name string, argString string) (core.Executer, error) {
 	ret := _m.Called(name, argString)
@@ -81,6 +127,53 @@ func (_c *MockMacroRepo_Get_Call) RunAndReturn(run func(string, string) (core.Ex
 	return _c
 }
 
+// GetNames provides a mock function with no fields
+func (_m *MockMacroRepo) GetNames() []string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetNames")
+	}
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	return r0
+}
+
+// MockMacroRepo_GetNames_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetNames'
+type MockMacroRepo_GetNames_Call struct {
+	*mock.Call
+}
+
+// GetNames is a helper method to define mock.On call
+func (_e *MockMacroRepo_Expecter) GetNames() *MockMacroRepo_GetNames_Call {
+	return &MockMacroRepo_GetNames_Call{Call: _e.mock.On("GetNames")}
+}
+
+func (_c *MockMacroRepo_GetNames_Call) Run(run func()) *MockMacroRepo_GetNames_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockMacroRepo_GetNames_Call) Return(_a0 []string) *MockMacroRepo_GetNames_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockMacroRepo_GetNames_Call) RunAndReturn(run func() []string) *MockMacroRepo_GetNames_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockMacroRepo creates a new instance of MockMacroRepo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockMacroRepo(t interface {
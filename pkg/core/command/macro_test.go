@@ -3,7 +3,11 @@ package command
 import (
 	"testing"
 
+	"github.com/fatih/color"
+	"github.com/ksysoev/wsget/pkg/core"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewMacroTemplates(t *testing.T) {
@@ -112,7 +116,7 @@ func TestTemplates_GetExecuter(t *testing.T) {
 			assert.NoError(t, err)
 
 			// Act
-			executer, err := templates.GetExecuter(tt.args)
+			executer, err := templates.GetExecuter(tt.args, nil)
 
 			// Assert
 			if tt.wantErr {
@@ -130,3 +134,59 @@ func TestTemplates_GetExecuter(t *testing.T) {
 		})
 	}
 }
+
+func TestTemplates_GetExecuter_Label(t *testing.T) {
+	templates, err := NewMacro([]string{"print Response {{index .Args 0}}"})
+	require.NoError(t, err)
+	require.NoError(t, templates.SetLabel("login as {{index .Args 0}}"))
+	assert.Equal(t, "login as {{index .Args 0}}", templates.Label())
+
+	executer, err := templates.GetExecuter([]string{"alice"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, NewSequence([]core.Executer{
+		NewMacroLabel("login as alice"),
+		NewPrintMsg(core.Message{Type: core.Response, Data: "alice"}),
+	}), executer)
+
+	var printed []string
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().Print(mock.Anything, mock.Anything).RunAndReturn(func(data string, _ ...color.Attribute) error {
+		printed = append(printed, data)
+		return nil
+	})
+	exCtx.EXPECT().PrintToFile("--- login as alice ---\n").Return(nil)
+	exCtx.EXPECT().ThrottleResponse().Return(true, 0)
+	exCtx.EXPECT().ResponseLatency().Return(0, false)
+	exCtx.EXPECT().FormatMessage(core.Message{Type: core.Response, Data: "alice"}, false).Return("alice", nil)
+	exCtx.EXPECT().SummaryMode().Return(false)
+	exCtx.EXPECT().Print("alice\n").RunAndReturn(func(data string, _ ...color.Attribute) error {
+		printed = append(printed, data)
+		return nil
+	})
+	exCtx.EXPECT().ShowRaw().Return(false)
+	exCtx.EXPECT().ShouldRecord(core.Response).Return(false)
+
+	cmd := core.Executer(executer)
+	for cmd != nil {
+		cmd, err = cmd.Execute(exCtx)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{"--- login as alice ---\n", "<-\n", "alice\n"}, printed)
+}
+
+func TestTemplates_SetLabel(t *testing.T) {
+	templates, err := NewMacro([]string{"send ping"})
+	require.NoError(t, err)
+
+	assert.Error(t, templates.SetLabel("{{.Args"))
+	assert.Empty(t, templates.Label())
+
+	require.NoError(t, templates.SetLabel("ping"))
+	require.NoError(t, templates.SetLabel(""))
+
+	executer, err := templates.GetExecuter(nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, NewSend("ping"), executer)
+}
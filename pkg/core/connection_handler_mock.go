@@ -23,6 +23,189 @@ func (_m *MockConnectionHandler) EXPECT() *MockConnectionHandler_Expecter {
 	return &MockConnectionHandler_Expecter{mock: &_m.Mock}
 }
 
+// Done provides a mock function with no fields
+func (_m *MockConnectionHandler) Done() <-chan struct{} {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Done")
+	}
+
+	var r0 <-chan struct{}
+	if rf, ok := ret.Get(0).(func() <-chan struct{}); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan struct{})
+		}
+	}
+
+	return r0
+}
+
+// MockConnectionHandler_Done_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Done'
+type MockConnectionHandler_Done_Call struct {
+	*mock.Call
+}
+
+// Done is a helper method to define mock.On call
+func (_e *MockConnectionHandler_Expecter) Done() *MockConnectionHandler_Done_Call {
+	return &MockConnectionHandler_Done_Call{Call: _e.mock.On("Done")}
+}
+
+func (_c *MockConnectionHandler_Done_Call) Run(run func()) *MockConnectionHandler_Done_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockConnectionHandler_Done_Call) Return(_a0 <-chan struct{}) *MockConnectionHandler_Done_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockConnectionHandler_Done_Call) RunAndReturn(run func() <-chan struct{}) *MockConnectionHandler_Done_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Hostname provides a mock function with no fields
+func (_m *MockConnectionHandler) Hostname() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Hostname")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// MockConnectionHandler_Hostname_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Hostname'
+type MockConnectionHandler_Hostname_Call struct {
+	*mock.Call
+}
+
+// Hostname is a helper method to define mock.On call
+func (_e *MockConnectionHandler_Expecter) Hostname() *MockConnectionHandler_Hostname_Call {
+	return &MockConnectionHandler_Hostname_Call{Call: _e.mock.On("Hostname")}
+}
+
+func (_c *MockConnectionHandler_Hostname_Call) Run(run func()) *MockConnectionHandler_Hostname_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockConnectionHandler_Hostname_Call) Return(_a0 string) *MockConnectionHandler_Hostname_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockConnectionHandler_Hostname_Call) RunAndReturn(run func() string) *MockConnectionHandler_Hostname_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Info provides a mock function with no fields
+func (_m *MockConnectionHandler) Info() ConnectionInfo {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Info")
+	}
+
+	var r0 ConnectionInfo
+	if rf, ok := ret.Get(0).(func() ConnectionInfo); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(ConnectionInfo)
+	}
+
+	return r0
+}
+
+// MockConnectionHandler_Info_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Info'
+type MockConnectionHandler_Info_Call struct {
+	*mock.Call
+}
+
+// Info is a helper method to define mock.On call
+func (_e *MockConnectionHandler_Expecter) Info() *MockConnectionHandler_Info_Call {
+	return &MockConnectionHandler_Info_Call{Call: _e.mock.On("Info")}
+}
+
+func (_c *MockConnectionHandler_Info_Call) Run(run func()) *MockConnectionHandler_Info_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockConnectionHandler_Info_Call) Return(_a0 ConnectionInfo) *MockConnectionHandler_Info_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockConnectionHandler_Info_Call) RunAndReturn(run func() ConnectionInfo) *MockConnectionHandler_Info_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Reconnect provides a mock function with given fields: ctx
+func (_m *MockConnectionHandler) Reconnect(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reconnect")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockConnectionHandler_Reconnect_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Reconnect'
+type MockConnectionHandler_Reconnect_Call struct {
+	*mock.Call
+}
+
+// Reconnect is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockConnectionHandler_Expecter) Reconnect(ctx interface{}) *MockConnectionHandler_Reconnect_Call {
+	return &MockConnectionHandler_Reconnect_Call{Call: _e.mock.On("Reconnect", ctx)}
+}
+
+func (_c *MockConnectionHandler_Reconnect_Call) Run(run func(ctx context.Context)) *MockConnectionHandler_Reconnect_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockConnectionHandler_Reconnect_Call) Return(_a0 error) *MockConnectionHandler_Reconnect_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockConnectionHandler_Reconnect_Call) RunAndReturn(run func(context.Context) error) *MockConnectionHandler_Reconnect_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Send provides a mock function with given fields: ctx, msg
 func (_m *MockConnectionHandler) Send(ctx context.Context, msg string) error {
 	ret := _m.Called(ctx, msg)
@@ -70,6 +253,86 @@ func (_c *MockConnectionHandler_Send_Call) RunAndReturn(run func(context.Context
 	return _c
 }
 
+// SendBinary provides a mock function with given fields: ctx, data
+func (_m *MockConnectionHandler) SendBinary(ctx context.Context, data []byte) error {
+	ret := _m.Called(ctx, data)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendBinary")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) error); ok {
+		r0 = rf(ctx, data)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockConnectionHandler_SendBinary_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SendBinary'
+type MockConnectionHandler_SendBinary_Call struct {
+	*mock.Call
+}
+
+// SendBinary is a helper method to define mock.On call
+//   - ctx context.Context
+//   - data []byte
+func (_e *MockConnectionHandler_Expecter) SendBinary(ctx interface{}, data interface{}) *MockConnectionHandler_SendBinary_Call {
+	return &MockConnectionHandler_SendBinary_Call{Call: _e.mock.On("SendBinary", ctx, data)}
+}
+
+func (_c *MockConnectionHandler_SendBinary_Call) Run(run func(ctx context.Context, data []byte)) *MockConnectionHandler_SendBinary_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]byte))
+	})
+	return _c
+}
+
+func (_c *MockConnectionHandler_SendBinary_Call) Return(_a0 error) *MockConnectionHandler_SendBinary_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockConnectionHandler_SendBinary_Call) RunAndReturn(run func(context.Context, []byte) error) *MockConnectionHandler_SendBinary_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetOnFrameError provides a mock function with given fields: _a0
+func (_m *MockConnectionHandler) SetOnFrameError(_a0 func(context.Context, error)) {
+	_m.Called(_a0)
+}
+
+// MockConnectionHandler_SetOnFrameError_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetOnFrameError'
+type MockConnectionHandler_SetOnFrameError_Call struct {
+	*mock.Call
+}
+
+// SetOnFrameError is a helper method to define mock.On call
+//   - _a0 func(context.Context , error)
+func (_e *MockConnectionHandler_Expecter) SetOnFrameError(_a0 interface{}) *MockConnectionHandler_SetOnFrameError_Call {
+	return &MockConnectionHandler_SetOnFrameError_Call{Call: _e.mock.On("SetOnFrameError", _a0)}
+}
+
+func (_c *MockConnectionHandler_SetOnFrameError_Call) Run(run func(_a0 func(context.Context, error))) *MockConnectionHandler_SetOnFrameError_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(func(context.Context, error)))
+	})
+	return _c
+}
+
+func (_c *MockConnectionHandler_SetOnFrameError_Call) Return() *MockConnectionHandler_SetOnFrameError_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockConnectionHandler_SetOnFrameError_Call) RunAndReturn(run func(func(context.Context, error))) *MockConnectionHandler_SetOnFrameError_Call {
+	_c.Run(run)
+	return _c
+}
+
 // SetOnMessage provides a mock function with given fields: _a0
 func (_m *MockConnectionHandler) SetOnMessage(_a0 func(context.Context, []byte)) {
 	_m.Called(_a0)
@@ -103,6 +366,117 @@ func (_c *MockConnectionHandler_SetOnMessage_Call) RunAndReturn(run func(func(co
 	return _c
 }
 
+// SetOnStatusChange provides a mock function with given fields: _a0
+func (_m *MockConnectionHandler) SetOnStatusChange(_a0 func(context.Context, string, error)) {
+	_m.Called(_a0)
+}
+
+// MockConnectionHandler_SetOnStatusChange_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetOnStatusChange'
+type MockConnectionHandler_SetOnStatusChange_Call struct {
+	*mock.Call
+}
+
+// SetOnStatusChange is a helper method to define mock.On call
+//   - _a0 func(context.Context , string , error)
+func (_e *MockConnectionHandler_Expecter) SetOnStatusChange(_a0 interface{}) *MockConnectionHandler_SetOnStatusChange_Call {
+	return &MockConnectionHandler_SetOnStatusChange_Call{Call: _e.mock.On("SetOnStatusChange", _a0)}
+}
+
+func (_c *MockConnectionHandler_SetOnStatusChange_Call) Run(run func(_a0 func(context.Context, string, error))) *MockConnectionHandler_SetOnStatusChange_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(func(context.Context, string, error)))
+	})
+	return _c
+}
+
+func (_c *MockConnectionHandler_SetOnStatusChange_Call) Return() *MockConnectionHandler_SetOnStatusChange_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockConnectionHandler_SetOnStatusChange_Call) RunAndReturn(run func(func(context.Context, string, error))) *MockConnectionHandler_SetOnStatusChange_Call {
+	_c.Run(run)
+	return _c
+}
+
+// SetSkipSSLVerification provides a mock function with given fields: skip
+func (_m *MockConnectionHandler) SetSkipSSLVerification(skip bool) {
+	_m.Called(skip)
+}
+
+// MockConnectionHandler_SetSkipSSLVerification_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetSkipSSLVerification'
+type MockConnectionHandler_SetSkipSSLVerification_Call struct {
+	*mock.Call
+}
+
+// SetSkipSSLVerification is a helper method to define mock.On call
+//   - skip bool
+func (_e *MockConnectionHandler_Expecter) SetSkipSSLVerification(skip interface{}) *MockConnectionHandler_SetSkipSSLVerification_Call {
+	return &MockConnectionHandler_SetSkipSSLVerification_Call{Call: _e.mock.On("SetSkipSSLVerification", skip)}
+}
+
+func (_c *MockConnectionHandler_SetSkipSSLVerification_Call) Run(run func(skip bool)) *MockConnectionHandler_SetSkipSSLVerification_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(bool))
+	})
+	return _c
+}
+
+func (_c *MockConnectionHandler_SetSkipSSLVerification_Call) Return() *MockConnectionHandler_SetSkipSSLVerification_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockConnectionHandler_SetSkipSSLVerification_Call) RunAndReturn(run func(bool)) *MockConnectionHandler_SetSkipSSLVerification_Call {
+	_c.Run(run)
+	return _c
+}
+
+// Status provides a mock function with no fields
+func (_m *MockConnectionHandler) Status() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Status")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// MockConnectionHandler_Status_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Status'
+type MockConnectionHandler_Status_Call struct {
+	*mock.Call
+}
+
+// Status is a helper method to define mock.On call
+func (_e *MockConnectionHandler_Expecter) Status() *MockConnectionHandler_Status_Call {
+	return &MockConnectionHandler_Status_Call{Call: _e.mock.On("Status")}
+}
+
+func (_c *MockConnectionHandler_Status_Call) Run(run func()) *MockConnectionHandler_Status_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockConnectionHandler_Status_Call) Return(_a0 string) *MockConnectionHandler_Status_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockConnectionHandler_Status_Call) RunAndReturn(run func() string) *MockConnectionHandler_Status_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockConnectionHandler creates a new instance of MockConnectionHandler. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockConnectionHandler(t interface {
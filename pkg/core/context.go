@@ -4,26 +4,51 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
+	"text/template"
 	"time"
 
 	"github.com/fatih/color"
 )
 
 type executionContext struct {
-	cli        *CLI
-	outputFile io.Writer
-	ctx        context.Context
+	cli              *CLI
+	clock            Clock
+	correlator       Correlator
+	ctx              context.Context
+	sentAt           time.Time
+	throttle         throttle
+	prompt           *template.Template
+	lastRequest      string
+	newline          string
+	trim             string
+	sinks            []*sink
+	hook             messageHook
+	pause            pauseBuffer
+	initialSendDelay time.Duration
+	fileDisabled     bool
+	onlyRequests     bool
+	onlyResponses    bool
+	showRaw          bool
+	continueOnError  bool
+	echoInput        bool
 }
 
 // newExecutionContext creates a new executionContext instance for the provided CLI and output file.
 // It takes cli of type *CLI, which manages command-line interactions, and outputFile of type io.Writer for output operations.
+// The output file becomes the first text sink of the session if it's not nil.
 // It returns an *executionContext initialized with the given CLI and output writer.
 func newExecutionContext(ctx context.Context, cli *CLI, outputFile io.Writer) *executionContext {
-	return &executionContext{
-		ctx:        ctx,
-		cli:        cli,
-		outputFile: outputFile,
+	c := &executionContext{
+		ctx: ctx,
+		cli: cli,
 	}
+
+	if outputFile != nil {
+		c.sinks = []*sink{{w: outputFile, format: SinkFormatText}}
+	}
+
+	return c
 }
 
 // Print writes the given data to the CLI's output with optional color attributes.
@@ -34,17 +59,165 @@ func (c *executionContext) Print(data string, attr ...color.Attribute) error {
 	return err
 }
 
-// PrintToFile writes the given data to the specified output file in the execution context.
-// It takes data of type string, which is the content to be written to the file.
-// It returns an error if writing to the output file fails or if there is an I/O issue.
+// PrintToFile writes the given data to the text output files in the execution context.
+// It takes data of type string, which is the content to be written to the files.
+// If writing fails, the error is reported once to the CLI output and further writes to the file are skipped
+// until recording is enabled again, so a failing file doesn't interrupt the session.
+// It returns an error only if the failure can't be reported to the CLI output.
 func (c *executionContext) PrintToFile(data string) error {
-	if c.outputFile == nil {
+	if c.fileDisabled {
 		return nil
 	}
 
-	_, err := fmt.Fprintln(c.outputFile, data)
+	for _, s := range c.sinks {
+		if s.format != SinkFormatText {
+			continue
+		}
 
-	return err
+		if err := c.writeSink(s, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RecordMessage writes the message to every output file in the format of the file.
+// It takes msg of type Message, text files get it formatted for a file and JSON lines files get it as a JSON object.
+// Write failures are handled like in PrintToFile.
+// It returns an error if the message can't be formatted or the write failure can't be reported to the CLI output.
+func (c *executionContext) RecordMessage(msg Message) error {
+	if c.fileDisabled {
+		return nil
+	}
+
+	var text *string
+
+	for _, s := range c.sinks {
+		if s.failed {
+			continue
+		}
+
+		var data string
+
+		switch s.format {
+		case SinkFormatJSONL:
+			line, err := encodeJSONRecord(msg, c.Clock().Now())
+			if err != nil {
+				return err
+			}
+
+			data = line
+		default:
+			if text == nil {
+				formatted, err := c.FormatMessage(msg, true)
+				if err != nil {
+					return fmt.Errorf("fail to format message for file: %w", err)
+				}
+
+				formatted += "\n"
+				text = &formatted
+			}
+
+			data = *text
+		}
+
+		if err := c.writeSink(s, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddSink opens an additional output file the following messages of the session are recorded to.
+// It takes path of type string, the file is truncated if it exists, and format of type string, text or jsonl.
+// It returns an error if the format is not supported or the file can't be created.
+func (c *executionContext) AddSink(path, format string) error {
+	s, err := openSink(path, format)
+	if err != nil {
+		return err
+	}
+
+	c.sinks = append(c.sinks, s)
+
+	return nil
+}
+
+// writeSink writes a line of data to the sink, the sink is skipped after the first failed write.
+// It returns an error only if the failure can't be reported to the CLI output.
+func (c *executionContext) writeSink(s *sink, data string) error {
+	if s.failed {
+		return nil
+	}
+
+	if _, err := fmt.Fprintln(s.w, data); err != nil {
+		s.failed = true
+
+		return c.Print(fmt.Sprintf("Fail to write to output file, recording is paused, use `record` to resume: %s\n", err), color.FgRed)
+	}
+
+	return nil
+}
+
+// closeSinks closes the output files opened during the session.
+func (c *executionContext) closeSinks() {
+	for _, s := range c.sinks {
+		if s.closer != nil {
+			_ = s.closer.Close()
+		}
+	}
+}
+
+// ShouldRecord reports whether messages of the given type are written to the output file.
+// It takes msgType of type MessageType, which is the direction of the message.
+// It returns true for both directions unless the session is limited to requests or responses only.
+func (c *executionContext) ShouldRecord(msgType MessageType) bool {
+	if c.onlyRequests == c.onlyResponses {
+		return true
+	}
+
+	if c.onlyRequests {
+		return msgType == Request
+	}
+
+	return msgType == Response
+}
+
+// ShowRaw reports whether printed messages are followed by a hexdump of their raw bytes.
+func (c *executionContext) ShowRaw() bool {
+	return c.showRaw
+}
+
+// SummaryMode reports whether messages are formatted as single line summaries,
+// so they are printed on the same line as their direction.
+func (c *executionContext) SummaryMode() bool {
+	return c.cli.formater.Summary()
+}
+
+// ContinueOnError reports whether sequences of commands, e.g. macros and input files, keep running after a failed step.
+func (c *executionContext) ContinueOnError() bool {
+	return c.continueOnError
+}
+
+// SetRecording enables or disables writing messages to the output files.
+// It takes enabled of type bool, which resumes writing to the files if true and pauses it otherwise.
+// Enabling recording also resumes writing to files that were skipped after a failed write.
+// It returns an error if no output file is configured for the session.
+func (c *executionContext) SetRecording(enabled bool) error {
+	if len(c.sinks) == 0 {
+		return fmt.Errorf("output file is not set")
+	}
+
+	c.fileDisabled = !enabled
+
+	if enabled {
+		for _, s := range c.sinks {
+			s.failed = false
+		}
+	}
+
+	return nil
 }
 
 // FormatMessage formats a Message based on its type and data.
@@ -59,30 +232,239 @@ func (c *executionContext) FormatMessage(msg Message, noColor bool) (string, err
 }
 
 // SendRequest sends a request message through the execution context's WebSocket connection.
-// It takes req of type string, which represents the request to be sent.
-// It returns an error if the WebSocket connection fails to send the request.
+// It takes req of type string, which represents the request to be sent, it's passed through the outbound middlewares.
+// It returns an error if a middleware fails or the WebSocket connection fails to send the request.
 func (c *executionContext) SendRequest(req string) error {
-	return c.cli.wsConn.Send(c.ctx, req)
+	data, err := applyMiddleware(c.cli.outbound, req)
+	if err != nil {
+		return err
+	}
+
+	c.delayFirstSend()
+	c.cli.touch()
+	c.cli.sent.Add(1)
+
+	if err := c.cli.wsConn.Send(c.ctx, data); err != nil {
+		return err
+	}
+
+	c.sentAt = c.Clock().Now()
+	c.lastRequest = req
+	c.cli.recent.add(Message{Type: Request, Data: req})
+	c.cli.events.message(Message{Type: Request, Data: req})
+	c.hookSent(data)
+
+	return nil
+}
+
+// SendBinary sends data as a binary frame through the execution context's WebSocket connection.
+// It takes data of type []byte, which is sent as is.
+// It returns an error if the WebSocket connection fails to send the data.
+func (c *executionContext) SendBinary(data []byte) error {
+	c.delayFirstSend()
+	c.cli.touch()
+	c.cli.sent.Add(1)
+
+	if err := c.cli.wsConn.SendBinary(c.ctx, data); err != nil {
+		return err
+	}
+
+	c.sentAt = c.Clock().Now()
+	c.cli.recent.add(Message{Type: Request, Data: string(data)})
+	c.cli.events.message(Message{Type: Request, Data: string(data)})
+	c.hookSent(string(data))
+
+	return nil
+}
+
+// SetThrottle limits how often received messages are shown in the terminal.
+// It takes interval of type time.Duration, at most one message is shown per interval, 0 shows every message.
+func (c *executionContext) SetThrottle(interval time.Duration) {
+	c.throttle = throttle{interval: interval}
+}
+
+// SetResponseDelay holds every received message for an artificial delay before it's delivered to commands.
+// It takes base and jitter of type time.Duration, each message is held for a random duration within base±jitter,
+// 0 base disables the delay. Sent messages are not delayed.
+func (c *executionContext) SetResponseDelay(base, jitter time.Duration) {
+	c.cli.latency.set(base, jitter)
+}
+
+// ThrottleResponse decides if a received message should be shown in the terminal according to the throttle interval.
+// It returns true if the message should be shown and the number of messages dropped since the last shown one.
+func (c *executionContext) ThrottleResponse() (show bool, dropped int) {
+	if c.throttle.interval <= 0 {
+		return true, 0
+	}
+
+	return c.throttle.allow(c.Clock().Now())
+}
+
+// ResponseLatency returns the time elapsed since the last request was sent, so the first response after it can be
+// annotated with it. The send time is reset, so later responses to the same request are not annotated.
+// The elapsed time is collected for the session report.
+// It returns false as the second value if nothing was sent since the previous call.
+func (c *executionContext) ResponseLatency() (time.Duration, bool) {
+	if c.sentAt.IsZero() {
+		return 0, false
+	}
+
+	elapsed := c.Clock().Now().Sub(c.sentAt)
+	c.sentAt = time.Time{}
+	c.cli.responseTimes.add(elapsed)
+
+	return elapsed, true
+}
+
+// SetSkipSSLVerification enables or disables verification of the server certificate for the next handshake.
+// It takes skip of type bool, if true the certificate is not verified when the connection is re-established.
+func (c *executionContext) SetSkipSSLVerification(skip bool) {
+	c.cli.wsConn.SetSkipSSLVerification(skip)
+}
+
+// Reconnect re-establishes the connection on demand, the session state, e.g. variables and the transcript, is kept.
+// The first send over the new connection waits for the initial send delay, as after an automatic reconnect.
+// It returns an error if the connection can't be re-established, the current connection is kept in this case.
+func (c *executionContext) Reconnect() error {
+	if err := c.cli.wsConn.Reconnect(c.ctx); err != nil {
+		return err
+	}
+
+	c.cli.sendDelayPending.Store(true)
+
+	return nil
+}
+
+// ConnectionInfo returns the details of the last handshake, e.g. the remote address and the negotiated subprotocol.
+func (c *executionContext) ConnectionInfo() ConnectionInfo {
+	return c.cli.wsConn.Info()
+}
+
+// delayFirstSend waits for the initial send delay if nothing was sent since the connection was (re)established.
+// Some servers don't accept application messages right after the handshake, later sends are not delayed.
+func (c *executionContext) delayFirstSend() {
+	if c.initialSendDelay <= 0 || !c.cli.sendDelayPending.Swap(false) {
+		return
+	}
+
+	c.Clock().Sleep(c.initialSendDelay)
+}
+
+// LastRequest returns the most recent request successfully sent in the session.
+// It returns false as the second value if no request has been sent yet.
+func (c *executionContext) LastRequest() (string, bool) {
+	return c.lastRequest, c.lastRequest != ""
 }
 
 // WaitForResponse waits for a response message from the CLI within a specified timeout period.
 // It takes timeout of type time.Duration to define the maximum wait time. If timeout is 0, it waits indefinitely.
 // It returns a Message containing the received data and an error if the context deadline exceeds or other issues occur.
 func (c *executionContext) WaitForResponse(timeout time.Duration) (Message, error) {
-	ctx := c.ctx
+	select {
+	case msg := <-c.cli.control:
+		return msg, nil
+	default:
+	}
 
-	if timeout > 0 {
-		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, timeout)
-		defer cancel()
+	timer := c.after(timeout)
+
+	for {
+		select {
+		case msg := <-c.cli.control:
+			return msg, nil
+		case msg := <-c.cli.messages:
+			if err := c.printPendingMarkers(); err != nil {
+				return Message{}, err
+			}
+
+			if printed, err := c.printMarker(msg); printed {
+				if err != nil {
+					return Message{}, err
+				}
+
+				continue
+			}
+
+			return msg, nil
+		case <-timer:
+			return Message{}, context.DeadlineExceeded
+		case <-c.ctx.Done():
+			return Message{}, c.ctx.Err()
+		}
 	}
+}
 
-	select {
-	case msg := <-c.cli.messages:
-		return msg, nil
-	case <-ctx.Done():
-		return Message{}, ctx.Err()
+// WaitForClose waits until the connection is closed by the server within a specified timeout period.
+// It takes timeout of type time.Duration to define the maximum wait time. If timeout is 0, it waits indefinitely.
+// It returns closed set to true once the connection is closed, or a Message if one arrives before the connection is closed,
+// so the caller can handle it and keep waiting. It returns an error if the timeout elapses or the context is canceled.
+func (c *executionContext) WaitForClose(timeout time.Duration) (msg Message, closed bool, err error) {
+	timer := c.after(timeout)
+
+	for {
+		select {
+		case <-c.cli.wsConn.Done():
+			return Message{}, true, nil
+		case msg := <-c.cli.control:
+			return msg, false, nil
+		case msg := <-c.cli.messages:
+			if err := c.printPendingMarkers(); err != nil {
+				return Message{}, false, err
+			}
+
+			if printed, err := c.printMarker(msg); printed {
+				if err != nil {
+					return Message{}, false, err
+				}
+
+				continue
+			}
+
+			return msg, false, nil
+		case <-timer:
+			return Message{}, false, context.DeadlineExceeded
+		case <-c.ctx.Done():
+			// The session context is canceled right after the connection is closed, so closing wins over cancellation.
+			select {
+			case <-c.cli.wsConn.Done():
+				return Message{}, true, nil
+			default:
+				return Message{}, false, c.ctx.Err()
+			}
+		}
+	}
+}
+
+// WaitForInterrupt waits for the timeout on the session clock while watching the keyboard,
+// so long-running commands, e.g. watch, can be stopped with Esc, Ctrl+C or Ctrl+D. Other keys are ignored.
+// It takes timeout of type time.Duration, if timeout is 0, it waits for a key indefinitely.
+// It returns true if one of the keys is pressed before the timeout elapses, and an error if the context is canceled.
+func (c *executionContext) WaitForInterrupt(timeout time.Duration) (bool, error) {
+	timer := c.after(timeout)
+
+	for {
+		select {
+		case event := <-c.cli.inputStream:
+			switch event.Key {
+			case KeyEsc, KeyCtrlC, KeyCtrlD:
+				return true, nil
+			}
+		case <-timer:
+			return false, nil
+		case <-c.ctx.Done():
+			return false, c.ctx.Err()
+		}
+	}
+}
+
+// after returns a channel that receives a value once the timeout elapses on the session clock.
+// It returns nil if timeout is not positive, so receiving from it blocks forever.
+func (c *executionContext) after(timeout time.Duration) <-chan time.Time {
+	if timeout <= 0 {
+		return nil
 	}
+
+	return c.Clock().After(timeout)
 }
 
 // EditorMode allows the user to edit text in an editor with a provided initial buffer.
@@ -92,16 +474,151 @@ func (c *executionContext) EditorMode(initBuffer string) (string, error) {
 	return c.cli.editor.Edit(c.ctx, initBuffer)
 }
 
-// CommandMode initiates command mode in the editor with the provided initial buffer.
-// It takes initBuffer of type string, which is the input buffer to initialize the command mode.
+// CommandMode initiates command mode in the editor with the provided prompt and initial buffer.
+// It takes prompt of type string, which is shown before the input, and initBuffer of type string to initialize the command mode.
 // It returns a string representing the final buffer after editing and an error if command mode fails.
-func (c *executionContext) CommandMode(initBuffer string) (string, error) {
-	return c.cli.editor.CommandMode(c.ctx, initBuffer)
+func (c *executionContext) CommandMode(prompt, initBuffer string) (string, error) {
+	return c.cli.editor.CommandMode(c.ctx, prompt, initBuffer)
+}
+
+// Prompt renders the command prompt with the current state of the connection and the session.
+// It returns the rendered prompt, DefaultPrompt if no prompt format is configured,
+// and an error if the template fails to render.
+func (c *executionContext) Prompt() (string, error) {
+	if c.prompt == nil {
+		return DefaultPrompt, nil
+	}
+
+	data := PromptData{
+		Host:     c.cli.wsConn.Hostname(),
+		Status:   c.cli.wsConn.Status(),
+		Sent:     c.cli.sent.Load(),
+		Received: c.cli.received.Load(),
+	}
+
+	var buf strings.Builder
+	if err := c.prompt.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("fail to render prompt: %w", err)
+	}
+
+	return buf.String(), nil
 }
 
 // CreateCommand creates an Executer from a raw command string.
 // It takes a raw string representing the command to be created.
+// If input echoing is enabled, the command prints its raw form before it's executed.
 // It returns an Executer and an error if the command cannot be created.
 func (c *executionContext) CreateCommand(raw string) (Executer, error) {
-	return c.cli.cmdFactory.Create(raw)
+	cmd, err := c.cli.cmdFactory.Create(raw)
+	if err != nil || !c.echoInput {
+		return cmd, err
+	}
+
+	return &echoCommand{cmd: cmd, raw: raw}, nil
+}
+
+// SetContentType forces the content type used to format subsequent messages.
+// It takes contentType of type string, which is passed to the formater, "auto" restores detection from message data.
+// It returns an error if the formater doesn't support the content type.
+func (c *executionContext) SetContentType(contentType string) error {
+	return c.cli.formater.SetContentType(contentType)
+}
+
+// SetFocus limits the terminal output of JSON messages to the value at the dot separated path.
+// It takes path of type string, messages that don't have the path and all messages if it's empty are shown as a whole.
+func (c *executionContext) SetFocus(path string) {
+	c.cli.formater.SetFocus(path)
+}
+
+// FormatOptions returns the current options of the formater by their names.
+func (c *executionContext) FormatOptions() map[string]string {
+	return c.cli.formater.Options()
+}
+
+// SetFormatOption changes the formater option with the given name, it affects subsequent messages.
+// It returns an error if the option is unknown or the value is not valid for it.
+func (c *executionContext) SetFormatOption(name, value string) error {
+	return c.cli.formater.SetOption(name, value)
+}
+
+// LastResponse returns the last message received from the server in the session.
+// It returns false as the second value if no response has been received yet.
+func (c *executionContext) LastResponse() (Message, bool) {
+	msg := c.cli.lastResponse.Load()
+	if msg == nil {
+		return Message{}, false
+	}
+
+	return *msg, true
+}
+
+// RecentMessages returns up to n last messages sent and received in the session, from the oldest to the newest.
+// It takes n of type int, non-positive n returns all messages kept in memory.
+func (c *executionContext) RecentMessages(n int) []Message {
+	return c.cli.recent.last(n)
+}
+
+// SetVariable stores the value in the session variable store under the given name.
+// It takes name of type string and value of type string, an existing variable with the same name is overwritten.
+func (c *executionContext) SetVariable(name, value string) {
+	c.cli.vars.Set(name, value)
+}
+
+// ExpandVariables replaces ${name} references in data with values from the session variable store.
+// It takes data of type string, references to variables that are not set are left unchanged.
+// It returns the data with all known variables expanded.
+func (c *executionContext) ExpandVariables(data string) string {
+	return c.cli.vars.Expand(data)
+}
+
+// NormalizeNewlines converts the line endings of data to the newline mode of the session.
+// It takes data of type string, which is the request about to be sent.
+// It returns data unchanged unless a newline mode is provided in RunOptions.
+func (c *executionContext) NormalizeNewlines(data string) string {
+	return NormalizeNewlines(data, c.newline)
+}
+
+// TrimPayload strips whitespace from data according to the trim mode of the session.
+// It takes data of type string, which is the request about to be sent.
+// It returns data unchanged unless a trim mode is provided in RunOptions.
+func (c *executionContext) TrimPayload(data string) string {
+	return TrimPayload(data, c.trim)
+}
+
+// Context returns the context of the session, it's canceled when the session is stopped.
+func (c *executionContext) Context() context.Context {
+	return c.ctx
+}
+
+// LimitTime bounds the session context with the timeout, so waiting and sending commands executed in the meantime
+// fail with context.DeadlineExceeded once the timeout elapses.
+// It takes timeout of type time.Duration, the time left for the commands.
+// It returns restore, the function releasing the limit and bringing back the previous context, it must be called
+// once the limited commands are done.
+func (c *executionContext) LimitTime(timeout time.Duration) (restore func()) {
+	parent := c.ctx
+
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	c.ctx = ctx
+
+	return func() {
+		cancel()
+		c.ctx = parent
+	}
+}
+
+// Correlator returns the correlator matching requests with their responses in the session.
+// It returns nil unless one is provided in RunOptions, responses are not matched by ID in this case.
+func (c *executionContext) Correlator() Correlator {
+	return c.correlator
+}
+
+// Clock returns the clock used by time-dependent commands in the session.
+// It returns the real clock unless another one is provided in RunOptions.
+func (c *executionContext) Clock() Clock {
+	if c.clock == nil {
+		return realClock{}
+	}
+
+	return c.clock
 }
@@ -5,17 +5,20 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewExecutionContext(t *testing.T) {
 	tests := []struct {
 		cli        *CLI
-		outputFile *bytes.Buffer
+		outputFile io.Writer
 		name       string
 	}{
 		{
@@ -41,7 +44,12 @@ func TestNewExecutionContext(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			executionContext := newExecutionContext(context.Background(), tt.cli, tt.outputFile)
 			assert.Equal(t, tt.cli, executionContext.cli, "CLI should match the input CLI")
-			assert.Equal(t, tt.outputFile, executionContext.outputFile, "Output file should match the input outputFile")
+
+			if tt.outputFile == nil {
+				assert.Empty(t, executionContext.sinks)
+			} else {
+				assert.Equal(t, []*sink{{w: tt.outputFile, format: SinkFormatText}}, executionContext.sinks)
+			}
 		})
 	}
 }
@@ -100,6 +108,141 @@ func TestExecutionContext_SendRequest(t *testing.T) {
 	}
 }
 
+func TestExecutionContext_InitialSendDelay(t *testing.T) {
+	ctx := context.Background()
+
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().Send(ctx, mock.Anything).Return(nil)
+	wsConn.EXPECT().SendBinary(ctx, mock.Anything).Return(nil)
+
+	clock := NewMockClock(t)
+	clock.EXPECT().Sleep(500 * time.Millisecond).Twice()
+	clock.EXPECT().Now().Return(time.Now())
+
+	cli := &CLI{wsConn: wsConn, messages: make(chan Message, 2)}
+	cli.sendDelayPending.Store(true)
+
+	ec := &executionContext{
+		cli:              cli,
+		ctx:              ctx,
+		clock:            clock,
+		initialSendDelay: 500 * time.Millisecond,
+	}
+
+	// The first send after connect is delayed, later ones are not.
+	require.NoError(t, ec.SendRequest("first"))
+	require.NoError(t, ec.SendRequest("second"))
+	require.NoError(t, ec.SendBinary([]byte("third")))
+
+	// The first send after a reconnect is delayed again.
+	cli.onStatusChange(ctx, StatusReconnecting, fmt.Errorf("connection reset"))
+	cli.onStatusChange(ctx, StatusConnected, nil)
+
+	require.NoError(t, ec.SendBinary([]byte("fourth")))
+	require.NoError(t, ec.SendRequest("fifth"))
+}
+
+func TestExecutionContext_InitialSendDelay_ManualReconnect(t *testing.T) {
+	ctx := context.Background()
+
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().Send(ctx, mock.Anything).Return(nil)
+	wsConn.EXPECT().Reconnect(ctx).Return(assert.AnError).Once()
+	wsConn.EXPECT().Reconnect(ctx).Return(nil).Once()
+
+	clock := NewMockClock(t)
+	clock.EXPECT().Sleep(500 * time.Millisecond).Once()
+	clock.EXPECT().Now().Return(time.Now())
+
+	cli := &CLI{wsConn: wsConn, messages: make(chan Message, 1)}
+
+	ec := &executionContext{
+		cli:              cli,
+		ctx:              ctx,
+		clock:            clock,
+		initialSendDelay: 500 * time.Millisecond,
+	}
+
+	// The current connection is kept after a failed reconnect, so the send is not delayed.
+	require.ErrorIs(t, ec.Reconnect(), assert.AnError)
+	require.NoError(t, ec.SendRequest("first"))
+
+	// The first send after a manual reconnect is delayed, the reconnect status doesn't re-arm the delay itself.
+	cli.onStatusChange(ctx, StatusConnected, nil)
+	require.NoError(t, ec.Reconnect())
+	require.NoError(t, ec.SendRequest("second"))
+	require.NoError(t, ec.SendRequest("third"))
+}
+
+func TestExecutionContext_SetSkipSSLVerification(t *testing.T) {
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().SetSkipSSLVerification(true).Once()
+
+	ec := &executionContext{cli: &CLI{wsConn: wsConn}}
+
+	ec.SetSkipSSLVerification(true)
+}
+
+func TestExecutionContext_Reconnect(t *testing.T) {
+	ctx := context.Background()
+
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().Reconnect(ctx).Return(nil).Once()
+	wsConn.EXPECT().Reconnect(ctx).Return(assert.AnError).Once()
+
+	ec := &executionContext{cli: &CLI{wsConn: wsConn}, ctx: ctx}
+
+	assert.NoError(t, ec.Reconnect())
+	assert.ErrorIs(t, ec.Reconnect(), assert.AnError)
+}
+
+func TestExecutionContext_ConnectionInfo(t *testing.T) {
+	info := ConnectionInfo{URL: "wss://example.com", Subprotocol: "json"}
+
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().Info().Return(info)
+
+	ec := &executionContext{cli: &CLI{wsConn: wsConn}}
+
+	assert.Equal(t, info, ec.ConnectionInfo())
+}
+
+func TestExecutionContext_ResponseLatency(t *testing.T) {
+	ctx := context.Background()
+	sentAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().Send(ctx, "ping").Return(nil)
+	wsConn.EXPECT().SendBinary(ctx, []byte("ping")).Return(nil)
+
+	clock := NewMockClock(t)
+	ec := &executionContext{cli: &CLI{wsConn: wsConn}, ctx: ctx, clock: clock}
+
+	_, ok := ec.ResponseLatency()
+	assert.False(t, ok)
+
+	clock.EXPECT().Now().Return(sentAt).Once()
+	require.NoError(t, ec.SendRequest("ping"))
+
+	clock.EXPECT().Now().Return(sentAt.Add(123 * time.Millisecond)).Once()
+
+	elapsed, ok := ec.ResponseLatency()
+	assert.True(t, ok)
+	assert.Equal(t, 123*time.Millisecond, elapsed)
+
+	_, ok = ec.ResponseLatency()
+	assert.False(t, ok, "only the first response after a request is annotated")
+
+	clock.EXPECT().Now().Return(sentAt).Once()
+	require.NoError(t, ec.SendBinary([]byte("ping")))
+
+	clock.EXPECT().Now().Return(sentAt.Add(2 * time.Second)).Once()
+
+	elapsed, ok = ec.ResponseLatency()
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Second, elapsed)
+}
+
 func TestExecutionContext_Print(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -160,7 +303,7 @@ func TestExecutionContext_CommandMode(t *testing.T) {
 	mockEditor := NewMockEditor(t)
 	ctx := context.Background()
 
-	mockEditor.EXPECT().CommandMode(ctx, "test").Return("test", nil)
+	mockEditor.EXPECT().CommandMode(ctx, ">", "test").Return("test", nil)
 
 	ec := &executionContext{
 		ctx: ctx,
@@ -169,11 +312,93 @@ func TestExecutionContext_CommandMode(t *testing.T) {
 		},
 	}
 
-	res, err := ec.CommandMode("test")
+	res, err := ec.CommandMode(">", "test")
 	assert.NoError(t, err, "Expected no error")
 	assert.Equal(t, "test", res, "Expected response to match")
 }
 
+func TestExecutionContext_Prompt(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		expected string
+	}{
+		{
+			name:     "Default prompt",
+			format:   "",
+			expected: ":",
+		},
+		{
+			name:     "Connection state",
+			format:   "{{.Host}} [{{.Status}}] {{.Sent}}/{{.Received}}:",
+			expected: "example.com [connected] 2/3:",
+		},
+		{
+			name:     "Static prompt",
+			format:   "> ",
+			expected: "> ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wsConn := NewMockConnectionHandler(t)
+			wsConn.EXPECT().Hostname().Return("example.com").Maybe()
+			wsConn.EXPECT().Status().Return("connected").Maybe()
+
+			prompt, err := newPromptTemplate(tt.format)
+			require.NoError(t, err)
+
+			ec := &executionContext{
+				ctx:    context.Background(),
+				cli:    &CLI{wsConn: wsConn},
+				prompt: prompt,
+			}
+
+			ec.cli.sent.Store(2)
+			ec.cli.received.Store(3)
+
+			res, err := ec.Prompt()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, res)
+		})
+	}
+}
+
+func TestExecutionContext_Prompt_NotConfigured(t *testing.T) {
+	ec := &executionContext{
+		ctx: context.Background(),
+		cli: &CLI{},
+	}
+
+	res, err := ec.Prompt()
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultPrompt, res)
+}
+
+func TestExecutionContext_Prompt_RenderError(t *testing.T) {
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().Hostname().Return("example.com")
+	wsConn.EXPECT().Status().Return("connected")
+
+	prompt, err := newPromptTemplate("{{.Unknown}}")
+	require.NoError(t, err)
+
+	ec := &executionContext{
+		ctx:    context.Background(),
+		cli:    &CLI{wsConn: wsConn},
+		prompt: prompt,
+	}
+
+	_, err = ec.Prompt()
+	assert.ErrorContains(t, err, "fail to render prompt")
+}
+
+func TestNewPromptTemplate_Invalid(t *testing.T) {
+	_, err := newPromptTemplate("{{.Host")
+	assert.ErrorContains(t, err, "invalid prompt format")
+}
+
 func TestExecutionContext_CreateCommand(t *testing.T) {
 	mockFactory := NewMockCommandFactory(t)
 	ctx := context.Background()
@@ -194,6 +419,140 @@ func TestExecutionContext_CreateCommand(t *testing.T) {
 	assert.Equal(t, expectCmd, cmd, "Expected command to match")
 }
 
+func TestExecutionContext_WaitForClose(t *testing.T) {
+	closedConn := make(chan struct{})
+	close(closedConn)
+
+	tests := []struct {
+		done        chan struct{}
+		messages    chan Message
+		name        string
+		expectedMsg Message
+		timeout     time.Duration
+		closed      bool
+		expectError bool
+	}{
+		{
+			name:     "Connection closed",
+			timeout:  time.Second,
+			done:     closedConn,
+			messages: make(chan Message),
+			closed:   true,
+		},
+		{
+			name:    "Message before close",
+			timeout: time.Second,
+			done:    make(chan struct{}),
+			messages: func() chan Message {
+				ch := make(chan Message, 1)
+				ch <- Message{Type: Response, Data: "bye"}
+
+				return ch
+			}(),
+			expectedMsg: Message{Type: Response, Data: "bye"},
+		},
+		{
+			name:        "Connection stays open",
+			timeout:     10 * time.Millisecond,
+			done:        make(chan struct{}),
+			messages:    make(chan Message),
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wsConn := NewMockConnectionHandler(t)
+			wsConn.EXPECT().Done().Return(tt.done)
+
+			ec := &executionContext{
+				ctx: context.Background(),
+				cli: &CLI{
+					wsConn:   wsConn,
+					messages: tt.messages,
+				},
+			}
+
+			msg, closed, err := ec.WaitForClose(tt.timeout)
+
+			if tt.expectError {
+				assert.ErrorIs(t, err, context.DeadlineExceeded)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.closed, closed)
+			assert.Equal(t, tt.expectedMsg, msg)
+		})
+	}
+}
+
+func TestExecutionContext_WaitForClose_ClosedWinsOverCancel(t *testing.T) {
+	closedConn := make(chan struct{})
+	close(closedConn)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().Done().Return(closedConn)
+
+	ec := &executionContext{
+		ctx: ctx,
+		cli: &CLI{
+			wsConn:   wsConn,
+			messages: make(chan Message),
+		},
+	}
+
+	for i := 0; i < 10; i++ {
+		_, closed, err := ec.WaitForClose(0)
+
+		assert.NoError(t, err)
+		assert.True(t, closed)
+	}
+}
+
+func TestExecutionContext_WaitForInterrupt(t *testing.T) {
+	ec := &executionContext{
+		ctx: context.Background(),
+		cli: &CLI{inputStream: make(chan KeyEvent)},
+	}
+
+	go func() {
+		ec.cli.inputStream <- KeyEvent{Rune: 'q'}
+		ec.cli.inputStream <- KeyEvent{Key: KeyEnter}
+		ec.cli.inputStream <- KeyEvent{Key: KeyCtrlC}
+	}()
+
+	interrupted, err := ec.WaitForInterrupt(0)
+
+	assert.NoError(t, err)
+	assert.True(t, interrupted)
+
+	tick := make(chan time.Time, 1)
+	tick <- time.Time{}
+
+	clock := NewMockClock(t)
+	clock.EXPECT().After(time.Second).Return(tick)
+	ec.clock = clock
+
+	interrupted, err = ec.WaitForInterrupt(time.Second)
+
+	assert.NoError(t, err)
+	assert.False(t, interrupted)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ec.ctx = ctx
+
+	interrupted, err = ec.WaitForInterrupt(0)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, interrupted)
+}
+
 func TestExecutionContext_WaitForResponse(t *testing.T) {
 	tests := []struct {
 		setupCLI       func(ctx context.Context) *CLI
@@ -286,6 +645,57 @@ func TestExecutionContext_WaitForResponse(t *testing.T) {
 	}
 }
 
+func TestExecutionContext_WaitForResponse_FakeClock(t *testing.T) {
+	elapsed := make(chan time.Time, 1)
+	elapsed <- time.Now()
+
+	clock := NewMockClock(t)
+	clock.EXPECT().After(time.Hour).Return(elapsed)
+
+	ec := &executionContext{
+		ctx:   context.Background(),
+		clock: clock,
+		cli:   &CLI{messages: make(chan Message)},
+	}
+
+	start := time.Now()
+	_, err := ec.WaitForResponse(time.Hour)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestExecutionContext_WaitForClose_FakeClock(t *testing.T) {
+	elapsed := make(chan time.Time, 1)
+	elapsed <- time.Now()
+
+	clock := NewMockClock(t)
+	clock.EXPECT().After(time.Hour).Return(elapsed)
+
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().Done().Return(make(chan struct{}))
+
+	ec := &executionContext{
+		ctx:   context.Background(),
+		clock: clock,
+		cli:   &CLI{wsConn: wsConn, messages: make(chan Message)},
+	}
+
+	_, closed, err := ec.WaitForClose(time.Hour)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.False(t, closed)
+}
+
+func TestExecutionContext_Clock(t *testing.T) {
+	ec := &executionContext{}
+	assert.Equal(t, realClock{}, ec.Clock())
+
+	clock := NewMockClock(t)
+	ec.clock = clock
+	assert.Equal(t, clock, ec.Clock())
+}
+
 func TestExecutionContext_PrintToFile(t *testing.T) {
 	tests := []struct {
 		setupOutput    func() io.Writer
@@ -318,9 +728,7 @@ func TestExecutionContext_PrintToFile(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			output := tt.setupOutput()
 
-			ec := &executionContext{
-				outputFile: output,
-			}
+			ec := newExecutionContext(context.Background(), &CLI{}, output)
 
 			err := ec.PrintToFile(tt.data)
 			if tt.expectedError {
@@ -424,3 +832,149 @@ func TestExecutionContext_FormatMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestExecutionContext_SetContentType(t *testing.T) {
+	mockFormatter := NewMockFormater(t)
+	mockFormatter.EXPECT().SetContentType("json").Return(nil)
+	mockFormatter.EXPECT().SetContentType("yaml").Return(assert.AnError)
+
+	ec := &executionContext{
+		cli: &CLI{formater: mockFormatter},
+	}
+
+	assert.NoError(t, ec.SetContentType("json"))
+	assert.ErrorIs(t, ec.SetContentType("yaml"), assert.AnError)
+}
+
+type failingWriter struct {
+	writes int
+}
+
+func (w *failingWriter) Write(_ []byte) (int, error) {
+	w.writes++
+	return 0, fmt.Errorf("no space left on device")
+}
+
+func TestExecutionContext_PrintToFile_WriteError(t *testing.T) {
+	file := &failingWriter{}
+	output := &bytes.Buffer{}
+
+	ec := newExecutionContext(context.Background(), &CLI{output: output}, file)
+
+	assert.NoError(t, ec.PrintToFile("first"))
+	assert.NoError(t, ec.PrintToFile("second"))
+
+	assert.Equal(t, 1, file.writes)
+	assert.Equal(t, 1, strings.Count(output.String(), "Fail to write to output file"))
+	assert.Contains(t, output.String(), "no space left on device")
+
+	assert.NoError(t, ec.SetRecording(true))
+	assert.NoError(t, ec.PrintToFile("third"))
+	assert.Equal(t, 2, file.writes)
+}
+
+func TestExecutionContext_SetRecording(t *testing.T) {
+	file := &bytes.Buffer{}
+	ec := newExecutionContext(context.Background(), &CLI{}, file)
+
+	assert.NoError(t, ec.SetRecording(false))
+	assert.NoError(t, ec.PrintToFile("skipped"))
+	assert.Empty(t, file.String())
+
+	assert.NoError(t, ec.SetRecording(true))
+	assert.NoError(t, ec.PrintToFile("written"))
+	assert.Equal(t, "written\n", file.String())
+
+	ec = &executionContext{}
+	assert.EqualError(t, ec.SetRecording(true), "output file is not set")
+}
+
+func TestExecutionContext_ShouldRecord(t *testing.T) {
+	tests := []struct {
+		name          string
+		onlyRequests  bool
+		onlyResponses bool
+		wantRequest   bool
+		wantResponse  bool
+	}{
+		{name: "Default", wantRequest: true, wantResponse: true},
+		{name: "OnlyRequests", onlyRequests: true, wantRequest: true},
+		{name: "OnlyResponses", onlyResponses: true, wantResponse: true},
+		{name: "Both", onlyRequests: true, onlyResponses: true, wantRequest: true, wantResponse: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ec := &executionContext{onlyRequests: tt.onlyRequests, onlyResponses: tt.onlyResponses}
+
+			assert.Equal(t, tt.wantRequest, ec.ShouldRecord(Request))
+			assert.Equal(t, tt.wantResponse, ec.ShouldRecord(Response))
+		})
+	}
+}
+
+func TestExecutionContext_Variables(t *testing.T) {
+	ec := newExecutionContext(context.Background(), &CLI{}, nil)
+
+	assert.Equal(t, `{"token": "${token}"}`, ec.ExpandVariables(`{"token": "${token}"}`))
+
+	ec.SetVariable("token", "abc")
+	ec.SetVariable("id", "42")
+
+	assert.Equal(t, `{"token": "abc", "id": 42, "other": "${other}", "raw": "$id"}`,
+		ec.ExpandVariables(`{"token": "${token}", "id": ${id}, "other": "${other}", "raw": "$id"}`))
+}
+
+func TestExecutionContext_LastResponse(t *testing.T) {
+	ec := newExecutionContext(context.Background(), &CLI{}, nil)
+
+	_, ok := ec.LastResponse()
+	assert.False(t, ok)
+
+	ec.cli.lastResponse.Store(&Message{Type: Response, Data: "data"})
+
+	msg, ok := ec.LastResponse()
+	assert.True(t, ok)
+	assert.Equal(t, Message{Type: Response, Data: "data"}, msg)
+}
+
+func TestExecutionContext_LastRequest(t *testing.T) {
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().Send(mock.Anything, "first").Return(nil)
+	wsConn.EXPECT().Send(mock.Anything, "second").Return(nil)
+	wsConn.EXPECT().Send(mock.Anything, "failed").Return(assert.AnError)
+
+	ec := newExecutionContext(context.Background(), &CLI{wsConn: wsConn}, nil)
+
+	_, ok := ec.LastRequest()
+	assert.False(t, ok)
+
+	assert.NoError(t, ec.SendRequest("first"))
+	assert.NoError(t, ec.SendRequest("second"))
+	assert.ErrorIs(t, ec.SendRequest("failed"), assert.AnError)
+
+	req, ok := ec.LastRequest()
+	assert.True(t, ok)
+	assert.Equal(t, "second", req)
+}
+
+func TestExecutionContext_LimitTime(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ec := newExecutionContext(ctx, &CLI{}, nil)
+
+	restore := ec.LimitTime(time.Millisecond)
+
+	limited := ec.Context()
+	_, ok := limited.Deadline()
+	assert.True(t, ok)
+
+	<-limited.Done()
+	assert.ErrorIs(t, limited.Err(), context.DeadlineExceeded)
+
+	restore()
+
+	assert.Equal(t, ctx, ec.Context())
+	assert.NoError(t, ec.Context().Err())
+}
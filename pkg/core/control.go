@@ -0,0 +1,111 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// DataBufferSize is the default number of data messages buffered while control messages are prioritized.
+	DataBufferSize = 1000
+	// controlBufferSize is the number of control messages buffered before reading from the connection blocks.
+	controlBufferSize = 100
+)
+
+// SetControlPattern enables the two-queue receive model, so control messages, e.g. heartbeats or acks,
+// are not starved by a flood of data messages.
+// It takes pattern of type *regexp.Regexp, received messages matching it are delivered on a separate path,
+// which is always checked first by the session and by commands waiting for messages,
+// and bufferSize of type int, the number of other messages buffered until they are handled,
+// when the buffer is full the oldest data message is dropped, so reading from the connection never blocks on data.
+// Non-positive bufferSize is replaced with DataBufferSize. A nil pattern keeps the single queue.
+// It should be called before the connection is established.
+func (c *CLI) SetControlPattern(pattern *regexp.Regexp, bufferSize int) {
+	if pattern == nil {
+		return
+	}
+
+	if bufferSize <= 0 {
+		bufferSize = DataBufferSize
+	}
+
+	c.controlPattern = pattern
+	c.control = make(chan Message, controlBufferSize)
+	c.messages = make(chan Message, bufferSize)
+}
+
+// deliver passes the received message to the session.
+// Control messages are queued on the control path, blocking until there is room for them,
+// other messages are queued on the data path without blocking, the oldest queued data message is dropped if it's full.
+// Markers are never dropped, a marker pushed out of the full buffer is kept aside and shown before the next message.
+func (c *CLI) deliver(ctx context.Context, msg Message) {
+	if c.controlPattern == nil {
+		select {
+		case c.messages <- msg:
+		case <-ctx.Done():
+		}
+
+		return
+	}
+
+	if c.controlPattern.MatchString(msg.Data) {
+		select {
+		case c.control <- msg:
+		case <-ctx.Done():
+		}
+
+		return
+	}
+
+	for {
+		select {
+		case c.messages <- msg:
+			return
+		default:
+		}
+
+		select {
+		case old := <-c.messages:
+			if old.marker == "" {
+				c.dataDropped.Add(1)
+				continue
+			}
+
+			c.carriedMu.Lock()
+			c.carried = append(c.carried, old.marker)
+			c.carriedMu.Unlock()
+		default:
+		}
+	}
+}
+
+// deliverMarker queues the marker on the data path, blocking until there is room for it, so it's printed
+// after the messages received before it and before the ones received after it.
+func (c *CLI) deliverMarker(ctx context.Context, text string) {
+	select {
+	case c.messages <- Message{marker: text}:
+	case <-ctx.Done():
+	}
+}
+
+// pendingMarkers returns a marker with the markers pushed out of the full data buffer, followed by the number
+// of data messages dropped since the last call, so they're shown before the next message taken from the buffer.
+// It returns nil if there is nothing to report.
+func (c *CLI) pendingMarkers() Executer {
+	c.carriedMu.Lock()
+	lines := c.carried
+	c.carried = nil
+	c.carriedMu.Unlock()
+
+	if dropped := c.dataDropped.Swap(0); dropped > 0 {
+		lines = append(lines, fmt.Sprintf("--- %d data messages dropped, the buffer is full ---", dropped))
+	}
+
+	if len(lines) == 0 {
+		return nil
+	}
+
+	return &marker{text: strings.Join(lines, "\n")}
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCLI_ControlPattern_SaturatedDataBuffer(t *testing.T) {
+	c := &CLI{}
+	c.SetControlPattern(regexp.MustCompile(`"type":"pong"`), 2)
+
+	ctx := context.Background()
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for i := 1; i <= 5; i++ {
+			c.deliver(ctx, Message{Type: Response, Data: fmt.Sprintf(`{"type":"data","n":%d}`, i)})
+		}
+
+		c.deliver(ctx, Message{Type: Response, Data: `{"type":"pong"}`})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("delivering data messages blocked on the full buffer")
+	}
+
+	assert.Equal(t, &marker{text: "--- 3 data messages dropped, the buffer is full ---"}, c.pendingMarkers())
+	assert.Nil(t, c.pendingMarkers())
+
+	ec := &executionContext{ctx: ctx, cli: c, clock: realClock{}}
+
+	msg, err := ec.WaitForResponse(time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"pong"}`, msg.Data, "control message should be delivered before buffered data")
+
+	msg, err = ec.WaitForResponse(time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"data","n":4}`, msg.Data, "the oldest data messages should be dropped")
+
+	msg, err = ec.WaitForResponse(time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"data","n":5}`, msg.Data)
+}
+
+func TestCLI_ControlPattern_SaturatedDataBuffer_KeepsMarkers(t *testing.T) {
+	output := &bytes.Buffer{}
+
+	c := &CLI{output: output}
+	c.SetControlPattern(regexp.MustCompile(`"type":"pong"`), 3)
+
+	ctx := context.Background()
+
+	c.deliver(ctx, Message{Type: Response, Data: "1"})
+	c.onStatusChange(ctx, StatusReconnecting, errors.New("connection reset"))
+	c.onStatusChange(ctx, StatusConnected, nil)
+
+	// The buffer is full with the message and the reconnect markers, the flood after the reconnect pushes them out.
+	for i := 2; i <= 5; i++ {
+		c.deliver(ctx, Message{Type: Response, Data: strconv.Itoa(i)})
+	}
+
+	ec := &executionContext{ctx: ctx, cli: c, clock: realClock{}}
+
+	msg, err := ec.WaitForResponse(time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "3", msg.Data)
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[0], "--- disconnected at ")
+	assert.Contains(t, lines[1], "--- reconnected at ")
+	assert.Contains(t, lines[2], "--- 2 data messages dropped, the buffer is full ---")
+
+	for _, want := range []string{"4", "5"} {
+		msg, err = ec.WaitForResponse(time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, want, msg.Data)
+	}
+
+	assert.Nil(t, c.pendingMarkers())
+}
+
+func TestCLI_ControlPattern_Disabled(t *testing.T) {
+	c := &CLI{messages: make(chan Message)}
+	c.SetControlPattern(nil, 10)
+
+	assert.Nil(t, c.control)
+	assert.Equal(t, 0, cap(c.messages))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Without a consumer delivery blocks until the context is canceled, as without the control path.
+	c.deliver(ctx, Message{Type: Response, Data: `{"type":"pong"}`})
+}
+
+func TestCLI_ControlPattern_DefaultBufferSize(t *testing.T) {
+	c := &CLI{}
+	c.SetControlPattern(regexp.MustCompile("ping"), 0)
+
+	assert.Equal(t, DataBufferSize, cap(c.messages))
+	assert.Equal(t, controlBufferSize, cap(c.control))
+}
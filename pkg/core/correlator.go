@@ -0,0 +1,66 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// DefaultCorrelationField is the JSON field holding the ID of requests and responses in most protocols.
+const DefaultCorrelationField = "id"
+
+// Correlator extracts the ID that correlates a request with its response from a message,
+// so a response can be told apart from other messages received while waiting for it.
+type Correlator interface {
+	// ID returns the correlation ID of the message and false if the message doesn't have one.
+	ID(msg Message) (string, bool)
+}
+
+// JSONFieldCorrelator reads the correlation ID from a field of JSON messages.
+type JSONFieldCorrelator struct {
+	path []string
+}
+
+// NewJSONFieldCorrelator creates a new JSONFieldCorrelator.
+// It takes field of type string, a dot separated path to the field in the JSON object, e.g. id or meta.reqId,
+// an empty field is replaced with DefaultCorrelationField.
+// It returns a pointer to the created JSONFieldCorrelator.
+func NewJSONFieldCorrelator(field string) *JSONFieldCorrelator {
+	if field == "" {
+		field = DefaultCorrelationField
+	}
+
+	return &JSONFieldCorrelator{path: strings.Split(field, ".")}
+}
+
+// ID returns the value of the field in the JSON message, numbers are returned as they are written in the message.
+// It returns false if the message is not a JSON object, the field doesn't exist or it's not a string or a number.
+func (c *JSONFieldCorrelator) ID(msg Message) (string, bool) {
+	dec := json.NewDecoder(bytes.NewReader([]byte(msg.Data)))
+	dec.UseNumber()
+
+	var current any
+	if err := dec.Decode(&current); err != nil {
+		return "", false
+	}
+
+	for _, key := range c.path {
+		obj, ok := current.(map[string]any)
+		if !ok {
+			return "", false
+		}
+
+		if current, ok = obj[key]; !ok {
+			return "", false
+		}
+	}
+
+	switch id := current.(type) {
+	case string:
+		return id, true
+	case json.Number:
+		return id.String(), true
+	default:
+		return "", false
+	}
+}
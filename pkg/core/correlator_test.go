@@ -0,0 +1,36 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONFieldCorrelator_ID(t *testing.T) {
+	tests := []struct {
+		name   string
+		field  string
+		data   string
+		wantID string
+		wantOK bool
+	}{
+		{name: "default field", data: `{"id":7,"method":"ping"}`, wantID: "7", wantOK: true},
+		{name: "string ID", field: "reqId", data: `{"reqId":"a-1"}`, wantID: "a-1", wantOK: true},
+		{name: "large number", data: `{"id":12345678901234567890}`, wantID: "12345678901234567890", wantOK: true},
+		{name: "nested field", field: "meta.correlationId", data: `{"meta":{"correlationId":"x"}}`, wantID: "x", wantOK: true},
+		{name: "missing field", data: `{"method":"ping"}`},
+		{name: "object ID", data: `{"id":{"a":1}}`},
+		{name: "path through scalar", field: "meta.id", data: `{"meta":1}`},
+		{name: "array", data: `[{"id":1}]`},
+		{name: "text", data: `ping`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, ok := NewJSONFieldCorrelator(tt.field).ID(Message{Type: Response, Data: tt.data})
+
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantID, id)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package core
+
+import (
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// dedup suppresses consecutive identical messages received within a window, so duplicate frames of a flaky server
+// don't clutter the output.
+type dedup struct {
+	shownAt    time.Time
+	window     time.Duration
+	hash       uint64
+	suppressed int
+	mu         sync.Mutex
+}
+
+// check decides if the message data received at now is a duplicate of the last shown message.
+// A message is a duplicate if its hash is equal to the hash of the last shown message and it arrived within
+// the window after it, so messages legitimately repeated later are shown.
+// It returns true if the message should be suppressed, and the number of duplicates suppressed before the message
+// otherwise, the counter is reset once it's reported.
+func (d *dedup) check(data string, now time.Time) (duplicate bool, suppressed int) {
+	if d == nil || d.window <= 0 {
+		return false, 0
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(data))
+	sum := h.Sum64()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.shownAt.IsZero() && sum == d.hash && now.Sub(d.shownAt) < d.window {
+		d.suppressed++
+		return true, 0
+	}
+
+	suppressed = d.suppressed
+	d.shownAt = now
+	d.hash = sum
+	d.suppressed = 0
+
+	return false, suppressed
+}
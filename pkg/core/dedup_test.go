@@ -0,0 +1,106 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDedup_Check(t *testing.T) {
+	now := time.Now()
+	d := &dedup{window: time.Second}
+
+	steps := []struct {
+		data           string
+		offset         time.Duration
+		wantDuplicate  bool
+		wantSuppressed int
+	}{
+		{data: "tick", offset: 0},
+		{data: "tick", offset: 100 * time.Millisecond, wantDuplicate: true},
+		{data: "tick", offset: 900 * time.Millisecond, wantDuplicate: true},
+		{data: "tock", offset: 950 * time.Millisecond, wantSuppressed: 2},
+		{data: "tick", offset: 960 * time.Millisecond},
+		{data: "tick", offset: 2 * time.Second},
+		{data: "tick", offset: 2500 * time.Millisecond, wantDuplicate: true},
+		{data: "tick", offset: 3 * time.Second, wantSuppressed: 1},
+	}
+
+	for _, step := range steps {
+		duplicate, suppressed := d.check(step.data, now.Add(step.offset))
+
+		assert.Equal(t, step.wantDuplicate, duplicate, "%s at %s", step.data, step.offset)
+		assert.Equal(t, step.wantSuppressed, suppressed, "%s at %s", step.data, step.offset)
+	}
+}
+
+func TestDedup_Disabled(t *testing.T) {
+	now := time.Now()
+
+	for _, d := range []*dedup{nil, {}} {
+		for range 2 {
+			duplicate, suppressed := d.check("tick", now)
+
+			assert.False(t, duplicate)
+			assert.Zero(t, suppressed)
+		}
+	}
+}
+
+func TestCLIRun_Dedup(t *testing.T) {
+	var onMessage func(context.Context, []byte)
+
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().SetOnMessage(mock.Anything).Run(func(cb func(context.Context, []byte)) { onMessage = cb })
+	wsConn.EXPECT().SetOnStatusChange(mock.Anything)
+	wsConn.EXPECT().SetOnFrameError(mock.Anything)
+
+	editor := NewMockEditor(t)
+	editor.EXPECT().SetInput(mock.Anything)
+
+	exitCmd := NewMockExecuter(t)
+	exitCmd.EXPECT().Execute(mock.Anything).Return(nil, ErrInterrupted)
+
+	var printed []string
+
+	factory := NewMockCommandFactory(t)
+	factory.EXPECT().Create(mock.Anything).RunAndReturn(func(raw string) (Executer, error) {
+		if raw == "exit" {
+			return exitCmd, nil
+		}
+
+		printCmd := NewMockExecuter(t)
+		printCmd.EXPECT().Execute(mock.Anything).RunAndReturn(func(ExecutionContext) (Executer, error) {
+			printed = append(printed, raw)
+			return nil, nil
+		})
+
+		return printCmd, nil
+	})
+
+	output := &bytes.Buffer{}
+	cli := NewCLI(factory, wsConn, output, editor, NewMockFormater(t))
+	cli.SetDedupWindow(time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		for _, msg := range []string{"a", "a", "a", "b", "a"} {
+			onMessage(ctx, []byte(msg))
+		}
+
+		cli.OnKeyEvent(KeyEvent{Key: KeyCtrlC})
+	}()
+
+	err := cli.Run(ctx, RunOptions{})
+
+	assert.ErrorIs(t, err, ErrInterrupted)
+	assert.Equal(t, []string{"print Response a", "print Response b", "print Response a"}, printed)
+	assert.Contains(t, output.String(), "\n--- 2 duplicate messages suppressed ---\n")
+	assert.Equal(t, int64(5), cli.received.Load())
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
+	"github.com/fatih/color"
 	"github.com/ksysoev/wsget/pkg/core"
+	"github.com/ksysoev/wsget/pkg/core/formater"
 )
 
 type HistoryRepo interface {
@@ -19,8 +22,12 @@ type HistoryRepo interface {
 
 const (
 	PastingTimingThresholdInMicrosec = 250
+	PasteEndTimeout                  = 20 * time.Millisecond
 	MacOSDeleteKey                   = 127
 	Bell                             = "\a"
+	SaveCursor                       = "\x1b7"
+	RestoreCursor                    = "\x1b8"
+	ClearToLineEnd                   = "\x1b[K"
 )
 
 type Option func(*Editor)
@@ -34,7 +41,13 @@ type Editor struct {
 	onOpen          func(io.Writer) error
 	onClose         func(io.Writer) error
 	buffer          *string
+	jsonStatus      string
+	pasteStart      int
+	prevKeyPos      int
 	isSingleLine    bool
+	prettifyPaste   bool
+	inPaste         bool
+	jsonIndicator   bool
 }
 
 // NewEditor initializes a new instance of Editor for text editing tasks.
@@ -66,6 +79,22 @@ func (ed *Editor) SetInput(input <-chan core.KeyEvent) {
 	ed.input = input
 }
 
+// SetPrettifyPaste enables or disables prettifying pasted JSON.
+// It takes enabled of type bool, if true a pasted JSON object or array is indented in the buffer,
+// so minified JSON can be edited comfortably. Other pasted content is inserted as is.
+func (ed *Editor) SetPrettifyPaste(enabled bool) {
+	ed.prettifyPaste = enabled
+}
+
+// SetJSONIndicator enables or disables the JSON validity indicator.
+// It takes enabled of type bool, if true a buffer starting like a JSON object or array gets a ✓ or ✗ mark
+// next to the edit mode marker on the line above the content, updated as the buffer changes.
+// The mark is informational only, the buffer is submitted whether it's valid or not.
+// It should be enabled only if the output is a terminal, as the mark is drawn with cursor movements.
+func (ed *Editor) SetJSONIndicator(enabled bool) {
+	ed.jsonIndicator = enabled
+}
+
 // Edit processes keyboard input to manipulate and return the edited content.
 // It takes a context ctx of type context.Context for cancellation and an initial buffer initBuffer of type string.
 // It returns the final edited string content or an error if input is unavailable, keyboard stream is closed, or an interrupt occurs.
@@ -82,19 +111,31 @@ func (ed *Editor) Edit(ctx context.Context, initBuffer string) (res string, err
 
 	ed.history.ResetPosition()
 	ed.buffer = nil
+	ed.inPaste = false
+	ed.jsonStatus = ""
 
 	if _, err := fmt.Fprint(ed.output, ed.content.ReplaceText(initBuffer)); err != nil {
 		return "", fmt.Errorf("failed to write initial buffer: %w", err)
 	}
 
+	ed.showJSONStatus()
+
 	if ed.input == nil {
 		return "", fmt.Errorf("input stream is not set")
 	}
 
 	for {
+		var pasteEnd <-chan time.Time
+		if ed.inPaste {
+			pasteEnd = time.After(PasteEndTimeout)
+		}
+
 		select {
 		case <-ctx.Done():
 			return "", core.ErrInterrupted
+		case <-pasteEnd:
+			ed.finishPaste()
+			ed.showJSONStatus()
 		case e, ok := <-ed.input:
 			if !ok {
 				return "", fmt.Errorf("keyboard stream was unexpectedly closed")
@@ -106,6 +147,7 @@ func (ed *Editor) Edit(ctx context.Context, initBuffer string) (res string, err
 			case err != nil:
 				return "", err
 			case next:
+				ed.showJSONStatus()
 				continue
 			default:
 				return s, nil
@@ -120,6 +162,7 @@ func (ed *Editor) Edit(ctx context.Context, initBuffer string) (res string, err
 // It returns an error if the operation is interrupted or invalid input occurs.
 func (ed *Editor) handleKey(e core.KeyEvent) (next bool, res string, err error) {
 	isPasting := ed.isPasting()
+	ed.trackPaste(isPasting)
 
 	switch e.Key {
 	case core.KeyAltBackspace:
@@ -185,6 +228,9 @@ func (ed *Editor) handleKey(e core.KeyEvent) (next bool, res string, err error)
 			return false, "", fmt.Errorf("failed to execute open hook: %w", err)
 		}
 
+		// The open hook redraws the marker line without the JSON mark.
+		ed.jsonStatus = ""
+
 		if _, err := fmt.Fprint(ed.output, ed.content.ReplaceText(content)); err != nil {
 			return false, "", fmt.Errorf("failed to write content: %w", err)
 		}
@@ -325,6 +371,82 @@ func (ed *Editor) isPasting() bool {
 	return elapsed.Microseconds() < PastingTimingThresholdInMicrosec
 }
 
+// trackPaste keeps the position where the pasted content starts, so it can be prettified once the paste is over.
+// It takes isPasting of type bool, indicating whether the current key is a part of a pasted sequence.
+// The first key of a paste is not detected as pasted, so the paste starts at the position before the previous key.
+func (ed *Editor) trackPaste(isPasting bool) {
+	if !ed.prettifyPaste {
+		return
+	}
+
+	switch {
+	case isPasting && !ed.inPaste:
+		ed.inPaste = true
+		ed.pasteStart = ed.prevKeyPos
+	case !isPasting && ed.inPaste:
+		ed.finishPaste()
+	}
+
+	ed.prevKeyPos = ed.content.GetPosition()
+}
+
+// finishPaste replaces the pasted content with prettified JSON if it's a JSON object or array.
+// The cursor is placed right after the prettified JSON. Other pasted content is kept as is.
+func (ed *Editor) finishPaste() {
+	ed.inPaste = false
+
+	start, end := ed.pasteStart, ed.content.GetPosition()
+	if start >= end {
+		return
+	}
+
+	text := []rune(ed.content.String())
+
+	pretty, ok := formater.PrettyJSON(string(text[start:end]))
+	if !ok {
+		return
+	}
+
+	_, _ = fmt.Fprint(ed.output, ed.content.ReplaceText(string(text[:start])+pretty+string(text[end:])))
+	_, _ = fmt.Fprint(ed.output, ed.content.MoveToPosition(start+len([]rune(pretty))))
+}
+
+// showJSONStatus draws the JSON validity mark of the buffer after the edit mode marker, if the indicator is enabled.
+// The mark is drawn only when it changes, the cursor is moved to the marker line above the content and back.
+func (ed *Editor) showJSONStatus() {
+	if !ed.jsonIndicator {
+		return
+	}
+
+	status := jsonStatus(ed.content.String())
+	if status == ed.jsonStatus {
+		return
+	}
+
+	ed.jsonStatus = status
+
+	text := []rune(ed.content.String())
+	row := strings.Count(string(text[:ed.content.GetPosition()]), string(NewLine))
+
+	_, _ = fmt.Fprintf(ed.output, "%s\x1b[%dA\r\x1b[%dC%s%s%s",
+		SaveCursor, row+1, len(EditMarker), ClearToLineEnd, status, RestoreCursor)
+}
+
+// jsonStatus returns the JSON validity mark of text, it's empty if text doesn't start like a JSON object or array,
+// so payloads that are not meant to be JSON get no mark.
+func jsonStatus(text string) string {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return ""
+	}
+
+	if _, ok := formater.PrettyJSON(trimmed); ok {
+		return color.New(color.FgGreen).Sprint(" ✓ JSON")
+	}
+
+	return color.New(color.FgRed).Sprint(" ✗ JSON")
+}
+
 // WithOpenHook sets the onOpen function for the Editor instance.
 // It takes a function hook of type func(io.Writer) error.
 // It returns an Option function to set the onOpen function.
@@ -4,12 +4,16 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ksysoev/wsget/pkg/core"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewEditor(t *testing.T) {
@@ -381,6 +385,171 @@ func TestNewLineOrDone(t *testing.T) {
 	}
 }
 
+func TestEditor_PrettifyPaste(t *testing.T) {
+	tests := []struct {
+		name     string
+		typed    string
+		pasted   string
+		want     string
+		prettify bool
+	}{
+		{
+			name:     "minified JSON",
+			typed:    "x ",
+			pasted:   `{"a":1,"b":[true]}`,
+			prettify: true,
+			want:     "x {\n  \"a\": 1,\n  \"b\": [\n    true\n  ]\n}",
+		},
+		{
+			name:     "not JSON",
+			pasted:   `{"a":1`,
+			prettify: true,
+			want:     `{"a":1`,
+		},
+		{
+			name:   "disabled",
+			pasted: `{"a":1}`,
+			want:   `{"a":1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			editor := NewEditor(new(bytes.Buffer), NewMockHistoryRepo(t), false)
+			editor.SetPrettifyPaste(tt.prettify)
+
+			for _, r := range tt.typed {
+				editor.trackPaste(false)
+				editor.content.InsertSymbol(r)
+			}
+
+			for i, r := range tt.pasted {
+				editor.trackPaste(i > 0)
+				editor.content.InsertSymbol(r)
+			}
+
+			editor.trackPaste(false)
+
+			assert.Equal(t, tt.want, editor.content.String())
+			assert.Equal(t, len([]rune(tt.want)), editor.content.GetPosition())
+		})
+	}
+}
+
+func TestEditor_Edit_PrettifyPaste(t *testing.T) {
+	history := NewMockHistoryRepo(t)
+	history.EXPECT().ResetPosition()
+	history.EXPECT().AddRequest("{\n  \"a\": 1\n}")
+
+	editor := NewEditor(new(bytes.Buffer), history, false)
+	editor.SetPrettifyPaste(true)
+
+	input := make(chan core.KeyEvent, 10)
+	editor.SetInput(input)
+
+	for _, r := range `{"a":1}` {
+		input <- core.KeyEvent{Rune: r}
+	}
+
+	done := make(chan string)
+
+	go func() {
+		res, err := editor.Edit(context.Background(), "")
+		assert.NoError(t, err)
+
+		done <- res
+	}()
+
+	time.Sleep(10 * PasteEndTimeout)
+	input <- core.KeyEvent{Key: core.KeyCtrlS}
+
+	assert.Equal(t, "{\n  \"a\": 1\n}", <-done)
+}
+
+func TestEditor_Edit_JSONIndicator(t *testing.T) {
+	mark := func(rows int, status string) string {
+		return SaveCursor + fmt.Sprintf("\x1b[%dA\r\x1b[2C", rows) + ClearToLineEnd + status + RestoreCursor
+	}
+
+	tests := []struct {
+		name       string
+		initBuffer string
+		typed      string
+		want       []string
+		enabled    bool
+	}{
+		{
+			name:    "valid after closing brace",
+			typed:   `{"a":1}`,
+			enabled: true,
+			want:    []string{mark(1, " ✗ JSON"), mark(1, " ✓ JSON")},
+		},
+		{
+			name:       "multiline buffer",
+			initBuffer: "[\n1",
+			typed:      "]",
+			enabled:    true,
+			want:       []string{mark(2, " ✗ JSON"), mark(2, " ✓ JSON")},
+		},
+		{
+			name:    "valid JSON becomes invalid",
+			typed:   "[]x",
+			enabled: true,
+			want:    []string{mark(1, " ✗ JSON"), mark(1, " ✓ JSON"), mark(1, " ✗ JSON")},
+		},
+		{
+			name:    "not JSON",
+			typed:   "ping",
+			enabled: true,
+		},
+		{
+			name:  "disabled",
+			typed: `{"a":1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := new(bytes.Buffer)
+			result := strings.TrimSpace(tt.initBuffer + tt.typed)
+
+			history := NewMockHistoryRepo(t)
+			history.EXPECT().ResetPosition()
+			history.EXPECT().AddRequest(result)
+
+			editor := NewEditor(output, history, false)
+			editor.SetJSONIndicator(tt.enabled)
+
+			input := make(chan core.KeyEvent)
+			editor.SetInput(input)
+
+			go func() {
+				for _, r := range tt.typed {
+					input <- core.KeyEvent{Rune: r}
+				}
+
+				input <- core.KeyEvent{Key: core.KeyCtrlS}
+			}()
+
+			res, err := editor.Edit(context.Background(), tt.initBuffer)
+
+			require.NoError(t, err)
+			assert.Equal(t, result, res)
+
+			var marks []string
+
+			for rest := output.String(); strings.Contains(rest, SaveCursor); {
+				start := strings.Index(rest, SaveCursor)
+				end := strings.Index(rest, RestoreCursor) + len(RestoreCursor)
+				marks = append(marks, rest[start:end])
+				rest = rest[end:]
+			}
+
+			assert.Equal(t, tt.want, marks)
+		})
+	}
+}
+
 func TestEditorHandleKey(t *testing.T) {
 	tests := []struct {
 		expectedErr    error
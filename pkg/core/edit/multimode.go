@@ -12,26 +12,32 @@ import (
 const (
 	HideCursor = "\x1b[?25l"
 	ShowCursor = "\x1b[?25h"
+	EditMarker = "->"
 )
 
 type MultiMode struct {
 	commandMode *Editor
 	editMode    *Editor
+	prompt      string
 }
 
 // NewMultiMode initializes a new MultiMode structure with separate editors for command and standard input modes.
 // It takes an io.Writer, two HistoryRepo instances for request and command histories, and an optional Dictionary.
 // It returns a pointer to the created MultiMode, setting up command and edit modes appropriately.
 func NewMultiMode(output io.Writer, reqHistory, cmdHistory HistoryRepo) *MultiMode {
-	commandMode := NewEditor(
+	m := &MultiMode{
+		prompt: core.DefaultPrompt,
+	}
+
+	m.commandMode = NewEditor(
 		output,
 		cmdHistory,
 		true,
-		WithOpenHook(cmdEditorOpenHook),
+		WithOpenHook(m.cmdEditorOpenHook),
 		WithCloseHook(cmdEditorCloseHook),
 	)
 
-	editMode := NewEditor(
+	m.editMode = NewEditor(
 		output,
 		reqHistory,
 		false,
@@ -39,15 +45,15 @@ func NewMultiMode(output io.Writer, reqHistory, cmdHistory HistoryRepo) *MultiMo
 		WithCloseHook(editorCloseHook),
 	)
 
-	return &MultiMode{
-		commandMode: commandMode,
-		editMode:    editMode,
-	}
+	return m
 }
 
 // CommandMode activates the command mode, reading user input from keyStream with an initial buffer initBuffer.
+// It takes prompt of type string, which is shown before the input until the command mode is closed.
 // It returns the resulting command string or an error if any issue occurs.
-func (m *MultiMode) CommandMode(ctx context.Context, initBuffer string) (string, error) {
+func (m *MultiMode) CommandMode(ctx context.Context, prompt, initBuffer string) (string, error) {
+	m.prompt = prompt
+
 	return m.commandMode.Edit(ctx, initBuffer)
 }
 
@@ -57,6 +63,16 @@ func (m *MultiMode) Edit(ctx context.Context, initBuffer string) (string, error)
 	return m.editMode.Edit(ctx, initBuffer)
 }
 
+// SetPrettifyPaste enables or disables prettifying JSON pasted in edit mode.
+func (m *MultiMode) SetPrettifyPaste(enabled bool) {
+	m.editMode.SetPrettifyPaste(enabled)
+}
+
+// SetJSONIndicator enables or disables the JSON validity indicator in edit mode.
+func (m *MultiMode) SetJSONIndicator(enabled bool) {
+	m.editMode.SetJSONIndicator(enabled)
+}
+
 // SetInput sets the input channel for both command and edit modes.
 func (m *MultiMode) SetInput(input <-chan core.KeyEvent) {
 	m.commandMode.SetInput(input)
@@ -67,7 +83,7 @@ func (m *MultiMode) SetInput(input <-chan core.KeyEvent) {
 // It takes w of type io.Writer to write initialization sequences.
 // It returns an error if writing to the provided io.Writer fails.
 func editorOpenHook(w io.Writer) error {
-	if _, err := color.New(color.FgGreen).Fprint(w, "->"); err != nil {
+	if _, err := color.New(color.FgGreen).Fprint(w, EditMarker); err != nil {
 		return err
 	}
 
@@ -85,11 +101,11 @@ func editorCloseHook(w io.Writer) error {
 	return err
 }
 
-// cmdEditorOpenHook prepares the command editor's environment when it opens.
+// cmdEditorOpenHook prepares the command editor's environment when it opens and prints the current prompt.
 // It takes w of type io.Writer to write initialization sequences.
 // It returns an error if writing to the provided io.Writer fails.
-func cmdEditorOpenHook(w io.Writer) error {
-	_, err := fmt.Fprint(w, ":"+ShowCursor)
+func (m *MultiMode) cmdEditorOpenHook(w io.Writer) error {
+	_, err := fmt.Fprint(w, m.prompt+ShowCursor)
 	return err
 }
 
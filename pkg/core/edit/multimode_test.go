@@ -20,6 +20,7 @@ func TestNewMultiMode(t *testing.T) {
 	assert.NotNil(t, multiMode)
 	assert.NotNil(t, multiMode.commandMode)
 	assert.NotNil(t, multiMode.editMode)
+	assert.Equal(t, core.DefaultPrompt, multiMode.prompt)
 }
 
 func TestMultiMode_CommandMode(t *testing.T) {
@@ -27,10 +28,8 @@ func TestMultiMode_CommandMode(t *testing.T) {
 	history.EXPECT().ResetPosition()
 	history.EXPECT().AddRequest("initial")
 
-	multiMode := &MultiMode{
-		editMode:    NewEditor(io.Discard, history, true),
-		commandMode: NewEditor(io.Discard, history, true),
-	}
+	output := &strings.Builder{}
+	multiMode := NewMultiMode(output, history, history)
 	keyStream := make(chan core.KeyEvent, 1)
 
 	defer close(keyStream)
@@ -39,9 +38,10 @@ func TestMultiMode_CommandMode(t *testing.T) {
 
 	multiMode.SetInput(keyStream)
 
-	result, err := multiMode.CommandMode(context.Background(), "initial")
+	result, err := multiMode.CommandMode(context.Background(), "host> ", "initial")
 	assert.NoError(t, err)
 	assert.Equal(t, "initial", result)
+	assert.True(t, strings.HasPrefix(output.String(), "host> "+ShowCursor))
 }
 
 func TestMultiMode_Edit(t *testing.T) {
@@ -163,7 +163,7 @@ func TestCmdEditorOpenHook(t *testing.T) {
 		{
 			name:           "Success with valid writer",
 			writer:         &strings.Builder{},
-			expectedOutput: ":" + ShowCursor, // ':' followed by ShowCursor
+			expectedOutput: "host> " + ShowCursor, // prompt followed by ShowCursor
 			expectedError:  nil,
 		},
 		{
@@ -180,7 +180,8 @@ func TestCmdEditorOpenHook(t *testing.T) {
 			builder, ok := tt.writer.(*strings.Builder)
 
 			// Execute the function
-			err := cmdEditorOpenHook(tt.writer)
+			m := &MultiMode{prompt: "host> "}
+			err := m.cmdEditorOpenHook(tt.writer)
 
 			// Assert expected outcomes
 			assert.Equal(t, tt.expectedError, err)
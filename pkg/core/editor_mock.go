@@ -23,9 +23,9 @@ func (_m *MockEditor) EXPECT() *MockEditor_Expecter {
 	return &MockEditor_Expecter{mock: &_m.Mock}
 }
 
-// CommandMode provides a mock function with given fields: ctx, initBuffer
-func (_m *MockEditor) CommandMode(ctx context.Context, initBuffer string) (string, error) {
-	ret := _m.Called(ctx, initBuffer)
+// CommandMode provides a mock function with given fields: ctx, prompt, initBuffer
+func (_m *MockEditor) CommandMode(ctx context.Context, prompt string, initBuffer string) (string, error) {
+	ret := _m.Called(ctx, prompt, initBuffer)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CommandMode")
@@ -33,17 +33,17 @@ func (_m *MockEditor) CommandMode(ctx context.Context, initBuffer string) (strin
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
-		return rf(ctx, initBuffer)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (string, error)); ok {
+		return rf(ctx, prompt, initBuffer)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
-		r0 = rf(ctx, initBuffer)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) string); ok {
+		r0 = rf(ctx, prompt, initBuffer)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, initBuffer)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, prompt, initBuffer)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -58,14 +58,15 @@ type MockEditor_CommandMode_Call struct {
 
 // CommandMode is a helper method to define mock.On call
 //   - ctx context.Context
+//   - prompt string
 //   - initBuffer string
-func (_e *MockEditor_Expecter) CommandMode(ctx interface{}, initBuffer interface{}) *MockEditor_CommandMode_Call {
-	return &MockEditor_CommandMode_Call{Call: _e.mock.On("CommandMode", ctx, initBuffer)}
+func (_e *MockEditor_Expecter) CommandMode(ctx interface{}, prompt interface{}, initBuffer interface{}) *MockEditor_CommandMode_Call {
+	return &MockEditor_CommandMode_Call{Call: _e.mock.On("CommandMode", ctx, prompt, initBuffer)}
 }
 
-func (_c *MockEditor_CommandMode_Call) Run(run func(ctx context.Context, initBuffer string)) *MockEditor_CommandMode_Call {
+func (_c *MockEditor_CommandMode_Call) Run(run func(ctx context.Context, prompt string, initBuffer string)) *MockEditor_CommandMode_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
 	})
 	return _c
 }
@@ -75,7 +76,7 @@ func (_c *MockEditor_CommandMode_Call) Return(_a0 string, _a1 error) *MockEditor
 	return _c
 }
 
-func (_c *MockEditor_CommandMode_Call) RunAndReturn(run func(context.Context, string) (string, error)) *MockEditor_CommandMode_Call {
+func (_c *MockEditor_CommandMode_Call) RunAndReturn(run func(context.Context, string, string) (string, error)) *MockEditor_CommandMode_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	EventConnect = "connect"
+	EventMessage = "message"
+	EventError   = "error"
+	EventClose   = "close"
+)
+
+// Event is a session event written to the structured event stream, one JSON object per line.
+type Event struct {
+	Time  time.Time `json:"time"`
+	Event string    `json:"event"`
+	Type  string    `json:"type,omitempty"`
+	Data  string    `json:"data,omitempty"`
+	URL   string    `json:"url,omitempty"`
+	Error string    `json:"error,omitempty"`
+}
+
+// EventWriter writes session events as JSON lines, so the session can be consumed by other tools.
+type EventWriter struct {
+	w  io.Writer
+	mu sync.Mutex
+}
+
+// NewEventWriter creates an EventWriter writing events to w.
+// It takes w of type io.Writer, usually the standard output.
+// It returns a pointer to the created EventWriter.
+func NewEventWriter(w io.Writer) *EventWriter {
+	return &EventWriter{w: w}
+}
+
+// Error writes an error event with the reason err.
+func (e *EventWriter) Error(err error) {
+	e.emit(Event{Event: EventError, Error: err.Error()})
+}
+
+// Connected writes a connect event for the connection established to url.
+func (e *EventWriter) Connected(url string) {
+	e.status(StatusConnected, url, nil)
+}
+
+// message writes a message event for the sent or received message.
+func (e *EventWriter) message(msg Message) {
+	e.emit(Event{Event: EventMessage, Type: msg.Type.String(), Data: msg.Data})
+}
+
+// status writes the event matching the change of the connection status, other changes are skipped.
+func (e *EventWriter) status(status, url string, err error) {
+	event := Event{URL: url}
+
+	switch status {
+	case StatusConnected:
+		event.Event = EventConnect
+	case StatusReconnecting:
+		event.Event = EventError
+	case StatusClosed:
+		event.Event = EventClose
+	default:
+		return
+	}
+
+	if err != nil {
+		event.Error = err.Error()
+	}
+
+	e.emit(event)
+}
+
+// emit writes the event stamped with the current time as a single JSON line.
+// It does nothing if the writer is not set, write errors are ignored, as there is nowhere to report them.
+func (e *EventWriter) emit(event Event) {
+	if e == nil {
+		return
+	}
+
+	event.Time = time.Now()
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	_, _ = e.w.Write(append(data, '\n'))
+}
@@ -0,0 +1,122 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// readEvents decodes the JSON lines written to the event stream, the time of the events is checked and dropped.
+func readEvents(t *testing.T, data string) []Event {
+	t.Helper()
+
+	var events []Event
+
+	for _, line := range strings.Split(strings.TrimSpace(data), "\n") {
+		var event Event
+		require.NoError(t, json.Unmarshal([]byte(line), &event), line)
+		assert.False(t, event.Time.IsZero(), line)
+
+		event.Time = time.Time{}
+		events = append(events, event)
+	}
+
+	return events
+}
+
+func TestEventWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	events := NewEventWriter(buf)
+
+	events.status(StatusConnected, "ws://example.com", nil)
+	events.message(Message{Type: Request, Data: `{"ping":1}`})
+	events.status("connecting", "ws://example.com", nil)
+	events.status(StatusReconnecting, "ws://example.com", errors.New("reset"))
+	events.Error(errors.New("fail"))
+	events.status(StatusClosed, "ws://example.com", nil)
+
+	assert.Equal(t, []Event{
+		{Event: EventConnect, URL: "ws://example.com"},
+		{Event: EventMessage, Type: "Request", Data: `{"ping":1}`},
+		{Event: EventError, URL: "ws://example.com", Error: "reset"},
+		{Event: EventError, Error: "fail"},
+		{Event: EventClose, URL: "ws://example.com"},
+	}, readEvents(t, buf.String()))
+
+	var disabled *EventWriter
+	disabled.message(Message{Type: Request, Data: "ignored"})
+}
+
+func TestCLIRun_EventStream(t *testing.T) {
+	var (
+		onMessage      func(context.Context, []byte)
+		onStatusChange func(context.Context, string, error)
+	)
+
+	sent := make(chan struct{})
+
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().SetOnMessage(mock.Anything).Run(func(cb func(context.Context, []byte)) { onMessage = cb })
+	wsConn.EXPECT().SetOnStatusChange(mock.Anything).Run(func(cb func(context.Context, string, error)) { onStatusChange = cb })
+	wsConn.EXPECT().SetOnFrameError(mock.Anything)
+	wsConn.EXPECT().Info().Return(ConnectionInfo{URL: "ws://example.com"})
+	wsConn.EXPECT().Send(mock.Anything, "ping").Return(nil)
+
+	editor := NewMockEditor(t)
+	editor.EXPECT().SetInput(mock.Anything)
+
+	exitCmd := NewMockExecuter(t)
+	exitCmd.EXPECT().Execute(mock.Anything).Return(nil, ErrInterrupted)
+
+	sendCmd := NewMockExecuter(t)
+	sendCmd.EXPECT().Execute(mock.Anything).RunAndReturn(func(exCtx ExecutionContext) (Executer, error) {
+		defer close(sent)
+		return nil, exCtx.SendRequest("ping")
+	})
+
+	factory := NewMockCommandFactory(t)
+	factory.EXPECT().Create(mock.Anything).RunAndReturn(func(raw string) (Executer, error) {
+		if raw == "exit" {
+			return exitCmd, nil
+		}
+
+		printCmd := NewMockExecuter(t)
+		printCmd.EXPECT().Execute(mock.Anything).Return(nil, nil)
+
+		return printCmd, nil
+	})
+
+	stream := &bytes.Buffer{}
+	cli := NewCLI(factory, wsConn, &bytes.Buffer{}, editor, NewMockFormater(t))
+	cli.SetEventWriter(NewEventWriter(stream))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	onStatusChange(ctx, StatusConnected, nil)
+
+	go func() {
+		<-sent
+		onMessage(ctx, []byte("pong"))
+		onStatusChange(ctx, StatusClosed, nil)
+		cli.OnKeyEvent(KeyEvent{Key: KeyCtrlC})
+	}()
+
+	err := cli.Run(ctx, RunOptions{Commands: []Executer{sendCmd}})
+
+	assert.ErrorIs(t, err, ErrInterrupted)
+	assert.Equal(t, []Event{
+		{Event: EventConnect, URL: "ws://example.com"},
+		{Event: EventMessage, Type: "Request", Data: "ping"},
+		{Event: EventMessage, Type: "Response", Data: "pong"},
+		{Event: EventClose, URL: "ws://example.com"},
+	}, readEvents(t, stream.String()))
+}
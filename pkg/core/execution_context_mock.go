@@ -5,7 +5,10 @@
 package core
 
 import (
+	context "context"
+
 	color "github.com/fatih/color"
+
 	mock "github.com/stretchr/testify/mock"
 
 	time "time"
@@ -24,9 +27,103 @@ func (_m *MockExecutionContext) EXPECT() *MockExecutionContext_Expecter {
 	return &MockExecutionContext_Expecter{mock: &_m.Mock}
 }
 
-// CommandMode provides a mock function with given fields: initBuffer
-func (_m *MockExecutionContext) CommandMode(initBuffer string) (string, error) {
-	ret := _m.Called(initBuffer)
+// AddSink provides a mock function with given fields: path, format
+func (_m *MockExecutionContext) AddSink(path string, format string) error {
+	ret := _m.Called(path, format)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddSink")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(path, format)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockExecutionContext_AddSink_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddSink'
+type MockExecutionContext_AddSink_Call struct {
+	*mock.Call
+}
+
+// AddSink is a helper method to define mock.On call
+//   - path string
+//   - format string
+func (_e *MockExecutionContext_Expecter) AddSink(path interface{}, format interface{}) *MockExecutionContext_AddSink_Call {
+	return &MockExecutionContext_AddSink_Call{Call: _e.mock.On("AddSink", path, format)}
+}
+
+func (_c *MockExecutionContext_AddSink_Call) Run(run func(path string, format string)) *MockExecutionContext_AddSink_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_AddSink_Call) Return(_a0 error) *MockExecutionContext_AddSink_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_AddSink_Call) RunAndReturn(run func(string, string) error) *MockExecutionContext_AddSink_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Clock provides a mock function with no fields
+func (_m *MockExecutionContext) Clock() Clock {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Clock")
+	}
+
+	var r0 Clock
+	if rf, ok := ret.Get(0).(func() Clock); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(Clock)
+		}
+	}
+
+	return r0
+}
+
+// MockExecutionContext_Clock_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Clock'
+type MockExecutionContext_Clock_Call struct {
+	*mock.Call
+}
+
+// Clock is a helper method to define mock.On call
+func (_e *MockExecutionContext_Expecter) Clock() *MockExecutionContext_Clock_Call {
+	return &MockExecutionContext_Clock_Call{Call: _e.mock.On("Clock")}
+}
+
+func (_c *MockExecutionContext_Clock_Call) Run(run func()) *MockExecutionContext_Clock_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_Clock_Call) Return(_a0 Clock) *MockExecutionContext_Clock_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_Clock_Call) RunAndReturn(run func() Clock) *MockExecutionContext_Clock_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CommandMode provides a mock function with given fields: prompt, initBuffer
+func (_m *MockExecutionContext) CommandMode(prompt string, initBuffer string) (string, error) {
+	ret := _m.Called(prompt, initBuffer)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CommandMode")
@@ -34,17 +131,17 @@ func (_m *MockExecutionContext) CommandMode(initBuffer string) (string, error) {
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (string, error)); ok {
-		return rf(initBuffer)
+	if rf, ok := ret.Get(0).(func(string, string) (string, error)); ok {
+		return rf(prompt, initBuffer)
 	}
-	if rf, ok := ret.Get(0).(func(string) string); ok {
-		r0 = rf(initBuffer)
+	if rf, ok := ret.Get(0).(func(string, string) string); ok {
+		r0 = rf(prompt, initBuffer)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(initBuffer)
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(prompt, initBuffer)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -58,14 +155,15 @@ type MockExecutionContext_CommandMode_Call struct {
 }
 
 // CommandMode is a helper method to define mock.On call
+//   - prompt string
 //   - initBuffer string
-func (_e *MockExecutionContext_Expecter) CommandMode(initBuffer interface{}) *MockExecutionContext_CommandMode_Call {
-	return &MockExecutionContext_CommandMode_Call{Call: _e.mock.On("CommandMode", initBuffer)}
+func (_e *MockExecutionContext_Expecter) CommandMode(prompt interface{}, initBuffer interface{}) *MockExecutionContext_CommandMode_Call {
+	return &MockExecutionContext_CommandMode_Call{Call: _e.mock.On("CommandMode", prompt, initBuffer)}
 }
 
-func (_c *MockExecutionContext_CommandMode_Call) Run(run func(initBuffer string)) *MockExecutionContext_CommandMode_Call {
+func (_c *MockExecutionContext_CommandMode_Call) Run(run func(prompt string, initBuffer string)) *MockExecutionContext_CommandMode_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(string), args[1].(string))
 	})
 	return _c
 }
@@ -75,7 +173,191 @@ func (_c *MockExecutionContext_CommandMode_Call) Return(_a0 string, _a1 error) *
 	return _c
 }
 
-func (_c *MockExecutionContext_CommandMode_Call) RunAndReturn(run func(string) (string, error)) *MockExecutionContext_CommandMode_Call {
+func (_c *MockExecutionContext_CommandMode_Call) RunAndReturn(run func(string, string) (string, error)) *MockExecutionContext_CommandMode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ConnectionInfo provides a mock function with no fields
+func (_m *MockExecutionContext) ConnectionInfo() ConnectionInfo {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConnectionInfo")
+	}
+
+	var r0 ConnectionInfo
+	if rf, ok := ret.Get(0).(func() ConnectionInfo); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(ConnectionInfo)
+	}
+
+	return r0
+}
+
+// MockExecutionContext_ConnectionInfo_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ConnectionInfo'
+type MockExecutionContext_ConnectionInfo_Call struct {
+	*mock.Call
+}
+
+// ConnectionInfo is a helper method to define mock.On call
+func (_e *MockExecutionContext_Expecter) ConnectionInfo() *MockExecutionContext_ConnectionInfo_Call {
+	return &MockExecutionContext_ConnectionInfo_Call{Call: _e.mock.On("ConnectionInfo")}
+}
+
+func (_c *MockExecutionContext_ConnectionInfo_Call) Run(run func()) *MockExecutionContext_ConnectionInfo_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_ConnectionInfo_Call) Return(_a0 ConnectionInfo) *MockExecutionContext_ConnectionInfo_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_ConnectionInfo_Call) RunAndReturn(run func() ConnectionInfo) *MockExecutionContext_ConnectionInfo_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Context provides a mock function with no fields
+func (_m *MockExecutionContext) Context() context.Context {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Context")
+	}
+
+	var r0 context.Context
+	if rf, ok := ret.Get(0).(func() context.Context); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(context.Context)
+		}
+	}
+
+	return r0
+}
+
+// MockExecutionContext_Context_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Context'
+type MockExecutionContext_Context_Call struct {
+	*mock.Call
+}
+
+// Context is a helper method to define mock.On call
+func (_e *MockExecutionContext_Expecter) Context() *MockExecutionContext_Context_Call {
+	return &MockExecutionContext_Context_Call{Call: _e.mock.On("Context")}
+}
+
+func (_c *MockExecutionContext_Context_Call) Run(run func()) *MockExecutionContext_Context_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_Context_Call) Return(_a0 context.Context) *MockExecutionContext_Context_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_Context_Call) RunAndReturn(run func() context.Context) *MockExecutionContext_Context_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ContinueOnError provides a mock function with no fields
+func (_m *MockExecutionContext) ContinueOnError() bool {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ContinueOnError")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// MockExecutionContext_ContinueOnError_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ContinueOnError'
+type MockExecutionContext_ContinueOnError_Call struct {
+	*mock.Call
+}
+
+// ContinueOnError is a helper method to define mock.On call
+func (_e *MockExecutionContext_Expecter) ContinueOnError() *MockExecutionContext_ContinueOnError_Call {
+	return &MockExecutionContext_ContinueOnError_Call{Call: _e.mock.On("ContinueOnError")}
+}
+
+func (_c *MockExecutionContext_ContinueOnError_Call) Run(run func()) *MockExecutionContext_ContinueOnError_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_ContinueOnError_Call) Return(_a0 bool) *MockExecutionContext_ContinueOnError_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_ContinueOnError_Call) RunAndReturn(run func() bool) *MockExecutionContext_ContinueOnError_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Correlator provides a mock function with no fields
+func (_m *MockExecutionContext) Correlator() Correlator {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Correlator")
+	}
+
+	var r0 Correlator
+	if rf, ok := ret.Get(0).(func() Correlator); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(Correlator)
+		}
+	}
+
+	return r0
+}
+
+// MockExecutionContext_Correlator_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Correlator'
+type MockExecutionContext_Correlator_Call struct {
+	*mock.Call
+}
+
+// Correlator is a helper method to define mock.On call
+func (_e *MockExecutionContext_Expecter) Correlator() *MockExecutionContext_Correlator_Call {
+	return &MockExecutionContext_Correlator_Call{Call: _e.mock.On("Correlator")}
+}
+
+func (_c *MockExecutionContext_Correlator_Call) Run(run func()) *MockExecutionContext_Correlator_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_Correlator_Call) Return(_a0 Correlator) *MockExecutionContext_Correlator_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_Correlator_Call) RunAndReturn(run func() Correlator) *MockExecutionContext_Correlator_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -194,6 +476,52 @@ func (_c *MockExecutionContext_EditorMode_Call) RunAndReturn(run func(string) (s
 	return _c
 }
 
+// ExpandVariables provides a mock function with given fields: data
+func (_m *MockExecutionContext) ExpandVariables(data string) string {
+	ret := _m.Called(data)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExpandVariables")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(data)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// MockExecutionContext_ExpandVariables_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExpandVariables'
+type MockExecutionContext_ExpandVariables_Call struct {
+	*mock.Call
+}
+
+// ExpandVariables is a helper method to define mock.On call
+//   - data string
+func (_e *MockExecutionContext_Expecter) ExpandVariables(data interface{}) *MockExecutionContext_ExpandVariables_Call {
+	return &MockExecutionContext_ExpandVariables_Call{Call: _e.mock.On("ExpandVariables", data)}
+}
+
+func (_c *MockExecutionContext_ExpandVariables_Call) Run(run func(data string)) *MockExecutionContext_ExpandVariables_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_ExpandVariables_Call) Return(_a0 string) *MockExecutionContext_ExpandVariables_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_ExpandVariables_Call) RunAndReturn(run func(string) string) *MockExecutionContext_ExpandVariables_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // FormatMessage provides a mock function with given fields: msg, noColor
 func (_m *MockExecutionContext) FormatMessage(msg Message, noColor bool) (string, error) {
 	ret := _m.Called(msg, noColor)
@@ -251,63 +579,359 @@ func (_c *MockExecutionContext_FormatMessage_Call) RunAndReturn(run func(Message
 	return _c
 }
 
-// Print provides a mock function with given fields: data, attr
-func (_m *MockExecutionContext) Print(data string, attr ...color.Attribute) error {
-	_va := make([]interface{}, len(attr))
-	for _i := range attr {
-		_va[_i] = attr[_i]
-	}
-	var _ca []interface{}
-	_ca = append(_ca, data)
-	_ca = append(_ca, _va...)
-	ret := _m.Called(_ca...)
+// FormatOptions provides a mock function with no fields
+func (_m *MockExecutionContext) FormatOptions() map[string]string {
+	ret := _m.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for Print")
+		panic("no return value specified for FormatOptions")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(string, ...color.Attribute) error); ok {
-		r0 = rf(data, attr...)
+	var r0 map[string]string
+	if rf, ok := ret.Get(0).(func() map[string]string); ok {
+		r0 = rf()
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]string)
+		}
 	}
 
 	return r0
 }
 
-// MockExecutionContext_Print_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Print'
-type MockExecutionContext_Print_Call struct {
+// MockExecutionContext_FormatOptions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FormatOptions'
+type MockExecutionContext_FormatOptions_Call struct {
 	*mock.Call
 }
 
-// Print is a helper method to define mock.On call
-//   - data string
-//   - attr ...color.Attribute
-func (_e *MockExecutionContext_Expecter) Print(data interface{}, attr ...interface{}) *MockExecutionContext_Print_Call {
-	return &MockExecutionContext_Print_Call{Call: _e.mock.On("Print",
-		append([]interface{}{data}, attr...)...)}
+// FormatOptions is a helper method to define mock.On call
+func (_e *MockExecutionContext_Expecter) FormatOptions() *MockExecutionContext_FormatOptions_Call {
+	return &MockExecutionContext_FormatOptions_Call{Call: _e.mock.On("FormatOptions")}
 }
 
-func (_c *MockExecutionContext_Print_Call) Run(run func(data string, attr ...color.Attribute)) *MockExecutionContext_Print_Call {
+func (_c *MockExecutionContext_FormatOptions_Call) Run(run func()) *MockExecutionContext_FormatOptions_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		variadicArgs := make([]color.Attribute, len(args)-1)
-		for i, a := range args[1:] {
-			if a != nil {
-				variadicArgs[i] = a.(color.Attribute)
-			}
-		}
-		run(args[0].(string), variadicArgs...)
+		run()
 	})
 	return _c
 }
 
-func (_c *MockExecutionContext_Print_Call) Return(_a0 error) *MockExecutionContext_Print_Call {
+func (_c *MockExecutionContext_FormatOptions_Call) Return(_a0 map[string]string) *MockExecutionContext_FormatOptions_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *MockExecutionContext_Print_Call) RunAndReturn(run func(string, ...color.Attribute) error) *MockExecutionContext_Print_Call {
+func (_c *MockExecutionContext_FormatOptions_Call) RunAndReturn(run func() map[string]string) *MockExecutionContext_FormatOptions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LastRequest provides a mock function with no fields
+func (_m *MockExecutionContext) LastRequest() (string, bool) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for LastRequest")
+	}
+
+	var r0 string
+	var r1 bool
+	if rf, ok := ret.Get(0).(func() (string, bool)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func() bool); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
+// MockExecutionContext_LastRequest_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LastRequest'
+type MockExecutionContext_LastRequest_Call struct {
+	*mock.Call
+}
+
+// LastRequest is a helper method to define mock.On call
+func (_e *MockExecutionContext_Expecter) LastRequest() *MockExecutionContext_LastRequest_Call {
+	return &MockExecutionContext_LastRequest_Call{Call: _e.mock.On("LastRequest")}
+}
+
+func (_c *MockExecutionContext_LastRequest_Call) Run(run func()) *MockExecutionContext_LastRequest_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_LastRequest_Call) Return(_a0 string, _a1 bool) *MockExecutionContext_LastRequest_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockExecutionContext_LastRequest_Call) RunAndReturn(run func() (string, bool)) *MockExecutionContext_LastRequest_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LastResponse provides a mock function with no fields
+func (_m *MockExecutionContext) LastResponse() (Message, bool) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for LastResponse")
+	}
+
+	var r0 Message
+	var r1 bool
+	if rf, ok := ret.Get(0).(func() (Message, bool)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() Message); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(Message)
+	}
+
+	if rf, ok := ret.Get(1).(func() bool); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
+// MockExecutionContext_LastResponse_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LastResponse'
+type MockExecutionContext_LastResponse_Call struct {
+	*mock.Call
+}
+
+// LastResponse is a helper method to define mock.On call
+func (_e *MockExecutionContext_Expecter) LastResponse() *MockExecutionContext_LastResponse_Call {
+	return &MockExecutionContext_LastResponse_Call{Call: _e.mock.On("LastResponse")}
+}
+
+func (_c *MockExecutionContext_LastResponse_Call) Run(run func()) *MockExecutionContext_LastResponse_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_LastResponse_Call) Return(_a0 Message, _a1 bool) *MockExecutionContext_LastResponse_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockExecutionContext_LastResponse_Call) RunAndReturn(run func() (Message, bool)) *MockExecutionContext_LastResponse_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LimitTime provides a mock function with given fields: timeout
+func (_m *MockExecutionContext) LimitTime(timeout time.Duration) func() {
+	ret := _m.Called(timeout)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LimitTime")
+	}
+
+	var r0 func()
+	if rf, ok := ret.Get(0).(func(time.Duration) func()); ok {
+		r0 = rf(timeout)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(func())
+		}
+	}
+
+	return r0
+}
+
+// MockExecutionContext_LimitTime_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LimitTime'
+type MockExecutionContext_LimitTime_Call struct {
+	*mock.Call
+}
+
+// LimitTime is a helper method to define mock.On call
+//   - timeout time.Duration
+func (_e *MockExecutionContext_Expecter) LimitTime(timeout interface{}) *MockExecutionContext_LimitTime_Call {
+	return &MockExecutionContext_LimitTime_Call{Call: _e.mock.On("LimitTime", timeout)}
+}
+
+func (_c *MockExecutionContext_LimitTime_Call) Run(run func(timeout time.Duration)) *MockExecutionContext_LimitTime_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_LimitTime_Call) Return(restore func()) *MockExecutionContext_LimitTime_Call {
+	_c.Call.Return(restore)
+	return _c
+}
+
+func (_c *MockExecutionContext_LimitTime_Call) RunAndReturn(run func(time.Duration) func()) *MockExecutionContext_LimitTime_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NormalizeNewlines provides a mock function with given fields: data
+func (_m *MockExecutionContext) NormalizeNewlines(data string) string {
+	ret := _m.Called(data)
+
+	if len(ret) == 0 {
+		panic("no return value specified for NormalizeNewlines")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(data)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// MockExecutionContext_NormalizeNewlines_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'NormalizeNewlines'
+type MockExecutionContext_NormalizeNewlines_Call struct {
+	*mock.Call
+}
+
+// NormalizeNewlines is a helper method to define mock.On call
+//   - data string
+func (_e *MockExecutionContext_Expecter) NormalizeNewlines(data interface{}) *MockExecutionContext_NormalizeNewlines_Call {
+	return &MockExecutionContext_NormalizeNewlines_Call{Call: _e.mock.On("NormalizeNewlines", data)}
+}
+
+func (_c *MockExecutionContext_NormalizeNewlines_Call) Run(run func(data string)) *MockExecutionContext_NormalizeNewlines_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_NormalizeNewlines_Call) Return(_a0 string) *MockExecutionContext_NormalizeNewlines_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_NormalizeNewlines_Call) RunAndReturn(run func(string) string) *MockExecutionContext_NormalizeNewlines_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Pause provides a mock function with no fields
+func (_m *MockExecutionContext) Pause() bool {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Pause")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// MockExecutionContext_Pause_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Pause'
+type MockExecutionContext_Pause_Call struct {
+	*mock.Call
+}
+
+// Pause is a helper method to define mock.On call
+func (_e *MockExecutionContext_Expecter) Pause() *MockExecutionContext_Pause_Call {
+	return &MockExecutionContext_Pause_Call{Call: _e.mock.On("Pause")}
+}
+
+func (_c *MockExecutionContext_Pause_Call) Run(run func()) *MockExecutionContext_Pause_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_Pause_Call) Return(_a0 bool) *MockExecutionContext_Pause_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_Pause_Call) RunAndReturn(run func() bool) *MockExecutionContext_Pause_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Print provides a mock function with given fields: data, attr
+func (_m *MockExecutionContext) Print(data string, attr ...color.Attribute) error {
+	_va := make([]interface{}, len(attr))
+	for _i := range attr {
+		_va[_i] = attr[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, data)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Print")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, ...color.Attribute) error); ok {
+		r0 = rf(data, attr...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockExecutionContext_Print_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Print'
+type MockExecutionContext_Print_Call struct {
+	*mock.Call
+}
+
+// Print is a helper method to define mock.On call
+//   - data string
+//   - attr ...color.Attribute
+func (_e *MockExecutionContext_Expecter) Print(data interface{}, attr ...interface{}) *MockExecutionContext_Print_Call {
+	return &MockExecutionContext_Print_Call{Call: _e.mock.On("Print",
+		append([]interface{}{data}, attr...)...)}
+}
+
+func (_c *MockExecutionContext_Print_Call) Run(run func(data string, attr ...color.Attribute)) *MockExecutionContext_Print_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]color.Attribute, len(args)-1)
+		for i, a := range args[1:] {
+			if a != nil {
+				variadicArgs[i] = a.(color.Attribute)
+			}
+		}
+		run(args[0].(string), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_Print_Call) Return(_a0 error) *MockExecutionContext_Print_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_Print_Call) RunAndReturn(run func(string, ...color.Attribute) error) *MockExecutionContext_Print_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -358,48 +982,1095 @@ func (_c *MockExecutionContext_PrintToFile_Call) RunAndReturn(run func(string) e
 	return _c
 }
 
-// SendRequest provides a mock function with given fields: req
-func (_m *MockExecutionContext) SendRequest(req string) error {
-	ret := _m.Called(req)
+// Prompt provides a mock function with no fields
+func (_m *MockExecutionContext) Prompt() (string, error) {
+	ret := _m.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for SendRequest")
+		panic("no return value specified for Prompt")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(req)
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (string, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(string)
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// MockExecutionContext_SendRequest_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SendRequest'
-type MockExecutionContext_SendRequest_Call struct {
+// MockExecutionContext_Prompt_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Prompt'
+type MockExecutionContext_Prompt_Call struct {
 	*mock.Call
 }
 
-// SendRequest is a helper method to define mock.On call
-//   - req string
-func (_e *MockExecutionContext_Expecter) SendRequest(req interface{}) *MockExecutionContext_SendRequest_Call {
-	return &MockExecutionContext_SendRequest_Call{Call: _e.mock.On("SendRequest", req)}
+// Prompt is a helper method to define mock.On call
+func (_e *MockExecutionContext_Expecter) Prompt() *MockExecutionContext_Prompt_Call {
+	return &MockExecutionContext_Prompt_Call{Call: _e.mock.On("Prompt")}
 }
 
-func (_c *MockExecutionContext_SendRequest_Call) Run(run func(req string)) *MockExecutionContext_SendRequest_Call {
+func (_c *MockExecutionContext_Prompt_Call) Run(run func()) *MockExecutionContext_Prompt_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run()
 	})
 	return _c
 }
 
-func (_c *MockExecutionContext_SendRequest_Call) Return(_a0 error) *MockExecutionContext_SendRequest_Call {
-	_c.Call.Return(_a0)
+func (_c *MockExecutionContext_Prompt_Call) Return(_a0 string, _a1 error) *MockExecutionContext_Prompt_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockExecutionContext_SendRequest_Call) RunAndReturn(run func(string) error) *MockExecutionContext_SendRequest_Call {
+func (_c *MockExecutionContext_Prompt_Call) RunAndReturn(run func() (string, error)) *MockExecutionContext_Prompt_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RecentMessages provides a mock function with given fields: n
+func (_m *MockExecutionContext) RecentMessages(n int) []Message {
+	ret := _m.Called(n)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecentMessages")
+	}
+
+	var r0 []Message
+	if rf, ok := ret.Get(0).(func(int) []Message); ok {
+		r0 = rf(n)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Message)
+		}
+	}
+
+	return r0
+}
+
+// MockExecutionContext_RecentMessages_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecentMessages'
+type MockExecutionContext_RecentMessages_Call struct {
+	*mock.Call
+}
+
+// RecentMessages is a helper method to define mock.On call
+//   - n int
+func (_e *MockExecutionContext_Expecter) RecentMessages(n interface{}) *MockExecutionContext_RecentMessages_Call {
+	return &MockExecutionContext_RecentMessages_Call{Call: _e.mock.On("RecentMessages", n)}
+}
+
+func (_c *MockExecutionContext_RecentMessages_Call) Run(run func(n int)) *MockExecutionContext_RecentMessages_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(int))
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_RecentMessages_Call) Return(_a0 []Message) *MockExecutionContext_RecentMessages_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_RecentMessages_Call) RunAndReturn(run func(int) []Message) *MockExecutionContext_RecentMessages_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Reconnect provides a mock function with no fields
+func (_m *MockExecutionContext) Reconnect() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reconnect")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockExecutionContext_Reconnect_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Reconnect'
+type MockExecutionContext_Reconnect_Call struct {
+	*mock.Call
+}
+
+// Reconnect is a helper method to define mock.On call
+func (_e *MockExecutionContext_Expecter) Reconnect() *MockExecutionContext_Reconnect_Call {
+	return &MockExecutionContext_Reconnect_Call{Call: _e.mock.On("Reconnect")}
+}
+
+func (_c *MockExecutionContext_Reconnect_Call) Run(run func()) *MockExecutionContext_Reconnect_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_Reconnect_Call) Return(_a0 error) *MockExecutionContext_Reconnect_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_Reconnect_Call) RunAndReturn(run func() error) *MockExecutionContext_Reconnect_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RecordMessage provides a mock function with given fields: msg
+func (_m *MockExecutionContext) RecordMessage(msg Message) error {
+	ret := _m.Called(msg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordMessage")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(Message) error); ok {
+		r0 = rf(msg)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockExecutionContext_RecordMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecordMessage'
+type MockExecutionContext_RecordMessage_Call struct {
+	*mock.Call
+}
+
+// RecordMessage is a helper method to define mock.On call
+//   - msg Message
+func (_e *MockExecutionContext_Expecter) RecordMessage(msg interface{}) *MockExecutionContext_RecordMessage_Call {
+	return &MockExecutionContext_RecordMessage_Call{Call: _e.mock.On("RecordMessage", msg)}
+}
+
+func (_c *MockExecutionContext_RecordMessage_Call) Run(run func(msg Message)) *MockExecutionContext_RecordMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Message))
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_RecordMessage_Call) Return(_a0 error) *MockExecutionContext_RecordMessage_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_RecordMessage_Call) RunAndReturn(run func(Message) error) *MockExecutionContext_RecordMessage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResponseLatency provides a mock function with no fields
+func (_m *MockExecutionContext) ResponseLatency() (time.Duration, bool) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResponseLatency")
+	}
+
+	var r0 time.Duration
+	var r1 bool
+	if rf, ok := ret.Get(0).(func() (time.Duration, bool)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() time.Duration); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(time.Duration)
+	}
+
+	if rf, ok := ret.Get(1).(func() bool); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
+// MockExecutionContext_ResponseLatency_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResponseLatency'
+type MockExecutionContext_ResponseLatency_Call struct {
+	*mock.Call
+}
+
+// ResponseLatency is a helper method to define mock.On call
+func (_e *MockExecutionContext_Expecter) ResponseLatency() *MockExecutionContext_ResponseLatency_Call {
+	return &MockExecutionContext_ResponseLatency_Call{Call: _e.mock.On("ResponseLatency")}
+}
+
+func (_c *MockExecutionContext_ResponseLatency_Call) Run(run func()) *MockExecutionContext_ResponseLatency_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_ResponseLatency_Call) Return(_a0 time.Duration, _a1 bool) *MockExecutionContext_ResponseLatency_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockExecutionContext_ResponseLatency_Call) RunAndReturn(run func() (time.Duration, bool)) *MockExecutionContext_ResponseLatency_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Resume provides a mock function with no fields
+func (_m *MockExecutionContext) Resume() (Executer, bool) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Resume")
+	}
+
+	var r0 Executer
+	var r1 bool
+	if rf, ok := ret.Get(0).(func() (Executer, bool)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() Executer); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(Executer)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() bool); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
+// MockExecutionContext_Resume_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Resume'
+type MockExecutionContext_Resume_Call struct {
+	*mock.Call
+}
+
+// Resume is a helper method to define mock.On call
+func (_e *MockExecutionContext_Expecter) Resume() *MockExecutionContext_Resume_Call {
+	return &MockExecutionContext_Resume_Call{Call: _e.mock.On("Resume")}
+}
+
+func (_c *MockExecutionContext_Resume_Call) Run(run func()) *MockExecutionContext_Resume_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_Resume_Call) Return(_a0 Executer, _a1 bool) *MockExecutionContext_Resume_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockExecutionContext_Resume_Call) RunAndReturn(run func() (Executer, bool)) *MockExecutionContext_Resume_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SendBinary provides a mock function with given fields: data
+func (_m *MockExecutionContext) SendBinary(data []byte) error {
+	ret := _m.Called(data)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendBinary")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]byte) error); ok {
+		r0 = rf(data)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockExecutionContext_SendBinary_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SendBinary'
+type MockExecutionContext_SendBinary_Call struct {
+	*mock.Call
+}
+
+// SendBinary is a helper method to define mock.On call
+//   - data []byte
+func (_e *MockExecutionContext_Expecter) SendBinary(data interface{}) *MockExecutionContext_SendBinary_Call {
+	return &MockExecutionContext_SendBinary_Call{Call: _e.mock.On("SendBinary", data)}
+}
+
+func (_c *MockExecutionContext_SendBinary_Call) Run(run func(data []byte)) *MockExecutionContext_SendBinary_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].([]byte))
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_SendBinary_Call) Return(_a0 error) *MockExecutionContext_SendBinary_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_SendBinary_Call) RunAndReturn(run func([]byte) error) *MockExecutionContext_SendBinary_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SendRequest provides a mock function with given fields: req
+func (_m *MockExecutionContext) SendRequest(req string) error {
+	ret := _m.Called(req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendRequest")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockExecutionContext_SendRequest_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SendRequest'
+type MockExecutionContext_SendRequest_Call struct {
+	*mock.Call
+}
+
+// SendRequest is a helper method to define mock.On call
+//   - req string
+func (_e *MockExecutionContext_Expecter) SendRequest(req interface{}) *MockExecutionContext_SendRequest_Call {
+	return &MockExecutionContext_SendRequest_Call{Call: _e.mock.On("SendRequest", req)}
+}
+
+func (_c *MockExecutionContext_SendRequest_Call) Run(run func(req string)) *MockExecutionContext_SendRequest_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_SendRequest_Call) Return(_a0 error) *MockExecutionContext_SendRequest_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_SendRequest_Call) RunAndReturn(run func(string) error) *MockExecutionContext_SendRequest_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetContentType provides a mock function with given fields: contentType
+func (_m *MockExecutionContext) SetContentType(contentType string) error {
+	ret := _m.Called(contentType)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetContentType")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(contentType)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockExecutionContext_SetContentType_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetContentType'
+type MockExecutionContext_SetContentType_Call struct {
+	*mock.Call
+}
+
+// SetContentType is a helper method to define mock.On call
+//   - contentType string
+func (_e *MockExecutionContext_Expecter) SetContentType(contentType interface{}) *MockExecutionContext_SetContentType_Call {
+	return &MockExecutionContext_SetContentType_Call{Call: _e.mock.On("SetContentType", contentType)}
+}
+
+func (_c *MockExecutionContext_SetContentType_Call) Run(run func(contentType string)) *MockExecutionContext_SetContentType_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_SetContentType_Call) Return(_a0 error) *MockExecutionContext_SetContentType_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_SetContentType_Call) RunAndReturn(run func(string) error) *MockExecutionContext_SetContentType_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetFocus provides a mock function with given fields: path
+func (_m *MockExecutionContext) SetFocus(path string) {
+	_m.Called(path)
+}
+
+// MockExecutionContext_SetFocus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetFocus'
+type MockExecutionContext_SetFocus_Call struct {
+	*mock.Call
+}
+
+// SetFocus is a helper method to define mock.On call
+//   - path string
+func (_e *MockExecutionContext_Expecter) SetFocus(path interface{}) *MockExecutionContext_SetFocus_Call {
+	return &MockExecutionContext_SetFocus_Call{Call: _e.mock.On("SetFocus", path)}
+}
+
+func (_c *MockExecutionContext_SetFocus_Call) Run(run func(path string)) *MockExecutionContext_SetFocus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_SetFocus_Call) Return() *MockExecutionContext_SetFocus_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockExecutionContext_SetFocus_Call) RunAndReturn(run func(string)) *MockExecutionContext_SetFocus_Call {
+	_c.Run(run)
+	return _c
+}
+
+// SetFormatOption provides a mock function with given fields: name, value
+func (_m *MockExecutionContext) SetFormatOption(name string, value string) error {
+	ret := _m.Called(name, value)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetFormatOption")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(name, value)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockExecutionContext_SetFormatOption_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetFormatOption'
+type MockExecutionContext_SetFormatOption_Call struct {
+	*mock.Call
+}
+
+// SetFormatOption is a helper method to define mock.On call
+//   - name string
+//   - value string
+func (_e *MockExecutionContext_Expecter) SetFormatOption(name interface{}, value interface{}) *MockExecutionContext_SetFormatOption_Call {
+	return &MockExecutionContext_SetFormatOption_Call{Call: _e.mock.On("SetFormatOption", name, value)}
+}
+
+func (_c *MockExecutionContext_SetFormatOption_Call) Run(run func(name string, value string)) *MockExecutionContext_SetFormatOption_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_SetFormatOption_Call) Return(_a0 error) *MockExecutionContext_SetFormatOption_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_SetFormatOption_Call) RunAndReturn(run func(string, string) error) *MockExecutionContext_SetFormatOption_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetMessageHook provides a mock function with given fields: command
+func (_m *MockExecutionContext) SetMessageHook(command string) {
+	_m.Called(command)
+}
+
+// MockExecutionContext_SetMessageHook_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetMessageHook'
+type MockExecutionContext_SetMessageHook_Call struct {
+	*mock.Call
+}
+
+// SetMessageHook is a helper method to define mock.On call
+//   - command string
+func (_e *MockExecutionContext_Expecter) SetMessageHook(command interface{}) *MockExecutionContext_SetMessageHook_Call {
+	return &MockExecutionContext_SetMessageHook_Call{Call: _e.mock.On("SetMessageHook", command)}
+}
+
+func (_c *MockExecutionContext_SetMessageHook_Call) Run(run func(command string)) *MockExecutionContext_SetMessageHook_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_SetMessageHook_Call) Return() *MockExecutionContext_SetMessageHook_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockExecutionContext_SetMessageHook_Call) RunAndReturn(run func(string)) *MockExecutionContext_SetMessageHook_Call {
+	_c.Run(run)
+	return _c
+}
+
+// SetRecording provides a mock function with given fields: enabled
+func (_m *MockExecutionContext) SetRecording(enabled bool) error {
+	ret := _m.Called(enabled)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetRecording")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(bool) error); ok {
+		r0 = rf(enabled)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockExecutionContext_SetRecording_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetRecording'
+type MockExecutionContext_SetRecording_Call struct {
+	*mock.Call
+}
+
+// SetRecording is a helper method to define mock.On call
+//   - enabled bool
+func (_e *MockExecutionContext_Expecter) SetRecording(enabled interface{}) *MockExecutionContext_SetRecording_Call {
+	return &MockExecutionContext_SetRecording_Call{Call: _e.mock.On("SetRecording", enabled)}
+}
+
+func (_c *MockExecutionContext_SetRecording_Call) Run(run func(enabled bool)) *MockExecutionContext_SetRecording_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(bool))
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_SetRecording_Call) Return(_a0 error) *MockExecutionContext_SetRecording_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_SetRecording_Call) RunAndReturn(run func(bool) error) *MockExecutionContext_SetRecording_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetResponseDelay provides a mock function with given fields: base, jitter
+func (_m *MockExecutionContext) SetResponseDelay(base time.Duration, jitter time.Duration) {
+	_m.Called(base, jitter)
+}
+
+// MockExecutionContext_SetResponseDelay_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetResponseDelay'
+type MockExecutionContext_SetResponseDelay_Call struct {
+	*mock.Call
+}
+
+// SetResponseDelay is a helper method to define mock.On call
+//   - base time.Duration
+//   - jitter time.Duration
+func (_e *MockExecutionContext_Expecter) SetResponseDelay(base interface{}, jitter interface{}) *MockExecutionContext_SetResponseDelay_Call {
+	return &MockExecutionContext_SetResponseDelay_Call{Call: _e.mock.On("SetResponseDelay", base, jitter)}
+}
+
+func (_c *MockExecutionContext_SetResponseDelay_Call) Run(run func(base time.Duration, jitter time.Duration)) *MockExecutionContext_SetResponseDelay_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(time.Duration), args[1].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_SetResponseDelay_Call) Return() *MockExecutionContext_SetResponseDelay_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockExecutionContext_SetResponseDelay_Call) RunAndReturn(run func(time.Duration, time.Duration)) *MockExecutionContext_SetResponseDelay_Call {
+	_c.Run(run)
+	return _c
+}
+
+// SetSkipSSLVerification provides a mock function with given fields: skip
+func (_m *MockExecutionContext) SetSkipSSLVerification(skip bool) {
+	_m.Called(skip)
+}
+
+// MockExecutionContext_SetSkipSSLVerification_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetSkipSSLVerification'
+type MockExecutionContext_SetSkipSSLVerification_Call struct {
+	*mock.Call
+}
+
+// SetSkipSSLVerification is a helper method to define mock.On call
+//   - skip bool
+func (_e *MockExecutionContext_Expecter) SetSkipSSLVerification(skip interface{}) *MockExecutionContext_SetSkipSSLVerification_Call {
+	return &MockExecutionContext_SetSkipSSLVerification_Call{Call: _e.mock.On("SetSkipSSLVerification", skip)}
+}
+
+func (_c *MockExecutionContext_SetSkipSSLVerification_Call) Run(run func(skip bool)) *MockExecutionContext_SetSkipSSLVerification_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(bool))
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_SetSkipSSLVerification_Call) Return() *MockExecutionContext_SetSkipSSLVerification_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockExecutionContext_SetSkipSSLVerification_Call) RunAndReturn(run func(bool)) *MockExecutionContext_SetSkipSSLVerification_Call {
+	_c.Run(run)
+	return _c
+}
+
+// SetThrottle provides a mock function with given fields: interval
+func (_m *MockExecutionContext) SetThrottle(interval time.Duration) {
+	_m.Called(interval)
+}
+
+// MockExecutionContext_SetThrottle_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetThrottle'
+type MockExecutionContext_SetThrottle_Call struct {
+	*mock.Call
+}
+
+// SetThrottle is a helper method to define mock.On call
+//   - interval time.Duration
+func (_e *MockExecutionContext_Expecter) SetThrottle(interval interface{}) *MockExecutionContext_SetThrottle_Call {
+	return &MockExecutionContext_SetThrottle_Call{Call: _e.mock.On("SetThrottle", interval)}
+}
+
+func (_c *MockExecutionContext_SetThrottle_Call) Run(run func(interval time.Duration)) *MockExecutionContext_SetThrottle_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_SetThrottle_Call) Return() *MockExecutionContext_SetThrottle_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockExecutionContext_SetThrottle_Call) RunAndReturn(run func(time.Duration)) *MockExecutionContext_SetThrottle_Call {
+	_c.Run(run)
+	return _c
+}
+
+// SetVariable provides a mock function with given fields: name, value
+func (_m *MockExecutionContext) SetVariable(name string, value string) {
+	_m.Called(name, value)
+}
+
+// MockExecutionContext_SetVariable_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetVariable'
+type MockExecutionContext_SetVariable_Call struct {
+	*mock.Call
+}
+
+// SetVariable is a helper method to define mock.On call
+//   - name string
+//   - value string
+func (_e *MockExecutionContext_Expecter) SetVariable(name interface{}, value interface{}) *MockExecutionContext_SetVariable_Call {
+	return &MockExecutionContext_SetVariable_Call{Call: _e.mock.On("SetVariable", name, value)}
+}
+
+func (_c *MockExecutionContext_SetVariable_Call) Run(run func(name string, value string)) *MockExecutionContext_SetVariable_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_SetVariable_Call) Return() *MockExecutionContext_SetVariable_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockExecutionContext_SetVariable_Call) RunAndReturn(run func(string, string)) *MockExecutionContext_SetVariable_Call {
+	_c.Run(run)
+	return _c
+}
+
+// ShouldRecord provides a mock function with given fields: msgType
+func (_m *MockExecutionContext) ShouldRecord(msgType MessageType) bool {
+	ret := _m.Called(msgType)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ShouldRecord")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(MessageType) bool); ok {
+		r0 = rf(msgType)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// MockExecutionContext_ShouldRecord_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ShouldRecord'
+type MockExecutionContext_ShouldRecord_Call struct {
+	*mock.Call
+}
+
+// ShouldRecord is a helper method to define mock.On call
+//   - msgType MessageType
+func (_e *MockExecutionContext_Expecter) ShouldRecord(msgType interface{}) *MockExecutionContext_ShouldRecord_Call {
+	return &MockExecutionContext_ShouldRecord_Call{Call: _e.mock.On("ShouldRecord", msgType)}
+}
+
+func (_c *MockExecutionContext_ShouldRecord_Call) Run(run func(msgType MessageType)) *MockExecutionContext_ShouldRecord_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(MessageType))
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_ShouldRecord_Call) Return(_a0 bool) *MockExecutionContext_ShouldRecord_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_ShouldRecord_Call) RunAndReturn(run func(MessageType) bool) *MockExecutionContext_ShouldRecord_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ShowRaw provides a mock function with no fields
+func (_m *MockExecutionContext) ShowRaw() bool {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ShowRaw")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// MockExecutionContext_ShowRaw_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ShowRaw'
+type MockExecutionContext_ShowRaw_Call struct {
+	*mock.Call
+}
+
+// ShowRaw is a helper method to define mock.On call
+func (_e *MockExecutionContext_Expecter) ShowRaw() *MockExecutionContext_ShowRaw_Call {
+	return &MockExecutionContext_ShowRaw_Call{Call: _e.mock.On("ShowRaw")}
+}
+
+func (_c *MockExecutionContext_ShowRaw_Call) Run(run func()) *MockExecutionContext_ShowRaw_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_ShowRaw_Call) Return(_a0 bool) *MockExecutionContext_ShowRaw_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_ShowRaw_Call) RunAndReturn(run func() bool) *MockExecutionContext_ShowRaw_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SummaryMode provides a mock function with no fields
+func (_m *MockExecutionContext) SummaryMode() bool {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for SummaryMode")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// MockExecutionContext_SummaryMode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SummaryMode'
+type MockExecutionContext_SummaryMode_Call struct {
+	*mock.Call
+}
+
+// SummaryMode is a helper method to define mock.On call
+func (_e *MockExecutionContext_Expecter) SummaryMode() *MockExecutionContext_SummaryMode_Call {
+	return &MockExecutionContext_SummaryMode_Call{Call: _e.mock.On("SummaryMode")}
+}
+
+func (_c *MockExecutionContext_SummaryMode_Call) Run(run func()) *MockExecutionContext_SummaryMode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_SummaryMode_Call) Return(_a0 bool) *MockExecutionContext_SummaryMode_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_SummaryMode_Call) RunAndReturn(run func() bool) *MockExecutionContext_SummaryMode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ThrottleResponse provides a mock function with no fields
+func (_m *MockExecutionContext) ThrottleResponse() (bool, int) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ThrottleResponse")
+	}
+
+	var r0 bool
+	var r1 int
+	if rf, ok := ret.Get(0).(func() (bool, int)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func() int); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	return r0, r1
+}
+
+// MockExecutionContext_ThrottleResponse_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ThrottleResponse'
+type MockExecutionContext_ThrottleResponse_Call struct {
+	*mock.Call
+}
+
+// ThrottleResponse is a helper method to define mock.On call
+func (_e *MockExecutionContext_Expecter) ThrottleResponse() *MockExecutionContext_ThrottleResponse_Call {
+	return &MockExecutionContext_ThrottleResponse_Call{Call: _e.mock.On("ThrottleResponse")}
+}
+
+func (_c *MockExecutionContext_ThrottleResponse_Call) Run(run func()) *MockExecutionContext_ThrottleResponse_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_ThrottleResponse_Call) Return(show bool, dropped int) *MockExecutionContext_ThrottleResponse_Call {
+	_c.Call.Return(show, dropped)
+	return _c
+}
+
+func (_c *MockExecutionContext_ThrottleResponse_Call) RunAndReturn(run func() (bool, int)) *MockExecutionContext_ThrottleResponse_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TrimPayload provides a mock function with given fields: data
+func (_m *MockExecutionContext) TrimPayload(data string) string {
+	ret := _m.Called(data)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TrimPayload")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(data)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// MockExecutionContext_TrimPayload_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TrimPayload'
+type MockExecutionContext_TrimPayload_Call struct {
+	*mock.Call
+}
+
+// TrimPayload is a helper method to define mock.On call
+//   - data string
+func (_e *MockExecutionContext_Expecter) TrimPayload(data interface{}) *MockExecutionContext_TrimPayload_Call {
+	return &MockExecutionContext_TrimPayload_Call{Call: _e.mock.On("TrimPayload", data)}
+}
+
+func (_c *MockExecutionContext_TrimPayload_Call) Run(run func(data string)) *MockExecutionContext_TrimPayload_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_TrimPayload_Call) Return(_a0 string) *MockExecutionContext_TrimPayload_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_TrimPayload_Call) RunAndReturn(run func(string) string) *MockExecutionContext_TrimPayload_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// WaitForClose provides a mock function with given fields: timeout
+func (_m *MockExecutionContext) WaitForClose(timeout time.Duration) (Message, bool, error) {
+	ret := _m.Called(timeout)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WaitForClose")
+	}
+
+	var r0 Message
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(time.Duration) (Message, bool, error)); ok {
+		return rf(timeout)
+	}
+	if rf, ok := ret.Get(0).(func(time.Duration) Message); ok {
+		r0 = rf(timeout)
+	} else {
+		r0 = ret.Get(0).(Message)
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Duration) bool); ok {
+		r1 = rf(timeout)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(time.Duration) error); ok {
+		r2 = rf(timeout)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockExecutionContext_WaitForClose_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WaitForClose'
+type MockExecutionContext_WaitForClose_Call struct {
+	*mock.Call
+}
+
+// WaitForClose is a helper method to define mock.On call
+//   - timeout time.Duration
+func (_e *MockExecutionContext_Expecter) WaitForClose(timeout interface{}) *MockExecutionContext_WaitForClose_Call {
+	return &MockExecutionContext_WaitForClose_Call{Call: _e.mock.On("WaitForClose", timeout)}
+}
+
+func (_c *MockExecutionContext_WaitForClose_Call) Run(run func(timeout time.Duration)) *MockExecutionContext_WaitForClose_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_WaitForClose_Call) Return(msg Message, closed bool, err error) *MockExecutionContext_WaitForClose_Call {
+	_c.Call.Return(msg, closed, err)
+	return _c
+}
+
+func (_c *MockExecutionContext_WaitForClose_Call) RunAndReturn(run func(time.Duration) (Message, bool, error)) *MockExecutionContext_WaitForClose_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// WaitForInterrupt provides a mock function with given fields: timeout
+func (_m *MockExecutionContext) WaitForInterrupt(timeout time.Duration) (bool, error) {
+	ret := _m.Called(timeout)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WaitForInterrupt")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Duration) (bool, error)); ok {
+		return rf(timeout)
+	}
+	if rf, ok := ret.Get(0).(func(time.Duration) bool); ok {
+		r0 = rf(timeout)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Duration) error); ok {
+		r1 = rf(timeout)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockExecutionContext_WaitForInterrupt_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WaitForInterrupt'
+type MockExecutionContext_WaitForInterrupt_Call struct {
+	*mock.Call
+}
+
+// WaitForInterrupt is a helper method to define mock.On call
+//   - timeout time.Duration
+func (_e *MockExecutionContext_Expecter) WaitForInterrupt(timeout interface{}) *MockExecutionContext_WaitForInterrupt_Call {
+	return &MockExecutionContext_WaitForInterrupt_Call{Call: _e.mock.On("WaitForInterrupt", timeout)}
+}
+
+func (_c *MockExecutionContext_WaitForInterrupt_Call) Run(run func(timeout time.Duration)) *MockExecutionContext_WaitForInterrupt_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_WaitForInterrupt_Call) Return(_a0 bool, _a1 error) *MockExecutionContext_WaitForInterrupt_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockExecutionContext_WaitForInterrupt_Call) RunAndReturn(run func(time.Duration) (bool, error)) *MockExecutionContext_WaitForInterrupt_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -0,0 +1,355 @@
+package formater
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+const (
+	cborUnsigned = iota
+	cborNegative
+	cborBytes
+	cborText
+	cborArray
+	cborMap
+	cborTag
+	cborSimple
+)
+
+const (
+	// cborIndefinite is the additional information of items with the length given by the break code.
+	cborIndefinite = 31
+	// cborBreak terminates items of indefinite length.
+	cborBreak = 0xff
+	// cborMaxDepth limits nesting of arrays, maps and tags, so a malicious message can't exhaust the stack.
+	cborMaxDepth = 512
+)
+
+var errCBORTruncated = errors.New("unexpected end of CBOR data")
+
+// decodeCBOR decodes a single CBOR item that takes the whole data into a value that can be formatted as JSON.
+// Integers are kept exact as json.Number, map keys are converted to strings, byte strings are shown in base64 with Base64Prefix, tags are skipped
+// and undefined and other simple values are shown as null.
+// It returns an error if data is not a valid CBOR item or has trailing bytes.
+func decodeCBOR(data []byte) (any, error) {
+	d := &cborDecoder{data: data}
+
+	v, err := d.item(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.pos != len(d.data) {
+		return nil, fmt.Errorf("unexpected data after CBOR item at byte %d", d.pos)
+	}
+
+	return v, nil
+}
+
+// cborDecoder reads CBOR items from data, pos is the offset of the next unread byte.
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+// item decodes the next item, depth is the nesting level of the item.
+func (d *cborDecoder) item(depth int) (any, error) {
+	if depth > cborMaxDepth {
+		return nil, errors.New("CBOR data is nested too deep")
+	}
+
+	if d.pos >= len(d.data) {
+		return nil, errCBORTruncated
+	}
+
+	head := d.data[d.pos]
+	d.pos++
+
+	major, info := head>>5, head&0x1f
+
+	if major == cborSimple {
+		return d.simple(info)
+	}
+
+	if info == cborIndefinite {
+		return d.indefinite(major, depth)
+	}
+
+	arg, err := d.argument(info)
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case cborUnsigned:
+		return json.Number(strconv.FormatUint(arg, 10)), nil
+	case cborNegative:
+		n := new(big.Int).SetUint64(arg)
+		return json.Number(n.Neg(n).Sub(n, big.NewInt(1)).String()), nil
+	case cborBytes, cborText:
+		raw, err := d.read(arg)
+		if err != nil {
+			return nil, err
+		}
+
+		if major == cborBytes {
+			return Base64Prefix + base64.StdEncoding.EncodeToString(raw), nil
+		}
+
+		return string(raw), nil
+	case cborArray:
+		arr := make([]any, 0, min(arg, uint64(len(d.data)-d.pos)))
+
+		for i := uint64(0); i < arg; i++ {
+			v, err := d.item(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+
+			arr = append(arr, v)
+		}
+
+		return arr, nil
+	case cborMap:
+		obj := make(map[string]any)
+
+		for i := uint64(0); i < arg; i++ {
+			if err := d.entry(obj, depth); err != nil {
+				return nil, err
+			}
+		}
+
+		return obj, nil
+	default:
+		return d.item(depth + 1)
+	}
+}
+
+// indefinite decodes a string, an array or a map of indefinite length, which is terminated by the break code.
+func (d *cborDecoder) indefinite(major byte, depth int) (any, error) {
+	var (
+		arr   []any
+		obj   = make(map[string]any)
+		chunk []byte
+	)
+
+	for {
+		if d.pos >= len(d.data) {
+			return nil, errCBORTruncated
+		}
+
+		if d.data[d.pos] == cborBreak {
+			d.pos++
+			break
+		}
+
+		switch major {
+		case cborBytes, cborText:
+			raw, err := d.chunk(major)
+			if err != nil {
+				return nil, err
+			}
+
+			chunk = append(chunk, raw...)
+		case cborArray:
+			v, err := d.item(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+
+			arr = append(arr, v)
+		case cborMap:
+			if err := d.entry(obj, depth); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("invalid indefinite length CBOR item at byte %d", d.pos-1)
+		}
+	}
+
+	switch major {
+	case cborBytes:
+		return Base64Prefix + base64.StdEncoding.EncodeToString(chunk), nil
+	case cborText:
+		return string(chunk), nil
+	case cborArray:
+		if arr == nil {
+			arr = []any{}
+		}
+
+		return arr, nil
+	default:
+		return obj, nil
+	}
+}
+
+// chunk reads a chunk of an indefinite length string, which must be a definite length string of the same major type.
+func (d *cborDecoder) chunk(major byte) ([]byte, error) {
+	head := d.data[d.pos]
+	if head>>5 != major || head&0x1f == cborIndefinite {
+		return nil, fmt.Errorf("invalid chunk of indefinite CBOR string at byte %d", d.pos)
+	}
+
+	d.pos++
+
+	n, err := d.argument(head & 0x1f)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.read(n)
+}
+
+// entry decodes a key and a value of a map into obj, keys that are not text strings are formatted as strings.
+func (d *cborDecoder) entry(obj map[string]any, depth int) error {
+	key, err := d.item(depth + 1)
+	if err != nil {
+		return err
+	}
+
+	value, err := d.item(depth + 1)
+	if err != nil {
+		return err
+	}
+
+	if s, ok := key.(string); ok {
+		obj[s] = value
+	} else {
+		obj[fmt.Sprint(key)] = value
+	}
+
+	return nil
+}
+
+// simple decodes booleans, null, undefined, other simple values and floats.
+func (d *cborDecoder) simple(info byte) (any, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 24:
+		_, err := d.read(1)
+		return nil, err
+	case 25:
+		raw, err := d.read(2)
+		if err != nil {
+			return nil, err
+		}
+
+		return halfToFloat(binary.BigEndian.Uint16(raw)), nil
+	case 26:
+		raw, err := d.read(4)
+		if err != nil {
+			return nil, err
+		}
+
+		return jsonFloat(float64(math.Float32frombits(binary.BigEndian.Uint32(raw)))), nil
+	case 27:
+		raw, err := d.read(8)
+		if err != nil {
+			return nil, err
+		}
+
+		return jsonFloat(math.Float64frombits(binary.BigEndian.Uint64(raw))), nil
+	case cborIndefinite:
+		return nil, fmt.Errorf("unexpected CBOR break code at byte %d", d.pos-1)
+	default:
+		if info > 27 {
+			return nil, fmt.Errorf("invalid CBOR simple value at byte %d", d.pos-1)
+		}
+
+		return nil, nil
+	}
+}
+
+// argument reads the argument of the item head, which is the value, the length or the count depending on the major type.
+func (d *cborDecoder) argument(info byte) (uint64, error) {
+	if info < 24 {
+		return uint64(info), nil
+	}
+
+	if info > 27 {
+		return 0, fmt.Errorf("invalid CBOR additional information %d at byte %d", info, d.pos-1)
+	}
+
+	raw, err := d.read(1 << (info - 24))
+	if err != nil {
+		return 0, err
+	}
+
+	var arg uint64
+	for _, b := range raw {
+		arg = arg<<8 | uint64(b)
+	}
+
+	return arg, nil
+}
+
+// read returns the next n bytes of data.
+func (d *cborDecoder) read(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errCBORTruncated
+	}
+
+	raw := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+
+	return raw, nil
+}
+
+// halfToFloat converts an IEEE 754 half precision float to a value that can be formatted as JSON.
+func halfToFloat(h uint16) any {
+	exp := (h >> 10) & 0x1f
+	mant := float64(h & 0x3ff)
+
+	var f float64
+
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, int(exp)-25)
+	}
+
+	if h&0x8000 != 0 {
+		f = -f
+	}
+
+	return jsonFloat(f)
+}
+
+// jsonFloat returns f, or its name as a string if it's infinite or NaN, which JSON can't represent.
+func jsonFloat(f float64) any {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return fmt.Sprint(f)
+	}
+
+	return f
+}
+
+// formatCBOR formats the message decoded from CBOR as JSON with the format function,
+// the message is shown as a hex dump with the format function for text if it's not valid CBOR.
+func formatCBOR(data string, formatJSON func(any) (string, error), formatText func(string) (string, error)) (string, error) {
+	v, err := decodeCBOR([]byte(data))
+	if err != nil {
+		return formatText(hex.Dump([]byte(data)))
+	}
+
+	return formatJSON(v)
+}
@@ -0,0 +1,83 @@
+package formater
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cborMessage is {"a": 1, "b": [true, null], "c": -10, "d": h'0102', "e": 1.5} encoded as CBOR.
+var cborMessage = string([]byte{
+	0xa5,
+	0x61, 'a', 0x01,
+	0x61, 'b', 0x82, 0xf5, 0xf6,
+	0x61, 'c', 0x29,
+	0x61, 'd', 0x42, 0x01, 0x02,
+	0x61, 'e', 0xf9, 0x3e, 0x00,
+})
+
+func TestFormat_CBOR(t *testing.T) {
+	formater := NewFormat()
+	require.NoError(t, formater.SetContentType(ContentTypeCBOR))
+
+	msg, err := formater.FormatMessage("Response", cborMessage)
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"a\": 1,\n  \"b\": [\n    true,\n    null\n  ],\n  \"c\": -10,\n  \"d\": \"b64:AQI=\",\n  \"e\": 1.5\n}", msg)
+
+	fileMsg, err := formater.FormatForFile("Response", cborMessage)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1,"b":[true,null],"c":-10,"d":"b64:AQI=","e":1.5}`, fileMsg)
+}
+
+func TestFormat_CBOR_FallsBackToHex(t *testing.T) {
+	formater := NewFormat()
+	require.NoError(t, formater.SetContentType(ContentTypeCBOR))
+
+	truncated := cborMessage[:5]
+
+	msg, err := formater.FormatMessage("Response", truncated)
+	require.NoError(t, err)
+	assert.Contains(t, msg, "a5 61 61 01 61")
+
+	fileMsg, err := formater.FormatForFile("Response", truncated)
+	require.NoError(t, err)
+	assert.Equal(t, "00000000  a5 61 61 01 61                                    |.aa.a|\n", fileMsg)
+}
+
+func TestDecodeCBOR(t *testing.T) {
+	tests := []struct {
+		want    any
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{name: "large unsigned", data: []byte{0x1b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, want: json.Number("18446744073709551615")},
+		{name: "large negative", data: []byte{0x3b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, want: json.Number("-18446744073709551616")},
+		{name: "text", data: []byte{0x64, 'I', 'E', 'T', 'F'}, want: "IETF"},
+		{name: "indefinite text", data: []byte{0x7f, 0x62, 'a', 'b', 0x61, 'c', 0xff}, want: "abc"},
+		{name: "indefinite array", data: []byte{0x9f, 0x01, 0x02, 0xff}, want: []any{json.Number("1"), json.Number("2")}},
+		{name: "integer key", data: []byte{0xa1, 0x01, 0x61, 'x'}, want: map[string]any{"1": "x"}},
+		{name: "tagged date", data: []byte{0xc1, 0x1a, 0x51, 0x4b, 0x67, 0xb0}, want: json.Number("1363896240")},
+		{name: "double", data: []byte{0xfb, 0x3f, 0xf1, 0x99, 0x99, 0x99, 0x99, 0x99, 0x9a}, want: 1.1},
+		{name: "half infinity", data: []byte{0xf9, 0x7c, 0x00}, want: "+Inf"},
+		{name: "trailing data", data: []byte{0x01, 0x02}, wantErr: true},
+		{name: "truncated text", data: []byte{0x64, 'I'}, wantErr: true},
+		{name: "unexpected break", data: []byte{0xff}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeCBOR(tt.data)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
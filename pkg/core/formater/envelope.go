@@ -0,0 +1,104 @@
+package formater
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// EnvelopeNote is the note shown before the body unwrapped from an envelope, %s is the declared content type.
+const EnvelopeNote = "(envelope: %s)"
+
+// SetEnvelope enables formatting of messages wrapped in an envelope that declares the content type of its body,
+// e.g. {"contentType":"application/json","body":"{\"a\":1}"}.
+// It takes typeField and bodyField of type string, the names of the envelope fields, empty names disable unwrapping.
+// JSON and XML bodies are indented, text bodies are shown as is and binary bodies are base64 decoded and shown as a hex dump.
+// Envelopes with unknown content types are shown as received.
+// Only the terminal output is affected, messages are written to the output file as received.
+func (f *Format) SetEnvelope(typeField, bodyField string) {
+	f.envelopeType, f.envelopeBody = typeField, bodyField
+}
+
+// formatEnvelope formats the body of the envelope according to its declared content type.
+// It returns false as the third value if the envelope is not enabled, data is not an envelope,
+// its content type is unknown or the body doesn't match the declared content type.
+func (f *Format) formatEnvelope(msgType string, data any) (string, bool, error) {
+	if f.envelopeType == "" || f.envelopeBody == "" {
+		return "", false, nil
+	}
+
+	obj, ok := data.(map[string]any)
+	if !ok {
+		return "", false, nil
+	}
+
+	contentType, ok := obj[f.envelopeType].(string)
+	if !ok {
+		return "", false, nil
+	}
+
+	body, ok := obj[f.envelopeBody].(string)
+	if !ok {
+		return "", false, nil
+	}
+
+	var (
+		output string
+		err    error
+	)
+
+	switch envelopeKind(contentType) {
+	case ContentTypeJSON:
+		inner, ok := f.parseJSON(body)
+		if !ok {
+			return "", false, nil
+		}
+
+		output, err = f.formatJSONValue(msgType, inner)
+	case ContentTypeXML:
+		output, err = f.formatXMLMessage(msgType, body)
+	case ContentTypeText:
+		output, err = f.formatTextMessage(msgType, body)
+	case ContentTypeBase64:
+		raw, decodeErr := base64.StdEncoding.DecodeString(body)
+		if decodeErr != nil {
+			return "", false, nil
+		}
+
+		output, err = f.formatTextMessage(msgType, hex.Dump(raw))
+	default:
+		return "", false, nil
+	}
+
+	if err != nil {
+		return "", false, err
+	}
+
+	return fmt.Sprintf(EnvelopeNote, contentType) + "\n" + output, true, nil
+}
+
+// envelopeKind maps the MIME type declared in the envelope to the content type used to format the body,
+// binary types are mapped to base64 as their body is base64 encoded.
+// It returns an empty string for unknown types.
+func envelopeKind(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+
+	switch {
+	case mediaType == "application/json", strings.HasSuffix(mediaType, "+json"):
+		return ContentTypeJSON
+	case mediaType == "application/xml", mediaType == "text/xml", strings.HasSuffix(mediaType, "+xml"):
+		return ContentTypeXML
+	case strings.HasPrefix(mediaType, "text/"):
+		return ContentTypeText
+	case mediaType == "application/octet-stream", strings.HasPrefix(mediaType, "image/"),
+		strings.HasPrefix(mediaType, "audio/"), strings.HasPrefix(mediaType, "video/"):
+		return ContentTypeBase64
+	default:
+		return ""
+	}
+}
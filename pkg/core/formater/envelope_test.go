@@ -0,0 +1,71 @@
+package formater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat_SetEnvelope(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "JSON body",
+			data: `{"body":"{\"a\":1,\"b\":[true]}","contentType":"application/json"}`,
+			want: "(envelope: application/json)\n{\n  \"a\": 1,\n  \"b\": [\n    true\n  ]\n}",
+		},
+		{
+			name: "JSON body with parameters",
+			data: `{"body":"[1]","contentType":"application/vnd.api+json; charset=utf-8"}`,
+			want: "(envelope: application/vnd.api+json; charset=utf-8)\n[\n  1\n]",
+		},
+		{
+			name: "XML body",
+			data: `{"body":"<note><to>bob</to></note>","contentType":"application/xml"}`,
+			want: "(envelope: application/xml)\n<note>\n  <to>bob</to>\n</note>",
+		},
+		{
+			name: "text body",
+			data: `{"body":"hello","contentType":"text/plain"}`,
+			want: "(envelope: text/plain)\nhello",
+		},
+		{
+			name: "binary body",
+			data: `{"body":"AAEC/w==","contentType":"application/octet-stream"}`,
+			want: "(envelope: application/octet-stream)\n00000000  00 01 02 ff                                       |....|\n",
+		},
+		{
+			name: "unknown content type",
+			data: `{"body":"x","contentType":"application/x-custom"}`,
+			want: "{\n  \"body\": \"x\",\n  \"contentType\": \"application/x-custom\"\n}",
+		},
+		{
+			name: "JSON body that is not JSON",
+			data: `{"body":"{oops","contentType":"application/json"}`,
+			want: "{\n  \"body\": \"{oops\",\n  \"contentType\": \"application/json\"\n}",
+		},
+		{
+			name: "not an envelope",
+			data: `{"a":1}`,
+			want: "{\n  \"a\": 1\n}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formater := NewFormat()
+			formater.SetEnvelope("contentType", "body")
+
+			got, err := formater.FormatMessage("Response", tt.data)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			fileMsg, err := formater.FormatForFile("Response", tt.data)
+			assert.NoError(t, err)
+			assert.NotContains(t, fileMsg, "(envelope:")
+		})
+	}
+}
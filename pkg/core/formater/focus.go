@@ -0,0 +1,70 @@
+package formater
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SetFocus limits the terminal output of JSON messages to the value at the path.
+// It takes path of type string, a dot separated path where numeric segments index arrays, e.g. data.items.0.
+// The keys around the value are listed in a note before it, so it's clear what is hidden.
+// Messages that don't have the path are shown as a whole, an empty path shows every message as a whole.
+// Only the terminal output is affected, messages are written to the output file as received.
+func (f *Format) SetFocus(path string) {
+	f.focus = path
+}
+
+// focusOn returns the value at the focus path in the parsed JSON data and the note describing the hidden keys.
+// It returns false as the third value if the focus is not set or the data doesn't have the path.
+func (f *Format) focusOn(data any) (value any, note string, ok bool) {
+	if f.focus == "" {
+		return nil, "", false
+	}
+
+	var (
+		hidden  []string
+		current = data
+		prefix  = ""
+	)
+
+	for _, key := range strings.Split(f.focus, ".") {
+		switch node := current.(type) {
+		case map[string]any:
+			if current, ok = node[key]; !ok {
+				return nil, "", false
+			}
+
+			for name := range node {
+				if name != key {
+					hidden = append(hidden, prefix+name)
+				}
+			}
+		case []any:
+			idx, err := strconv.Atoi(key)
+			if err != nil || idx < 0 || idx >= len(node) {
+				return nil, "", false
+			}
+
+			current = node[idx]
+
+			if len(node) > 1 {
+				hidden = append(hidden, fmt.Sprintf("%s[%d other items]", strings.TrimSuffix(prefix, "."), len(node)-1))
+			}
+		default:
+			return nil, "", false
+		}
+
+		prefix += key + "."
+	}
+
+	slices.Sort(hidden)
+
+	note = "(focus: " + f.focus
+	if len(hidden) > 0 {
+		note += ", hidden: " + strings.Join(hidden, ", ")
+	}
+
+	return current, note + ")", true
+}
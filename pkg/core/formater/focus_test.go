@@ -0,0 +1,68 @@
+package formater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat_SetFocus(t *testing.T) {
+	tests := []struct {
+		name  string
+		focus string
+		data  string
+		want  string
+	}{
+		{
+			name:  "nested object",
+			focus: "data.user",
+			data:  `{"data":{"ts":5,"user":{"name":"bob","roles":["admin"]}},"id":7,"type":"update"}`,
+			want:  "(focus: data.user, hidden: data.ts, id, type)\n{\n  \"name\": \"bob\",\n  \"roles\": [\n    \"admin\"\n  ]\n}",
+		},
+		{
+			name:  "array item",
+			focus: "items.1",
+			data:  `{"items":[1,{"a":true},3]}`,
+			want:  "(focus: items.1, hidden: items[2 other items])\n{\n  \"a\": true\n}",
+		},
+		{
+			name:  "scalar without siblings",
+			focus: "data.count",
+			data:  `{"data":{"count":3}}`,
+			want:  "(focus: data.count)\n3",
+		},
+		{
+			name:  "missing path",
+			focus: "data.user",
+			data:  `{"data":{"ts":5}}`,
+			want:  "{\n  \"data\": {\n    \"ts\": 5\n  }\n}",
+		},
+		{
+			name:  "path through scalar",
+			focus: "data.ts.value",
+			data:  `{"data":{"ts":5}}`,
+			want:  "{\n  \"data\": {\n    \"ts\": 5\n  }\n}",
+		},
+		{
+			name:  "text message",
+			focus: "data",
+			data:  "hello",
+			want:  "hello",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formater := NewFormat()
+			formater.SetFocus(tt.focus)
+
+			got, err := formater.FormatMessage("Response", tt.data)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			fileMsg, err := formater.FormatForFile("Response", tt.data)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.data, fileMsg)
+		})
+	}
+}
@@ -1,50 +1,309 @@
 package formater
 
 import (
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
 )
 
-// Format is a struct that contains two formatters, one for text and one for JSON.
+const (
+	ContentTypeAuto = "auto"
+	ContentTypeJSON = "json"
+	ContentTypeXML  = "xml"
+	ContentTypeText = "text"
+	ContentTypeHex  = "hex"
+
+	ContentTypeBase64 = "base64"
+
+	// ContentTypeCBOR decodes binary CBOR messages and formats them as JSON.
+	ContentTypeCBOR = "cbor"
+
+	// ContentTypeSocketIO labels the packet type of Engine.IO and Socket.IO frames and formats their JSON payload.
+	ContentTypeSocketIO = "socketio"
+
+	// Base64Prefix marks message data shown as base64, so it can't be mistaken for text.
+	Base64Prefix = "b64:"
+
+	// DoubleEncodedNote is shown before JSON unwrapped from a JSON string.
+	DoubleEncodedNote = "(double-encoded JSON)"
+
+	FileFormatCompact = "compact"
+	FileFormatPretty  = "pretty"
+
+	prettyIndent = "  "
+)
+
+// Format is a struct that contains formatters for every supported content type.
+// By default, the content type is detected from the message data, unless it's forced with SetContentType.
 type Format struct {
-	text *TextFormat
-	json *JSONFormat
+	text         *TextFormat
+	json         *JSONFormat
+	xml          *XMLFormat
+	contentType  string
+	utf8Mode     string
+	focus        string
+	envelopeType string
+	envelopeBody string
+	strictJSON   bool
+	unwrapJSON   bool
+	showNewlines bool
+	summary      bool
+	table        bool
 }
 
 // NewFormat creates a new instance of Format struct.
 func NewFormat() *Format {
 	return &Format{
-		text: NewTextFormat(),
-		json: NewJSONFormat(),
+		text:        NewTextFormat(),
+		json:        NewJSONFormat(),
+		xml:         NewXMLFormat(),
+		contentType: ContentTypeAuto,
+		utf8Mode:    UTF8Replace,
+	}
+}
+
+// SetContentType forces the formatter to use the given content type for subsequent messages.
+// It takes contentType of type string, one of json, xml, text, hex, base64, socketio, cbor
+// or auto to restore detection from message data.
+// It returns an error if the content type is not supported.
+func (f *Format) SetContentType(contentType string) error {
+	switch contentType {
+	case ContentTypeAuto, ContentTypeJSON, ContentTypeXML, ContentTypeText, ContentTypeHex, ContentTypeBase64, ContentTypeSocketIO,
+		ContentTypeCBOR:
+		f.contentType = contentType
+		return nil
+	default:
+		return fmt.Errorf("unsupported content type: %s", contentType)
+	}
+}
+
+// SetFileFormat sets how JSON and XML messages are laid out in the output file, independently of the terminal.
+// It takes fileFormat of type string, compact writes every message on a single line, which is the default,
+// and pretty indents them like in the terminal, without colors.
+// It returns an error if the file format is not supported.
+func (f *Format) SetFileFormat(fileFormat string) error {
+	switch fileFormat {
+	case FileFormatCompact:
+		f.json.fileIndent, f.xml.fileIndent = "", ""
+	case FileFormatPretty:
+		f.json.fileIndent, f.xml.fileIndent = prettyIndent, prettyIndent
+	default:
+		return fmt.Errorf("unsupported file format: %s", fileFormat)
 	}
+
+	return nil
+}
+
+// SetStrictJSON enables or disables the strict JSON mode.
+// It takes strict of type bool, if true messages that look like JSON, but fail to parse, are not formatted as text,
+// formatting them returns an error instead, so typos in JSON requests are not hidden.
+func (f *Format) SetStrictJSON(strict bool) {
+	f.strictJSON = strict
+}
+
+// SetUnwrapJSON enables or disables unwrapping of double-encoded JSON.
+// It takes unwrap of type bool, if true a message that is a JSON string containing a JSON object or array,
+// e.g. "{\"a\":1}", is shown as the inner JSON with DoubleEncodedNote before it.
+// Only the terminal output is affected, messages are written to the output file as received.
+func (f *Format) SetUnwrapJSON(unwrap bool) {
+	f.unwrapJSON = unwrap
 }
 
 // FormatMessage formats the given WebSocket message based on its type and data.
-// If the data is a valid JSON, it will be formatted using the JSON formatter.
-// Otherwise, it will be formatted using the text formatter.
+// If the content type is forced, the message is formatted with the respective formatter.
+// Otherwise, if the data is a valid JSON, it will be formatted using the JSON formatter,
+// and using the text formatter in other cases.
+// In the socketio mode the packet type of Engine.IO and Socket.IO frames is shown as a label before the payload,
+// which is formatted as detected, other messages are formatted as detected as well.
+// In the cbor mode messages are decoded from CBOR and formatted as JSON, or shown as a hex dump if they are not valid CBOR.
+// Invalid UTF-8 in the data is handled according to the UTF-8 mode, unless the content type is forced to hex, base64 or cbor.
+// In the summary mode the message is shown as a single line with its size and a preview, regardless of the content type.
 func (f *Format) FormatMessage(msgType, msgData string) (string, error) {
+	if !f.isBinary() {
+		var err error
+		if msgData, err = f.applyUTF8Mode(msgData); err != nil {
+			return "", err
+		}
+	}
+
+	if f.summary {
+		return summarize(msgData), nil
+	}
+
+	switch f.contentType {
+	case ContentTypeJSON:
+		return f.formatForcedJSON(msgData, func(obj any) (string, error) { return f.formatJSONMessage(msgType, obj) })
+	case ContentTypeXML:
+		return f.formatXMLMessage(msgType, msgData)
+	case ContentTypeText:
+		return f.formatTextMessage(msgType, f.markNewlines(msgData))
+	case ContentTypeHex:
+		return f.formatTextMessage(msgType, hex.Dump([]byte(msgData)))
+	case ContentTypeBase64:
+		return f.formatTextMessage(msgType, encodeBase64(msgData))
+	case ContentTypeCBOR:
+		return formatCBOR(
+			msgData,
+			func(obj any) (string, error) { return f.formatJSONMessage(msgType, obj) },
+			func(data string) (string, error) { return f.formatTextMessage(msgType, data) },
+		)
+	case ContentTypeSocketIO:
+		return formatSocketIO(
+			msgData,
+			func(label string) (string, error) { return f.formatTextMessage(msgType, label) },
+			func(data string) (string, error) { return f.formatDetectedMessage(msgType, data) },
+		)
+	}
+
+	return f.formatDetectedMessage(msgType, msgData)
+}
+
+// formatDetectedMessage formats the message data as JSON if it's a valid JSON, and as text otherwise.
+func (f *Format) formatDetectedMessage(msgType, msgData string) (string, error) {
 	obj, ok := f.parseJSON(msgData)
 
 	if !ok {
-		return f.formatTextMessage(msgType, msgData)
+		if err := f.checkStrictJSON(msgData); err != nil {
+			return "", err
+		}
+
+		return f.formatTextMessage(msgType, f.markNewlines(msgData))
 	}
 
 	return f.formatJSONMessage(msgType, obj)
 }
 
 // FormatForFile formats the given WebSocket message for a file.
-// It first tries to parse the message data as JSON, and if successful, formats it as JSON.
+// If the content type is forced, the message is formatted with the respective formatter.
+// Otherwise, it first tries to parse the message data as JSON, and if successful, formats it as JSON.
 // If parsing fails, it formats the message data as plain text.
+// Invalid UTF-8 in the data is handled according to the UTF-8 mode, unless the content type is forced to hex, base64 or cbor.
 func (f *Format) FormatForFile(_, msgData string) (string, error) {
+	if !f.isBinary() {
+		var err error
+		if msgData, err = f.applyUTF8Mode(msgData); err != nil {
+			return "", err
+		}
+	}
+
+	switch f.contentType {
+	case ContentTypeJSON:
+		return f.formatForcedJSON(msgData, f.json.FormatForFile)
+	case ContentTypeXML:
+		return f.xml.FormatForFile(msgData)
+	case ContentTypeText:
+		return f.text.FormatForFile(msgData)
+	case ContentTypeHex:
+		return f.text.FormatForFile(hex.Dump([]byte(msgData)))
+	case ContentTypeBase64:
+		return f.text.FormatForFile(encodeBase64(msgData))
+	case ContentTypeCBOR:
+		return formatCBOR(msgData, f.json.FormatForFile, f.text.FormatForFile)
+	case ContentTypeSocketIO:
+		return formatSocketIO(msgData, f.text.FormatForFile, f.formatDetectedForFile)
+	}
+
+	return f.formatDetectedForFile(msgData)
+}
+
+// formatDetectedForFile formats the message data for a file as JSON if it's a valid JSON, and as text otherwise.
+func (f *Format) formatDetectedForFile(msgData string) (string, error) {
 	obj, ok := f.parseJSON(msgData)
 
 	if !ok {
+		if err := f.checkStrictJSON(msgData); err != nil {
+			return "", err
+		}
+
 		return f.text.FormatForFile(msgData)
 	}
 
 	return f.json.FormatForFile(obj)
 }
 
+// isBinary returns true if the content type shows or decodes the raw bytes of messages, so the data is not treated as text.
+func (f *Format) isBinary() bool {
+	return f.contentType == ContentTypeHex || f.contentType == ContentTypeBase64 || f.contentType == ContentTypeCBOR
+}
+
+// encodeBase64 returns data encoded with standard base64 and marked with Base64Prefix.
+func encodeBase64(data string) string {
+	return Base64Prefix + base64.StdEncoding.EncodeToString([]byte(data))
+}
+
+// checkStrictJSON returns the parse error of data that failed to parse as JSON, if the strict JSON mode is enabled
+// and data looks like JSON, i.e. starts with { or [. Plain text that never looked like JSON passes the check.
+func (f *Format) checkStrictJSON(data string) error {
+	if !f.strictJSON {
+		return nil
+	}
+
+	trimmed := strings.TrimSpace(data)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return nil
+	}
+
+	var obj any
+	if err := json.Unmarshal([]byte(data), &obj); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	return nil
+}
+
+// formatForcedJSON formats the message data as JSON even if it is not detected as a single JSON document.
+// It strips a leading byte order mark and formats each document of a newline delimited JSON stream with format.
+// It returns an error if the data contains invalid JSON.
+func (f *Format) formatForcedJSON(data string, format func(any) (string, error)) (string, error) {
+	dec := json.NewDecoder(strings.NewReader(strings.TrimPrefix(data, "\ufeff")))
+
+	var docs []string
+
+	for {
+		var obj any
+
+		err := dec.Decode(&obj)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return "", fmt.Errorf("invalid JSON: %w", err)
+		}
+
+		doc, err := format(obj)
+		if err != nil {
+			return "", err
+		}
+
+		docs = append(docs, doc)
+	}
+
+	if len(docs) == 0 {
+		return "", fmt.Errorf("invalid JSON: empty message")
+	}
+
+	return strings.Join(docs, "\n"), nil
+}
+
+// formatXMLMessage formats the given WebSocket message data as XML based on its type.
+func (f *Format) formatXMLMessage(msgType, data string) (string, error) {
+	switch msgType {
+	case "Request":
+		return f.xml.FormatRequest(data)
+	case "Response":
+		return f.xml.FormatResponse(data)
+	case "NotDefined":
+		return "", fmt.Errorf("unknown message type")
+	default:
+		panic("Unexpected message type: " + msgType)
+	}
+}
+
 // formatTextMessage formats the given WebSocket message data as text based on its type.
 func (f *Format) formatTextMessage(msgType, data string) (string, error) {
 	switch msgType {
@@ -60,7 +319,42 @@ func (f *Format) formatTextMessage(msgType, data string) (string, error) {
 }
 
 // formatJSONMessage formats the given WebSocket message data as JSON based on its type.
+// Double-encoded JSON is unwrapped if it's enabled with SetUnwrapJSON, the body of an envelope is formatted
+// according to its content type if it's enabled with SetEnvelope,
+// and only the value at the focus path is shown if it's set with SetFocus.
 func (f *Format) formatJSONMessage(msgType string, data any) (string, error) {
+	if inner, ok := f.unwrapDoubleEncoded(data); ok {
+		output, err := f.formatJSONMessage(msgType, inner)
+		if err != nil {
+			return "", err
+		}
+
+		return DoubleEncodedNote + "\n" + output, nil
+	}
+
+	if output, ok, err := f.formatEnvelope(msgType, data); ok || err != nil {
+		return output, err
+	}
+
+	if value, note, ok := f.focusOn(data); ok {
+		output, err := f.formatJSONValue(msgType, value)
+		if err != nil {
+			return "", err
+		}
+
+		return note + "\n" + output, nil
+	}
+
+	return f.formatJSONValue(msgType, data)
+}
+
+// formatJSONValue formats the parsed JSON value with the formatter of the message type,
+// or as a table if it's enabled with SetTable and the value is an array of uniform objects.
+func (f *Format) formatJSONValue(msgType string, data any) (string, error) {
+	if table, ok := f.formatTable(msgType, data); ok {
+		return table, nil
+	}
+
 	switch msgType {
 	case "Request":
 		return f.json.FormatRequest(data)
@@ -73,6 +367,27 @@ func (f *Format) formatJSONMessage(msgType string, data any) (string, error) {
 	}
 }
 
+// unwrapDoubleEncoded returns the JSON object or array encoded in the parsed JSON string.
+// It returns false as the second value if unwrapping is disabled or data is not a string containing an object or array.
+func (f *Format) unwrapDoubleEncoded(data any) (any, bool) {
+	encoded, ok := data.(string)
+	if !f.unwrapJSON || !ok {
+		return nil, false
+	}
+
+	inner, ok := f.parseJSON(encoded)
+	if !ok {
+		return nil, false
+	}
+
+	switch inner.(type) {
+	case map[string]any, []any:
+		return inner, true
+	default:
+		return nil, false
+	}
+}
+
 // parseJSON parses the given string as JSON and returns the parsed object.
 // If the string is not a valid JSON, it returns false as the second value.
 func (f *Format) parseJSON(data string) (any, bool) {
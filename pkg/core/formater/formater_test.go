@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFormat_FormatMessage(t *testing.T) {
@@ -199,3 +200,310 @@ func TestFormat_parseJSON(t *testing.T) {
 	assert.False(t, ok)
 	assert.Nil(t, parsedInvalidJSON)
 }
+
+func TestFormat_SetContentType(t *testing.T) {
+	formater := NewFormat()
+
+	for _, contentType := range []string{ContentTypeJSON, ContentTypeXML, ContentTypeText, ContentTypeHex, ContentTypeSocketIO, ContentTypeAuto} {
+		assert.NoError(t, formater.SetContentType(contentType))
+		assert.Equal(t, contentType, formater.contentType)
+	}
+
+	assert.EqualError(t, formater.SetContentType("yaml"), "unsupported content type: yaml")
+	assert.Equal(t, ContentTypeAuto, formater.contentType)
+}
+
+func TestFormat_ForcedContentType(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		data        string
+		wantMessage string
+		wantFile    string
+		wantErr     bool
+	}{
+		{
+			name:        "json with byte order mark",
+			contentType: ContentTypeJSON,
+			data:        "\ufeff{\"a\": 1}",
+			wantMessage: "{\n  \"a\": 1\n}",
+			wantFile:    `{"a":1}`,
+		},
+		{
+			name:        "newline delimited json",
+			contentType: ContentTypeJSON,
+			data:        "{\"a\": 1}\n{\"b\": 2}\n",
+			wantMessage: "{\n  \"a\": 1\n}\n{\n  \"b\": 2\n}",
+			wantFile:    "{\"a\":1}\n{\"b\":2}",
+		},
+		{
+			name:        "invalid json",
+			contentType: ContentTypeJSON,
+			data:        `{"a": 1`,
+			wantErr:     true,
+		},
+		{
+			name:        "empty json",
+			contentType: ContentTypeJSON,
+			data:        " ",
+			wantErr:     true,
+		},
+		{
+			name:        "text on valid json",
+			contentType: ContentTypeText,
+			data:        `{"a": 1}`,
+			wantMessage: `{"a": 1}`,
+			wantFile:    `{"a": 1}`,
+		},
+		{
+			name:        "xml without declaration",
+			contentType: ContentTypeXML,
+			data:        "<a><b>text</b>  <c x=\"1\"/></a>",
+			wantMessage: "<a>\n  <b>text</b>\n  <c x=\"1\"></c>\n</a>",
+			wantFile:    "<a><b>text</b><c x=\"1\"></c></a>",
+		},
+		{
+			name:        "invalid xml",
+			contentType: ContentTypeXML,
+			data:        "<a><b></a>",
+			wantErr:     true,
+		},
+		{
+			name:        "hex",
+			contentType: ContentTypeHex,
+			data:        "hi",
+			wantMessage: "00000000  68 69                                             |hi|\n",
+			wantFile:    "00000000  68 69                                             |hi|\n",
+		},
+		{
+			name:        "base64",
+			contentType: ContentTypeBase64,
+			data:        "\x00\x01\x02\xff",
+			wantMessage: "b64:AAEC/w==",
+			wantFile:    "b64:AAEC/w==",
+		},
+		{
+			name:        "socketio event",
+			contentType: ContentTypeSocketIO,
+			data:        `42["chat",{"text":"hi"}]`,
+			wantMessage: "[event] [\n  \"chat\",\n  {\n    \"text\": \"hi\"\n  }\n]",
+			wantFile:    `[event] ["chat",{"text":"hi"}]`,
+		},
+		{
+			name:        "socketio plain frame",
+			contentType: ContentTypeSocketIO,
+			data:        `{"text": "hi"}`,
+			wantMessage: "{\n  \"text\": \"hi\"\n}",
+			wantFile:    `{"text":"hi"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formater := NewFormat()
+			assert.NoError(t, formater.SetContentType(tt.contentType))
+
+			msg, err := formater.FormatMessage("Response", tt.data)
+			fileMsg, fileErr := formater.FormatForFile("Response", tt.data)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Error(t, fileErr)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.NoError(t, fileErr)
+			assert.Equal(t, tt.wantMessage, msg)
+			assert.Equal(t, tt.wantFile, fileMsg)
+		})
+	}
+}
+
+func TestFormat_ForcedContentType_RequestXML(t *testing.T) {
+	formater := NewFormat()
+	assert.NoError(t, formater.SetContentType(ContentTypeXML))
+
+	msg, err := formater.FormatMessage("Request", "<a/>")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "<a></a>", msg)
+
+	_, err = formater.FormatMessage("NotDefined", "<a/>")
+	assert.Error(t, err)
+}
+
+func TestFormat_StrictJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    string
+		wantErr string
+	}{
+		{name: "malformed object", data: `{"status": 200,}`, wantErr: "invalid JSON: invalid character '}' looking for beginning of object key string"},
+		{name: "malformed array", data: ` [1, 2`, wantErr: "invalid JSON: unexpected end of JSON input"},
+		{name: "plain text", data: "Hello, world!", want: "Hello, world!"},
+		{name: "valid JSON", data: `{"status":200}`, want: "{\"status\":200}"},
+	}
+
+	formater := NewFormat()
+	formater.SetStrictJSON(true)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formater.FormatForFile("Request", tt.data)
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Empty(t, got)
+
+				_, err = formater.FormatMessage("Request", tt.data)
+				assert.EqualError(t, err, tt.wantErr)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			_, err = formater.FormatMessage("Request", tt.data)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestFormat_UnwrapJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		want   string
+		unwrap bool
+	}{
+		{
+			name:   "double-encoded object",
+			data:   `"{\"a\":1}"`,
+			unwrap: true,
+			want:   DoubleEncodedNote + "\n{\n  \"a\": 1\n}",
+		},
+		{
+			name:   "double-encoded array",
+			data:   `"[1,2]"`,
+			unwrap: true,
+			want:   DoubleEncodedNote + "\n[\n  1,\n  2\n]",
+		},
+		{
+			name:   "string that is not JSON",
+			data:   `"hello world"`,
+			unwrap: true,
+			want:   `"hello world"`,
+		},
+		{
+			name:   "string with JSON scalar",
+			data:   `"123"`,
+			unwrap: true,
+			want:   `"123"`,
+		},
+		{
+			name: "disabled",
+			data: `"{\"a\":1}"`,
+			want: `"{\"a\":1}"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formater := NewFormat()
+			formater.SetUnwrapJSON(tt.unwrap)
+
+			got, err := formater.FormatMessage("Response", tt.data)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			fileMsg, err := formater.FormatForFile("Response", tt.data)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.data, fileMsg)
+		})
+	}
+}
+
+func TestFormat_SetFileFormat(t *testing.T) {
+	tests := []struct {
+		name       string
+		fileFormat string
+		data       string
+		want       string
+	}{
+		{
+			name:       "compact JSON",
+			fileFormat: FileFormatCompact,
+			data:       `{"a": 1, "b": [true]}`,
+			want:       `{"a":1,"b":[true]}`,
+		},
+		{
+			name:       "pretty JSON",
+			fileFormat: FileFormatPretty,
+			data:       `{"a": 1, "b": [true]}`,
+			want:       "{\n  \"a\": 1,\n  \"b\": [\n    true\n  ]\n}",
+		},
+		{
+			name:       "pretty text",
+			fileFormat: FileFormatPretty,
+			data:       "Hello, world!",
+			want:       "Hello, world!",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formater := NewFormat()
+			require.NoError(t, formater.SetFileFormat(tt.fileFormat))
+
+			got, err := formater.FormatForFile("Response", tt.data)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFormat_SetFileFormat_XML(t *testing.T) {
+	formater := NewFormat()
+	require.NoError(t, formater.SetContentType(ContentTypeXML))
+
+	got, err := formater.FormatForFile("Response", "<a><b>text</b></a>")
+	assert.NoError(t, err)
+	assert.Equal(t, "<a><b>text</b></a>", got)
+
+	require.NoError(t, formater.SetFileFormat(FileFormatPretty))
+
+	got, err = formater.FormatForFile("Response", "<a><b>text</b></a>")
+	assert.NoError(t, err)
+	assert.Equal(t, "<a>\n  <b>text</b>\n</a>", got)
+
+	assert.EqualError(t, formater.SetFileFormat("yaml"), "unsupported file format: yaml")
+}
+
+func TestPrettyJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		want   string
+		wantOK bool
+	}{
+		{name: "object keeps key order", data: `{"b":1,"a":[1,2]}`, want: "{\n  \"b\": 1,\n  \"a\": [\n    1,\n    2\n  ]\n}", wantOK: true},
+		{name: "surrounding spaces", data: " [] \n", want: "[]", wantOK: true},
+		{name: "invalid JSON", data: `{"a":`, wantOK: false},
+		{name: "scalar", data: `42`, wantOK: false},
+		{name: "text", data: `hello`, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := PrettyJSON(tt.data)
+
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
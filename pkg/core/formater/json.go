@@ -1,7 +1,9 @@
 package formater
 
 import (
+	"bytes"
 	"encoding/json"
+	"strings"
 
 	"github.com/TylerBrock/colorjson"
 	"github.com/fatih/color"
@@ -9,8 +11,9 @@ import (
 
 // JSONFormat is a struct that contains two colorjson formatters for request and response.
 type JSONFormat struct {
-	request  *colorjson.Formatter
-	response *colorjson.Formatter
+	request    *colorjson.Formatter
+	response   *colorjson.Formatter
+	fileIndent string
 }
 
 // NewJSONFormat creates a new instance of JSONFormat and returns a pointer to it.
@@ -58,7 +61,13 @@ func (jf *JSONFormat) FormatResponse(data any) (string, error) {
 }
 
 // FormatForFile formats the given data as a JSON string using the default json package.
+// The JSON is compact unless the file indentation is set.
 func (jf *JSONFormat) FormatForFile(data any) (string, error) {
+	if jf.fileIndent != "" {
+		output, err := json.MarshalIndent(data, "", jf.fileIndent)
+		return string(output), err
+	}
+
 	output, err := json.Marshal(data)
 	if err != nil {
 		return "", err
@@ -66,3 +75,19 @@ func (jf *JSONFormat) FormatForFile(data any) (string, error) {
 
 	return string(output), nil
 }
+
+// PrettyJSON indents the JSON object or array in data with two spaces, keeping the order of the keys.
+// It returns false as the second value if data is not a valid JSON object or array.
+func PrettyJSON(data string) (string, bool) {
+	trimmed := strings.TrimSpace(data)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return "", false
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(trimmed), "", "  "); err != nil {
+		return "", false
+	}
+
+	return buf.String(), true
+}
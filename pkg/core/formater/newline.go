@@ -0,0 +1,23 @@
+package formater
+
+import "strings"
+
+// newlineMarkers replaces line endings with visible markers, each line ending still breaks the line.
+var newlineMarkers = strings.NewReplacer("\r\n", "␍␊\n", "\r", "␍\n", "\n", "␊\n")
+
+// SetShowNewlines enables or disables showing line endings of text messages.
+// It takes show of type bool, if true CR and LF in text messages are shown as ␍ and ␊ before the line break,
+// so CRLF and LF line endings can be told apart.
+// Only the terminal output is affected, messages are written to the output file as received.
+func (f *Format) SetShowNewlines(show bool) {
+	f.showNewlines = show
+}
+
+// markNewlines returns data with visible line endings if it's enabled with SetShowNewlines, and data unchanged otherwise.
+func (f *Format) markNewlines(data string) string {
+	if !f.showNewlines {
+		return data
+	}
+
+	return newlineMarkers.Replace(data)
+}
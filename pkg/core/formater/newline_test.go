@@ -0,0 +1,66 @@
+package formater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat_SetShowNewlines(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		data        string
+		wantMessage string
+	}{
+		{
+			name:        "crlf",
+			contentType: ContentTypeAuto,
+			data:        "HTTP/1.1 200 OK\r\nHost: example.com\r\n\r\n",
+			wantMessage: "HTTP/1.1 200 OK␍␊\nHost: example.com␍␊\n␍␊\n",
+		},
+		{
+			name:        "lf",
+			contentType: ContentTypeText,
+			data:        "line 1\nline 2",
+			wantMessage: "line 1␊\nline 2",
+		},
+		{
+			name:        "lone cr",
+			contentType: ContentTypeText,
+			data:        "line 1\rline 2",
+			wantMessage: "line 1␍\nline 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formater := NewFormat()
+			assert.NoError(t, formater.SetContentType(tt.contentType))
+
+			msg, err := formater.FormatMessage("Response", tt.data)
+			assert.NoError(t, err)
+			assert.NotContains(t, msg, "␊")
+
+			formater.SetShowNewlines(true)
+
+			msg, err = formater.FormatMessage("Response", tt.data)
+			assert.NoError(t, err)
+			assert.Contains(t, msg, tt.wantMessage)
+
+			fileMsg, err := formater.FormatForFile("Response", tt.data)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.data, fileMsg)
+		})
+	}
+}
+
+func TestFormat_SetShowNewlines_JSON(t *testing.T) {
+	formater := NewFormat()
+	formater.SetShowNewlines(true)
+
+	msg, err := formater.FormatMessage("Response", "{\"a\": \"b\\r\\n\"}")
+
+	assert.NoError(t, err)
+	assert.NotContains(t, msg, "␊")
+}
@@ -0,0 +1,134 @@
+package formater
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	OptionContentType  = "content-type"
+	OptionFileFormat   = "file-format"
+	OptionUTF8         = "utf8"
+	OptionStrictJSON   = "strict-json"
+	OptionUnwrapJSON   = "unwrap-json"
+	OptionShowNewlines = "show-newlines"
+	OptionFocus        = "focus"
+	OptionEnvelope     = "envelope"
+	OptionSummary      = "summary"
+	OptionTable        = "table"
+
+	optionOn  = "on"
+	optionOff = "off"
+)
+
+// Options returns the current formatter options by their names, as they are accepted by SetOption.
+// Switches are shown as on or off, an unset focus or envelope is shown as off.
+func (f *Format) Options() map[string]string {
+	fileFormat := FileFormatCompact
+	if f.json.fileIndent != "" {
+		fileFormat = FileFormatPretty
+	}
+
+	envelope := optionOff
+	if f.envelopeType != "" && f.envelopeBody != "" {
+		envelope = f.envelopeType + "," + f.envelopeBody
+	}
+
+	focus := optionOff
+	if f.focus != "" {
+		focus = f.focus
+	}
+
+	return map[string]string{
+		OptionContentType:  f.contentType,
+		OptionFileFormat:   fileFormat,
+		OptionUTF8:         f.utf8Mode,
+		OptionStrictJSON:   switchValue(f.strictJSON),
+		OptionUnwrapJSON:   switchValue(f.unwrapJSON),
+		OptionShowNewlines: switchValue(f.showNewlines),
+		OptionFocus:        focus,
+		OptionEnvelope:     envelope,
+		OptionSummary:      switchValue(f.summary),
+		OptionTable:        switchValue(f.table),
+	}
+}
+
+// SetOption changes the formatter option with the given name, it affects subsequent messages.
+// It takes name of type string, one of the names returned by Options, and value of type string,
+// switches accept on or off, focus and envelope accept off to disable them and envelope expects the field names as type,body.
+// It returns an error if the option is unknown or the value is not valid for it.
+func (f *Format) SetOption(name, value string) error {
+	switch name {
+	case OptionContentType:
+		return f.SetContentType(value)
+	case OptionFileFormat:
+		return f.SetFileFormat(value)
+	case OptionUTF8:
+		return f.SetUTF8Mode(value)
+	case OptionStrictJSON, OptionUnwrapJSON, OptionShowNewlines, OptionSummary, OptionTable:
+		enabled, err := parseSwitch(name, value)
+		if err != nil {
+			return err
+		}
+
+		switch name {
+		case OptionStrictJSON:
+			f.SetStrictJSON(enabled)
+		case OptionUnwrapJSON:
+			f.SetUnwrapJSON(enabled)
+		case OptionSummary:
+			f.SetSummary(enabled)
+		case OptionTable:
+			f.SetTable(enabled)
+		default:
+			f.SetShowNewlines(enabled)
+		}
+
+		return nil
+	case OptionFocus:
+		if value == optionOff {
+			value = ""
+		}
+
+		f.SetFocus(value)
+
+		return nil
+	case OptionEnvelope:
+		if value == optionOff {
+			f.SetEnvelope("", "")
+			return nil
+		}
+
+		typeField, bodyField, ok := strings.Cut(value, ",")
+		if !ok || typeField == "" || bodyField == "" || strings.Contains(bodyField, ",") {
+			return fmt.Errorf("invalid envelope: %s, expected the content type and the body field names, e.g. contentType,body", value)
+		}
+
+		f.SetEnvelope(typeField, bodyField)
+
+		return nil
+	default:
+		return fmt.Errorf("unknown format option: %s", name)
+	}
+}
+
+// switchValue returns the value of a switch option as it's shown by Options.
+func switchValue(enabled bool) string {
+	if enabled {
+		return optionOn
+	}
+
+	return optionOff
+}
+
+// parseSwitch parses the value of a switch option, which is on or off.
+func parseSwitch(name, value string) (bool, error) {
+	switch value {
+	case optionOn:
+		return true, nil
+	case optionOff:
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid value of %s: %s, expected on or off", name, value)
+	}
+}
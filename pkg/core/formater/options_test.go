@@ -0,0 +1,85 @@
+package formater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat_Options(t *testing.T) {
+	formater := NewFormat()
+
+	assert.Equal(t, map[string]string{
+		OptionContentType:  ContentTypeAuto,
+		OptionFileFormat:   FileFormatCompact,
+		OptionUTF8:         UTF8Replace,
+		OptionStrictJSON:   "off",
+		OptionUnwrapJSON:   "off",
+		OptionShowNewlines: "off",
+		OptionFocus:        "off",
+		OptionEnvelope:     "off",
+		OptionSummary:      "off",
+		OptionTable:        "off",
+	}, formater.Options())
+
+	require.NoError(t, formater.SetOption(OptionContentType, ContentTypeText))
+	require.NoError(t, formater.SetOption(OptionFileFormat, FileFormatPretty))
+	require.NoError(t, formater.SetOption(OptionUTF8, UTF8Escape))
+	require.NoError(t, formater.SetOption(OptionStrictJSON, "on"))
+	require.NoError(t, formater.SetOption(OptionUnwrapJSON, "on"))
+	require.NoError(t, formater.SetOption(OptionShowNewlines, "on"))
+	require.NoError(t, formater.SetOption(OptionFocus, "data.user"))
+	require.NoError(t, formater.SetOption(OptionEnvelope, "contentType,body"))
+	require.NoError(t, formater.SetOption(OptionSummary, "on"))
+	require.NoError(t, formater.SetOption(OptionTable, "on"))
+
+	assert.Equal(t, map[string]string{
+		OptionContentType:  ContentTypeText,
+		OptionFileFormat:   FileFormatPretty,
+		OptionUTF8:         UTF8Escape,
+		OptionStrictJSON:   "on",
+		OptionUnwrapJSON:   "on",
+		OptionShowNewlines: "on",
+		OptionFocus:        "data.user",
+		OptionEnvelope:     "contentType,body",
+		OptionSummary:      "on",
+		OptionTable:        "on",
+	}, formater.Options())
+
+	require.NoError(t, formater.SetOption(OptionFocus, "off"))
+	require.NoError(t, formater.SetOption(OptionEnvelope, "off"))
+
+	assert.Equal(t, "off", formater.Options()[OptionFocus])
+	assert.Equal(t, "off", formater.Options()[OptionEnvelope])
+}
+
+func TestFormat_SetOption_Errors(t *testing.T) {
+	formater := NewFormat()
+
+	assert.EqualError(t, formater.SetOption("indent", "4"), "unknown format option: indent")
+	assert.EqualError(t, formater.SetOption(OptionStrictJSON, "yes"), "invalid value of strict-json: yes, expected on or off")
+	assert.EqualError(t, formater.SetOption(OptionContentType, "yaml"), "unsupported content type: yaml")
+	assert.ErrorContains(t, formater.SetOption(OptionEnvelope, "contentType"), "invalid envelope: contentType")
+}
+
+func TestFormat_SetOption_ChangesOutput(t *testing.T) {
+	formater := NewFormat()
+
+	msg, err := formater.FormatMessage("Response", `{"data":{"user":"bob","ts":5}}`)
+	require.NoError(t, err)
+	assert.Contains(t, msg, `"ts"`)
+
+	require.NoError(t, formater.SetOption(OptionFocus, "data.user"))
+
+	msg, err = formater.FormatMessage("Response", `{"data":{"user":"bob","ts":5}}`)
+	require.NoError(t, err)
+	assert.NotContains(t, msg, `"ts": 5`)
+	assert.Contains(t, msg, "(focus: data.user, hidden: data.ts)")
+
+	require.NoError(t, formater.SetOption(OptionFileFormat, FileFormatPretty))
+
+	fileMsg, err := formater.FormatForFile("Response", `{"a":1}`)
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"a\": 1\n}", fileMsg)
+}
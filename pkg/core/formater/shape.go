@@ -0,0 +1,83 @@
+package formater
+
+import (
+	"sort"
+	"strings"
+)
+
+// Shape returns the structural fingerprint of the JSON message, messages with the same set of keys share it.
+// Objects are described by their sorted keys with the shape of nested objects and arrays, e.g. {data:{id},type},
+// arrays by the shape of their first element and scalar values by their JSON type.
+// It returns false as the second value if data is not a valid JSON.
+func Shape(data string) (string, bool) {
+	var f Format
+
+	obj, ok := f.parseJSON(data)
+	if !ok {
+		return "", false
+	}
+
+	var b strings.Builder
+
+	writeShape(&b, obj, true)
+
+	return b.String(), true
+}
+
+// writeShape writes the shape of the parsed JSON value to b.
+// Scalar values are only described at the top level, inside objects the key is enough to tell the shape.
+func writeShape(b *strings.Builder, v any, top bool) {
+	switch v := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		b.WriteByte('{')
+
+		for i, k := range keys {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+
+			b.WriteString(k)
+
+			switch v[k].(type) {
+			case map[string]any, []any:
+				b.WriteByte(':')
+				writeShape(b, v[k], false)
+			}
+		}
+
+		b.WriteByte('}')
+	case []any:
+		b.WriteByte('[')
+
+		if len(v) > 0 {
+			writeShape(b, v[0], false)
+		}
+
+		b.WriteByte(']')
+	default:
+		if top {
+			b.WriteString(jsonType(v))
+		}
+	}
+}
+
+// jsonType returns the name of the JSON type of the parsed scalar value.
+func jsonType(v any) string {
+	switch v.(type) {
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "bool"
+	default:
+		return "null"
+	}
+}
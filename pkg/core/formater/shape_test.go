@@ -0,0 +1,33 @@
+package formater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShape(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		want   string
+		wantOK bool
+	}{
+		{name: "object", data: `{"type":"tick","price":1}`, want: "{price,type}", wantOK: true},
+		{name: "same keys in other order", data: `{"price":2,"type":"quote"}`, want: "{price,type}", wantOK: true},
+		{name: "nested object", data: `{"data":{"id":1},"type":"x"}`, want: "{data:{id},type}", wantOK: true},
+		{name: "array of objects", data: `{"items":[{"id":1},{"id":2}]}`, want: "{items:[{id}]}", wantOK: true},
+		{name: "empty array", data: `[]`, want: "[]", wantOK: true},
+		{name: "scalar", data: `42`, want: "number", wantOK: true},
+		{name: "not JSON", data: `pong`, want: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Shape(tt.data)
+
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package formater
+
+import (
+	"strings"
+)
+
+// engineIOTypes are the names of Engine.IO packet types, indexed by the type digit.
+var engineIOTypes = []string{"open", "close", "ping", "pong", "message", "upgrade", "noop"}
+
+// socketIOTypes are the names of Socket.IO packet types carried in Engine.IO messages, indexed by the type digit.
+var socketIOTypes = []string{"connect", "disconnect", "event", "ack", "connect_error", "binary_event", "binary_ack"}
+
+// socketIOPacket is an Engine.IO or Socket.IO frame split into its header and the JSON payload.
+type socketIOPacket struct {
+	label   string
+	payload string
+}
+
+// parseSocketIO splits the Engine.IO or Socket.IO frame, e.g. 42/chat,7["event",{...}], into the packet label
+// and the JSON payload. The label names the packet type and includes the namespace and the ack id if they are set,
+// e.g. "event /chat ack=7".
+// It returns false as the second value if the data is not a frame or the payload is not a valid JSON.
+func parseSocketIO(data string) (socketIOPacket, bool) {
+	engineType, rest, ok := cutTypeDigit(data, engineIOTypes)
+	if !ok {
+		return socketIOPacket{}, false
+	}
+
+	label := engineIOTypes[engineType]
+
+	if engineType == 4 {
+		var socketType int
+		if socketType, rest, ok = cutTypeDigit(rest, socketIOTypes); !ok {
+			return socketIOPacket{}, false
+		}
+
+		label = socketIOTypes[socketType]
+
+		if attachments, after, found := strings.Cut(rest, "-"); found && isDigits(attachments) {
+			label += " attachments=" + attachments
+			rest = after
+		}
+
+		if strings.HasPrefix(rest, "/") {
+			namespace, after, _ := strings.Cut(rest, ",")
+			label += " " + namespace
+			rest = after
+		}
+
+		if ackLen := len(rest) - len(strings.TrimLeft(rest, "0123456789")); ackLen > 0 {
+			label += " ack=" + rest[:ackLen]
+			rest = rest[ackLen:]
+		}
+	}
+
+	if rest != "" {
+		var f Format
+		if _, ok := f.parseJSON(rest); !ok {
+			return socketIOPacket{}, false
+		}
+	}
+
+	return socketIOPacket{label: label, payload: rest}, true
+}
+
+// cutTypeDigit cuts the leading packet type digit from data.
+// It returns the packet type, the rest of data and false as the third value if data doesn't start with a known type.
+func cutTypeDigit(data string, types []string) (packetType int, rest string, ok bool) {
+	if data == "" || data[0] < '0' || int(data[0]-'0') >= len(types) {
+		return 0, data, false
+	}
+
+	return int(data[0] - '0'), data[1:], true
+}
+
+// isDigits returns true if s is a non-empty string of decimal digits.
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+// formatSocketIO formats the Engine.IO or Socket.IO frame as the packet label followed by the formatted JSON payload.
+// Data that is not a frame is passed through to formatPayload as is.
+func formatSocketIO(data string, formatLabel func(string) (string, error), formatPayload func(string) (string, error)) (string, error) {
+	packet, ok := parseSocketIO(data)
+	if !ok {
+		return formatPayload(data)
+	}
+
+	label, err := formatLabel("[" + packet.label + "]")
+	if err != nil || packet.payload == "" {
+		return label, err
+	}
+
+	payload, err := formatPayload(packet.payload)
+	if err != nil {
+		return "", err
+	}
+
+	return label + " " + payload, nil
+}
@@ -0,0 +1,72 @@
+package formater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSocketIO(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		want   socketIOPacket
+		wantOk bool
+	}{
+		{
+			name:   "event",
+			data:   `42["chat",{"text":"hi"}]`,
+			want:   socketIOPacket{label: "event", payload: `["chat",{"text":"hi"}]`},
+			wantOk: true,
+		},
+		{
+			name:   "event with namespace and ack id",
+			data:   `42/chat,7["chat"]`,
+			want:   socketIOPacket{label: "event /chat ack=7", payload: `["chat"]`},
+			wantOk: true,
+		},
+		{
+			name:   "binary event",
+			data:   `451-["upload",{"_placeholder":true,"num":0}]`,
+			want:   socketIOPacket{label: "binary_event attachments=1", payload: `["upload",{"_placeholder":true,"num":0}]`},
+			wantOk: true,
+		},
+		{
+			name:   "engine open",
+			data:   `0{"sid":"abc","pingInterval":25000}`,
+			want:   socketIOPacket{label: "open", payload: `{"sid":"abc","pingInterval":25000}`},
+			wantOk: true,
+		},
+		{
+			name:   "engine ping",
+			data:   "2",
+			want:   socketIOPacket{label: "ping"},
+			wantOk: true,
+		},
+		{
+			name: "plain JSON",
+			data: `{"text":"hi"}`,
+		},
+		{
+			name: "text payload",
+			data: "3probe",
+		},
+		{
+			name: "unknown packet type",
+			data: `9["chat"]`,
+		},
+		{
+			name: "empty",
+			data: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseSocketIO(tt.data)
+
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
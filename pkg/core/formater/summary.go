@@ -0,0 +1,117 @@
+package formater
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// summaryPreviewLength is the maximum number of characters of the message preview shown in the summary mode.
+const summaryPreviewLength = 80
+
+// SetSummary enables or disables the summary mode.
+// It takes enabled of type bool, if true every message is shown as a single line with its size in bytes and
+// a truncated preview, top-level fields of JSON objects are listed as key:value, nested objects and arrays are elided.
+// It's meant for high-volume streams, only the terminal output is affected.
+func (f *Format) SetSummary(enabled bool) {
+	f.summary = enabled
+}
+
+// Summary reports whether the summary mode is enabled, so the message is printed on the same line as its direction.
+func (f *Format) Summary() bool {
+	return f.summary
+}
+
+// summarize returns the size of data in bytes followed by a single line preview of it, e.g. 42B {type:"tick",sym:"AAPL"}.
+func summarize(data string) string {
+	preview, ok := previewJSON(data)
+	if !ok {
+		preview = strings.Join(strings.Fields(data), " ")
+	}
+
+	return fmt.Sprintf("%dB %s", len(data), truncatePreview(preview))
+}
+
+// previewJSON returns the top-level fields of a JSON object as key:value in the order they are received,
+// or the elements of a JSON array. Other JSON values are returned compacted.
+// It returns false if data is not a valid JSON.
+func previewJSON(data string) (string, bool) {
+	if !json.Valid([]byte(data)) {
+		return "", false
+	}
+
+	dec := json.NewDecoder(strings.NewReader(data))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return "", false
+	}
+
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return previewValue(json.RawMessage(strings.TrimSpace(data))), true
+	}
+
+	var fields []string
+
+	for dec.More() {
+		var key string
+
+		if delim == '{' {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return "", false
+			}
+
+			key, _ = keyTok.(string)
+			key += ":"
+		}
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return "", false
+		}
+
+		fields = append(fields, key+previewValue(value))
+	}
+
+	if delim == '{' {
+		return "{" + strings.Join(fields, ",") + "}", true
+	}
+
+	return "[" + strings.Join(fields, ",") + "]", true
+}
+
+// previewValue returns a short form of a JSON value, nested objects are shown as {…} and arrays as [n]
+// with the number of their elements.
+func previewValue(value json.RawMessage) string {
+	switch value[0] {
+	case '{':
+		return "{…}"
+	case '[':
+		var elements []json.RawMessage
+		if err := json.Unmarshal(value, &elements); err != nil {
+			return "[…]"
+		}
+
+		return fmt.Sprintf("[%d]", len(elements))
+	}
+
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, value); err != nil {
+		return string(value)
+	}
+
+	return buf.String()
+}
+
+// truncatePreview cuts the preview to summaryPreviewLength characters, marking the cut with an ellipsis.
+func truncatePreview(preview string) string {
+	if utf8.RuneCountInString(preview) <= summaryPreviewLength {
+		return preview
+	}
+
+	return string([]rune(preview)[:summaryPreviewLength-1]) + "…"
+}
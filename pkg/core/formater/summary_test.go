@@ -0,0 +1,60 @@
+package formater
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat_Summary(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "JSON object",
+			data: `{"type": "tick", "sym": "AAPL", "price": 189.5, "live": true, "meta": {"src": "x"}, "bids": [1, 2, 3]}`,
+			want: `102B {type:"tick",sym:"AAPL",price:189.5,live:true,meta:{…},bids:[3]}`,
+		},
+		{
+			name: "JSON array",
+			data: `[{"id": 1}, "a", null]`,
+			want: `22B [{…},"a",null]`,
+		},
+		{
+			name: "JSON scalar",
+			data: `"pong"`,
+			want: `6B "pong"`,
+		},
+		{
+			name: "Text",
+			data: "hello\n  world",
+			want: "13B hello world",
+		},
+		{
+			name: "Long text",
+			data: strings.Repeat("a", 100),
+			want: "100B " + strings.Repeat("a", 79) + "…",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFormat()
+			f.SetSummary(true)
+
+			assert.True(t, f.Summary())
+
+			got, err := f.FormatMessage("Response", tt.data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			file, err := f.FormatForFile("Response", tt.data)
+			require.NoError(t, err)
+			assert.NotEqual(t, tt.want, file, "the output file is not affected")
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package formater
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+)
+
+const (
+	// tableCellWidth is the maximum number of characters of a table cell, longer values are truncated.
+	tableCellWidth = 32
+
+	tableColumnGap = "  "
+)
+
+// SetTable enables or disables the table mode.
+// It takes enabled of type bool, if true JSON arrays of objects with the same keys are shown as an aligned table
+// with a column for every key and a row for every object, values longer than tableCellWidth are truncated.
+// Other messages are formatted as usual. Only the terminal output is affected.
+func (f *Format) SetTable(enabled bool) {
+	f.table = enabled
+}
+
+// formatTable renders the parsed JSON data as a table with the header in the key color of the message type.
+// It returns false as the second value if the table mode is disabled or data is not an array of uniform objects.
+func (f *Format) formatTable(msgType string, data any) (string, bool) {
+	if !f.table {
+		return "", false
+	}
+
+	columns, rows, ok := tabulate(data)
+	if !ok {
+		return "", false
+	}
+
+	header := f.json.response.KeyColor
+	if msgType == "Request" {
+		header = f.json.request.KeyColor
+	}
+
+	widths := make([]int, len(columns))
+	for i, column := range columns {
+		widths[i] = utf8.RuneCountInString(column)
+	}
+
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	lines := make([]string, 0, len(rows)+1)
+	lines = append(lines, tableLine(columns, widths, header))
+
+	for _, row := range rows {
+		lines = append(lines, tableLine(row, widths, nil))
+	}
+
+	return strings.Join(lines, "\n"), true
+}
+
+// tabulate splits the parsed JSON data into the sorted keys of its objects and the truncated values of every object.
+// It returns false as the third value if data is not a non-empty array of objects that all have the same keys.
+func tabulate(data any) (columns []string, rows [][]string, ok bool) {
+	items, ok := data.([]any)
+	if !ok || len(items) == 0 {
+		return nil, nil, false
+	}
+
+	for _, item := range items {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return nil, nil, false
+		}
+
+		if columns == nil {
+			for key := range obj {
+				columns = append(columns, key)
+			}
+
+			if len(columns) == 0 {
+				return nil, nil, false
+			}
+
+			slices.Sort(columns)
+		}
+
+		if len(obj) != len(columns) {
+			return nil, nil, false
+		}
+
+		row := make([]string, len(columns))
+
+		for i, key := range columns {
+			value, ok := obj[key]
+			if !ok {
+				return nil, nil, false
+			}
+
+			row[i] = tableCell(value)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return columns, rows, true
+}
+
+// tableCell returns the value as it's shown in a table cell, strings are shown without quotes,
+// other values as compact JSON, whitespace is collapsed to keep the row on a single line.
+func tableCell(value any) string {
+	cell, ok := value.(string)
+	if !ok {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return "?"
+		}
+
+		cell = string(raw)
+	}
+
+	cell = strings.Join(strings.Fields(cell), " ")
+
+	if utf8.RuneCountInString(cell) > tableCellWidth {
+		return string([]rune(cell)[:tableCellWidth-1]) + "…"
+	}
+
+	return cell
+}
+
+// tableLine pads the cells to the column widths and joins them, the trailing padding of the last cell is dropped.
+// If c is not nil, cells are colored with it after padding, so colors don't affect the alignment.
+func tableLine(cells []string, widths []int, c *color.Color) string {
+	padded := make([]string, len(cells))
+
+	for i, cell := range cells {
+		if i < len(cells)-1 {
+			cell += strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+		}
+
+		if c != nil {
+			cell = c.Sprint(cell)
+		}
+
+		padded[i] = cell
+	}
+
+	return strings.Join(padded, tableColumnGap)
+}
@@ -0,0 +1,88 @@
+package formater
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat_SetTable(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "uniform objects",
+			data: `[{"name":"alice","id":1,"online":true},{"id":22,"online":false,"name":"bob"}]`,
+			want: "id  name   online\n1   alice  true\n22  bob    false",
+		},
+		{
+			name: "nested values and long strings",
+			data: `[{"id":1,"tags":["a","b"],"note":"` + strings.Repeat("x", 40) + `"},{"id":2,"tags":null,"note":"multi\nline"}]`,
+			want: "id  note                              tags\n" +
+				"1   " + strings.Repeat("x", 31) + "…  [\"a\",\"b\"]\n" +
+				"2   multi line                        null",
+		},
+		{
+			name: "different keys",
+			data: `[{"id":1},{"name":"bob"}]`,
+			want: "[\n  {\n    \"id\": 1\n  },\n  {\n    \"name\": \"bob\"\n  }\n]",
+		},
+		{
+			name: "extra key",
+			data: `[{"id":1},{"id":2,"name":"bob"}]`,
+			want: "[\n  {\n    \"id\": 1\n  },\n  {\n    \"id\": 2,\n    \"name\": \"bob\"\n  }\n]",
+		},
+		{
+			name: "not only objects",
+			data: `[{"id":1},2]`,
+			want: "[\n  {\n    \"id\": 1\n  },\n  2\n]",
+		},
+		{
+			name: "empty objects",
+			data: `[{},{}]`,
+			want: "[\n  {},\n  {}\n]",
+		},
+		{
+			name: "object",
+			data: `{"id":1}`,
+			want: "{\n  \"id\": 1\n}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formater := NewFormat()
+			formater.SetTable(true)
+
+			got, err := formater.FormatMessage("Response", tt.data)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFormat_SetTable_WithFocus(t *testing.T) {
+	formater := NewFormat()
+	formater.SetTable(true)
+	formater.SetFocus("data.items")
+
+	got, err := formater.FormatMessage("Response", `{"data":{"items":[{"id":1},{"id":2}]}}`)
+
+	require.NoError(t, err)
+	assert.Equal(t, "(focus: data.items)\nid\n1\n2", got)
+}
+
+func TestFormat_SetTable_FileOutput(t *testing.T) {
+	formater := NewFormat()
+	formater.SetTable(true)
+
+	got, err := formater.FormatForFile("Response", `[{"id":1},{"id":2}]`)
+
+	require.NoError(t, err)
+	assert.Equal(t, `[{"id":1},{"id":2}]`, got)
+}
@@ -0,0 +1,76 @@
+package formater
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	UTF8Replace = "replace"
+	UTF8Reject  = "reject"
+	UTF8Escape  = "escape"
+)
+
+// SetUTF8Mode sets how the formatter handles messages that contain invalid UTF-8.
+// It takes mode of type string: replace keeps the data as is, so invalid bytes are rendered as replacement characters,
+// reject fails formatting of such messages and escape shows every invalid byte inline as a hex escape, e.g. \xff.
+// It returns an error if the mode is not supported.
+func (f *Format) SetUTF8Mode(mode string) error {
+	switch mode {
+	case UTF8Replace, UTF8Reject, UTF8Escape:
+		f.utf8Mode = mode
+		return nil
+	default:
+		return fmt.Errorf("unsupported UTF-8 mode: %s", mode)
+	}
+}
+
+// applyUTF8Mode prepares the message data according to the configured UTF-8 mode.
+// It takes data of type string, which is the raw message data.
+// It returns the data to format and an error if the data contains invalid UTF-8 and the mode is reject.
+func (f *Format) applyUTF8Mode(data string) (string, error) {
+	if f.utf8Mode == UTF8Replace || f.utf8Mode == "" || utf8.ValidString(data) {
+		return data, nil
+	}
+
+	if f.utf8Mode == UTF8Reject {
+		return "", fmt.Errorf("invalid UTF-8 at byte %d", invalidUTF8Offset(data))
+	}
+
+	return escapeInvalidUTF8(data), nil
+}
+
+// invalidUTF8Offset returns the offset of the first byte that is not a part of a valid UTF-8 sequence, or -1 if there is none.
+func invalidUTF8Offset(data string) int {
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRuneInString(data[i:])
+		if r == utf8.RuneError && size == 1 {
+			return i
+		}
+
+		i += size
+	}
+
+	return -1
+}
+
+// escapeInvalidUTF8 replaces every byte that is not a part of a valid UTF-8 sequence with its hex escape.
+func escapeInvalidUTF8(data string) string {
+	var b strings.Builder
+
+	b.Grow(len(data))
+
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRuneInString(data[i:])
+		if r == utf8.RuneError && size == 1 {
+			fmt.Fprintf(&b, `\x%02x`, data[i])
+		} else {
+			b.WriteString(data[i : i+size])
+		}
+
+		i += size
+	}
+
+	return b.String()
+}
@@ -0,0 +1,99 @@
+package formater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat_SetUTF8Mode(t *testing.T) {
+	formater := NewFormat()
+	assert.Equal(t, UTF8Replace, formater.utf8Mode)
+
+	for _, mode := range []string{UTF8Reject, UTF8Escape, UTF8Replace} {
+		assert.NoError(t, formater.SetUTF8Mode(mode))
+		assert.Equal(t, mode, formater.utf8Mode)
+	}
+
+	assert.EqualError(t, formater.SetUTF8Mode("ignore"), "unsupported UTF-8 mode: ignore")
+	assert.Equal(t, UTF8Replace, formater.utf8Mode)
+}
+
+func TestFormat_UTF8Modes(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        string
+		data        string
+		wantMessage string
+		wantFile    string
+		wantErr     string
+	}{
+		{
+			name:        "replace keeps invalid byte",
+			mode:        UTF8Replace,
+			data:        "caf\xff é",
+			wantMessage: "caf\xff é",
+			wantFile:    "caf\xff é",
+		},
+		{
+			name:    "reject fails on invalid byte",
+			mode:    UTF8Reject,
+			data:    "caf\xff é",
+			wantErr: "invalid UTF-8 at byte 3",
+		},
+		{
+			name:        "escape shows invalid byte as hex",
+			mode:        UTF8Escape,
+			data:        "caf\xff é",
+			wantMessage: `caf\xff é`,
+			wantFile:    `caf\xff é`,
+		},
+		{
+			name:        "escape keeps valid data",
+			mode:        UTF8Escape,
+			data:        "café",
+			wantMessage: "café",
+			wantFile:    "café",
+		},
+		{
+			name:        "reject accepts valid data",
+			mode:        UTF8Reject,
+			data:        "café",
+			wantMessage: "café",
+			wantFile:    "café",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formater := NewFormat()
+			assert.NoError(t, formater.SetUTF8Mode(tt.mode))
+
+			msg, err := formater.FormatMessage("Response", tt.data)
+			fileMsg, fileErr := formater.FormatForFile("Response", tt.data)
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.EqualError(t, fileErr, tt.wantErr)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.NoError(t, fileErr)
+			assert.Contains(t, msg, tt.wantMessage)
+			assert.Equal(t, tt.wantFile, fileMsg)
+		})
+	}
+}
+
+func TestFormat_UTF8Modes_HexIgnoresMode(t *testing.T) {
+	formater := NewFormat()
+	assert.NoError(t, formater.SetUTF8Mode(UTF8Reject))
+	assert.NoError(t, formater.SetContentType(ContentTypeHex))
+
+	msg, err := formater.FormatForFile("Response", "\xff")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "00000000  ff                                                |.|\n", msg)
+}
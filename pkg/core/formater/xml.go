@@ -0,0 +1,88 @@
+package formater
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// XMLFormat is a struct that holds the colors for XML request and response.
+type XMLFormat struct {
+	request    *color.Color
+	response   *color.Color
+	fileIndent string
+}
+
+// NewXMLFormat creates a new instance of XMLFormat.
+func NewXMLFormat() *XMLFormat {
+	return &XMLFormat{
+		request:  color.New(color.FgMagenta),
+		response: color.New(color.FgCyan),
+	}
+}
+
+// FormatRequest formats the given data as indented XML using the request color.
+func (xf *XMLFormat) FormatRequest(data string) (string, error) {
+	output, err := indentXML(data, "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return xf.request.Sprint(output), nil
+}
+
+// FormatResponse formats the given data as indented XML using the response color.
+func (xf *XMLFormat) FormatResponse(data string) (string, error) {
+	output, err := indentXML(data, "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return xf.response.Sprint(output), nil
+}
+
+// FormatForFile formats the given data as XML without colors, compact unless the file indentation is set.
+func (xf *XMLFormat) FormatForFile(data string) (string, error) {
+	return indentXML(data, xf.fileIndent)
+}
+
+// indentXML re-encodes the XML document in data with the provided indentation.
+// Whitespace between elements is dropped, so an empty indent produces compact XML.
+// It returns an error if data is not a well-formed XML document.
+func indentXML(data, indent string) (string, error) {
+	dec := xml.NewDecoder(strings.NewReader(data))
+
+	var buf bytes.Buffer
+
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", indent)
+
+	for {
+		token, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		if charData, ok := token.(xml.CharData); ok && len(bytes.TrimSpace(charData)) == 0 {
+			continue
+		}
+
+		if err := enc.EncodeToken(token); err != nil {
+			return "", err
+		}
+	}
+
+	if err := enc.Flush(); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
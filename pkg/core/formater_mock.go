@@ -133,6 +133,224 @@ func (_c *MockFormater_FormatMessage_Call) RunAndReturn(run func(string, string)
 	return _c
 }
 
+// Options provides a mock function with no fields
+func (_m *MockFormater) Options() map[string]string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Options")
+	}
+
+	var r0 map[string]string
+	if rf, ok := ret.Get(0).(func() map[string]string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]string)
+		}
+	}
+
+	return r0
+}
+
+// MockFormater_Options_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Options'
+type MockFormater_Options_Call struct {
+	*mock.Call
+}
+
+// Options is a helper method to define mock.On call
+func (_e *MockFormater_Expecter) Options() *MockFormater_Options_Call {
+	return &MockFormater_Options_Call{Call: _e.mock.On("Options")}
+}
+
+func (_c *MockFormater_Options_Call) Run(run func()) *MockFormater_Options_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockFormater_Options_Call) Return(_a0 map[string]string) *MockFormater_Options_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockFormater_Options_Call) RunAndReturn(run func() map[string]string) *MockFormater_Options_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetContentType provides a mock function with given fields: contentType
+func (_m *MockFormater) SetContentType(contentType string) error {
+	ret := _m.Called(contentType)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetContentType")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(contentType)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockFormater_SetContentType_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetContentType'
+type MockFormater_SetContentType_Call struct {
+	*mock.Call
+}
+
+// SetContentType is a helper method to define mock.On call
+//   - contentType string
+func (_e *MockFormater_Expecter) SetContentType(contentType interface{}) *MockFormater_SetContentType_Call {
+	return &MockFormater_SetContentType_Call{Call: _e.mock.On("SetContentType", contentType)}
+}
+
+func (_c *MockFormater_SetContentType_Call) Run(run func(contentType string)) *MockFormater_SetContentType_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockFormater_SetContentType_Call) Return(_a0 error) *MockFormater_SetContentType_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockFormater_SetContentType_Call) RunAndReturn(run func(string) error) *MockFormater_SetContentType_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetFocus provides a mock function with given fields: path
+func (_m *MockFormater) SetFocus(path string) {
+	_m.Called(path)
+}
+
+// MockFormater_SetFocus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetFocus'
+type MockFormater_SetFocus_Call struct {
+	*mock.Call
+}
+
+// SetFocus is a helper method to define mock.On call
+//   - path string
+func (_e *MockFormater_Expecter) SetFocus(path interface{}) *MockFormater_SetFocus_Call {
+	return &MockFormater_SetFocus_Call{Call: _e.mock.On("SetFocus", path)}
+}
+
+func (_c *MockFormater_SetFocus_Call) Run(run func(path string)) *MockFormater_SetFocus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockFormater_SetFocus_Call) Return() *MockFormater_SetFocus_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockFormater_SetFocus_Call) RunAndReturn(run func(string)) *MockFormater_SetFocus_Call {
+	_c.Run(run)
+	return _c
+}
+
+// SetOption provides a mock function with given fields: name, value
+func (_m *MockFormater) SetOption(name string, value string) error {
+	ret := _m.Called(name, value)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetOption")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(name, value)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockFormater_SetOption_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetOption'
+type MockFormater_SetOption_Call struct {
+	*mock.Call
+}
+
+// SetOption is a helper method to define mock.On call
+//   - name string
+//   - value string
+func (_e *MockFormater_Expecter) SetOption(name interface{}, value interface{}) *MockFormater_SetOption_Call {
+	return &MockFormater_SetOption_Call{Call: _e.mock.On("SetOption", name, value)}
+}
+
+func (_c *MockFormater_SetOption_Call) Run(run func(name string, value string)) *MockFormater_SetOption_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockFormater_SetOption_Call) Return(_a0 error) *MockFormater_SetOption_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockFormater_SetOption_Call) RunAndReturn(run func(string, string) error) *MockFormater_SetOption_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Summary provides a mock function with no fields
+func (_m *MockFormater) Summary() bool {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Summary")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// MockFormater_Summary_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Summary'
+type MockFormater_Summary_Call struct {
+	*mock.Call
+}
+
+// Summary is a helper method to define mock.On call
+func (_e *MockFormater_Expecter) Summary() *MockFormater_Summary_Call {
+	return &MockFormater_Summary_Call{Call: _e.mock.On("Summary")}
+}
+
+func (_c *MockFormater_Summary_Call) Run(run func()) *MockFormater_Summary_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockFormater_Summary_Call) Return(_a0 bool) *MockFormater_Summary_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockFormater_Summary_Call) RunAndReturn(run func() bool) *MockFormater_Summary_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockFormater creates a new instance of MockFormater. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockFormater(t interface {
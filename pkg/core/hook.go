@@ -0,0 +1,112 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+const (
+	// MessageVariable is the session variable holding the data of the message the on-message hook runs for.
+	MessageVariable = "msg"
+
+	hookRateLimit  = 100
+	hookRateWindow = time.Second
+)
+
+// messageHook is the command run for every received message, e.g. to acknowledge it.
+type messageHook struct {
+	windowStart time.Time
+	command     string
+	sent        string
+	runs        int
+	running     bool
+}
+
+// SetMessageHook registers the command run for every received message before it's printed.
+// It takes command of type string, the command as it would be entered in command mode,
+// the data of the message is available in it as ${msg}. An empty command removes the hook.
+func (c *executionContext) SetMessageHook(command string) {
+	c.hook = messageHook{command: command}
+}
+
+// hookFor returns the command running the on-message hook for the received message.
+// The hook doesn't run for a message equal to the last one sent by the hook, so it doesn't loop on an echo server.
+// If the hook runs more than 100 times in a second, it's considered looping and removed with a warning.
+// It returns nil if the hook is not set or shouldn't run for the message.
+func (c *executionContext) hookFor(msg Message) Executer {
+	if c.hook.command == "" || msg.Type != Response {
+		return nil
+	}
+
+	if c.hook.sent != "" && c.hook.sent == msg.Data {
+		c.hook.sent = ""
+		return nil
+	}
+
+	now := c.Clock().Now()
+	if now.Sub(c.hook.windowStart) >= hookRateWindow {
+		c.hook.windowStart = now
+		c.hook.runs = 0
+	}
+
+	if c.hook.runs++; c.hook.runs > hookRateLimit {
+		command := c.hook.command
+		c.hook = messageHook{}
+
+		return &hookWarning{
+			text: fmt.Sprintf("on-message hook %q is removed, it ran more than %d times in %s\n", command, hookRateLimit, hookRateWindow),
+		}
+	}
+
+	return &hookCommand{hook: &c.hook, command: c.hook.command, data: msg.Data}
+}
+
+// hookSent remembers the data sent while the on-message hook is running, so an echo of it doesn't run the hook again.
+func (c *executionContext) hookSent(data string) {
+	if c.hook.running {
+		c.hook.sent = data
+	}
+}
+
+// hookCommand runs the on-message hook for a single received message.
+type hookCommand struct {
+	hook    *messageHook
+	command string
+	data    string
+}
+
+// Execute stores the message data in the msg variable and runs the hook command with all commands it returns.
+// If the hook command is invalid, it prints the reason instead of interrupting the session.
+// It returns an error if the hook command fails.
+func (h *hookCommand) Execute(exCtx ExecutionContext) (Executer, error) {
+	exCtx.SetVariable(MessageVariable, h.data)
+
+	cmd, err := exCtx.CreateCommand(h.command)
+	if err != nil {
+		return nil, exCtx.Print(fmt.Sprintf("Invalid on-message command: %s\n", h.command), color.FgRed)
+	}
+
+	h.hook.running = true
+	defer func() { h.hook.running = false }()
+
+	for cmd != nil {
+		if cmd, err = cmd.Execute(exCtx); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+// hookWarning reports that the on-message hook was removed.
+type hookWarning struct {
+	text string
+}
+
+// Execute prints the warning to the terminal.
+// It returns an error if printing fails.
+func (w *hookWarning) Execute(exCtx ExecutionContext) (Executer, error) {
+	return nil, exCtx.Print(w.text, color.FgRed)
+}
@@ -0,0 +1,110 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCLIRun_MessageHook(t *testing.T) {
+	var onMessage func(context.Context, []byte)
+
+	sent := make(chan string)
+
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().SetOnMessage(mock.Anything).Run(func(cb func(context.Context, []byte)) { onMessage = cb })
+	wsConn.EXPECT().SetOnStatusChange(mock.Anything)
+	wsConn.EXPECT().SetOnFrameError(mock.Anything)
+	wsConn.EXPECT().Send(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, msg string) error {
+		sent <- msg
+		return nil
+	})
+
+	editor := NewMockEditor(t)
+	editor.EXPECT().SetInput(mock.Anything)
+
+	exitCmd := NewMockExecuter(t)
+	exitCmd.EXPECT().Execute(mock.Anything).Return(nil, ErrInterrupted)
+
+	ackCmd := NewMockExecuter(t)
+	ackCmd.EXPECT().Execute(mock.Anything).RunAndReturn(func(exCtx ExecutionContext) (Executer, error) {
+		return nil, exCtx.SendRequest(exCtx.ExpandVariables("ack ${msg}"))
+	})
+
+	factory := NewMockCommandFactory(t)
+	factory.EXPECT().Create(mock.Anything).RunAndReturn(func(raw string) (Executer, error) {
+		switch raw {
+		case "exit":
+			return exitCmd, nil
+		case "ack":
+			return ackCmd, nil
+		}
+
+		printCmd := NewMockExecuter(t)
+		printCmd.EXPECT().Execute(mock.Anything).Return(nil, nil)
+
+		return printCmd, nil
+	})
+
+	cli := NewCLI(factory, wsConn, &bytes.Buffer{}, editor, NewMockFormater(t))
+
+	registered := make(chan struct{})
+
+	setHook := NewMockExecuter(t)
+	setHook.EXPECT().Execute(mock.Anything).RunAndReturn(func(exCtx ExecutionContext) (Executer, error) {
+		exCtx.SetMessageHook("ack")
+		close(registered)
+
+		return nil, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var acks []string
+
+	go func() {
+		<-registered
+
+		for _, msg := range []string{"1", "2", "3"} {
+			onMessage(ctx, []byte(msg))
+			acks = append(acks, <-sent)
+		}
+
+		// The server echoes the last ack, the hook must not answer it.
+		onMessage(ctx, []byte("ack 3"))
+		cli.OnKeyEvent(KeyEvent{Key: KeyCtrlC})
+	}()
+
+	err := cli.Run(ctx, RunOptions{Commands: []Executer{setHook}})
+
+	assert.ErrorIs(t, err, ErrInterrupted)
+	assert.Equal(t, []string{"ack 1", "ack 2", "ack 3"}, acks)
+}
+
+func TestExecutionContext_HookFor(t *testing.T) {
+	now := time.Now()
+
+	clock := NewMockClock(t)
+	clock.EXPECT().Now().Return(now)
+
+	ec := &executionContext{clock: clock}
+
+	assert.Nil(t, ec.hookFor(Message{Type: Response, Data: "1"}))
+
+	ec.SetMessageHook("ack")
+
+	assert.Nil(t, ec.hookFor(Message{Type: Request, Data: "1"}))
+	assert.Equal(t, &hookCommand{hook: &ec.hook, command: "ack", data: "1"}, ec.hookFor(Message{Type: Response, Data: "1"}))
+
+	for i := 1; i < hookRateLimit; i++ {
+		assert.IsType(t, &hookCommand{}, ec.hookFor(Message{Type: Response, Data: "1"}))
+	}
+
+	assert.IsType(t, &hookWarning{}, ec.hookFor(Message{Type: Response, Data: "1"}))
+	assert.Nil(t, ec.hookFor(Message{Type: Response, Data: "1"}))
+}
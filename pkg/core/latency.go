@@ -0,0 +1,142 @@
+package core
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// latency holds received messages for an artificial delay before they're delivered to commands,
+// so timeout handling of clients can be tested against a slow server.
+// The delay of every message starts when it's received, so the connection is never blocked by the delay
+// and a burst of messages is delivered within the band after arrival, not one delay after another.
+type latency struct {
+	clock    Clock
+	randN    func(n int64) int64
+	queue    []delayedMessage
+	base     time.Duration
+	jitter   time.Duration
+	mu       sync.Mutex
+	draining bool
+}
+
+// delayedMessage is a received message waiting for its delay to elapse.
+type delayedMessage struct {
+	ready <-chan time.Time
+	data  []byte
+}
+
+// set changes the delay of the following messages.
+// It takes base and jitter of type time.Duration, every message is held for a random duration within base±jitter,
+// non-positive base disables the delay.
+func (l *latency) set(base, jitter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.base = base
+	l.jitter = max(jitter, 0)
+}
+
+// setClock sets the clock used to wait for the delay.
+func (l *latency) setClock(clock Clock) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.clock = clock
+}
+
+// next picks the delay of the next message.
+// It returns the delay and the clock to wait on, the delay is 0 if it's disabled.
+func (l *latency) next() (time.Duration, Clock) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.base <= 0 {
+		return 0, nil
+	}
+
+	randN := l.randN
+	if randN == nil {
+		randN = rand.Int64N
+	}
+
+	delay := l.base
+	if l.jitter > 0 {
+		delay += time.Duration(randN(2*int64(l.jitter)+1)) - l.jitter
+	}
+
+	clock := l.clock
+	if clock == nil {
+		clock = realClock{}
+	}
+
+	return max(delay, 0), clock
+}
+
+// schedule passes the received message to deliver once its delay elapses, without blocking the caller.
+// The timer of the message is started right away, messages are delivered in the order they were received
+// by a separate goroutine, so a message with a shorter delay still waits for the ones received before it.
+// It takes ctx of type context.Context, messages still waiting when it's canceled are dropped,
+// msg of type []byte with the received data and deliver, the function handling the message once it's due.
+// If the delay is disabled and no message is waiting, the message is delivered right away by the caller.
+func (l *latency) schedule(ctx context.Context, msg []byte, deliver func(context.Context, []byte)) {
+	delay, clock := l.next()
+
+	l.mu.Lock()
+
+	if delay <= 0 && !l.draining {
+		l.mu.Unlock()
+		deliver(ctx, msg)
+
+		return
+	}
+
+	var ready <-chan time.Time
+	if delay > 0 {
+		ready = clock.After(delay)
+	}
+
+	l.queue = append(l.queue, delayedMessage{ready: ready, data: msg})
+
+	start := !l.draining
+	l.draining = true
+
+	l.mu.Unlock()
+
+	if start {
+		go l.drain(ctx, deliver)
+	}
+}
+
+// drain delivers the queued messages in order once their delays elapse, it returns when the queue is empty
+// or the context is canceled, the messages left in the queue are dropped in this case.
+func (l *latency) drain(ctx context.Context, deliver func(context.Context, []byte)) {
+	for {
+		l.mu.Lock()
+
+		if len(l.queue) == 0 || ctx.Err() != nil {
+			l.queue = nil
+			l.draining = false
+			l.mu.Unlock()
+
+			return
+		}
+
+		msg := l.queue[0]
+		l.queue[0] = delayedMessage{}
+		l.queue = l.queue[1:]
+
+		l.mu.Unlock()
+
+		if msg.ready != nil {
+			select {
+			case <-msg.ready:
+			case <-ctx.Done():
+				continue
+			}
+		}
+
+		deliver(ctx, msg.data)
+	}
+}
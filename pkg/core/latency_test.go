@@ -0,0 +1,248 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func firedAfter(delays *[]time.Duration) func(time.Duration) <-chan time.Time {
+	return func(d time.Duration) <-chan time.Time {
+		*delays = append(*delays, d)
+
+		ch := make(chan time.Time, 1)
+		ch <- time.Time{}
+
+		return ch
+	}
+}
+
+// collect returns the deliver function of latency.schedule recording the delivered messages in order.
+func collect(t *testing.T) (func(context.Context, []byte), func() []string) {
+	t.Helper()
+
+	var (
+		delivered []string
+		mu        sync.Mutex
+	)
+
+	deliver := func(_ context.Context, msg []byte) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		delivered = append(delivered, string(msg))
+	}
+
+	get := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return append([]string(nil), delivered...)
+	}
+
+	return deliver, get
+}
+
+func TestLatency_Schedule_WithinBand(t *testing.T) {
+	var delays []time.Duration
+
+	clock := NewMockClock(t)
+	clock.EXPECT().After(mock.Anything).RunAndReturn(firedAfter(&delays))
+
+	l := &latency{}
+	l.setClock(clock)
+	l.set(200*time.Millisecond, 50*time.Millisecond)
+
+	deliver, delivered := collect(t)
+
+	for range 100 {
+		l.schedule(context.Background(), []byte("msg"), deliver)
+	}
+
+	assert.Eventually(t, func() bool { return len(delivered()) == 100 }, time.Second, time.Millisecond)
+	require.Len(t, delays, 100)
+
+	for _, d := range delays {
+		assert.GreaterOrEqual(t, d, 150*time.Millisecond)
+		assert.LessOrEqual(t, d, 250*time.Millisecond)
+	}
+}
+
+func TestLatency_Schedule_BandEdges(t *testing.T) {
+	var delays []time.Duration
+
+	clock := NewMockClock(t)
+	clock.EXPECT().After(mock.Anything).RunAndReturn(firedAfter(&delays))
+
+	l := &latency{clock: clock}
+	l.set(100*time.Millisecond, 150*time.Millisecond)
+
+	deliver, delivered := collect(t)
+
+	l.randN = func(int64) int64 { return 0 }
+	l.schedule(context.Background(), []byte("1"), deliver)
+
+	l.randN = func(n int64) int64 { return n - 1 }
+	l.schedule(context.Background(), []byte("2"), deliver)
+
+	l.set(100*time.Millisecond, 0)
+	l.schedule(context.Background(), []byte("3"), deliver)
+
+	assert.Eventually(t, func() bool { return len(delivered()) == 3 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"1", "2", "3"}, delivered())
+
+	// The lower edge is clamped to 0, so the first message is delivered without waiting.
+	assert.Equal(t, []time.Duration{250 * time.Millisecond, 100 * time.Millisecond}, delays)
+}
+
+func TestLatency_Schedule_Disabled(t *testing.T) {
+	l := &latency{clock: NewMockClock(t)}
+
+	deliver, delivered := collect(t)
+
+	l.schedule(context.Background(), []byte("1"), deliver)
+
+	l.set(200*time.Millisecond, 50*time.Millisecond)
+	l.set(0, 50*time.Millisecond)
+	l.schedule(context.Background(), []byte("2"), deliver)
+
+	assert.Equal(t, []string{"1", "2"}, delivered(), "messages should be delivered right away")
+}
+
+func TestLatency_Schedule_KeepsOrder(t *testing.T) {
+	first := make(chan time.Time)
+
+	clock := NewMockClock(t)
+	clock.EXPECT().After(time.Second).Return(first).Once()
+
+	l := &latency{clock: clock}
+	l.set(time.Second, 0)
+
+	deliver, delivered := collect(t)
+
+	l.schedule(context.Background(), []byte("1"), deliver)
+
+	// The delay is disabled while the first message is still waiting, the second one is queued behind it.
+	l.set(0, 0)
+	l.schedule(context.Background(), []byte("2"), deliver)
+
+	assert.Empty(t, delivered())
+
+	close(first)
+
+	assert.Eventually(t, func() bool { return len(delivered()) == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"1", "2"}, delivered())
+}
+
+func TestLatency_Schedule_Canceled(t *testing.T) {
+	clock := NewMockClock(t)
+	clock.EXPECT().After(time.Second).Return(make(chan time.Time))
+
+	l := &latency{clock: clock}
+	l.set(time.Second, 0)
+
+	deliver, delivered := collect(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	l.schedule(ctx, []byte("1"), deliver)
+	l.schedule(ctx, []byte("2"), deliver)
+	cancel()
+
+	assert.Eventually(t, func() bool {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		return !l.draining
+	}, time.Second, time.Millisecond)
+	assert.Empty(t, delivered())
+}
+
+func TestLatency_Schedule_Burst(t *testing.T) {
+	const (
+		burst = 10
+		base  = 50 * time.Millisecond
+	)
+
+	l := &latency{}
+	l.set(base, 0)
+
+	var (
+		received  [burst]time.Time
+		delivered [burst]time.Time
+		count     atomic.Int32
+	)
+
+	deliver := func(_ context.Context, msg []byte) {
+		i, err := strconv.Atoi(string(msg))
+		require.NoError(t, err)
+
+		delivered[i] = time.Now()
+		count.Add(1)
+	}
+
+	start := time.Now()
+
+	for i := range burst {
+		received[i] = time.Now()
+		l.schedule(context.Background(), []byte(strconv.Itoa(i)), deliver)
+	}
+
+	assert.Less(t, time.Since(start), base, "receiving the burst should not wait for the delay")
+	assert.Eventually(t, func() bool { return count.Load() == burst }, time.Second, time.Millisecond)
+
+	// Every message is delayed from its own arrival, not from the delivery of the previous one.
+	for i := range burst {
+		delay := delivered[i].Sub(received[i])
+		assert.GreaterOrEqual(t, delay, base, "message %d", i)
+		assert.Less(t, delay, 2*base, "message %d", i)
+	}
+}
+
+func TestCLI_ResponseDelay(t *testing.T) {
+	var onMessage func(context.Context, []byte)
+
+	ctx := context.Background()
+
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().SetOnMessage(mock.Anything).Run(func(cb func(context.Context, []byte)) { onMessage = cb })
+	wsConn.EXPECT().SetOnStatusChange(mock.Anything)
+	wsConn.EXPECT().SetOnFrameError(mock.Anything)
+	wsConn.EXPECT().Send(ctx, "ping").Return(nil)
+
+	editor := NewMockEditor(t)
+	editor.EXPECT().SetInput(mock.Anything)
+
+	var delays []time.Duration
+
+	clock := NewMockClock(t)
+	clock.EXPECT().After(mock.Anything).RunAndReturn(firedAfter(&delays))
+
+	cli := NewCLI(NewMockCommandFactory(t), wsConn, &bytes.Buffer{}, editor, NewMockFormater(t))
+	cli.latency.setClock(clock)
+
+	ec := &executionContext{cli: cli, ctx: ctx}
+	ec.SetResponseDelay(300*time.Millisecond, 0)
+
+	require.NoError(t, ec.SendRequest("ping"))
+	assert.Empty(t, delays, "sent messages should not be delayed")
+
+	go onMessage(ctx, []byte("pong"))
+
+	select {
+	case msg := <-cli.messages:
+		assert.Equal(t, Message{Type: Response, Data: "pong"}, msg)
+	case <-time.After(time.Second):
+		t.Fatal("expected delayed message")
+	}
+
+	assert.Equal(t, []time.Duration{300 * time.Millisecond}, delays)
+}
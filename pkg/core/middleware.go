@@ -0,0 +1,34 @@
+package core
+
+import "fmt"
+
+// Middleware transforms the data of a message, e.g. signs it or wraps it in an envelope.
+// It returns the transformed data or an error if the data can't be transformed.
+type Middleware func(data string) (string, error)
+
+// UseOutbound adds middlewares applied to text requests before they are sent, in the order they are added.
+// Requests are printed and recorded as entered, only the data sent to the server is transformed.
+// It should be called before the session is run.
+func (c *CLI) UseOutbound(mw ...Middleware) {
+	c.outbound = append(c.outbound, mw...)
+}
+
+// UseInbound adds middlewares applied to received messages before they are formatted, in the order they are added.
+// A message that fails to transform is passed on as received and the error is reported in the output.
+// It should be called before the connection is established.
+func (c *CLI) UseInbound(mw ...Middleware) {
+	c.inbound = append(c.inbound, mw...)
+}
+
+// applyMiddleware passes data through the chain of middlewares.
+// It returns the transformed data or an error from the first middleware that fails.
+func applyMiddleware(chain []Middleware, data string) (string, error) {
+	for _, mw := range chain {
+		var err error
+		if data, err = mw(data); err != nil {
+			return "", fmt.Errorf("fail to transform message: %w", err)
+		}
+	}
+
+	return data, nil
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func wrap(data string) (string, error) {
+	return `{"payload":` + data + `}`, nil
+}
+
+func sign(data string) (string, error) {
+	return data + "|sig", nil
+}
+
+func TestExecutionContext_SendRequest_OutboundMiddleware(t *testing.T) {
+	ctx := context.Background()
+
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().Send(ctx, `{"payload":{"ping":1}}|sig`).Return(nil)
+
+	cli := &CLI{wsConn: wsConn}
+	cli.UseOutbound(wrap, sign)
+
+	ec := &executionContext{cli: cli, ctx: ctx}
+
+	require.NoError(t, ec.SendRequest(`{"ping":1}`))
+
+	last, ok := ec.LastRequest()
+	assert.True(t, ok)
+	assert.Equal(t, `{"ping":1}`, last)
+}
+
+func TestExecutionContext_SendRequest_OutboundMiddlewareError(t *testing.T) {
+	cli := &CLI{wsConn: NewMockConnectionHandler(t)}
+	cli.UseOutbound(func(string) (string, error) { return "", errors.New("no key") })
+
+	ec := &executionContext{cli: cli, ctx: context.Background()}
+
+	err := ec.SendRequest(`{"ping":1}`)
+
+	assert.EqualError(t, err, "fail to transform message: no key")
+}
+
+func TestCLI_TransformInbound(t *testing.T) {
+	ctx := context.Background()
+
+	cli := &CLI{messages: make(chan Message, 1)}
+	assert.Equal(t, "raw", cli.transformInbound(ctx, "raw"))
+
+	cli.UseInbound(sign)
+	assert.Equal(t, "raw|sig", cli.transformInbound(ctx, "raw"))
+
+	cli.UseInbound(func(string) (string, error) { return "", errors.New("bad envelope") })
+	assert.Equal(t, "raw", cli.transformInbound(ctx, "raw"))
+
+	select {
+	case m := <-cli.messages:
+		assert.Equal(t, Message{marker: "--- fail to transform message: bad envelope ---"}, m)
+	default:
+		t.Fatal("expected error marker")
+	}
+}
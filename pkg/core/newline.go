@@ -0,0 +1,39 @@
+package core
+
+import "strings"
+
+const (
+	// NewlineKeep sends line endings as they are entered.
+	NewlineKeep = "keep"
+	// NewlineLF converts line endings of sent messages to LF.
+	NewlineLF = "lf"
+	// NewlineCRLF converts line endings of sent messages to CRLF.
+	NewlineCRLF = "crlf"
+)
+
+// NormalizeNewlines converts the line endings of data to the given mode.
+// It takes data of type string and mode of type string, one of NewlineLF or NewlineCRLF,
+// any other mode, including NewlineKeep, leaves data unchanged.
+// CRLF, LF and lone CR are all treated as line endings.
+// It returns the data with normalized line endings.
+func NormalizeNewlines(data, mode string) string {
+	var eol string
+
+	switch mode {
+	case NewlineLF:
+		eol = "\n"
+	case NewlineCRLF:
+		eol = "\r\n"
+	default:
+		return data
+	}
+
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	data = strings.ReplaceAll(data, "\r", "\n")
+
+	if eol == "\n" {
+		return data
+	}
+
+	return strings.ReplaceAll(data, "\n", eol)
+}
@@ -0,0 +1,33 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeNewlines(t *testing.T) {
+	data := "GET / HTTP/1.1\r\nHost: example.com\nAccept: */*\r\r\n"
+
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{mode: NewlineKeep, want: data},
+		{mode: "", want: data},
+		{mode: NewlineLF, want: "GET / HTTP/1.1\nHost: example.com\nAccept: */*\n\n"},
+		{mode: NewlineCRLF, want: "GET / HTTP/1.1\r\nHost: example.com\r\nAccept: */*\r\n\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			assert.Equal(t, tt.want, NormalizeNewlines(data, tt.mode))
+		})
+	}
+}
+
+func TestExecutionContext_NormalizeNewlines(t *testing.T) {
+	exCtx := &executionContext{newline: NewlineCRLF}
+
+	assert.Equal(t, "a\r\nb", exCtx.NormalizeNewlines("a\nb"))
+}
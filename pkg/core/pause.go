@@ -0,0 +1,134 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+)
+
+const (
+	// PauseBufferSize is the number of received messages held while the output is paused,
+	// once it's full the session stops taking received messages, so reading from the connection blocks.
+	PauseBufferSize = 1000
+
+	pauseWarnLevel = PauseBufferSize * 9 / 10
+)
+
+// pauseBuffer holds received messages while the output is paused, so they can be read later in the order of arrival.
+type pauseBuffer struct {
+	messages []Message
+	paused   bool
+	warned   bool
+}
+
+// full reports whether the output is paused and no more messages can be held.
+func (b *pauseBuffer) full() bool {
+	return b.paused && len(b.messages) >= PauseBufferSize
+}
+
+// Pause stops delivering received messages to the terminal and the output file, they are held until Resume.
+// Messages are still read from the connection until PauseBufferSize of them are held, then reading blocks
+// and the server is slowed down by the backpressure, no message is dropped.
+// Commands waiting for a message, e.g. request, still receive it while paused, as they show it themselves.
+// It returns false if the output is already paused.
+func (c *executionContext) Pause() bool {
+	if c.pause.paused {
+		return false
+	}
+
+	c.pause = pauseBuffer{paused: true}
+
+	return true
+}
+
+// Resume continues delivering received messages.
+// It returns the command delivering the messages held while the output was paused, and false if it wasn't paused.
+func (c *executionContext) Resume() (Executer, bool) {
+	if !c.pause.paused {
+		return nil, false
+	}
+
+	held := &heldMessages{ec: c, messages: c.pause.messages}
+	c.pause = pauseBuffer{}
+
+	return held, true
+}
+
+// hold keeps the received message in the pause buffer if the output is paused.
+// Once the buffer is almost full, a warning is queued. The session stops taking messages when it's full,
+// see pauseBuffer.full, so messages are never dropped.
+// It returns false if the output is not paused and the message should be delivered.
+func (c *executionContext) hold(msg Message) bool {
+	if !c.pause.paused {
+		return false
+	}
+
+	c.pause.messages = append(c.pause.messages, msg)
+
+	if !c.pause.warned && len(c.pause.messages) >= pauseWarnLevel {
+		c.pause.warned = true
+		c.cli.commands <- &warning{
+			text: fmt.Sprintf("Output is paused with %d of %d messages held, reading from the connection stops once it's full\n", len(c.pause.messages), PauseBufferSize),
+		}
+	}
+
+	return true
+}
+
+// messageCommands creates the commands run for a received message,
+// the on-message hook, if it's set, and printing the message, or printing the marker if msg is a marker.
+// It returns an error if the print command can't be created.
+func (c *executionContext) messageCommands(msg Message) ([]Executer, error) {
+	if msg.marker != "" {
+		return []Executer{&marker{text: msg.marker}}, nil
+	}
+
+	cmd, err := c.cli.cmdFactory.Create(fmt.Sprintf("print %s %s", msg.Type.String(), QuoteArg(msg.Data)))
+	if err != nil {
+		return nil, fmt.Errorf("fail to create print command: %w", err)
+	}
+
+	if hook := c.hookFor(msg); hook != nil {
+		return []Executer{hook, cmd}, nil
+	}
+
+	return []Executer{cmd}, nil
+}
+
+// heldMessages delivers the messages held while the output was paused.
+type heldMessages struct {
+	ec       *executionContext
+	messages []Message
+}
+
+// Execute runs the commands for every held message in order.
+// It returns an error if any of the commands fails.
+func (h *heldMessages) Execute(exCtx ExecutionContext) (Executer, error) {
+	for _, msg := range h.messages {
+		cmds, err := h.ec.messageCommands(msg)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, cmd := range cmds {
+			for cmd != nil {
+				if cmd, err = cmd.Execute(exCtx); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// warning prints a message to the terminal only, it's not written to the output file.
+type warning struct {
+	text string
+}
+
+// Execute prints the warning to the terminal.
+// It returns an error if printing fails.
+func (w *warning) Execute(exCtx ExecutionContext) (Executer, error) {
+	return nil, exCtx.Print(w.text, color.FgYellow)
+}
@@ -0,0 +1,220 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutionContext_Hold(t *testing.T) {
+	ec := &executionContext{cli: &CLI{commands: make(chan Executer, 1)}}
+
+	assert.False(t, ec.hold(Message{Type: Response, Data: "before"}))
+
+	_, ok := ec.Resume()
+	assert.False(t, ok)
+
+	require.True(t, ec.Pause())
+	assert.False(t, ec.Pause())
+
+	for i := range PauseBufferSize {
+		assert.False(t, ec.pause.full())
+		assert.True(t, ec.hold(Message{Type: Response, Data: strconv.Itoa(i)}))
+	}
+
+	assert.True(t, ec.pause.full())
+
+	require.Len(t, ec.cli.commands, 1)
+	assert.Equal(t, &warning{text: "Output is paused with 900 of 1000 messages held, reading from the connection stops once it's full\n"}, <-ec.cli.commands)
+
+	held, ok := ec.Resume()
+	require.True(t, ok)
+	require.IsType(t, &heldMessages{}, held)
+
+	messages := held.(*heldMessages).messages
+	assert.Len(t, messages, PauseBufferSize)
+	assert.Equal(t, "0", messages[0].Data)
+	assert.Equal(t, strconv.Itoa(PauseBufferSize-1), messages[len(messages)-1].Data)
+	assert.False(t, ec.pause.full())
+
+	assert.False(t, ec.hold(Message{Type: Response, Data: "after"}))
+}
+
+func TestCLIRun_PauseResume(t *testing.T) {
+	var onMessage func(context.Context, []byte)
+
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().SetOnMessage(mock.Anything).Run(func(cb func(context.Context, []byte)) { onMessage = cb })
+	wsConn.EXPECT().SetOnStatusChange(mock.Anything)
+	wsConn.EXPECT().SetOnFrameError(mock.Anything)
+
+	editor := NewMockEditor(t)
+	editor.EXPECT().SetInput(mock.Anything)
+
+	file := &bytes.Buffer{}
+	paused := make(chan struct{})
+
+	pauseCmd := NewMockExecuter(t)
+	pauseCmd.EXPECT().Execute(mock.Anything).RunAndReturn(func(exCtx ExecutionContext) (Executer, error) {
+		assert.True(t, exCtx.Pause())
+		close(paused)
+
+		return nil, nil
+	})
+
+	resumeCmd := NewMockExecuter(t)
+	resumeCmd.EXPECT().Execute(mock.Anything).RunAndReturn(func(exCtx ExecutionContext) (Executer, error) {
+		assert.Empty(t, file.String(), "messages should be held while paused")
+
+		held, ok := exCtx.Resume()
+		assert.True(t, ok)
+
+		return held, nil
+	})
+
+	exitCmd := NewMockExecuter(t)
+	exitCmd.EXPECT().Execute(mock.Anything).Return(nil, ErrInterrupted)
+
+	factory := NewMockCommandFactory(t)
+	factory.EXPECT().Create(mock.Anything).RunAndReturn(func(raw string) (Executer, error) {
+		switch raw {
+		case "resume":
+			return resumeCmd, nil
+		case "exit":
+			return exitCmd, nil
+		}
+
+		printCmd := NewMockExecuter(t)
+		printCmd.EXPECT().Execute(mock.Anything).RunAndReturn(func(exCtx ExecutionContext) (Executer, error) {
+			return nil, exCtx.PrintToFile(raw)
+		})
+
+		return printCmd, nil
+	})
+
+	cli := NewCLI(factory, wsConn, &bytes.Buffer{}, editor, NewMockFormater(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		<-paused
+
+		for _, data := range []string{"1", "2", "3"} {
+			onMessage(ctx, []byte(data))
+		}
+
+		cli.OnCommand(ctx, "resume")
+		onMessage(ctx, []byte("4"))
+		cli.OnKeyEvent(KeyEvent{Key: KeyCtrlC})
+	}()
+
+	err := cli.Run(ctx, RunOptions{OutputFile: file, Commands: []Executer{pauseCmd}})
+	assert.ErrorIs(t, err, ErrInterrupted)
+
+	assert.Equal(t, []string{
+		"print Response 1",
+		"print Response 2",
+		"print Response 3",
+		"print Response 4",
+	}, strings.Split(strings.TrimSpace(file.String()), "\n"))
+}
+
+func TestCLIRun_PauseBackpressure(t *testing.T) {
+	var onMessage func(context.Context, []byte)
+
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().SetOnMessage(mock.Anything).Run(func(cb func(context.Context, []byte)) { onMessage = cb })
+	wsConn.EXPECT().SetOnStatusChange(mock.Anything)
+	wsConn.EXPECT().SetOnFrameError(mock.Anything)
+
+	editor := NewMockEditor(t)
+	editor.EXPECT().SetInput(mock.Anything)
+
+	file := &bytes.Buffer{}
+	paused := make(chan struct{})
+
+	var delivered atomic.Int64
+
+	pauseCmd := NewMockExecuter(t)
+	pauseCmd.EXPECT().Execute(mock.Anything).RunAndReturn(func(exCtx ExecutionContext) (Executer, error) {
+		assert.True(t, exCtx.Pause())
+		close(paused)
+
+		return nil, nil
+	})
+
+	resumeCmd := NewMockExecuter(t)
+	resumeCmd.EXPECT().Execute(mock.Anything).RunAndReturn(func(exCtx ExecutionContext) (Executer, error) {
+		assert.Equal(t, int64(PauseBufferSize), delivered.Load(), "reading should block once the pause buffer is full")
+
+		held, ok := exCtx.Resume()
+		assert.True(t, ok)
+
+		return held, nil
+	})
+
+	factory := NewMockCommandFactory(t)
+	factory.EXPECT().Create(mock.Anything).RunAndReturn(func(raw string) (Executer, error) {
+		if raw == "resume" {
+			return resumeCmd, nil
+		}
+
+		printCmd := NewMockExecuter(t)
+		printCmd.EXPECT().Execute(mock.Anything).RunAndReturn(func(exCtx ExecutionContext) (Executer, error) {
+			if raw == "print Response last" {
+				return nil, ErrInterrupted
+			}
+
+			return nil, exCtx.PrintToFile(raw)
+		})
+
+		return printCmd, nil
+	})
+
+	cli := NewCLI(factory, wsConn, &bytes.Buffer{}, editor, NewMockFormater(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		<-paused
+
+		for i := range PauseBufferSize {
+			onMessage(ctx, []byte(strconv.Itoa(i)))
+			delivered.Add(1)
+		}
+
+		blocked := make(chan struct{})
+
+		go func() {
+			defer close(blocked)
+
+			onMessage(ctx, []byte("last"))
+		}()
+
+		select {
+		case <-blocked:
+			t.Error("message should not be taken while the pause buffer is full")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		cli.OnCommand(ctx, "resume")
+	}()
+
+	err := cli.Run(ctx, RunOptions{OutputFile: file, Commands: []Executer{pauseCmd}})
+	assert.ErrorIs(t, err, ErrInterrupted)
+
+	lines := strings.Split(strings.TrimSpace(file.String()), "\n")
+	require.Len(t, lines, PauseBufferSize)
+	assert.Equal(t, "print Response 0", lines[0])
+	assert.Equal(t, "print Response "+strconv.Itoa(PauseBufferSize-1), lines[PauseBufferSize-1])
+}
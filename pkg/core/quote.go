@@ -0,0 +1,13 @@
+package core
+
+import "strings"
+
+// QuoteArg wraps s in single quotes if the command factory would otherwise unquote it, i.e. if s starts with a quote,
+// so the data is passed to the created command unchanged. Other data is returned as is.
+func QuoteArg(s string) string {
+	if !strings.HasPrefix(s, "'") {
+		return s
+	}
+
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
@@ -0,0 +1,65 @@
+package core
+
+import "sync"
+
+// DefaultRecentSize is the number of the last messages kept in memory by default.
+const DefaultRecentSize = 100
+
+// recentMessages is a ring buffer with the last sent and received messages of the session,
+// so they can be inspected later without an output file.
+type recentMessages struct {
+	buf   []Message
+	start int
+	count int
+	mu    sync.Mutex
+}
+
+// newRecentMessages creates a ring buffer keeping up to size messages, non-positive size disables it.
+func newRecentMessages(size int) *recentMessages {
+	return &recentMessages{buf: make([]Message, max(size, 0))}
+}
+
+// add stores the message, replacing the oldest one if the buffer is full.
+// It does nothing if the buffer is not created.
+func (r *recentMessages) add(msg Message) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.buf) == 0 {
+		return
+	}
+
+	r.buf[(r.start+r.count)%len(r.buf)] = msg
+
+	if r.count < len(r.buf) {
+		r.count++
+	} else {
+		r.start = (r.start + 1) % len(r.buf)
+	}
+}
+
+// last returns up to n most recent messages from the oldest to the newest, non-positive n returns all of them.
+func (r *recentMessages) last(n int) []Message {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if n <= 0 || n > r.count {
+		n = r.count
+	}
+
+	msgs := make([]Message, 0, n)
+
+	for i := r.count - n; i < r.count; i++ {
+		msgs = append(msgs, r.buf[(r.start+i)%len(r.buf)])
+	}
+
+	return msgs
+}
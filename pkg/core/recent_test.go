@@ -0,0 +1,59 @@
+package core
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecentMessages(t *testing.T) {
+	r := newRecentMessages(3)
+
+	assert.Empty(t, r.last(0))
+
+	for i := 1; i <= 5; i++ {
+		r.add(Message{Type: Response, Data: strconv.Itoa(i)})
+	}
+
+	assert.Equal(t, []Message{
+		{Type: Response, Data: "3"},
+		{Type: Response, Data: "4"},
+		{Type: Response, Data: "5"},
+	}, r.last(0))
+
+	assert.Equal(t, []Message{
+		{Type: Response, Data: "4"},
+		{Type: Response, Data: "5"},
+	}, r.last(2))
+
+	assert.Len(t, r.last(10), 3)
+}
+
+func TestRecentMessages_Disabled(t *testing.T) {
+	r := newRecentMessages(0)
+	r.add(Message{Type: Request, Data: "ping"})
+
+	assert.Empty(t, r.last(0))
+}
+
+func TestExecutionContext_RecentMessages(t *testing.T) {
+	ctx := context.Background()
+
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().Send(ctx, mock.Anything).Return(nil)
+
+	cli := &CLI{wsConn: wsConn}
+	cli.SetRecentSize(2)
+
+	ec := &executionContext{cli: cli, ctx: ctx}
+
+	for _, req := range []string{"a", "b", "c"} {
+		require.NoError(t, ec.SendRequest(req))
+	}
+
+	assert.Equal(t, []Message{{Type: Request, Data: "b"}, {Type: Request, Data: "c"}}, ec.RecentMessages(0))
+}
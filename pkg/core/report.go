@@ -0,0 +1,116 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Report is a machine-readable summary of a session, e.g. to keep as a CI artifact.
+type Report struct {
+	StartedAt     time.Time         `json:"started_at"`
+	FinishedAt    time.Time         `json:"finished_at"`
+	Errors        []string          `json:"errors"`
+	ResponseTimes ResponseTimeStats `json:"response_times"`
+	DurationMS    int64             `json:"duration_ms"`
+	Sent          int64             `json:"sent"`
+	Received      int64             `json:"received"`
+}
+
+// ResponseTimeStats summarizes the time between requests and the first responses to them, in milliseconds.
+type ResponseTimeStats struct {
+	Count int     `json:"count"`
+	Min   float64 `json:"min_ms"`
+	P50   float64 `json:"p50_ms"`
+	P90   float64 `json:"p90_ms"`
+	P99   float64 `json:"p99_ms"`
+	Max   float64 `json:"max_ms"`
+}
+
+// responseTimes collects the response times measured during the session.
+type responseTimes struct {
+	samples []time.Duration
+	l       sync.Mutex
+}
+
+// add records the time between a request and the first response to it.
+func (r *responseTimes) add(d time.Duration) {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	r.samples = append(r.samples, d)
+}
+
+// stats returns the count, the extremes and the percentiles of the collected response times.
+// Percentiles are taken with the nearest-rank method, all values are zero if nothing was collected.
+func (r *responseTimes) stats() ResponseTimeStats {
+	r.l.Lock()
+	sorted := slices.Clone(r.samples)
+	r.l.Unlock()
+
+	if len(sorted) == 0 {
+		return ResponseTimeStats{}
+	}
+
+	slices.Sort(sorted)
+
+	percentile := func(p int) float64 {
+		rank := (p*len(sorted) + 99) / 100 // nearest rank, rounded up
+
+		return toMS(sorted[max(rank, 1)-1])
+	}
+
+	return ResponseTimeStats{
+		Count: len(sorted),
+		Min:   toMS(sorted[0]),
+		P50:   percentile(50), // median
+		P90:   percentile(90), // 90th percentile
+		P99:   percentile(99), // 99th percentile
+		Max:   toMS(sorted[len(sorted)-1]),
+	}
+}
+
+// toMS converts the duration to fractional milliseconds.
+func toMS(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// Report returns the summary of the session up to now.
+// It takes runErr of type error, the error the session ended with, it's listed in the report unless it's nil.
+func (c *CLI) Report(runErr error) Report {
+	finishedAt := time.Now()
+
+	report := Report{
+		StartedAt:     c.startedAt,
+		FinishedAt:    finishedAt,
+		DurationMS:    finishedAt.Sub(c.startedAt).Milliseconds(),
+		Sent:          c.sent.Load(),
+		Received:      c.received.Load(),
+		ResponseTimes: c.responseTimes.stats(),
+		Errors:        []string{},
+	}
+
+	if runErr != nil {
+		report.Errors = append(report.Errors, runErr.Error())
+	}
+
+	return report
+}
+
+// WriteReport writes the report to the file as indented JSON, the file is replaced if it exists.
+// It returns an error if the report can't be encoded or the file can't be written.
+func WriteReport(path string, report Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("fail to encode report: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("fail to write report: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseTimes_Stats(t *testing.T) {
+	var rt responseTimes
+
+	assert.Equal(t, ResponseTimeStats{}, rt.stats())
+
+	for i := 100; i >= 1; i-- {
+		rt.add(time.Duration(i) * time.Millisecond)
+	}
+
+	assert.Equal(t, ResponseTimeStats{Count: 100, Min: 1, P50: 50, P90: 90, P99: 99, Max: 100}, rt.stats())
+}
+
+func TestCLI_Report(t *testing.T) {
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().SetOnMessage(mock.Anything)
+	wsConn.EXPECT().SetOnStatusChange(mock.Anything)
+	wsConn.EXPECT().SetOnFrameError(mock.Anything)
+	wsConn.EXPECT().Send(mock.Anything, "ping").Return(nil)
+
+	editor := NewMockEditor(t)
+	editor.EXPECT().SetInput(mock.Anything)
+
+	cli := NewCLI(NewMockCommandFactory(t), wsConn, io.Discard, editor, NewMockFormater(t))
+
+	sendCmd := NewMockExecuter(t)
+	sendCmd.EXPECT().Execute(mock.Anything).RunAndReturn(func(exCtx ExecutionContext) (Executer, error) {
+		if err := exCtx.SendRequest("ping"); err != nil {
+			return nil, err
+		}
+
+		_, _ = exCtx.ResponseLatency()
+
+		return nil, nil
+	})
+
+	exitCmd := NewMockExecuter(t)
+	exitCmd.EXPECT().Execute(mock.Anything).Return(nil, ErrInterrupted)
+
+	err := cli.Run(context.Background(), RunOptions{Commands: []Executer{sendCmd, exitCmd}})
+	require.ErrorIs(t, err, ErrInterrupted)
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	require.NoError(t, WriteReport(path, cli.Report(errors.New("connection closed: StatusGoingAway"))))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var report map[string]any
+
+	require.NoError(t, json.Unmarshal(data, &report))
+
+	assert.Contains(t, report, "started_at")
+	assert.Contains(t, report, "finished_at")
+	assert.Contains(t, report, "duration_ms")
+	assert.Equal(t, float64(1), report["sent"])
+	assert.Equal(t, float64(0), report["received"])
+	assert.Equal(t, []any{"connection closed: StatusGoingAway"}, report["errors"])
+
+	responseTimes, ok := report["response_times"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, float64(1), responseTimes["count"])
+	assert.Contains(t, responseTimes, "p99_ms")
+
+	assert.Equal(t, []string{}, cli.Report(nil).Errors)
+}
+
+func TestWriteReport_Fails(t *testing.T) {
+	err := WriteReport(filepath.Join(t.TempDir(), "missing", "report.json"), Report{})
+
+	assert.ErrorContains(t, err, "fail to write report")
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+const (
+	SinkFormatText  = "text"
+	SinkFormatJSONL = "jsonl"
+)
+
+// sink is an output file messages of the session are recorded to.
+// Text sinks get messages formatted for a file, JSON lines sinks get one JSON object per message.
+type sink struct {
+	w      io.Writer
+	closer io.Closer
+	format string
+	failed bool
+}
+
+// jsonRecord is a message as written to JSON lines sinks.
+type jsonRecord struct {
+	Time time.Time `json:"time"`
+	Type string    `json:"type"`
+	Data string    `json:"data"`
+}
+
+// openSink creates the file at path and returns a sink writing to it in the given format.
+// It returns an error if the format is not supported or the file can't be created.
+func openSink(path, format string) (*sink, error) {
+	if err := validateSinkFormat(format); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("fail to open output file: %w", err)
+	}
+
+	return &sink{w: file, closer: file, format: format}, nil
+}
+
+// validateSinkFormat returns an error if format is not a supported sink format.
+func validateSinkFormat(format string) error {
+	switch format {
+	case SinkFormatText, SinkFormatJSONL:
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+// encodeJSONRecord returns msg received at t as a single JSON line.
+func encodeJSONRecord(msg Message, t time.Time) (string, error) {
+	data, err := json.Marshal(jsonRecord{Time: t, Type: msg.Type.String(), Data: msg.Data})
+	if err != nil {
+		return "", fmt.Errorf("fail to encode message: %w", err)
+	}
+
+	return string(data), nil
+}
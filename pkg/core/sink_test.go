@@ -0,0 +1,67 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutionContext_RecordMessage_MultipleSinks(t *testing.T) {
+	formater := NewMockFormater(t)
+	formater.EXPECT().FormatForFile("Response", `{"pong":1}`).Return("{\n  \"pong\": 1\n}", nil).Once()
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	clock := NewMockClock(t)
+	clock.EXPECT().Now().Return(now)
+
+	text := &bytes.Buffer{}
+	ec := newExecutionContext(context.Background(), &CLI{formater: formater}, text)
+	ec.clock = clock
+
+	jsonPath := filepath.Join(t.TempDir(), "log.jsonl")
+	require.NoError(t, ec.AddSink(jsonPath, SinkFormatJSONL))
+
+	require.NoError(t, ec.RecordMessage(Message{Type: Response, Data: `{"pong":1}`}))
+
+	ec.closeSinks()
+
+	assert.Equal(t, "{\n  \"pong\": 1\n}\n\n", text.String())
+
+	data, err := os.ReadFile(jsonPath)
+	require.NoError(t, err)
+	assert.Equal(t, `{"time":"2024-05-01T12:00:00Z","type":"Response","data":"{\"pong\":1}"}`+"\n", string(data))
+}
+
+func TestExecutionContext_RecordMessage_Paused(t *testing.T) {
+	text := &bytes.Buffer{}
+	ec := newExecutionContext(context.Background(), &CLI{}, text)
+
+	require.NoError(t, ec.SetRecording(false))
+	require.NoError(t, ec.RecordMessage(Message{Type: Request, Data: "ping"}))
+
+	assert.Empty(t, text.String())
+}
+
+func TestExecutionContext_PrintToFile_SkipsJSONLSinks(t *testing.T) {
+	jsonl := &bytes.Buffer{}
+	ec := &executionContext{sinks: []*sink{{w: jsonl, format: SinkFormatJSONL}}}
+
+	require.NoError(t, ec.PrintToFile("--- marker ---"))
+
+	assert.Empty(t, jsonl.String())
+}
+
+func TestExecutionContext_AddSink_Errors(t *testing.T) {
+	ec := newExecutionContext(context.Background(), &CLI{}, nil)
+
+	assert.EqualError(t, ec.AddSink(filepath.Join(t.TempDir(), "log.csv"), "csv"), "unsupported output format: csv")
+	assert.ErrorContains(t, ec.AddSink(filepath.Join(t.TempDir(), "missing", "log.txt"), SinkFormatText), "fail to open output file")
+	assert.Empty(t, ec.sinks)
+}
@@ -0,0 +1,31 @@
+package core
+
+import "time"
+
+// throttle limits the rate of messages shown in the terminal, so it can keep up with a chatty server.
+type throttle struct {
+	shownAt  time.Time
+	interval time.Duration
+	dropped  int
+}
+
+// allow decides if a message arrived at now can be shown.
+// The first message of every interval is shown, the rest are dropped and counted.
+// It returns true if the message should be shown and the number of messages dropped since the last shown one,
+// the counter is reset once it's reported.
+func (t *throttle) allow(now time.Time) (show bool, dropped int) {
+	if t.interval <= 0 {
+		return true, 0
+	}
+
+	if !t.shownAt.IsZero() && now.Sub(t.shownAt) < t.interval {
+		t.dropped++
+		return false, 0
+	}
+
+	dropped = t.dropped
+	t.shownAt = now
+	t.dropped = 0
+
+	return true, dropped
+}
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThrottle_Allow(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	th := throttle{interval: 100 * time.Millisecond}
+
+	var (
+		shown   int
+		dropped []int
+	)
+
+	// A message every 10ms for a second, so one message per interval is shown.
+	for i := 0; i < 100; i++ {
+		show, d := th.allow(start.Add(time.Duration(i) * 10 * time.Millisecond))
+		if !show {
+			continue
+		}
+
+		shown++
+
+		dropped = append(dropped, d)
+	}
+
+	assert.Equal(t, 10, shown)
+	assert.Equal(t, []int{0, 9, 9, 9, 9, 9, 9, 9, 9, 9}, dropped)
+}
+
+func TestThrottle_Allow_Disabled(t *testing.T) {
+	th := throttle{}
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		show, dropped := th.allow(now)
+
+		assert.True(t, show)
+		assert.Zero(t, dropped)
+	}
+}
+
+func TestExecutionContext_ThrottleResponse(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ec := &executionContext{}
+
+	show, _ := ec.ThrottleResponse()
+	assert.True(t, show, "every message is shown without throttle")
+
+	clock := NewMockClock(t)
+	clock.EXPECT().Now().Return(start).Twice()
+	clock.EXPECT().Now().Return(start.Add(time.Second)).Once()
+
+	ec.clock = clock
+	ec.SetThrottle(time.Second)
+
+	show, _ = ec.ThrottleResponse()
+	assert.True(t, show)
+
+	show, _ = ec.ThrottleResponse()
+	assert.False(t, show)
+
+	show, dropped := ec.ThrottleResponse()
+	assert.True(t, show)
+	assert.Equal(t, 1, dropped)
+
+	ec.SetThrottle(0)
+
+	show, _ = ec.ThrottleResponse()
+	assert.True(t, show)
+}
@@ -0,0 +1,27 @@
+package core
+
+import "strings"
+
+const (
+	// TrimNone sends messages with the exact bytes entered.
+	TrimNone = "none"
+	// TrimSpace strips leading and trailing whitespace from sent messages.
+	TrimSpace = "space"
+	// TrimNewline strips trailing line endings from sent messages, e.g. left by copy-pasting.
+	TrimNewline = "newline"
+)
+
+// TrimPayload strips whitespace from data according to the given mode.
+// It takes data of type string and mode of type string, one of TrimSpace or TrimNewline,
+// any other mode, including TrimNone, leaves data unchanged.
+// It returns the trimmed data.
+func TrimPayload(data, mode string) string {
+	switch mode {
+	case TrimSpace:
+		return strings.TrimSpace(data)
+	case TrimNewline:
+		return strings.TrimRight(data, "\r\n")
+	default:
+		return data
+	}
+}
@@ -0,0 +1,33 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrimPayload(t *testing.T) {
+	data := "  {\"type\": \"ping\"} \r\n\n"
+
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{mode: TrimNone, want: data},
+		{mode: "", want: data},
+		{mode: TrimSpace, want: "{\"type\": \"ping\"}"},
+		{mode: TrimNewline, want: "  {\"type\": \"ping\"} "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			assert.Equal(t, tt.want, TrimPayload(data, tt.mode))
+		})
+	}
+}
+
+func TestExecutionContext_TrimPayload(t *testing.T) {
+	exCtx := &executionContext{trim: TrimSpace}
+
+	assert.Equal(t, "ping", exCtx.TrimPayload(" ping\n"))
+}
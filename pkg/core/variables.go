@@ -0,0 +1,47 @@
+package core
+
+import (
+	"regexp"
+	"sync"
+)
+
+var variablePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// variables is the session variable store shared by commands and the connection.
+// It is safe for concurrent use, because the connection reads it from its own goroutine on reconnect.
+type variables struct {
+	values map[string]string
+	l      sync.RWMutex
+}
+
+// Set stores the value under the given name, overwriting an existing variable with the same name.
+func (v *variables) Set(name, value string) {
+	v.l.Lock()
+	defer v.l.Unlock()
+
+	if v.values == nil {
+		v.values = make(map[string]string)
+	}
+
+	v.values[name] = value
+}
+
+// Expand replaces ${name} references in data with the stored values.
+// It takes data of type string, references to variables that are not set are left unchanged.
+// It returns the data with all known variables expanded.
+func (v *variables) Expand(data string) string {
+	v.l.RLock()
+	defer v.l.RUnlock()
+
+	if len(v.values) == 0 {
+		return data
+	}
+
+	return variablePattern.ReplaceAllStringFunc(data, func(ref string) string {
+		if value, ok := v.values[ref[2:len(ref)-1]]; ok {
+			return value
+		}
+
+		return ref
+	})
+}
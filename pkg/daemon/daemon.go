@@ -0,0 +1,294 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ksysoev/wsget/pkg/ws"
+)
+
+const (
+	// DefaultTimeout is the time to wait for the response when the request doesn't set it.
+	DefaultTimeout = 10 * time.Second
+
+	messageBuffer = 100
+)
+
+var ErrNoResponse = errors.New("no response received")
+
+// Request is a message sent through the daemon, connections are reused for requests with the same URL and options.
+type Request struct {
+	URL      string        `json:"url"`
+	Message  string        `json:"message"`
+	Headers  []string      `json:"headers,omitempty"`
+	Timeout  time.Duration `json:"timeout,omitempty"`
+	Insecure bool          `json:"insecure,omitempty"`
+}
+
+// Response is the first message received after the request is sent, or the reason it's not received.
+type Response struct {
+	Data   string `json:"data,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Reused bool   `json:"reused"`
+}
+
+// key identifies the pooled connection the request can be sent over.
+func (r Request) key() string {
+	return fmt.Sprintf("%s\x00%t\x00%s", r.URL, r.Insecure, strings.Join(r.Headers, "\x00"))
+}
+
+// pooled is an open connection kept by the daemon with the messages received over it.
+type pooled struct {
+	conn     *ws.Connection
+	messages chan []byte
+	mu       sync.Mutex
+}
+
+// closed returns true if the connection stopped reading messages, so it can't be reused.
+func (p *pooled) closed() bool {
+	select {
+	case <-p.conn.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// roundTrip sends the message and waits for the first message received after it.
+// Requests over the same connection are sent one at a time and messages left from previous requests are discarded,
+// so responses are not mixed up between clients.
+// It returns the received message, or an error if sending fails, the connection is closed or the timeout elapses.
+func (p *pooled) roundTrip(ctx context.Context, msg string, timeout time.Duration) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for len(p.messages) > 0 {
+		<-p.messages
+	}
+
+	if err := p.conn.Send(ctx, msg); err != nil {
+		return nil, fmt.Errorf("fail to send message: %w", err)
+	}
+
+	select {
+	case data := <-p.messages:
+		return data, nil
+	case <-p.conn.Done():
+		return nil, ws.ErrConnectionClosed
+	case <-time.After(timeout):
+		return nil, fmt.Errorf("%w in %s", ErrNoResponse, timeout)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Server keeps WebSocket connections open between short-lived wsget invocations, so they don't pay the handshake cost
+// every time. Clients send requests over a local socket with Send.
+type Server struct {
+	ctx   context.Context
+	conns map[string]*pooled
+	mu    sync.Mutex
+}
+
+// NewServer creates a new Server without open connections.
+// It returns a pointer to the created Server.
+func NewServer() *Server {
+	return &Server{conns: make(map[string]*pooled)}
+}
+
+// Serve accepts client connections on the listener and handles a single request per client connection.
+// It takes ctx of type context.Context, pooled connections are closed and the listener is closed once it's canceled,
+// and ln of type net.Listener, e.g. a Unix socket.
+// It returns nil when the context is canceled, or an error if accepting client connections fails.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	s.mu.Lock()
+	s.ctx = ctx
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+
+	defer func() {
+		wg.Wait()
+		s.closeAll()
+	}()
+
+	go func() {
+		<-ctx.Done()
+		_ = ln.Close()
+	}()
+
+	for {
+		client, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return fmt.Errorf("fail to accept client connection: %w", err)
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			s.handle(ctx, client)
+		}()
+	}
+}
+
+// handle reads the request from the client connection and writes the response back.
+func (s *Server) handle(ctx context.Context, client net.Conn) {
+	defer func() { _ = client.Close() }()
+
+	var req Request
+
+	line, err := bufio.NewReader(client).ReadBytes('\n')
+	if err == nil {
+		err = json.Unmarshal(line, &req)
+	}
+
+	resp := Response{Error: fmt.Sprintf("invalid request: %v", err)}
+	if err == nil {
+		resp = s.Do(ctx, req)
+	}
+
+	_ = json.NewEncoder(client).Encode(resp)
+}
+
+// Do sends the request over a pooled connection, the connection is established if there is no open one yet.
+// It returns the response with the first message received after the request, or with the reason it's not received.
+func (s *Server) Do(ctx context.Context, req Request) Response {
+	p, reused, err := s.get(ctx, req)
+	if err != nil {
+		return Response{Error: err.Error()}
+	}
+
+	timeout := req.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	data, err := p.roundTrip(ctx, req.Message, timeout)
+	if err != nil {
+		return Response{Error: err.Error(), Reused: reused}
+	}
+
+	return Response{Data: string(data), Reused: reused}
+}
+
+// get returns the open connection for the request, connections closed since they were pooled are replaced.
+// The connection is established without holding the lock, so a slow endpoint doesn't block requests to other ones.
+// If another request established a connection with the same options meanwhile, it's used and the new one is closed.
+// It returns the connection, true if it was already open, and an error if the connection can't be established.
+func (s *Server) get(ctx context.Context, req Request) (*pooled, bool, error) {
+	key := req.key()
+
+	s.mu.Lock()
+	p, ok := s.conns[key]
+	serveCtx := s.ctx
+	s.mu.Unlock()
+
+	if ok && !p.closed() {
+		return p, true, nil
+	}
+
+	p, err := s.connect(ctx, serveCtx, req)
+	if err != nil {
+		return nil, false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if other, ok := s.conns[key]; ok && other != p && !other.closed() {
+		_ = p.conn.Close()
+		return other, true, nil
+	}
+
+	s.conns[key] = p
+
+	return p, false, nil
+}
+
+// connect establishes a new connection for the request, it stays open until serveCtx is canceled
+// or the server is stopped.
+// It returns an error if the options are invalid or the handshake fails.
+func (s *Server) connect(ctx, serveCtx context.Context, req Request) (*pooled, error) {
+	conn, err := ws.New(req.URL, ws.Options{Headers: req.Headers, SkipSSLVerification: req.Insecure})
+	if err != nil {
+		return nil, err
+	}
+
+	p := &pooled{conn: conn, messages: make(chan []byte, messageBuffer)}
+
+	conn.SetOnMessage(func(_ context.Context, data []byte) {
+		select {
+		case p.messages <- data:
+		default:
+		}
+	})
+
+	connErr := make(chan error, 1)
+
+	go func() {
+		connErr <- conn.Connect(serveCtx)
+	}()
+
+	select {
+	case <-conn.Ready():
+		return p, nil
+	case err := <-connErr:
+		return nil, fmt.Errorf("fail to connect: %w", err)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// closeAll closes all pooled connections and waits until they stop reading messages.
+func (s *Server) closeAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, p := range s.conns {
+		_ = p.conn.Close()
+		<-p.conn.Done()
+
+		delete(s.conns, key)
+	}
+}
+
+// Send sends the request to the daemon listening on the Unix socket and waits for the response.
+// It takes ctx of type context.Context, socket of type string, the path of the daemon socket, and req of type Request.
+// It returns the response of the daemon, or an error if the daemon is not running or the exchange fails.
+func Send(ctx context.Context, socket string, req Request) (Response, error) {
+	var d net.Dialer
+
+	client, err := d.DialContext(ctx, "unix", socket)
+	if err != nil {
+		return Response{}, fmt.Errorf("fail to connect to daemon: %w", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = client.SetDeadline(deadline)
+	}
+
+	if err := json.NewEncoder(client).Encode(req); err != nil {
+		return Response{}, fmt.Errorf("fail to send request to daemon: %w", err)
+	}
+
+	var resp Response
+	if err := json.NewDecoder(client).Decode(&resp); err != nil {
+		return Response{}, fmt.Errorf("fail to read response from daemon: %w", err)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,166 @@
+package daemon
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEchoServer(t *testing.T, handshakes *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handshakes.Add(1)
+
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = c.CloseNow() }()
+
+		for {
+			msgType, data, err := c.Read(r.Context())
+			if err != nil {
+				return
+			}
+
+			if string(data) == "silence" {
+				continue
+			}
+
+			if err := c.Write(r.Context(), msgType, append([]byte("echo "), data...)); err != nil {
+				return
+			}
+		}
+	}))
+
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func startDaemon(t *testing.T) string {
+	t.Helper()
+
+	socket := filepath.Join(t.TempDir(), "wsget.sock")
+
+	ln, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+
+	go func() {
+		served <- NewServer().Serve(ctx, ln)
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-served)
+	})
+
+	return socket
+}
+
+func TestServer_ReusesConnection(t *testing.T) {
+	var handshakes atomic.Int32
+
+	s := newEchoServer(t, &handshakes)
+	socket := startDaemon(t)
+	url := "ws://" + s.Listener.Addr().String()
+
+	resp, err := Send(context.Background(), socket, Request{URL: url, Message: "first"})
+	require.NoError(t, err)
+	assert.Equal(t, Response{Data: "echo first"}, resp)
+
+	resp, err = Send(context.Background(), socket, Request{URL: url, Message: "second"})
+	require.NoError(t, err)
+	assert.Equal(t, Response{Data: "echo second", Reused: true}, resp)
+
+	assert.Equal(t, int32(1), handshakes.Load())
+
+	resp, err = Send(context.Background(), socket, Request{URL: url, Message: "third", Headers: []string{"X-Client: test"}})
+	require.NoError(t, err)
+	assert.Equal(t, Response{Data: "echo third"}, resp, "requests with other options should use their own connection")
+
+	assert.Equal(t, int32(2), handshakes.Load())
+}
+
+func TestServer_NoResponse(t *testing.T) {
+	var handshakes atomic.Int32
+
+	s := newEchoServer(t, &handshakes)
+	socket := startDaemon(t)
+
+	resp, err := Send(context.Background(), socket, Request{
+		URL:     "ws://" + s.Listener.Addr().String(),
+		Message: "silence",
+		Timeout: 50 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "no response received in 50ms", resp.Error)
+}
+
+func TestServer_SlowHandshakeDoesNotBlockOthers(t *testing.T) {
+	var handshakes atomic.Int32
+
+	s := newEchoServer(t, &handshakes)
+	socket := startDaemon(t)
+
+	// The slow endpoint accepts TCP connections, but never answers the handshake.
+	slow, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = slow.Close() })
+
+	accepted := make(chan net.Conn, 1)
+
+	go func() {
+		if conn, err := slow.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+
+	go func() {
+		_, _ = Send(context.Background(), socket, Request{URL: "ws://" + slow.Addr().String(), Message: "ping"})
+	}()
+
+	select {
+	case conn := <-accepted:
+		t.Cleanup(func() { _ = conn.Close() })
+	case <-time.After(time.Second):
+		t.Fatal("slow endpoint wasn't dialed")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	resp, err := Send(ctx, socket, Request{URL: "ws://" + s.Listener.Addr().String(), Message: "fast"})
+	require.NoError(t, err)
+	assert.Equal(t, Response{Data: "echo fast"}, resp)
+}
+
+func TestServer_ConnectFails(t *testing.T) {
+	socket := startDaemon(t)
+
+	resp, err := Send(context.Background(), socket, Request{URL: "ws://127.0.0.1:0", Message: "ping"})
+	require.NoError(t, err)
+	assert.Contains(t, resp.Error, "fail to connect")
+	assert.False(t, resp.Reused)
+}
+
+func TestSend_NoDaemon(t *testing.T) {
+	_, err := Send(context.Background(), filepath.Join(t.TempDir(), "missing.sock"), Request{URL: "ws://localhost", Message: "ping"})
+
+	assert.ErrorContains(t, err, "fail to connect to daemon")
+}
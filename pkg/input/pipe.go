@@ -0,0 +1,80 @@
+package input
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+type CommandHandler interface {
+	OnCommand(ctx context.Context, raw string)
+}
+
+// Pipe reads commands from a named pipe, so a running session can be controlled by other programs.
+type Pipe struct {
+	handler CommandHandler
+	path    string
+}
+
+// NewPipe creates a new Pipe reading commands from the named pipe at path.
+// It takes path of type string, the pipe created beforehand, e.g. with mkfifo, and handler of type CommandHandler,
+// which receives every command read from the pipe.
+// It returns a pointer to the created Pipe.
+func NewPipe(path string, handler CommandHandler) *Pipe {
+	return &Pipe{
+		path:    path,
+		handler: handler,
+	}
+}
+
+// Run reads commands from the pipe line by line and passes them to the handler until the context is canceled.
+// Empty lines are skipped. Writers can open and close the pipe any number of times while the session is running.
+// It returns an error if the path is not a named pipe, it can't be opened or reading from it fails.
+func (p *Pipe) Run(ctx context.Context) error {
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return fmt.Errorf("fail to open control pipe: %w", err)
+	}
+
+	if info.Mode()&os.ModeNamedPipe == 0 {
+		return fmt.Errorf("control pipe is not a named pipe: %s", p.path)
+	}
+
+	// The pipe is opened for writing as well, so it doesn't reach the end of file when a writer closes it.
+	file, err := os.OpenFile(p.path, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("fail to open control pipe: %w", err)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+
+		_ = file.Close()
+	}()
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		if raw := strings.TrimSpace(scanner.Text()); raw != "" {
+			p.handler.OnCommand(ctx, raw)
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("fail to read control pipe: %w", err)
+	}
+
+	return nil
+}
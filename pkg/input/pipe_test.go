@@ -0,0 +1,74 @@
+//go:build !windows
+
+package input
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type commandRecorder chan string
+
+func (r commandRecorder) OnCommand(_ context.Context, raw string) {
+	r <- raw
+}
+
+func TestPipe_Run(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wsget.fifo")
+	require.NoError(t, syscall.Mkfifo(path, 0o600))
+
+	commands := make(commandRecorder, 3)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+
+	go func() { done <- NewPipe(path, commands).Run(ctx) }()
+
+	// Every write opens the pipe separately, the same way as `echo cmd > pipe` does.
+	for _, data := range []string{"send {\"ping\":1}\n\n", "  sleep 1  \nexit\n"} {
+		w, err := os.OpenFile(path, os.O_WRONLY, 0)
+		require.NoError(t, err)
+
+		_, err = w.WriteString(data)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+	}
+
+	for _, want := range []string{`send {"ping":1}`, "sleep 1", "exit"} {
+		select {
+		case got := <-commands:
+			assert.Equal(t, want, got)
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for %q", want)
+		}
+	}
+
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("pipe is not closed after the context is canceled")
+	}
+}
+
+func TestPipe_Run_NotPipe(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "commands.txt")
+	require.NoError(t, os.WriteFile(path, []byte("exit\n"), 0o600))
+
+	err := NewPipe(path, make(commandRecorder)).Run(context.Background())
+
+	assert.EqualError(t, err, "control pipe is not a named pipe: "+path)
+
+	err = NewPipe(filepath.Join(t.TempDir(), "missing"), make(commandRecorder)).Run(context.Background())
+
+	assert.ErrorContains(t, err, "fail to open control pipe")
+}
@@ -0,0 +1,278 @@
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ksysoev/wsget/pkg/ws"
+)
+
+const (
+	// maxRate is the highest rate whose send interval is still at least a nanosecond.
+	maxRate = int(time.Second)
+
+	// maxInFlightPerConn limits the number of sends waiting on a single connection, once it's reached the load
+	// generator waits for a send to finish instead of starting a new goroutine, so the actual rate drops.
+	maxInFlightPerConn = 16
+
+	// sendTimeout is the time a single message is given to be written before the send is counted as failed.
+	sendTimeout = 5 * time.Second
+)
+
+var ErrNoConnections = errors.New("no connection could be established")
+
+// Options describes the load generated by Run.
+type Options struct {
+	URL         string
+	Payload     string
+	Headers     []string
+	Connections int
+	Rate        int
+	Duration    time.Duration
+	Ramp        time.Duration
+	Insecure    bool
+}
+
+// Report is the aggregated result of a load test run.
+type Report struct {
+	Connections int
+	ConnectErrs int
+	Sent        int64
+	SendErrs    int64
+	Received    int64
+	Elapsed     time.Duration
+}
+
+// Throughput returns the number of messages sent per second.
+func (r Report) Throughput() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+
+	return float64(r.Sent) / r.Elapsed.Seconds()
+}
+
+// ErrorRate returns the share of failed sends among all attempted sends.
+func (r Report) ErrorRate() float64 {
+	total := r.Sent + r.SendErrs
+	if total == 0 {
+		return 0
+	}
+
+	return float64(r.SendErrs) / float64(total)
+}
+
+// String formats the report as a short human readable summary.
+func (r Report) String() string {
+	return fmt.Sprintf(
+		"connections: %d open, %d failed\nsent: %d in %s (%.1f msg/s)\nsend errors: %d (%.2f%%)\nreceived: %d\n",
+		r.Connections, r.ConnectErrs,
+		r.Sent, r.Elapsed.Round(time.Millisecond), r.Throughput(),
+		r.SendErrs, r.ErrorRate()*100, // percents
+		r.Received,
+	)
+}
+
+// Run opens the connections and sends the payload at the target rate across them for the duration.
+// Connections are opened evenly over the ramp period before sending starts and closed once the duration elapses,
+// messages are sent to the open connections in turn.
+// It takes ctx of type context.Context, the run stops early and the partial report is returned once it's canceled.
+// It returns the report, or an error if options are invalid or none of the connections could be established,
+// the error wraps ErrNoConnections and the first dial error in this case.
+func Run(ctx context.Context, opts Options) (Report, error) {
+	if opts.Connections <= 0 || opts.Rate <= 0 || opts.Duration <= 0 {
+		return Report{}, fmt.Errorf("connections, rate and duration must be positive")
+	}
+
+	if opts.Rate > maxRate {
+		return Report{}, fmt.Errorf("rate must not exceed %d messages per second", maxRate)
+	}
+
+	var (
+		report   Report
+		received atomic.Int64
+	)
+
+	conns, dialErr := openConnections(ctx, opts, &received)
+
+	defer closeConnections(conns)
+
+	report.Connections = len(conns)
+	report.ConnectErrs = opts.Connections - len(conns)
+
+	if len(conns) == 0 {
+		if dialErr != nil {
+			return report, fmt.Errorf("%w: %w", ErrNoConnections, dialErr)
+		}
+
+		return report, ErrNoConnections
+	}
+
+	sent, sendErrs, elapsed := sendLoad(ctx, opts, conns)
+
+	report.Sent = sent
+	report.SendErrs = sendErrs
+	report.Elapsed = elapsed
+	report.Received = received.Load()
+
+	return report, nil
+}
+
+// openConnections opens the connections evenly over the ramp period.
+// It returns the connections that were established, failed ones are left out, and the first dial error if any.
+func openConnections(ctx context.Context, opts Options, received *atomic.Int64) ([]*ws.Connection, error) {
+	var (
+		firstErr error
+		conns    []*ws.Connection
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+	)
+
+	step := opts.Ramp / time.Duration(opts.Connections)
+
+	for i := 0; i < opts.Connections && ctx.Err() == nil; i++ {
+		if i > 0 && step > 0 {
+			select {
+			case <-time.After(step):
+			case <-ctx.Done():
+			}
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			conn, err := dial(ctx, opts, received)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+
+				return
+			}
+
+			conns = append(conns, conn)
+		}()
+	}
+
+	wg.Wait()
+
+	return conns, firstErr
+}
+
+// sendLoad sends the payload at the target rate until the duration elapses or the context is canceled.
+// Every message is sent in its own goroutine, so a slow connection doesn't lower the rate of the others,
+// up to maxInFlightPerConn sends per connection are in flight, past that the rate drops until one of them finishes.
+// Sends are bound to the duration and sendTimeout, those still in flight when the duration elapses are canceled
+// and waited for, but not counted as failed.
+// It returns the number of sent messages, failed sends and the time the load was generated for.
+func sendLoad(ctx context.Context, opts Options, conns []*ws.Connection) (sent, sendErrs int64, elapsed time.Duration) {
+	var (
+		okCount  atomic.Int64
+		errCount atomic.Int64
+		wg       sync.WaitGroup
+	)
+
+	loadCtx, cancel := context.WithTimeout(ctx, opts.Duration)
+	defer cancel()
+
+	interval := time.Second / time.Duration(opts.Rate)
+	ticker := time.NewTicker(interval)
+
+	defer ticker.Stop()
+
+	inFlight := make(chan struct{}, len(conns)*maxInFlightPerConn)
+	start := time.Now()
+	send := func(conn *ws.Connection) {
+		defer func() {
+			<-inFlight
+			wg.Done()
+		}()
+
+		err := conn.Send(loadCtx, opts.Payload)
+
+		switch {
+		case err == nil:
+			okCount.Add(1)
+		case loadCtx.Err() == nil:
+			errCount.Add(1)
+		}
+	}
+
+	done := func() (int64, int64, time.Duration) {
+		elapsed = time.Since(start)
+
+		wg.Wait()
+
+		return okCount.Load(), errCount.Load(), elapsed
+	}
+
+	for next := 0; ; next = (next + 1) % len(conns) {
+		select {
+		case inFlight <- struct{}{}:
+		case <-loadCtx.Done():
+			return done()
+		}
+
+		wg.Add(1)
+
+		go send(conns[next])
+
+		select {
+		case <-ticker.C:
+		case <-loadCtx.Done():
+			return done()
+		}
+	}
+}
+
+// closeConnections closes the connections and waits until they stop reading messages.
+func closeConnections(conns []*ws.Connection) {
+	for _, conn := range conns {
+		_ = conn.Close()
+	}
+
+	for _, conn := range conns {
+		<-conn.Done()
+	}
+}
+
+// dial opens a connection to the URL and waits until it's ready to send messages.
+// Messages received over the connection are only counted.
+// It returns an error if the options are invalid or the handshake fails.
+func dial(ctx context.Context, opts Options, received *atomic.Int64) (*ws.Connection, error) {
+	conn, err := ws.New(opts.URL, ws.Options{
+		Headers:             opts.Headers,
+		SkipSSLVerification: opts.Insecure,
+		WriteTimeout:        sendTimeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	conn.SetOnMessage(func(context.Context, []byte) { received.Add(1) })
+
+	connErr := make(chan error, 1)
+
+	go func() {
+		connErr <- conn.Connect(ctx)
+	}()
+
+	select {
+	case <-conn.Ready():
+		return conn, nil
+	case err := <-connErr:
+		return nil, fmt.Errorf("fail to connect: %w", err)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
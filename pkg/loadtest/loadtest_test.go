@@ -0,0 +1,149 @@
+package loadtest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEchoServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = c.CloseNow() }()
+
+		for {
+			msgType, data, err := c.Read(r.Context())
+			if err != nil {
+				return
+			}
+
+			if err := c.Write(r.Context(), msgType, data); err != nil {
+				return
+			}
+		}
+	}))
+
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func TestRun(t *testing.T) {
+	s := newEchoServer(t)
+
+	report, err := Run(context.Background(), Options{
+		URL:         "ws://" + s.Listener.Addr().String(),
+		Payload:     "ping",
+		Connections: 5,
+		Rate:        200,
+		Duration:    500 * time.Millisecond,
+		Ramp:        50 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, report.Connections)
+	assert.Zero(t, report.ConnectErrs)
+	assert.Zero(t, report.SendErrs)
+	assert.InDelta(t, 100, report.Sent, 30)
+	assert.LessOrEqual(t, report.Received, report.Sent)
+	assert.Positive(t, report.Received)
+	assert.InDelta(t, 200, report.Throughput(), 60)
+}
+
+func TestRun_ConnectionsFail(t *testing.T) {
+	report, err := Run(context.Background(), Options{
+		URL:         "ws://localhost:0",
+		Payload:     "ping",
+		Connections: 2,
+		Rate:        10,
+		Duration:    100 * time.Millisecond,
+	})
+
+	assert.ErrorIs(t, err, ErrNoConnections)
+	assert.Equal(t, 2, report.ConnectErrs)
+}
+
+func TestRun_ConnectionsFail_DialError(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	t.Cleanup(s.Close)
+
+	_, err := Run(context.Background(), Options{
+		URL:         "ws://" + s.Listener.Addr().String(),
+		Payload:     "ping",
+		Connections: 1,
+		Rate:        10,
+		Duration:    100 * time.Millisecond,
+	})
+
+	assert.ErrorIs(t, err, ErrNoConnections)
+	assert.ErrorContains(t, err, "403")
+}
+
+func TestRun_StalledServer(t *testing.T) {
+	stop := make(chan struct{})
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = c.CloseNow() }()
+
+		<-stop
+	}))
+
+	t.Cleanup(s.Close)
+	t.Cleanup(func() { close(stop) })
+
+	start := time.Now()
+
+	report, err := Run(context.Background(), Options{
+		URL:         "ws://" + s.Listener.Addr().String(),
+		Payload:     strings.Repeat("x", 1<<20),
+		Connections: 1,
+		Rate:        1000,
+		Duration:    200 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	assert.Less(t, time.Since(start), 2*time.Second)
+	assert.Zero(t, report.SendErrs)
+	assert.LessOrEqual(t, report.Sent, int64(maxInFlightPerConn))
+}
+
+func TestRun_InvalidOptions(t *testing.T) {
+	_, err := Run(context.Background(), Options{URL: "ws://localhost", Connections: 1, Rate: 0, Duration: time.Second})
+
+	assert.EqualError(t, err, "connections, rate and duration must be positive")
+
+	_, err = Run(context.Background(), Options{URL: "ws://localhost", Connections: 1, Rate: maxRate + 1, Duration: time.Second})
+
+	assert.EqualError(t, err, "rate must not exceed 1000000000 messages per second")
+}
+
+func TestReport_String(t *testing.T) {
+	report := Report{Connections: 3, ConnectErrs: 1, Sent: 99, SendErrs: 1, Received: 90, Elapsed: 2 * time.Second}
+
+	assert.Equal(t,
+		"connections: 3 open, 1 failed\nsent: 99 in 2s (49.5 msg/s)\nsend errors: 1 (1.00%)\nreceived: 90\n",
+		report.String(),
+	)
+	assert.Zero(t, Report{}.Throughput())
+	assert.Zero(t, Report{}.ErrorRate())
+}
@@ -0,0 +1,95 @@
+//go:build !windows && !plan9
+
+package output
+
+import (
+	"fmt"
+	"io"
+	"log/syslog"
+	"net/url"
+	"strings"
+)
+
+const syslogTag = "wsget"
+
+var syslogFacilities = map[string]syslog.Priority{
+	"kern":     syslog.LOG_KERN,
+	"user":     syslog.LOG_USER,
+	"mail":     syslog.LOG_MAIL,
+	"daemon":   syslog.LOG_DAEMON,
+	"auth":     syslog.LOG_AUTH,
+	"syslog":   syslog.LOG_SYSLOG,
+	"lpr":      syslog.LOG_LPR,
+	"news":     syslog.LOG_NEWS,
+	"uucp":     syslog.LOG_UUCP,
+	"cron":     syslog.LOG_CRON,
+	"authpriv": syslog.LOG_AUTHPRIV,
+	"ftp":      syslog.LOG_FTP,
+	"local0":   syslog.LOG_LOCAL0,
+	"local1":   syslog.LOG_LOCAL1,
+	"local2":   syslog.LOG_LOCAL2,
+	"local3":   syslog.LOG_LOCAL3,
+	"local4":   syslog.LOG_LOCAL4,
+	"local5":   syslog.LOG_LOCAL5,
+	"local6":   syslog.LOG_LOCAL6,
+	"local7":   syslog.LOG_LOCAL7,
+}
+
+var syslogSeverities = map[string]syslog.Priority{
+	"emerg":   syslog.LOG_EMERG,
+	"alert":   syslog.LOG_ALERT,
+	"crit":    syslog.LOG_CRIT,
+	"err":     syslog.LOG_ERR,
+	"warning": syslog.LOG_WARNING,
+	"notice":  syslog.LOG_NOTICE,
+	"info":    syslog.LOG_INFO,
+	"debug":   syslog.LOG_DEBUG,
+}
+
+// NewSyslog connects to the syslog daemon, so messages of the session can be recorded to it.
+// It takes priority of type string, the facility and the severity of the messages, e.g. local0.info,
+// and addr of type string, the address of a remote daemon, e.g. udp://logs.example.com:514,
+// the local daemon is used if it's empty. Every write becomes a separate syslog message tagged wsget.
+// It returns the writer, or an error if the priority or the address is invalid or the daemon can't be reached.
+func NewSyslog(priority, addr string) (io.WriteCloser, error) {
+	prio, err := parseSyslogPriority(priority)
+	if err != nil {
+		return nil, err
+	}
+
+	var network, raddr string
+
+	if addr != "" {
+		u, err := url.Parse(addr)
+		if err != nil || u.Host == "" || (u.Scheme != "udp" && u.Scheme != "tcp") {
+			return nil, fmt.Errorf("invalid syslog address, expected udp://host:port or tcp://host:port: %s", addr)
+		}
+
+		network, raddr = u.Scheme, u.Host
+	}
+
+	w, err := syslog.Dial(network, raddr, prio, syslogTag)
+	if err != nil {
+		return nil, fmt.Errorf("fail to connect to syslog: %w", err)
+	}
+
+	return w, nil
+}
+
+// parseSyslogPriority parses the priority given as facility.severity, e.g. local0.info.
+// It returns the syslog priority or an error if the facility or the severity is unknown.
+func parseSyslogPriority(priority string) (syslog.Priority, error) {
+	facilityName, severityName, _ := strings.Cut(priority, ".")
+
+	facility, ok := syslogFacilities[facilityName]
+	if !ok {
+		return 0, fmt.Errorf("unknown syslog facility: %s", facilityName)
+	}
+
+	severity, ok := syslogSeverities[severityName]
+	if !ok {
+		return 0, fmt.Errorf("unknown syslog severity: %s", severityName)
+	}
+
+	return facility | severity, nil
+}
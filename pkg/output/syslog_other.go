@@ -0,0 +1,13 @@
+//go:build windows || plan9
+
+package output
+
+import (
+	"fmt"
+	"io"
+)
+
+// NewSyslog always fails, syslog is not available on this platform.
+func NewSyslog(_, _ string) (io.WriteCloser, error) {
+	return nil, fmt.Errorf("syslog output is not supported on this platform")
+}
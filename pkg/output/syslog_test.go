@@ -0,0 +1,63 @@
+//go:build !windows && !plan9
+
+package output
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSyslog(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer func() { _ = conn.Close() }()
+
+	w, err := NewSyslog("local0.notice", "udp://"+conn.LocalAddr().String())
+	require.NoError(t, err)
+
+	defer func() { _ = w.Close() }()
+
+	_, err = w.Write([]byte(`{"ping":1}` + "\n"))
+	require.NoError(t, err)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	buf := make([]byte, 1024)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+
+	packet := string(buf[:n])
+
+	assert.Regexp(t, `^<133>`, packet)
+	assert.Contains(t, packet, " wsget[")
+	assert.Contains(t, packet, `]: {"ping":1}`)
+}
+
+func TestNewSyslog_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		priority string
+		addr     string
+		wantErr  string
+	}{
+		{name: "unknown facility", priority: "local9.info", wantErr: "unknown syslog facility: local9"},
+		{name: "unknown severity", priority: "user.loud", wantErr: "unknown syslog severity: loud"},
+		{name: "missing severity", priority: "user", wantErr: "unknown syslog severity: "},
+		{name: "invalid address", priority: "user.info", addr: "localhost:514", wantErr: "invalid syslog address"},
+		{name: "unsupported network", priority: "user.info", addr: "http://localhost:514", wantErr: "invalid syslog address"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := NewSyslog(tt.priority, tt.addr)
+
+			assert.Nil(t, w)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
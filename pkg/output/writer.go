@@ -0,0 +1,110 @@
+package output
+
+import (
+	"io"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+)
+
+const esc = 0x1b
+
+type escState uint8
+
+const (
+	stateText escState = iota
+	stateEsc
+	stateCSI
+	stateOSC
+)
+
+// StripWriter is an io.Writer that removes ANSI escape sequences before writing to the underlying writer.
+// It keeps the parsing state between writes, so sequences split across several writes are removed as well.
+type StripWriter struct {
+	w     io.Writer
+	state escState
+}
+
+// New returns the writer that should be used for the terminal output.
+// It takes w of type io.Writer, which is the destination of the output, and forceColor of type bool.
+// It returns w unchanged if it is a terminal or forceColor is set, otherwise it returns a StripWriter wrapping w.
+// When forceColor is set, colors are enabled even if the output is not a terminal.
+func New(w io.Writer, forceColor bool) io.Writer {
+	if forceColor {
+		color.NoColor = false
+		return w
+	}
+
+	if IsTerminal(w) {
+		return w
+	}
+
+	return NewStripWriter(w)
+}
+
+// IsTerminal reports whether the provided writer is connected to a terminal.
+// It takes w of type io.Writer and returns true only for files that are terminals.
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// NewStripWriter creates a new StripWriter that writes to w.
+// It takes w of type io.Writer, which receives the output without escape sequences.
+// It returns a pointer to the created StripWriter.
+func NewStripWriter(w io.Writer) *StripWriter {
+	return &StripWriter{w: w}
+}
+
+// Write removes ANSI escape sequences from p and writes the rest to the underlying writer.
+// It takes p of type []byte with the data to write.
+// It returns len(p) if the underlying write succeeds, and an error if writing to the underlying writer fails.
+func (s *StripWriter) Write(p []byte) (int, error) {
+	out := make([]byte, 0, len(p))
+
+	for _, b := range p {
+		switch s.state {
+		case stateText:
+			if b == esc {
+				s.state = stateEsc
+				continue
+			}
+
+			out = append(out, b)
+		case stateEsc:
+			switch b {
+			case '[':
+				s.state = stateCSI
+			case ']':
+				s.state = stateOSC
+			default:
+				s.state = stateText
+			}
+		case stateCSI:
+			if b >= 0x40 && b <= 0x7e {
+				s.state = stateText
+			}
+		case stateOSC:
+			if b == '\a' {
+				s.state = stateText
+			} else if b == esc {
+				s.state = stateEsc
+			}
+		}
+	}
+
+	if len(out) == 0 {
+		return len(p), nil
+	}
+
+	if _, err := s.w.Write(out); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
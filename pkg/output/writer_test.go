@@ -0,0 +1,126 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/ksysoev/wsget/pkg/core"
+	"github.com/ksysoev/wsget/pkg/core/edit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStripWriter_Write(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   string
+		writes []string
+	}{
+		{
+			name:   "plain text",
+			writes: []string{"hello world\n"},
+			want:   "hello world\n",
+		},
+		{
+			name:   "cursor sequences",
+			writes: []string{core.HideCursor + "text" + core.ShowCursor + edit.LineUp + edit.LineClear + "\r"},
+			want:   "text\r",
+		},
+		{
+			name:   "clear terminal",
+			writes: []string{core.ClearTerminal + core.WelcomMessage},
+			want:   core.WelcomMessage,
+		},
+		{
+			name:   "color sequences",
+			writes: []string{"\x1b[31m<-\x1b[0m\n", "\x1b[1;36mresponse\x1b[0m"},
+			want:   "<-\nresponse",
+		},
+		{
+			name:   "sequence split across writes",
+			writes: []string{"a\x1b", "[3", "2mb\x1b[0", "m"},
+			want:   "ab",
+		},
+		{
+			name:   "osc sequence",
+			writes: []string{"\x1b]0;title\ab\x1b]0;title\x1b\\c"},
+			want:   "bc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			w := NewStripWriter(&buf)
+
+			for _, data := range tt.writes {
+				n, err := w.Write([]byte(data))
+
+				require.NoError(t, err)
+				assert.Equal(t, len(data), n)
+			}
+
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestStripWriter_Write_Error(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	n, err := NewStripWriter(f).Write([]byte("data"))
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestNew(t *testing.T) {
+	noColor := color.NoColor
+
+	t.Cleanup(func() { color.NoColor = noColor })
+
+	var buf bytes.Buffer
+
+	assert.IsType(t, &StripWriter{}, New(&buf, false))
+	assert.Equal(t, &buf, New(&buf, true))
+	assert.False(t, color.NoColor)
+}
+
+func TestIsTerminal(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	require.NoError(t, err)
+
+	defer func() { _ = f.Close() }()
+
+	assert.False(t, IsTerminal(&bytes.Buffer{}))
+	assert.False(t, IsTerminal(f))
+}
+
+func TestStripWriter_Editor(t *testing.T) {
+	var buf bytes.Buffer
+
+	input := make(chan core.KeyEvent, 3)
+	input <- core.KeyEvent{Rune: 'h'}
+	input <- core.KeyEvent{Rune: 'i'}
+	input <- core.KeyEvent{Key: core.KeyCtrlS}
+
+	history := edit.NewMockHistoryRepo(t)
+	history.EXPECT().ResetPosition()
+	history.EXPECT().AddRequest("hi")
+
+	editor := edit.NewMultiMode(NewStripWriter(&buf), history, history)
+	editor.SetInput(input)
+
+	req, err := editor.Edit(context.Background(), "")
+
+	require.NoError(t, err)
+	assert.Equal(t, "hi", req)
+	assert.NotContains(t, buf.String(), "\x1b")
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the project config file discovered from the working directory upward.
+const FileName = ".wsget.yaml"
+
+// Config holds per-project defaults for the connection and the session.
+// Values from the config are used only for options that are not set with command-line flags.
+type Config struct {
+	URL      string   `yaml:"url"`
+	MacroDir string   `yaml:"macro_dir"`
+	Format   string   `yaml:"format"`
+	UTF8     string   `yaml:"utf8"`
+	Path     string   `yaml:"-"`
+	Headers  []string `yaml:"headers"`
+}
+
+// Load reads the project config from the file at path.
+// The macro directory can be a list of directories separated by the OS path list separator, e.g. org:personal,
+// relative directories are resolved against the directory of the config file.
+// It returns an error if the file can't be read or isn't a valid YAML document.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("fail to parse config %s: %w", path, err)
+	}
+
+	cfg.Path = path
+
+	if cfg.MacroDir != "" {
+		dirs := filepath.SplitList(cfg.MacroDir)
+
+		for i, dir := range dirs {
+			if !filepath.IsAbs(dir) {
+				dirs[i] = filepath.Join(filepath.Dir(path), dir)
+			}
+		}
+
+		cfg.MacroDir = strings.Join(dirs, string(os.PathListSeparator))
+	}
+
+	return cfg, nil
+}
+
+// Discover looks for the project config file in dir and its parent directories, the nearest file wins.
+// It takes dir of type string to start from and stopDir of type string, the last directory to check,
+// usually the home directory. The search also stops at the filesystem root.
+// It returns nil without an error if no config file is found, or an error if the found file can't be loaded.
+func Discover(dir, stopDir string) (*Config, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if stopDir != "" {
+		if stopDir, err = filepath.Abs(stopDir); err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		cfg, err := Load(filepath.Join(dir, FileName))
+		if err == nil {
+			return cfg, nil
+		}
+
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+
+		parent := filepath.Dir(dir)
+		if dir == stopDir || parent == dir {
+			return nil, nil
+		}
+
+		dir = parent
+	}
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, dir, content string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, FileName), []byte(content), 0o600))
+}
+
+func TestDiscover(t *testing.T) {
+	home := t.TempDir()
+	project := filepath.Join(home, "project")
+	service := filepath.Join(project, "service")
+	nested := filepath.Join(service, "api", "v1")
+
+	writeConfig(t, project, `
+url: wss://project.example.com
+headers: ["X-Project: 1"]
+`)
+	writeConfig(t, service, `
+url: wss://service.example.com
+macro_dir: macros
+format: json
+utf8: escape
+headers: ["Authorization: Bearer ${token}"]
+`)
+	require.NoError(t, os.MkdirAll(nested, 0o755))
+
+	tests := []struct {
+		want *Config
+		name string
+		dir  string
+	}{
+		{
+			name: "nearest config in parent directory",
+			dir:  nested,
+			want: &Config{
+				URL:      "wss://service.example.com",
+				MacroDir: filepath.Join(service, "macros"),
+				Format:   "json",
+				UTF8:     "escape",
+				Headers:  []string{"Authorization: Bearer ${token}"},
+				Path:     filepath.Join(service, FileName),
+			},
+		},
+		{
+			name: "config in current directory",
+			dir:  project,
+			want: &Config{
+				URL:     "wss://project.example.com",
+				Headers: []string{"X-Project: 1"},
+				Path:    filepath.Join(project, FileName),
+			},
+		},
+		{
+			name: "no config up to stop directory",
+			dir:  home,
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Discover(tt.dir, home)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cfg)
+		})
+	}
+}
+
+func TestDiscover_StopsAtStopDir(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+
+	writeConfig(t, root, "url: wss://outside.example.com")
+	require.NoError(t, os.MkdirAll(filepath.Join(home, "project"), 0o755))
+
+	cfg, err := Discover(filepath.Join(home, "project"), home)
+
+	require.NoError(t, err)
+	assert.Nil(t, cfg)
+}
+
+func TestDiscover_InvalidConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	writeConfig(t, dir, "url: : invalid")
+
+	cfg, err := Discover(dir, dir)
+
+	assert.ErrorContains(t, err, "fail to parse config")
+	assert.Nil(t, cfg)
+}
+
+func TestLoad_MacroDirList(t *testing.T) {
+	dir := t.TempDir()
+	orgDir := filepath.Join(t.TempDir(), "org")
+
+	writeConfig(t, dir, "macro_dir: "+orgDir+string(os.PathListSeparator)+"macro")
+
+	cfg, err := Load(filepath.Join(dir, FileName))
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{orgDir, filepath.Join(dir, "macro")}, filepath.SplitList(cfg.MacroDir))
+}
+
+func TestLoad_AbsoluteMacroDir(t *testing.T) {
+	dir := t.TempDir()
+	macroDir := filepath.Join(t.TempDir(), "macro")
+
+	writeConfig(t, dir, "macro_dir: "+macroDir)
+
+	cfg, err := Load(filepath.Join(dir, FileName))
+
+	require.NoError(t, err)
+	assert.Equal(t, macroDir, cfg.MacroDir)
+}
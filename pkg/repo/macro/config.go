@@ -1,6 +1,7 @@
 package macro
 
 import (
+	"errors"
 	"fmt"
 	"io"
 
@@ -8,22 +9,22 @@ import (
 )
 
 // config represents the configuration structure used for YAML parsing and validation.
-// It contains fields for the version, source file, macros, and associated domains.
+// It contains fields for the version, source file, macros, their optional labels and timeouts, and associated domains.
 type config struct {
-	Version string              `yaml:"version"`
-	Source  string              `yaml:"source,omitempty"`
-	Macro   map[string][]string `yaml:"macro"`
-	Domains []string            `yaml:"domains"`
+	Version  string              `yaml:"version"`
+	Source   string              `yaml:"source,omitempty"`
+	Macro    map[string][]string `yaml:"macro"`
+	Labels   map[string]string   `yaml:"labels,omitempty"`
+	Timeouts map[string]string   `yaml:"timeouts,omitempty"`
+	Domains  []string            `yaml:"domains"`
 }
 
 // newConfig creates and initializes a new config object from the provided YAML input.
 // It takes src of type io.Reader which contains the YAML configuration data.
 // It returns a pointer to a config instance and an error if the decoding or validation of the configuration fails.
 func newConfig(src io.Reader) (*config, error) {
-	var cfg *config
-
-	decoder := yaml.NewDecoder(src)
-	if err := decoder.Decode(&cfg); err != nil {
+	cfg, err := decodeConfig(src)
+	if err != nil {
 		return nil, err
 	}
 
@@ -34,6 +35,20 @@ func newConfig(src io.Reader) (*config, error) {
 	return cfg, nil
 }
 
+// decodeConfig decodes the YAML configuration data without validating it.
+// It takes src of type io.Reader which contains the YAML configuration data.
+// It returns a pointer to a config instance, empty if src has no data, and an error if the decoding fails.
+func decodeConfig(src io.Reader) (*config, error) {
+	var cfg config
+
+	decoder := yaml.NewDecoder(src)
+	if err := decoder.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
 // SetSource sets the Source field of the config struct to the provided string value.
 // It takes source of type string as input and updates the Source field of the receiver.
 // It does not return any values and does not perform validation on the input.
@@ -54,25 +69,49 @@ func (c *config) CreateRepo() (*Repo, error) {
 		}
 	}
 
+	for name, label := range c.Labels {
+		if err := repo.SetLabel(name, label); err != nil {
+			return nil, fmt.Errorf("fail to add macro label: %w", err)
+		}
+	}
+
+	for name, timeout := range c.Timeouts {
+		if err := repo.setRawTimeout(name, timeout); err != nil {
+			return nil, fmt.Errorf("fail to add macro timeout: %w", err)
+		}
+	}
+
 	return repo, nil
 }
 
 // validate ensures that the config structure is properly initialized and contains valid data.
 // It returns an error if the Version is unsupported, Domains are empty, or Macro commands are missing.
 func (c *config) validate() error {
+	if problems := c.problems(); len(problems) > 0 {
+		return problems[0]
+	}
+
+	return nil
+}
+
+// problems checks the config the same way as validate, but doesn't stop at the first problem.
+// It returns a slice of errors for the unsupported Version, empty Domains and missing Macro commands.
+func (c *config) problems() []error {
+	var errs []error
+
 	if c.Version != "1" {
-		return fmt.Errorf("unsupported macro version: %s", c.Version)
+		errs = append(errs, fmt.Errorf("unsupported macro version: %s", c.Version))
 	}
 
 	if len(c.Domains) == 0 {
-		return fmt.Errorf("domains are required")
+		errs = append(errs, fmt.Errorf("domains are required"))
 	}
 
 	if len(c.Macro) == 0 {
-		return fmt.Errorf("macro commands are required")
+		errs = append(errs, fmt.Errorf("macro commands are required"))
 	}
 
-	return nil
+	return errs
 }
 
 // Write encodes the config structure in YAML format and writes it to the provided io.Writer.
@@ -2,9 +2,11 @@ package macro
 
 import (
 	"fmt"
-	"log"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/ksysoev/wsget/pkg/core"
 	"github.com/ksysoev/wsget/pkg/core/command"
@@ -50,7 +52,53 @@ func (m *Repo) AddCommands(name string, rawCommands []string) error {
 	return nil
 }
 
+// SetLabel sets the label printed before the steps of the named macro run.
+// It takes name of type string, the name of an added macro, and label of type string, a template with the macro arguments.
+// It returns an error if the macro doesn't exist or the label template fails to parse.
+func (m *Repo) SetLabel(name, label string) error {
+	tmpls, ok := m.macro[name]
+	if !ok {
+		return fmt.Errorf("label for unknown macro: %s", name)
+	}
+
+	if err := tmpls.SetLabel(label); err != nil {
+		return fmt.Errorf("invalid label of macro %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// SetTimeout sets the time the named macro run is allowed to take, the run is aborted once it's exceeded.
+// It takes name of type string, the name of an added macro, and timeout of type time.Duration.
+// It returns an error if the macro doesn't exist or the timeout is not positive.
+func (m *Repo) SetTimeout(name string, timeout time.Duration) error {
+	tmpls, ok := m.macro[name]
+	if !ok {
+		return fmt.Errorf("timeout for unknown macro: %s", name)
+	}
+
+	if timeout <= 0 {
+		return fmt.Errorf("invalid timeout of macro %s: %s, it must be positive", name, timeout)
+	}
+
+	tmpls.SetTimeout(timeout)
+
+	return nil
+}
+
+// setRawTimeout sets the timeout of the named macro from its config form, a duration like 30s or 1m30s.
+// It returns an error if the duration can't be parsed or the timeout can't be set.
+func (m *Repo) setRawTimeout(name, rawTimeout string) error {
+	timeout, err := time.ParseDuration(rawTimeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout of macro %s: %w", name, err)
+	}
+
+	return m.SetTimeout(name, timeout)
+}
+
 // merge merges the given macro into the current macro.
+// The domains of the given macro are added to the current ones, so the merged set can be exported as a whole.
 // If a macro with the same name already exists, an error is returned.
 func (m *Repo) merge(macro *Repo) error {
 	for name, cmd := range macro.macro {
@@ -61,17 +109,76 @@ func (m *Repo) merge(macro *Repo) error {
 		m.macro[name] = cmd
 	}
 
+	for _, domain := range macro.domains {
+		if !slices.Contains(m.domains, domain) {
+			m.domains = append(m.domains, domain)
+		}
+	}
+
 	return nil
 }
 
 // Get returns the Executer associated with the given name, or an error if the name is not found.
+// Arguments in argString are separated by spaces, an argument wrapped in single quotes keeps its spaces.
+// Steps of the macro can call other macros by name with arguments, they are expanded when the executer is built.
+// It returns command.ErrMacroCycle if the macro calls itself directly or through other macros.
 func (m *Repo) Get(name, argString string) (core.Executer, error) {
-	if cmd, ok := m.macro[name]; ok {
-		args := strings.Fields(argString)
-		return cmd.GetExecuter(args)
+	return m.resolve(name, argString, nil)
+}
+
+// resolve builds the executer of the macro called from the macros in stack.
+// If the macro has a timeout, the executer runs it within the timeout.
+// It returns command.ErrMacroCycle if the macro is already in the stack.
+func (m *Repo) resolve(name, argString string, stack []string) (core.Executer, error) {
+	cmd, ok := m.macro[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown command: %s", name)
+	}
+
+	stack = append(slices.Clip(stack), name)
+
+	if slices.Contains(stack[:len(stack)-1], name) {
+		return nil, &command.ErrMacroCycle{Path: stack}
 	}
 
-	return nil, fmt.Errorf("unknown command: %s", name)
+	args, err := command.SplitArgs(argString)
+	if err != nil {
+		return nil, fmt.Errorf("invalid arguments of macro %s: %w", name, err)
+	}
+
+	exec, err := cmd.GetExecuter(args, &call{repo: m, stack: stack})
+	if err != nil || cmd.Timeout() == 0 {
+		return exec, err
+	}
+
+	return command.NewTimeLimit(name, cmd.Timeout(), exec), nil
+}
+
+// calledFrom returns the macro repository as seen from the steps of the named macro.
+func (m *Repo) calledFrom(name string) *call {
+	return &call{repo: m, stack: []string{name}}
+}
+
+// call is the macro repository seen from the steps of a macro being built.
+// It keeps the chain of macro calls, so recursion is detected instead of overflowing the stack.
+type call struct {
+	repo  *Repo
+	stack []string
+}
+
+// Get builds the executer of the macro called from the current macro.
+func (c *call) Get(name, argString string) (core.Executer, error) {
+	return c.repo.resolve(name, argString, c.stack)
+}
+
+// GetNames returns the names of all macros in the repository.
+func (c *call) GetNames() []string {
+	return c.repo.GetNames()
+}
+
+// Export writes the macros of the repository to the file at path.
+func (c *call) Export(path string) error {
+	return c.repo.Export(path)
 }
 
 // GetNames returns a list of all macro names stored in the Repo instance.
@@ -87,6 +194,54 @@ func (m *Repo) GetNames() []string {
 	return names
 }
 
+// Export writes the macros with their raw commands and domains to a file at the given path.
+// The file is written in the version 1 macro config format, so it can be loaded back with LoadFromFile.
+// It returns an error if the file can't be created or written.
+func (m *Repo) Export(path string) (err error) {
+	cfg := &config{
+		Version: "1",
+		Domains: m.domains,
+		Macro:   make(map[string][]string, len(m.macro)),
+	}
+
+	for name, tmpls := range m.macro {
+		cfg.Macro[name] = tmpls.Raw()
+
+		if label := tmpls.Label(); label != "" {
+			if cfg.Labels == nil {
+				cfg.Labels = make(map[string]string)
+			}
+
+			cfg.Labels[name] = label
+		}
+
+		if timeout := tmpls.Timeout(); timeout > 0 {
+			if cfg.Timeouts == nil {
+				cfg.Timeouts = make(map[string]string)
+			}
+
+			cfg.Timeouts[name] = timeout.String()
+		}
+	}
+
+	if err := cfg.validate(); err != nil {
+		return fmt.Errorf("fail to export macro: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("fail to create macro file %s: %w", path, err)
+	}
+
+	defer func() {
+		if e := file.Close(); err == nil && e != nil {
+			err = fmt.Errorf("fail to close macro file %s: %w", path, e)
+		}
+	}()
+
+	return cfg.Write(file)
+}
+
 // LoadFromFile loads a macro configuration from a file at the given path.
 // It returns a Repo instance and an error if the file cannot be read or parsed.
 func LoadFromFile(path string) (r *Repo, err error) {
@@ -109,53 +264,72 @@ func LoadFromFile(path string) (r *Repo, err error) {
 	return cfg.CreateRepo()
 }
 
-// LoadMacroForDomain loads and merges macros for a specific domain from YAML files in a given directory.
-// It takes macroDir, a string specifying the directory path, and domain, a string specifying the target domain.
+// LoadMacroForDomain loads and merges macros for a specific domain from YAML files in the given directories.
+// It takes macroDirs, the directory paths in the order they are layered, e.g. org-wide macros before personal ones,
+// and domain, a string specifying the target domain.
 // It returns a pointer to a Repo containing merged macros for the domain, or an error in case of failure.
-// Errors may occur if the directory cannot be read, files cannot be parsed, or macros fail to merge.
+// Errors may occur if a directory cannot be read, files cannot be parsed, or macros fail to merge.
+// A macro defined in more than one file is a conflict, the error names both files.
 // Ignores non-YAML files, directories, and files without a matching domain.
-func LoadMacroForDomain(macroDir, domain string) (*Repo, error) {
-	files, err := os.ReadDir(macroDir)
-	if err != nil {
-		log.Fatal(err)
-	}
-
+func LoadMacroForDomain(macroDirs []string, domain string) (*Repo, error) {
 	var macro *Repo
 
-	for _, file := range files {
-		if file.IsDir() || (!strings.HasSuffix(file.Name(), ".yaml") && !strings.HasSuffix(file.Name(), ".yml")) {
-			continue
-		}
-
-		fileMacro, err := LoadFromFile(macroDir + "/" + file.Name())
+	origin := make(map[string]string)
 
+	for _, macroDir := range macroDirs {
+		files, err := os.ReadDir(macroDir)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("fail to read macro directory: %w", err)
 		}
 
-		hasDomain := false
+		for _, file := range files {
+			if file.IsDir() || (!strings.HasSuffix(file.Name(), ".yaml") && !strings.HasSuffix(file.Name(), ".yml")) {
+				continue
+			}
 
-		for _, fileDomain := range fileMacro.domains {
-			if strings.HasSuffix(domain, fileDomain) {
-				hasDomain = true
-				break
+			path := filepath.Join(macroDir, file.Name())
+
+			fileMacro, err := LoadFromFile(path)
+			if err != nil {
+				return nil, err
 			}
-		}
 
-		if !hasDomain {
-			continue
-		}
+			if !fileMacro.matchDomain(domain) {
+				continue
+			}
 
-		if macro == nil {
-			macro = fileMacro
-		} else {
-			err := macro.merge(fileMacro)
+			names := fileMacro.GetNames()
+			slices.Sort(names)
 
-			if err != nil {
-				return nil, fmt.Errorf("fail to loading macro from file %s, %w ", file.Name(), err)
+			for _, name := range names {
+				if defined, ok := origin[name]; ok {
+					return nil, fmt.Errorf("duplicate macro %s in %s, it's already defined in %s", name, path, defined)
+				}
+
+				origin[name] = path
+			}
+
+			if macro == nil {
+				macro = fileMacro
+				continue
+			}
+
+			if err := macro.merge(fileMacro); err != nil {
+				return nil, fmt.Errorf("fail to loading macro from file %s, %w ", path, err)
 			}
 		}
 	}
 
 	return macro, nil
 }
+
+// matchDomain reports whether the macros are meant for the domain, i.e. it ends with one of their domains.
+func (m *Repo) matchDomain(domain string) bool {
+	for _, macroDomain := range m.domains {
+		if strings.HasSuffix(domain, macroDomain) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,8 +1,12 @@
 package macro
 
 import (
+	"context"
 	"os"
+	"path/filepath"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/ksysoev/wsget/pkg/core"
 	"github.com/ksysoev/wsget/pkg/core/command"
@@ -259,6 +263,142 @@ func TestMacro_Get(t *testing.T) {
 		})
 	}
 }
+func TestMacro_Get_CallsMacro(t *testing.T) {
+	repo := New([]string{"example.com"})
+
+	require.NoError(t, repo.AddCommands("inner", []string{"send {{index .Args 0}}"}))
+	require.NoError(t, repo.AddCommands("outer", []string{"inner {{index .Args 0}}"}))
+
+	cmd, err := repo.Get("outer", "ping")
+
+	require.NoError(t, err)
+	assert.Equal(t, command.NewSend("ping"), cmd)
+}
+
+func TestMacro_Timeout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "macro.yaml")
+
+	require.NoError(t, os.WriteFile(path, []byte(`
+version: "1"
+domains: ["example.com"]
+macro:
+  slow:
+    - wait
+  fast:
+    - send ping
+timeouts:
+  slow: 50ms
+`), 0o600))
+
+	repo, err := LoadFromFile(path)
+	require.NoError(t, err)
+
+	fast, err := repo.Get("fast", "")
+	require.NoError(t, err)
+	assert.Equal(t, command.NewSend("ping"), fast)
+
+	slow, err := repo.Get("slow", "")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().LimitTime(50 * time.Millisecond).RunAndReturn(func(timeout time.Duration) func() {
+		parent := ctx
+
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(parent, timeout)
+
+		return func() {
+			cancel()
+			ctx = parent
+		}
+	})
+	exCtx.EXPECT().Context().RunAndReturn(func() context.Context { return ctx })
+	exCtx.EXPECT().WaitForResponse(time.Duration(0)).RunAndReturn(func(time.Duration) (core.Message, error) {
+		<-ctx.Done()
+		return core.Message{}, ctx.Err()
+	})
+
+	start := time.Now()
+	_, err = slow.Execute(exCtx)
+
+	assert.ErrorIs(t, err, core.ErrAborted)
+	assert.EqualError(t, err, "aborted: macro slow exceeded its timeout of 50ms")
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	assert.NoError(t, ctx.Err(), "the context should be restored")
+
+	assert.EqualError(t, repo.SetTimeout("missing", time.Second), "timeout for unknown macro: missing")
+	assert.EqualError(t, repo.SetTimeout("fast", 0), "invalid timeout of macro fast: 0s, it must be positive")
+}
+
+func TestMacro_SetLabel(t *testing.T) {
+	repo := New([]string{"example.com"})
+
+	require.NoError(t, repo.AddCommands("login", []string{"send {{index .Args 0}}"}))
+	require.NoError(t, repo.SetLabel("login", "login as {{index .Args 0}}"))
+
+	cmd, err := repo.Get("login", "alice")
+
+	require.NoError(t, err)
+	assert.Equal(t, command.NewSequence([]core.Executer{command.NewMacroLabel("login as alice"), command.NewSend("alice")}), cmd)
+
+	assert.EqualError(t, repo.SetLabel("logout", "logout"), "label for unknown macro: logout")
+	assert.ErrorContains(t, repo.SetLabel("login", "{{.Args"), "invalid label of macro login")
+}
+
+func TestMacro_Get_QuotedArgs(t *testing.T) {
+	repo := New([]string{"example.com"})
+
+	require.NoError(t, repo.AddCommands("greet", []string{`send {"text":"{{index .Args 0}}","to":"{{index .Args 1}}"}`}))
+
+	cmd, err := repo.Get("greet", "'hello world' bob")
+
+	require.NoError(t, err)
+	assert.Equal(t, command.NewSend(`{"text":"hello world","to":"bob"}`), cmd)
+
+	_, err = repo.Get("greet", "'hello world bob")
+
+	assert.ErrorContains(t, err, "invalid arguments of macro greet")
+}
+
+func TestMacro_Get_Cycle(t *testing.T) {
+	tests := []struct {
+		macros  map[string][]string
+		name    string
+		wantErr string
+	}{
+		{
+			name:    "direct recursion",
+			macros:  map[string][]string{"a": {"a"}},
+			wantErr: "macro recursion detected: a -> a",
+		},
+		{
+			name:    "indirect recursion",
+			macros:  map[string][]string{"a": {"send ping", "b"}, "b": {"a"}},
+			wantErr: "macro recursion detected: a -> b -> a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := New([]string{"example.com"})
+
+			for name, steps := range tt.macros {
+				require.NoError(t, repo.AddCommands(name, steps))
+			}
+
+			_, err := repo.Get("a", "")
+
+			var cycleErr *command.ErrMacroCycle
+
+			assert.ErrorAs(t, err, &cycleErr)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
 func TestLoadFromFile(t *testing.T) {
 	macroDir := os.TempDir()
 	domain := "example.com"
@@ -539,7 +679,7 @@ macro:
 				tt.setup(macroDir)
 			}
 
-			got, err := LoadMacroForDomain(macroDir, tt.domain)
+			got, err := LoadMacroForDomain([]string{macroDir}, tt.domain)
 
 			if tt.expectedErr == "" {
 				assert.NoError(t, err)
@@ -556,3 +696,120 @@ macro:
 		})
 	}
 }
+
+func TestMacro_LoadMacroForDomain_MultipleDirs(t *testing.T) {
+	orgDir, personalDir := t.TempDir(), t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(orgDir, "org.yaml"), []byte(`
+version: 1
+domains:
+  - example.com
+macro:
+  login:
+    - send login
+`), 0o600))
+
+	require.NoError(t, os.WriteFile(filepath.Join(personalDir, "mine.yaml"), []byte(`
+version: 1
+domains:
+  - api.example.com
+macro:
+  ping:
+    - send ping
+`), 0o600))
+
+	got, err := LoadMacroForDomain([]string{orgDir, personalDir}, "api.example.com")
+	require.NoError(t, err)
+
+	names := got.GetNames()
+	slices.Sort(names)
+	assert.Equal(t, []string{"login", "ping"}, names)
+
+	conflict := filepath.Join(personalDir, "login.yaml")
+	require.NoError(t, os.WriteFile(conflict, []byte(`
+version: 1
+domains:
+  - example.com
+macro:
+  login:
+    - send my login
+`), 0o600))
+
+	_, err = LoadMacroForDomain([]string{orgDir, personalDir}, "api.example.com")
+	assert.EqualError(t, err,
+		"duplicate macro login in "+conflict+", it's already defined in "+filepath.Join(orgDir, "org.yaml"))
+
+	_, err = LoadMacroForDomain([]string{orgDir, filepath.Join(orgDir, "missing")}, "api.example.com")
+	assert.ErrorContains(t, err, "fail to read macro directory")
+}
+
+func TestMacro_Export_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "source.yaml")
+
+	require.NoError(t, os.WriteFile(src, []byte(`
+version: 1
+domains:
+  - example.com
+macro:
+  ping:
+    - 'send {"ping": 1}'
+    - wait 5
+  echo:
+    - "send {{index .Args 0}}"
+labels:
+  echo: "echo {{index .Args 0}}"
+timeouts:
+  ping: 1m30s
+`), 0o600))
+
+	loaded, err := LoadFromFile(src)
+	require.NoError(t, err)
+
+	exported := filepath.Join(dir, "exported.yaml")
+	require.NoError(t, loaded.Export(exported))
+
+	reloaded, err := LoadFromFile(exported)
+	require.NoError(t, err)
+
+	assert.Equal(t, loaded.domains, reloaded.domains)
+	assert.ElementsMatch(t, loaded.GetNames(), reloaded.GetNames())
+
+	for name, tmpls := range loaded.macro {
+		assert.Equal(t, tmpls.Raw(), reloaded.macro[name].Raw(), name)
+		assert.Equal(t, tmpls.Label(), reloaded.macro[name].Label(), name)
+		assert.Equal(t, tmpls.Timeout(), reloaded.macro[name].Timeout(), name)
+	}
+
+	want, err := loaded.Get("echo", "hello")
+	require.NoError(t, err)
+
+	got, err := reloaded.Get("echo", "hello")
+	require.NoError(t, err)
+
+	assert.Equal(t, want, got)
+}
+
+func TestMacro_Export_MergedDomains(t *testing.T) {
+	repo := New([]string{"example.com"})
+	require.NoError(t, repo.AddCommands("ping", []string{"send ping"}))
+
+	other := New([]string{"api.example.com"})
+	require.NoError(t, other.AddCommands("pong", []string{"send pong"}))
+	require.NoError(t, repo.merge(other))
+
+	path := filepath.Join(t.TempDir(), "macro.yaml")
+	require.NoError(t, repo.Export(path))
+
+	reloaded, err := LoadFromFile(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"example.com", "api.example.com"}, reloaded.domains)
+	assert.ElementsMatch(t, []string{"ping", "pong"}, reloaded.GetNames())
+}
+
+func TestMacro_Export_Empty(t *testing.T) {
+	err := New([]string{"example.com"}).Export(filepath.Join(t.TempDir(), "macro.yaml"))
+
+	assert.ErrorContains(t, err, "fail to export macro: macro commands are required")
+}
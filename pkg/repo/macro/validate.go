@@ -0,0 +1,224 @@
+package macro
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/ksysoev/wsget/pkg/core/command"
+)
+
+// Validate checks the macro configuration file at the given path without loading it into a repository.
+// It takes path of type string, which is the location of the macro file.
+// It returns a slice of warnings for fields that have no effect and an error joining every problem found in the file.
+// Each error carries the file, macro and step context, so all problems are reported at once instead of only the first one.
+func Validate(path string) (warnings []string, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("fail to open macro file %s: %w", path, err)
+	}
+
+	defer func() { _ = file.Close() }()
+
+	cfg, err := decodeConfig(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var errs []error
+
+	for _, err := range cfg.problems() {
+		errs = append(errs, fmt.Errorf("%s: %w", path, err))
+	}
+
+	warnings = append(warnings, cfg.unusedFieldWarnings(path)...)
+
+	names := make([]string, 0, len(cfg.Macro))
+	repo := New(cfg.Domains)
+
+	for name, rawCommands := range cfg.Macro {
+		names = append(names, name)
+
+		// Invalid macros are reported by validateStep, the repo is only used to resolve calls between macros.
+		_ = repo.AddCommands(name, rawCommands)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		rawCommands := cfg.Macro[name]
+		if len(rawCommands) == 0 {
+			errs = append(errs, fmt.Errorf("%s: macro %q: empty macro", path, name))
+			continue
+		}
+
+		for i, rawCommand := range rawCommands {
+			if err := validateStep(rawCommand, repo.calledFrom(name)); err != nil {
+				errs = append(errs, fmt.Errorf("%s: macro %q step %d: %w", path, name, i+1, err))
+			}
+		}
+	}
+
+	labels := make([]string, 0, len(cfg.Labels))
+	for name := range cfg.Labels {
+		labels = append(labels, name)
+	}
+
+	sort.Strings(labels)
+
+	for _, name := range labels {
+		if err := repo.SetLabel(name, cfg.Labels[name]); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+	}
+
+	timeouts := make([]string, 0, len(cfg.Timeouts))
+	for name := range cfg.Timeouts {
+		timeouts = append(timeouts, name)
+	}
+
+	sort.Strings(timeouts)
+
+	for _, name := range timeouts {
+		if err := repo.setRawTimeout(name, cfg.Timeouts[name]); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+	}
+
+	return warnings, errors.Join(errs...)
+}
+
+// validateStep checks a single macro step by parsing its template and building the command through the factory.
+// It takes rawCommand of type string, which is the raw template of the step,
+// and macro of type command.MacroRepo, used to resolve calls to other macros of the file.
+// It returns an error if the template cannot be parsed, refers to fields other than .Args, or the command is invalid.
+// The command of a step referring to .Args depends on the arguments of the macro call,
+// so only its template is checked, other steps are rendered and built through the factory.
+func validateStep(rawCommand string, macro command.MacroRepo) error {
+	tmpl, err := template.New("macro").Parse(rawCommand)
+	if err != nil {
+		return err
+	}
+
+	fields := make(map[string]bool)
+	collectFields(tmpl.Root, fields)
+
+	unknown := make([]string, 0, len(fields))
+
+	for name := range fields {
+		if name != "Args" {
+			unknown = append(unknown, "."+name)
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown template fields %s, only .Args is set", strings.Join(unknown, ", "))
+	}
+
+	if fields["Args"] {
+		return nil
+	}
+
+	macroTmpl, err := command.NewMacro([]string{rawCommand})
+	if err != nil {
+		return err
+	}
+
+	_, err = macroTmpl.GetExecuter(nil, macro)
+
+	return err
+}
+
+// collectFields walks the parsed template and records the fields of the template data it refers to, e.g. Args
+// for .Args or $.Args. The bodies of range and with are skipped, as the dot refers to other data in them.
+func collectFields(node parse.Node, fields map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+
+		for _, child := range n.Nodes {
+			collectFields(child, fields)
+		}
+	case *parse.ActionNode:
+		collectFields(n.Pipe, fields)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+
+		for _, cmd := range n.Cmds {
+			collectFields(cmd, fields)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			collectFields(arg, fields)
+		}
+	case *parse.ChainNode:
+		collectFields(n.Node, fields)
+	case *parse.FieldNode:
+		fields[n.Ident[0]] = true
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			fields[n.Ident[1]] = true
+		}
+	case *parse.IfNode:
+		collectFields(n.Pipe, fields)
+		collectFields(n.List, fields)
+		collectFields(n.ElseList, fields)
+	case *parse.RangeNode:
+		collectFields(n.Pipe, fields)
+		collectFields(n.ElseList, fields)
+	case *parse.WithNode:
+		collectFields(n.Pipe, fields)
+		collectFields(n.ElseList, fields)
+	case *parse.TemplateNode:
+		collectFields(n.Pipe, fields)
+	}
+}
+
+// unusedFieldWarnings reports fields of the config that are present but have no effect when macros are loaded.
+// It takes path of type string, which is used to prefix every warning with the file name.
+// It returns a slice of warning messages, empty if all fields are meaningful.
+func (c *config) unusedFieldWarnings(path string) []string {
+	var warnings []string
+
+	seen := make(map[string]bool, len(c.Domains))
+
+	for _, domain := range c.Domains {
+		switch {
+		case domain == "":
+			warnings = append(warnings, fmt.Sprintf("%s: empty domain matches every host", path))
+		case seen[domain]:
+			warnings = append(warnings, fmt.Sprintf("%s: domain %q is listed more than once", path, domain))
+		}
+
+		seen[domain] = true
+	}
+
+	for _, domain := range c.Domains {
+		for _, other := range c.Domains {
+			if other != "" && other != domain && strings.HasSuffix(domain, other) {
+				warnings = append(warnings, fmt.Sprintf("%s: domain %q is unused, hosts ending with it already match %q", path, domain, other))
+				break
+			}
+		}
+	}
+
+	if c.Source != "" {
+		if u, err := url.Parse(c.Source); err != nil || u.Scheme == "" || u.Host == "" {
+			warnings = append(warnings, fmt.Sprintf("%s: source %q is not a valid URL and can't be used to refresh the macro", path, c.Source))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("%s: source %q is unused, macros are loaded from the file only", path, c.Source))
+		}
+	}
+
+	return warnings
+}
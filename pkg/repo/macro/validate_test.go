@@ -0,0 +1,167 @@
+package macro
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate_ValidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "valid.yaml")
+
+	err := os.WriteFile(path, []byte(`
+version: "1"
+domains: ["example.com"]
+macro:
+  ping:
+    - 'send {"ping": 1}'
+    - wait 5
+  auth:
+    - 'send {"authorize": "{{index .Args 0}}"}'
+`), 0o600)
+	require.NoError(t, err)
+
+	warnings, err := Validate(path)
+
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+}
+
+func TestValidate_ReportsAllErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invalid.yaml")
+
+	err := os.WriteFile(path, []byte(`
+version: "2"
+source: not a url
+domains: ["example.com", "example.com", "", "api.example.com"]
+macro:
+  broken:
+    - 'send {"ping": 1}'
+    - wait abc
+    - unknown
+  template:
+    - 'send {{.Args'
+  exec:
+    - 'send {{.Name}} {{index .Args 0}}'
+  empty: []
+labels:
+  missing: missing
+  broken: '{{.Args'
+timeouts:
+  missing: 5s
+  broken: soon
+`), 0o600)
+	require.NoError(t, err)
+
+	warnings, err := Validate(path)
+
+	require.Error(t, err)
+	assert.ErrorContains(t, err, path+": unsupported macro version: 2")
+	assert.ErrorContains(t, err, path+`: macro "broken" step 2: invalid timeout: abc`)
+	assert.ErrorContains(t, err, path+`: macro "broken" step 3: unknown command: unknown`)
+	assert.ErrorContains(t, err, path+`: macro "template" step 1: template:`)
+	assert.ErrorContains(t, err, path+`: macro "exec" step 1: unknown template fields .Name, only .Args is set`)
+	assert.ErrorContains(t, err, path+`: macro "empty": empty macro`)
+	assert.ErrorContains(t, err, path+": label for unknown macro: missing")
+	assert.ErrorContains(t, err, path+": invalid label of macro broken")
+	assert.ErrorContains(t, err, path+": timeout for unknown macro: missing")
+	assert.ErrorContains(t, err, path+`: invalid timeout of macro broken: time: invalid duration "soon"`)
+
+	assert.Len(t, warnings, 4)
+	assert.Contains(t, warnings, path+`: domain "api.example.com" is unused, hosts ending with it already match "example.com"`)
+	assert.Contains(t, warnings, path+`: domain "example.com" is listed more than once`)
+	assert.Contains(t, warnings, path+": empty domain matches every host")
+	assert.Contains(t, warnings, path+`: source "not a url" is not a valid URL and can't be used to refresh the macro`)
+}
+
+func TestValidate_ManyArgs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "args.yaml")
+
+	err := os.WriteFile(path, []byte(`
+version: "1"
+domains: ["example.com"]
+macro:
+  order:
+    - 'send {"id": {{index .Args 10}}, "side": "{{index .Args 0}}"}'
+    - 'wait {{index .Args 1}}'
+    - '{{if .Args}}send {"args": {{len .Args}}}{{end}}'
+`), 0o600)
+	require.NoError(t, err)
+
+	warnings, err := Validate(path)
+
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+}
+
+func TestValidate_UnusedSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "source.yaml")
+
+	err := os.WriteFile(path, []byte(`
+version: "1"
+source: https://example.com/macro.yaml
+domains: ["example.com"]
+macro:
+  ping:
+    - 'send {"ping": 1}'
+`), 0o600)
+	require.NoError(t, err)
+
+	warnings, err := Validate(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{path + `: source "https://example.com/macro.yaml" is unused, macros are loaded from the file only`}, warnings)
+}
+
+func TestValidate_FileNotExists(t *testing.T) {
+	_, err := Validate(filepath.Join(t.TempDir(), "missing.yaml"))
+
+	assert.ErrorContains(t, err, "fail to open macro file")
+}
+
+func TestValidate_InvalidYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invalid.yaml")
+
+	require.NoError(t, os.WriteFile(path, []byte("key: : value"), 0o600))
+
+	_, err := Validate(path)
+
+	assert.ErrorContains(t, err, "yaml: mapping values are not allowed in this context")
+}
+
+func TestValidate_CallsBetweenMacros(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calls.yaml")
+
+	err := os.WriteFile(path, []byte(`
+version: "1"
+domains: ["example.com"]
+macro:
+  auth:
+    - 'send {"authorize": "{{index .Args 0}}"}'
+  login:
+    - auth token
+    - missing
+`), 0o600)
+	require.NoError(t, err)
+
+	_, err = Validate(path)
+
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), `step 1`)
+	assert.ErrorContains(t, err, path+`: macro "login" step 2: unknown command: missing`)
+}
+
+func TestValidate_EmptyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.yaml")
+
+	require.NoError(t, os.WriteFile(path, nil, 0o600))
+
+	_, err := Validate(path)
+
+	assert.ErrorContains(t, err, path+": unsupported macro version: ")
+	assert.ErrorContains(t, err, path+": domains are required")
+	assert.ErrorContains(t, err, path+": macro commands are required")
+}
@@ -0,0 +1,96 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+// closeHookTimeout is the time the close hook is given to finish, it's killed after that, so a hanging command
+// doesn't keep the connection from returning.
+const closeHookTimeout = 10 * time.Second
+
+// closeHook runs an external command when the connection is closed unexpectedly,
+// e.g. to send an alert when the server drops the connection.
+// The command runs at most once per session, later drops, e.g. after a reconnect, don't run it again.
+type closeHook struct {
+	run     func(ctx context.Context, command string, env []string) error
+	command string
+	timeout time.Duration
+	once    sync.Once
+}
+
+// newCloseHook creates the close hook from the connection options.
+// It takes command of type string, the command line run with the system shell.
+// It returns nil if the command is empty.
+func newCloseHook(command string) *closeHook {
+	if command == "" {
+		return nil
+	}
+
+	return &closeHook{command: command, run: runShellWithEnv, timeout: closeHookTimeout}
+}
+
+// closeEnv returns the environment variables describing why the connection was closed.
+// WSGET_CLOSE_CODE is the close code sent by the server, or 1006 if the connection was dropped without a close frame,
+// and WSGET_CLOSE_REASON is the close reason, or the read error if there is no close frame.
+func closeEnv(err error) []string {
+	code, reason := websocket.StatusAbnormalClosure, err.Error()
+
+	var ce websocket.CloseError
+	if errors.As(err, &ce) {
+		code, reason = ce.Code, ce.Reason
+	}
+
+	return []string{
+		"WSGET_CLOSE_CODE=" + strconv.Itoa(int(code)),
+		"WSGET_CLOSE_REASON=" + reason,
+	}
+}
+
+// fireCloseHook runs the close hook for the first unexpected close of the connection.
+// It takes ctx of type context.Context and err of type error, the error that ended the read loop.
+// The hook isn't run if it's not configured, if it has already run, if the connection was closed with Close
+// or the context is canceled.
+// The command runs in the background and Connect waits for it before returning, its failures are ignored.
+// The command is killed if it doesn't finish within the timeout of the hook.
+func (c *Connection) fireCloseHook(ctx context.Context, err error) {
+	if c.closeHook == nil || err == nil || ctx.Err() != nil || c.closing.Load() {
+		return
+	}
+
+	c.closeHook.once.Do(func() {
+		env := closeEnv(err)
+		hookCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.closeHook.timeout)
+
+		c.wg.Add(1)
+
+		go func() {
+			defer c.wg.Done()
+			defer cancel()
+
+			_ = c.closeHook.run(hookCtx, c.closeHook.command, env)
+		}()
+	})
+}
+
+// runShellWithEnv runs the command line with the system shell, adding env to the environment of the process.
+func runShellWithEnv(ctx context.Context, command string, env []string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+
+	cmd.Env = append(os.Environ(), env...)
+
+	return cmd.Run()
+}
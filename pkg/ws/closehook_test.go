@@ -0,0 +1,219 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type hookRecorder struct {
+	envs [][]string
+	l    sync.Mutex
+}
+
+func (r *hookRecorder) run(_ context.Context, _ string, env []string) error {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	r.envs = append(r.envs, env)
+
+	return nil
+}
+
+func (r *hookRecorder) get() [][]string {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	return append([][]string(nil), r.envs...)
+}
+
+func TestCloseEnv(t *testing.T) {
+	assert.Equal(t,
+		[]string{"WSGET_CLOSE_CODE=1011", "WSGET_CLOSE_REASON=server failure"},
+		closeEnv(websocket.CloseError{Code: websocket.StatusInternalError, Reason: "server failure"}),
+	)
+	assert.Equal(t,
+		[]string{"WSGET_CLOSE_CODE=1006", "WSGET_CLOSE_REASON=connection reset"},
+		closeEnv(errors.New("connection reset")),
+	)
+	assert.Nil(t, newCloseHook(""))
+}
+
+func TestConnection_ExecOnClose_ServerClose(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		_ = c.Close(websocket.StatusGoingAway, "maintenance")
+	}))
+	defer s.Close()
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), Options{ExecOnClose: "alert"})
+	require.NoError(t, err)
+
+	hooks := &hookRecorder{}
+	conn.closeHook.run = hooks.run
+
+	conn.SetOnMessage(func(context.Context, []byte) {})
+
+	err = conn.Connect(context.Background())
+
+	assert.EqualError(t, err, "connection closed: StatusGoingAway maintenance")
+	assert.Equal(t, [][]string{{"WSGET_CLOSE_CODE=1001", "WSGET_CLOSE_REASON=maintenance"}}, hooks.get())
+}
+
+func TestConnection_ExecOnClose_Reconnect(t *testing.T) {
+	var (
+		accepted int
+		l        sync.Mutex
+	)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		l.Lock()
+		accepted++
+		n := accepted
+		l.Unlock()
+
+		if n < 3 {
+			_ = c.Close(websocket.StatusInternalError, "server failure")
+			return
+		}
+
+		_ = c.Close(websocket.StatusNormalClosure, "bye")
+	}))
+	defer s.Close()
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), Options{
+		ExecOnClose:       "alert",
+		ReconnectAttempts: 3,
+		ReconnectDelay:    time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	hooks := &hookRecorder{}
+	conn.closeHook.run = hooks.run
+
+	conn.SetOnMessage(func(context.Context, []byte) {})
+
+	_ = conn.Connect(context.Background())
+
+	l.Lock()
+	assert.Equal(t, 3, accepted)
+	l.Unlock()
+
+	assert.Equal(t, [][]string{{"WSGET_CLOSE_CODE=1011", "WSGET_CLOSE_REASON=server failure"}}, hooks.get())
+}
+
+func TestConnection_ExecOnClose_ManualClose(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = c.CloseNow() }()
+
+		_, _, _ = c.Read(r.Context())
+	}))
+	defer s.Close()
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), Options{ExecOnClose: "alert"})
+	require.NoError(t, err)
+
+	hooks := &hookRecorder{}
+	conn.closeHook.run = hooks.run
+
+	conn.SetOnMessage(func(context.Context, []byte) {})
+
+	connErr := make(chan error, 1)
+
+	go func() {
+		connErr <- conn.Connect(context.Background())
+	}()
+
+	select {
+	case <-conn.ready:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for connection")
+	}
+
+	require.NoError(t, conn.Close())
+
+	select {
+	case <-connErr:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for connection to close")
+	}
+
+	assert.Empty(t, hooks.get())
+}
+
+func TestConnection_ExecOnClose_HangingHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test command requires a POSIX shell")
+	}
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		_ = c.Close(websocket.StatusInternalError, "server failure")
+	}))
+	defer s.Close()
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), Options{ExecOnClose: "sleep 60"})
+	require.NoError(t, err)
+
+	conn.closeHook.timeout = 100 * time.Millisecond
+
+	conn.SetOnMessage(func(context.Context, []byte) {})
+
+	connErr := make(chan error, 1)
+
+	go func() {
+		connErr <- conn.Connect(context.Background())
+	}()
+
+	select {
+	case err := <-connErr:
+		assert.ErrorContains(t, err, "server failure")
+	case <-time.After(5 * time.Second):
+		t.Fatal("hanging close hook blocked Connect from returning")
+	}
+}
+
+func TestRunShellWithEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test command requires a POSIX shell")
+	}
+
+	out := filepath.Join(t.TempDir(), "close.txt")
+
+	err := runShellWithEnv(context.Background(), `printf '%s %s' "$WSGET_CLOSE_CODE" "$WSGET_CLOSE_REASON" > `+out,
+		[]string{"WSGET_CLOSE_CODE=1001", "WSGET_CLOSE_REASON=going away"})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "1001 going away", string(data))
+}
@@ -0,0 +1,106 @@
+package ws
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const extensionsHeader = "Sec-WebSocket-Extensions"
+
+// extensionNegotiator is an http.RoundTripper that passes WebSocket extensions through the handshake.
+// It offers the configured extensions to the server and records the ones the server accepted.
+// The frames of the negotiated extensions are not processed, so only extensions that don't change framing are safe to use.
+type extensionNegotiator struct {
+	transport  http.RoundTripper
+	offered    []string
+	negotiated []string
+	l          sync.Mutex
+}
+
+// newExtensionNegotiator creates a new extensionNegotiator wrapping the provided transport.
+// It takes transport of type http.RoundTripper, used to execute the handshake, and offered of type []string with the extensions to offer.
+// It returns a pointer to the created extensionNegotiator.
+func newExtensionNegotiator(transport http.RoundTripper, offered []string) *extensionNegotiator {
+	return &extensionNegotiator{
+		transport: transport,
+		offered:   offered,
+	}
+}
+
+// RoundTrip sends the handshake request with the offered extensions and validates the server's response.
+// It takes req of type *http.Request, which is cloned before the extensions header is set.
+// It returns the response without the extensions header, so the WebSocket library doesn't reject extensions it doesn't know.
+// It returns an error if the underlying transport fails or the server accepted an extension that wasn't offered.
+func (e *extensionNegotiator) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set(extensionsHeader, strings.Join(e.offered, ", "))
+
+	resp, err := e.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	accepted := headerTokens(resp.Header.Values(extensionsHeader))
+
+	if err := e.validate(accepted); err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+
+	resp.Header.Del(extensionsHeader)
+
+	e.l.Lock()
+	e.negotiated = accepted
+	e.l.Unlock()
+
+	return resp, nil
+}
+
+// Negotiated returns the extensions accepted by the server during the last handshake.
+func (e *extensionNegotiator) Negotiated() []string {
+	e.l.Lock()
+	defer e.l.Unlock()
+
+	return append([]string(nil), e.negotiated...)
+}
+
+// validate checks that every extension accepted by the server was offered by the client.
+// It takes accepted of type []string with the extensions from the server's response.
+// It returns an error naming the first extension that wasn't offered.
+func (e *extensionNegotiator) validate(accepted []string) error {
+	offered := make(map[string]bool, len(e.offered))
+	for _, ext := range e.offered {
+		offered[extensionName(ext)] = true
+	}
+
+	for _, ext := range accepted {
+		if !offered[extensionName(ext)] {
+			return fmt.Errorf("server accepted extension that was not offered: %s", ext)
+		}
+	}
+
+	return nil
+}
+
+// headerTokens splits comma separated header values into trimmed tokens, skipping empty ones.
+func headerTokens(values []string) []string {
+	var tokens []string
+
+	for _, value := range values {
+		for _, token := range strings.Split(value, ",") {
+			if token = strings.TrimSpace(token); token != "" {
+				tokens = append(tokens, token)
+			}
+		}
+	}
+
+	return tokens
+}
+
+// extensionName returns the name of the extension without its parameters.
+func extensionName(ext string) string {
+	name, _, _ := strings.Cut(ext, ";")
+	return strings.TrimSpace(name)
+}
@@ -0,0 +1,102 @@
+package ws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createExtensionsWSHandler(accepted string, offered chan<- string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		offered <- r.Header.Get("Sec-WebSocket-Extensions")
+
+		if accepted != "" {
+			w.Header().Set("Sec-WebSocket-Extensions", accepted)
+		}
+
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = c.CloseNow() }()
+
+		_, _, _ = c.Read(r.Context())
+	}
+}
+
+func TestConnection_Extensions(t *testing.T) {
+	offered := make(chan string, 1)
+
+	s := httptest.NewServer(createExtensionsWSHandler("x-custom; level=1", offered))
+	defer s.Close()
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), Options{
+		Extensions: []string{"x-custom; level=1", "x-other"},
+	})
+	require.NoError(t, err)
+
+	conn.SetOnMessage(func(context.Context, []byte) {})
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	defer func() {
+		_ = conn.Close()
+
+		wg.Wait()
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		_ = conn.Connect(context.Background())
+	}()
+
+	select {
+	case <-conn.Ready():
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for connection")
+	}
+
+	assert.Equal(t, "x-custom; level=1, x-other", <-offered)
+	assert.Equal(t, []string{"x-custom; level=1"}, conn.NegotiatedExtensions())
+}
+
+func TestConnection_Extensions_NotOffered(t *testing.T) {
+	offered := make(chan string, 1)
+
+	s := httptest.NewServer(createExtensionsWSHandler("x-unexpected", offered))
+	defer s.Close()
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), Options{
+		Extensions: []string{"x-custom"},
+	})
+	require.NoError(t, err)
+
+	conn.SetOnMessage(func(context.Context, []byte) {})
+
+	err = conn.Connect(context.Background())
+
+	assert.ErrorContains(t, err, "server accepted extension that was not offered: x-unexpected")
+	assert.Empty(t, conn.NegotiatedExtensions())
+}
+
+func TestConnection_NegotiatedExtensions_NotConfigured(t *testing.T) {
+	conn, err := New("ws://localhost:0", Options{})
+	require.NoError(t, err)
+
+	assert.Nil(t, conn.NegotiatedExtensions())
+}
+
+func TestHeaderTokens(t *testing.T) {
+	assert.Equal(t, []string{"a; x=1", "b", "c"}, headerTokens([]string{" a; x=1 , b", "", "c,"}))
+	assert.Nil(t, headerTokens(nil))
+}
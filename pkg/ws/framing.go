@@ -0,0 +1,75 @@
+package ws
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// LengthPrefixFraming splits a single binary frame into several logical messages.
+// Every message in the frame is preceded by its length, encoded as an unsigned integer of PrefixSize bytes.
+type LengthPrefixFraming struct {
+	byteOrder  binary.ByteOrder
+	prefixSize int
+}
+
+// NewLengthPrefixFraming creates a new framing decoder for length prefixed messages.
+// It takes prefixSize of type int, the number of bytes in the length prefix, and byteOrder of type binary.ByteOrder.
+// It returns a pointer to a LengthPrefixFraming and an error if the prefix size is not 1, 2, 4 or 8 bytes or byte order is missing.
+func NewLengthPrefixFraming(prefixSize int, byteOrder binary.ByteOrder) (*LengthPrefixFraming, error) {
+	switch prefixSize {
+	case 1, 2, 4, 8:
+	default:
+		return nil, fmt.Errorf("invalid length prefix size: %d", prefixSize)
+	}
+
+	if byteOrder == nil {
+		return nil, fmt.Errorf("byte order is required")
+	}
+
+	return &LengthPrefixFraming{
+		prefixSize: prefixSize,
+		byteOrder:  byteOrder,
+	}, nil
+}
+
+// Split decodes all length prefixed messages from the frame data.
+// It takes data of type []byte, which is the payload of a single binary frame.
+// It returns a slice of messages in the order they appear in the frame.
+// It returns an error if a prefix is truncated or declares more bytes than left in the frame.
+func (f *LengthPrefixFraming) Split(data []byte) ([][]byte, error) {
+	var msgs [][]byte
+
+	for len(data) > 0 {
+		if len(data) < f.prefixSize {
+			return nil, fmt.Errorf("truncated length prefix: %d bytes left", len(data))
+		}
+
+		size := f.readLength(data[:f.prefixSize])
+		data = data[f.prefixSize:]
+
+		if size > uint64(len(data)) {
+			return nil, fmt.Errorf("message length %d exceeds frame size %d", size, len(data))
+		}
+
+		msgs = append(msgs, data[:size])
+		data = data[size:]
+	}
+
+	return msgs, nil
+}
+
+// readLength decodes the length prefix according to the configured size and byte order.
+// It takes prefix of type []byte, which must be exactly prefixSize bytes long.
+// It returns the decoded length as uint64.
+func (f *LengthPrefixFraming) readLength(prefix []byte) uint64 {
+	switch f.prefixSize {
+	case 1:
+		return uint64(prefix[0])
+	case 2:
+		return uint64(f.byteOrder.Uint16(prefix))
+	case 4:
+		return uint64(f.byteOrder.Uint32(prefix))
+	default:
+		return f.byteOrder.Uint64(prefix)
+	}
+}
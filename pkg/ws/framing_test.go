@@ -0,0 +1,133 @@
+package ws
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodeFrame(t *testing.T, size int, order binary.ByteOrder, docs ...string) []byte {
+	t.Helper()
+
+	var frame []byte
+
+	for _, doc := range docs {
+		prefix := make([]byte, 8)
+		order.PutUint64(prefix, uint64(len(doc)))
+
+		if order == binary.BigEndian {
+			frame = append(frame, prefix[8-size:]...)
+		} else {
+			frame = append(frame, prefix[:size]...)
+		}
+
+		frame = append(frame, doc...)
+	}
+
+	return frame
+}
+
+func TestNewLengthPrefixFraming(t *testing.T) {
+	tests := []struct {
+		order   binary.ByteOrder
+		name    string
+		size    int
+		wantErr bool
+	}{
+		{name: "1 byte", size: 1, order: binary.BigEndian},
+		{name: "2 bytes", size: 2, order: binary.LittleEndian},
+		{name: "4 bytes", size: 4, order: binary.BigEndian},
+		{name: "8 bytes", size: 8, order: binary.BigEndian},
+		{name: "invalid size", size: 3, order: binary.BigEndian, wantErr: true},
+		{name: "missing byte order", size: 4, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewLengthPrefixFraming(tt.size, tt.order)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, f)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, f)
+			}
+		})
+	}
+}
+
+func TestLengthPrefixFraming_Split(t *testing.T) {
+	tests := []struct {
+		order binary.ByteOrder
+		name  string
+		size  int
+	}{
+		{name: "4 bytes big endian", size: 4, order: binary.BigEndian},
+		{name: "4 bytes little endian", size: 4, order: binary.LittleEndian},
+		{name: "2 bytes big endian", size: 2, order: binary.BigEndian},
+		{name: "1 byte", size: 1, order: binary.BigEndian},
+		{name: "8 bytes little endian", size: 8, order: binary.LittleEndian},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewLengthPrefixFraming(tt.size, tt.order)
+			require.NoError(t, err)
+
+			msgs, err := f.Split(encodeFrame(t, tt.size, tt.order, `{"id":1}`, `{"id":2,"ok":true}`))
+
+			require.NoError(t, err)
+			assert.Equal(t, [][]byte{[]byte(`{"id":1}`), []byte(`{"id":2,"ok":true}`)}, msgs)
+		})
+	}
+}
+
+func TestLengthPrefixFraming_Split_Errors(t *testing.T) {
+	f, err := NewLengthPrefixFraming(4, binary.BigEndian)
+	require.NoError(t, err)
+
+	_, err = f.Split([]byte{0, 0})
+	assert.ErrorContains(t, err, "truncated length prefix")
+
+	_, err = f.Split([]byte{0, 0, 0, 10, '{', '}'})
+	assert.ErrorContains(t, err, "message length 10 exceeds frame size 2")
+}
+
+func TestConnection_HandleMessage_Framing(t *testing.T) {
+	f, err := NewLengthPrefixFraming(4, binary.BigEndian)
+	require.NoError(t, err)
+
+	var received []string
+
+	conn := &Connection{
+		framing: f,
+		onMessage: func(_ context.Context, data []byte) {
+			received = append(received, string(data))
+		},
+	}
+
+	frame := encodeFrame(t, 4, binary.BigEndian, `{"a":1}`, `{"b":2}`)
+
+	err = conn.handleMessage(context.Background(), websocket.MessageBinary, bytes.NewReader(frame))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`{"a":1}`, `{"b":2}`}, received)
+
+	var frameErr error
+
+	conn.SetOnFrameError(func(_ context.Context, err error) { frameErr = err })
+
+	err = conn.handleMessage(context.Background(), websocket.MessageBinary, bytes.NewReader([]byte{0, 0, 0, 5}))
+	assert.NoError(t, err)
+	assert.ErrorContains(t, frameErr, "fail to split binary frame")
+
+	err = conn.handleMessage(context.Background(), websocket.MessageBinary, bytes.NewReader(encodeFrame(t, 4, binary.BigEndian, `{"c":3}`)))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`{"a":1}`, `{"b":2}`, `{"c":3}`}, received)
+}
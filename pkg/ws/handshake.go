@@ -0,0 +1,116 @@
+package ws
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+const defaultAckTimeout = 10 * time.Second
+
+var ErrNoAck = errors.New("connection is not acknowledged")
+
+// appHandshake is the application level handshake performed after the WebSocket handshake,
+// e.g. sending a token as the first message and waiting for the server to accept it.
+type appHandshake struct {
+	ack     *regexp.Regexp
+	message string
+	timeout time.Duration
+}
+
+// pendingMessage is a message received during the application level handshake,
+// it's passed to the message callback once the connection is ready.
+type pendingMessage struct {
+	data    []byte
+	msgType websocket.MessageType
+}
+
+// newAppHandshake creates the application level handshake from the connection options.
+// It takes message of type string, sent as is right after the connection is established,
+// ack of type string, the pattern the first acknowledged message should match, and timeout of type time.Duration,
+// the time to wait for the ack, non-positive value is replaced with the default of 10 seconds.
+// It returns an appHandshake, or an error if the ack pattern is not a valid regular expression.
+func newAppHandshake(message, ack string, timeout time.Duration) (appHandshake, error) {
+	h := appHandshake{message: message, timeout: timeout}
+
+	if h.timeout <= 0 {
+		h.timeout = defaultAckTimeout
+	}
+
+	if ack == "" {
+		return h, nil
+	}
+
+	var err error
+	if h.ack, err = regexp.Compile(ack); err != nil {
+		return appHandshake{}, fmt.Errorf("invalid connect ack pattern: %w", err)
+	}
+
+	return h, nil
+}
+
+// handshake sends the connect message and waits for the ack message over the new connection, if they are configured.
+// Messages received before the ack, including the ack itself, are kept and passed to the message callback
+// when the connection starts reading messages, so they still show up in the output.
+// It returns an error wrapping ErrNoAck if the ack doesn't arrive in time, the connection is closed in this case,
+// or the processed error if sending or reading fails.
+func (c *Connection) handshake(ctx context.Context, ws *websocket.Conn) error {
+	if c.appHandshake.message != "" {
+		if err := ws.Write(ctx, websocket.MessageText, []byte(c.appHandshake.message)); err != nil {
+			return handleError(err)
+		}
+	}
+
+	if c.appHandshake.ack == nil {
+		return nil
+	}
+
+	ackCtx, cancel := context.WithTimeout(ctx, c.appHandshake.timeout)
+	defer cancel()
+
+	var pending []pendingMessage
+
+	for {
+		msgType, data, err := ws.Read(ackCtx)
+
+		switch {
+		case err != nil && ctx.Err() == nil && errors.Is(ackCtx.Err(), context.DeadlineExceeded):
+			_ = ws.Close(websocket.StatusPolicyViolation, "connection is not acknowledged")
+			return fmt.Errorf("%w: no message matching %q in %s", ErrNoAck, c.appHandshake.ack, c.appHandshake.timeout)
+		case err != nil:
+			return handleError(err)
+		}
+
+		pending = append(pending, pendingMessage{msgType: msgType, data: data})
+
+		if msgType == websocket.MessageText && c.appHandshake.ack.Match(data) {
+			c.l.Lock()
+			c.pending = pending
+			c.l.Unlock()
+
+			return nil
+		}
+	}
+}
+
+// flushPending passes the messages received during the application level handshake to the message callback.
+// It returns an error if handling any of the messages fails.
+func (c *Connection) flushPending(ctx context.Context) error {
+	c.l.Lock()
+	pending := c.pending
+	c.pending = nil
+	c.l.Unlock()
+
+	for _, msg := range pending {
+		if err := c.handleMessage(ctx, msg.msgType, bytes.NewReader(msg.data)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package ws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAckServer(t *testing.T, ack string) *httptest.Server {
+	t.Helper()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = c.CloseNow() }()
+
+		_, data, err := c.Read(r.Context())
+		if err != nil || string(data) != "AUTH secret" {
+			return
+		}
+
+		_ = c.Write(r.Context(), websocket.MessageText, []byte(`{"hello":1}`))
+
+		if ack != "" {
+			_ = c.Write(r.Context(), websocket.MessageText, []byte(ack))
+		}
+
+		_, _, _ = c.Read(r.Context())
+	}))
+
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func TestConnection_Connect_Ack(t *testing.T) {
+	s := newAckServer(t, `{"status":"ok"}`)
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), Options{
+		ConnectMessage: "AUTH secret",
+		ConnectAck:     `"status":"ok"`,
+	})
+	require.NoError(t, err)
+
+	received := make(chan string, 2)
+
+	conn.SetOnMessage(func(_ context.Context, data []byte) { received <- string(data) })
+
+	connErr := make(chan error, 1)
+
+	go func() {
+		connErr <- conn.Connect(context.Background())
+	}()
+
+	select {
+	case <-conn.Ready():
+	case err := <-connErr:
+		t.Fatalf("unexpected error: %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for connection")
+	}
+
+	for _, want := range []string{`{"hello":1}`, `{"status":"ok"}`} {
+		select {
+		case got := <-received:
+			assert.Equal(t, want, got)
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for message")
+		}
+	}
+
+	_ = conn.Close()
+	<-connErr
+}
+
+func TestConnection_Connect_NoAck(t *testing.T) {
+	s := newAckServer(t, "")
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), Options{
+		ConnectMessage:    "AUTH secret",
+		ConnectAck:        `"status":"ok"`,
+		ConnectAckTimeout: 50 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	conn.SetOnMessage(func(context.Context, []byte) {})
+
+	err = conn.Connect(context.Background())
+
+	assert.ErrorIs(t, err, ErrNoAck)
+
+	select {
+	case <-conn.Ready():
+		t.Fatal("connection should not be ready")
+	default:
+	}
+}
+
+func TestNew_InvalidConnectAck(t *testing.T) {
+	_, err := New("ws://localhost", Options{ConnectAck: "("})
+
+	assert.ErrorContains(t, err, "invalid connect ack pattern")
+}
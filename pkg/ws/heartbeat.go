@@ -0,0 +1,44 @@
+package ws
+
+import (
+	"context"
+	"time"
+)
+
+type heartbeat struct {
+	message  string
+	interval time.Duration
+}
+
+// startHeartbeat starts sending the heartbeat message on the configured interval in a separate goroutine.
+// It takes ctx of type context.Context, the heartbeat stops once it's canceled.
+// Heartbeat messages are regular text messages sent to keep the session alive on the application level,
+// they are not WebSocket pings. Failed sends are ignored, as the connection error is handled by the reading loop.
+// It does nothing if the message or the interval is not configured.
+func (c *Connection) startHeartbeat(ctx context.Context) {
+	if c.heartbeat.message == "" || c.heartbeat.interval <= 0 {
+		return
+	}
+
+	c.wg.Add(1)
+
+	go func() {
+		defer c.wg.Done()
+
+		ticker := time.NewTicker(c.heartbeat.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if c.reconnecting.Load() {
+					continue
+				}
+
+				_ = c.Send(ctx, c.heartbeat.message)
+			}
+		}
+	}()
+}
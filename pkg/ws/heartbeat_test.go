@@ -0,0 +1,96 @@
+package ws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnection_Heartbeat(t *testing.T) {
+	const interval = 20 * time.Millisecond
+
+	received := make(chan time.Time, 100)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = c.CloseNow() }()
+
+		for {
+			_, data, err := c.Read(r.Context())
+			if err != nil {
+				return
+			}
+
+			if string(data) == `{"ping":1}` {
+				received <- time.Now()
+			}
+		}
+	}))
+	defer s.Close()
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), Options{
+		HeartbeatMessage:  `{"ping":1}`,
+		HeartbeatInterval: interval,
+	})
+	require.NoError(t, err)
+
+	conn.SetOnMessage(func(context.Context, []byte) {})
+
+	connErr := make(chan error, 1)
+
+	go func() {
+		connErr <- conn.Connect(context.Background())
+	}()
+
+	<-conn.Ready()
+
+	start := time.Now()
+
+	var last time.Time
+
+	for i := 0; i < 3; i++ {
+		select {
+		case last = <-received:
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for heartbeat")
+		}
+	}
+
+	assert.GreaterOrEqual(t, last.Sub(start), 2*interval)
+
+	_ = conn.Close()
+
+	select {
+	case <-connErr:
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for connection to close")
+	}
+
+	for len(received) > 0 {
+		<-received
+	}
+
+	select {
+	case <-received:
+		t.Fatal("heartbeat was sent after close")
+	case <-time.After(3 * interval):
+	}
+}
+
+func TestConnection_Heartbeat_Disabled(t *testing.T) {
+	conn, err := New("ws://localhost:0", Options{HeartbeatMessage: "ping"})
+	require.NoError(t, err)
+
+	conn.startHeartbeat(context.Background())
+	conn.wg.Wait()
+}
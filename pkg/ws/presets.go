@@ -0,0 +1,49 @@
+package ws
+
+import (
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// headerPresets maps the names of common authentication patterns to functions building the header from the argument.
+var headerPresets = map[string]func(arg string) string{
+	"bearer": func(token string) string { return "Authorization: Bearer " + token },
+	"basic": func(credentials string) string {
+		return "Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+	},
+	"apikey": func(key string) string { return "X-API-Key: " + key },
+	"token":  func(token string) string { return "Authorization: Token " + token },
+}
+
+// ExpandHeaderPreset expands the named header preset into the header in "Name: value" form.
+// It takes preset of type string in "name:argument" form, e.g. bearer:TOKEN, apikey:KEY or basic:user:password,
+// the argument of basic is encoded with base64.
+// It returns the header or an error if the preset is unknown or the argument is empty.
+func ExpandHeaderPreset(preset string) (string, error) {
+	name, arg, _ := strings.Cut(preset, ":")
+
+	build, ok := headerPresets[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return "", fmt.Errorf("unknown header preset %q, available presets: %s", name, strings.Join(HeaderPresetNames(), ", "))
+	}
+
+	if arg = strings.TrimSpace(arg); arg == "" {
+		return "", fmt.Errorf("header preset %s requires an argument, e.g. %s:value", name, name)
+	}
+
+	return build(arg), nil
+}
+
+// HeaderPresetNames returns the sorted names of the available header presets.
+func HeaderPresetNames() []string {
+	names := make([]string, 0, len(headerPresets))
+	for name := range headerPresets {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
@@ -0,0 +1,57 @@
+package ws
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandHeaderPreset(t *testing.T) {
+	tests := []struct {
+		name    string
+		preset  string
+		want    string
+		wantErr string
+	}{
+		{name: "bearer", preset: "bearer:abc.def", want: "Authorization: Bearer abc.def"},
+		{name: "basic", preset: "basic:user:secret", want: "Authorization: Basic dXNlcjpzZWNyZXQ="},
+		{name: "apikey", preset: "apikey:KEY", want: "X-API-Key: KEY"},
+		{name: "token", preset: "token:abc", want: "Authorization: Token abc"},
+		{name: "case insensitive name", preset: "Bearer:abc", want: "Authorization: Bearer abc"},
+		{name: "unknown preset", preset: "oauth:abc", wantErr: `unknown header preset "oauth", available presets: apikey, basic, bearer, token`},
+		{name: "missing argument", preset: "bearer", wantErr: "header preset bearer requires an argument, e.g. bearer:value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandHeaderPreset(tt.preset)
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNew_HeaderPresets(t *testing.T) {
+	conn, err := New("ws://localhost", Options{
+		Headers:       []string{"X-Trace: 1"},
+		HeaderPresets: []string{"bearer:abc", "apikey:KEY"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, http.Header{
+		"X-Trace":       {"1"},
+		"Authorization": {"Bearer abc"},
+		"X-Api-Key":     {"KEY"},
+	}, conn.opts.HTTPHeader)
+
+	_, err = New("ws://localhost", Options{HeaderPresets: []string{"digest:abc"}})
+	assert.ErrorContains(t, err, "unknown header preset")
+}
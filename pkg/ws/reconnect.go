@@ -0,0 +1,198 @@
+package ws
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+const (
+	defaultReconnectDelay = time.Second
+	maxReconnectDelay     = 30 * time.Second
+
+	JitterNone  = "none"
+	JitterFull  = "full"
+	JitterEqual = "equal"
+)
+
+// reconnectPolicy defines how many times and how often a dropped connection is re-established.
+type reconnectPolicy struct {
+	randN    func(n int64) int64
+	jitter   string
+	attempts int
+	delay    time.Duration
+}
+
+// newReconnectPolicy creates a reconnect policy from the connection options.
+// It takes attempts of type int, the number of consecutive attempts, 0 disables reconnecting,
+// delay of type time.Duration, the delay before the first attempt, which doubles after every failed attempt,
+// and jitter of type string, the strategy randomizing the delay: none, full or equal, see wait.
+// It returns a reconnectPolicy, non-positive delay is replaced with the default delay of one second,
+// or an error if the jitter strategy is unknown.
+func newReconnectPolicy(attempts int, delay time.Duration, jitter string) (reconnectPolicy, error) {
+	switch jitter {
+	case "", JitterNone, JitterFull, JitterEqual:
+	default:
+		return reconnectPolicy{}, fmt.Errorf("invalid reconnect jitter: %s, expected none, full or equal", jitter)
+	}
+
+	if delay <= 0 {
+		delay = defaultReconnectDelay
+	}
+
+	return reconnectPolicy{
+		attempts: max(attempts, 0),
+		delay:    delay,
+		jitter:   jitter,
+	}, nil
+}
+
+// backoff returns the delay before the given attempt, starting from 1.
+// The delay doubles with every attempt and is capped at 30 seconds.
+func (p reconnectPolicy) backoff(attempt int) time.Duration {
+	delay := p.delay
+
+	for i := 1; i < attempt && delay < maxReconnectDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxReconnectDelay)
+}
+
+// wait returns the randomized delay before the given attempt, starting from 1, so clients dropped at the same time
+// don't reconnect in sync. With full jitter the delay is random between zero and the backoff,
+// with equal jitter it's random between half of the backoff and the backoff, without jitter it's the backoff.
+func (p reconnectPolicy) wait(attempt int) time.Duration {
+	delay := p.backoff(attempt)
+
+	randN := p.randN
+	if randN == nil {
+		randN = rand.Int64N
+	}
+
+	switch p.jitter {
+	case JitterFull:
+		return time.Duration(randN(int64(delay) + 1))
+	case JitterEqual:
+		half := delay / 2
+		return delay - half + time.Duration(randN(int64(half)+1))
+	default:
+		return delay
+	}
+}
+
+// shouldReconnect decides if the connection should be re-established after reading from it failed with err.
+// It returns false if reconnecting is disabled, the context is canceled, the connection was closed by the client,
+// or the server closed the connection normally.
+func (c *Connection) shouldReconnect(ctx context.Context, err error) bool {
+	if c.reconnect.attempts == 0 || err == nil || ctx.Err() != nil || c.closing.Load() {
+		return false
+	}
+
+	return websocket.CloseStatus(err) != websocket.StatusNormalClosure
+}
+
+// redial re-establishes a dropped connection according to the reconnect policy.
+// It takes ctx of type context.Context and cause of type error, the reason the connection was dropped.
+// It returns the new connection, or nil and an error if all attempts failed.
+// It returns nil and nil if the context is canceled or the connection is closed by the client while waiting.
+func (c *Connection) redial(ctx context.Context, cause error) (*websocket.Conn, error) {
+	c.reconnecting.Store(true)
+	defer c.reconnecting.Store(false)
+
+	c.notifyStatus(ctx, StatusReconnecting, cause)
+
+	var err error
+
+	for attempt := 1; attempt <= c.reconnect.attempts; attempt++ {
+		select {
+		case <-time.After(c.reconnect.wait(attempt)):
+		case <-ctx.Done():
+			return nil, nil
+		}
+
+		if c.closing.Load() {
+			return nil, nil
+		}
+
+		var ws *websocket.Conn
+
+		if ws, err = c.dial(ctx); ws != nil {
+			c.l.Lock()
+			c.ws = ws
+			c.l.Unlock()
+
+			c.notifyStatus(ctx, StatusConnected, nil)
+
+			return ws, nil
+		}
+
+		if err == nil {
+			return nil, nil
+		}
+	}
+
+	return nil, fmt.Errorf("fail to reconnect after %d attempts: %w", c.reconnect.attempts, err)
+}
+
+// Reconnect re-establishes an open connection on demand, e.g. after the server is redeployed.
+// It takes ctx of type context.Context to control the handshake.
+// The new connection is dialed with the same options while the current one keeps working,
+// it replaces the current one only if the handshake succeeds, then the current one is closed normally.
+// It returns an error if the connection is not established, is closed or is being re-established,
+// or if the handshake fails, in which case the current connection is kept.
+func (c *Connection) Reconnect(ctx context.Context) error {
+	select {
+	case <-c.closed:
+		return ErrConnectionClosed
+	case <-c.ready:
+	default:
+		return fmt.Errorf("connection is not established")
+	}
+
+	if !c.reconnecting.CompareAndSwap(false, true) {
+		return fmt.Errorf("connection is being re-established")
+	}
+
+	defer c.reconnecting.Store(false)
+
+	c.l.Lock()
+	info := c.info
+	c.l.Unlock()
+
+	ws, err := c.dial(ctx)
+	if ws == nil {
+		c.l.Lock()
+		c.info = info
+		c.l.Unlock()
+
+		return cmp.Or(err, ctx.Err())
+	}
+
+	c.l.Lock()
+
+	select {
+	case <-c.closed:
+		c.l.Unlock()
+
+		_ = ws.CloseNow()
+
+		return ErrConnectionClosed
+	default:
+	}
+
+	old := c.ws
+	c.ws = ws
+
+	c.l.Unlock()
+
+	_ = old.Close(websocket.StatusNormalClosure, "reconnecting")
+
+	c.notifyStatus(ctx, StatusConnected, nil)
+
+	return nil
+}
@@ -0,0 +1,407 @@
+package ws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type statusRecorder struct {
+	statuses []string
+	l        sync.Mutex
+}
+
+func (r *statusRecorder) record(_ context.Context, status string, _ error) {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	r.statuses = append(r.statuses, status)
+}
+
+func (r *statusRecorder) get() []string {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	return append([]string(nil), r.statuses...)
+}
+
+func TestReconnectPolicy_Backoff(t *testing.T) {
+	p, err := newReconnectPolicy(5, 10*time.Second, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, 10*time.Second, p.backoff(1))
+	assert.Equal(t, 20*time.Second, p.backoff(2))
+	assert.Equal(t, 30*time.Second, p.backoff(3))
+	assert.Equal(t, 30*time.Second, p.backoff(10))
+
+	p, err = newReconnectPolicy(-1, 0, JitterNone)
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, p.attempts)
+	assert.Equal(t, defaultReconnectDelay, p.delay)
+	assert.Equal(t, defaultReconnectDelay, p.wait(1))
+
+	_, err = newReconnectPolicy(5, 0, "random")
+	assert.EqualError(t, err, "invalid reconnect jitter: random, expected none, full or equal")
+}
+
+func TestReconnectPolicy_Jitter(t *testing.T) {
+	tests := []struct {
+		name    string
+		jitter  string
+		attempt int
+		minWait time.Duration
+		maxWait time.Duration
+	}{
+		{name: "full first attempt", jitter: JitterFull, attempt: 1, minWait: 0, maxWait: 10 * time.Second},
+		{name: "full capped", jitter: JitterFull, attempt: 5, minWait: 0, maxWait: maxReconnectDelay},
+		{name: "equal first attempt", jitter: JitterEqual, attempt: 1, minWait: 5 * time.Second, maxWait: 10 * time.Second},
+		{name: "equal second attempt", jitter: JitterEqual, attempt: 2, minWait: 10 * time.Second, maxWait: 20 * time.Second},
+		{name: "none", jitter: JitterNone, attempt: 2, minWait: 20 * time.Second, maxWait: 20 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := newReconnectPolicy(5, 10*time.Second, tt.jitter)
+			require.NoError(t, err)
+
+			p.randN = func(int64) int64 { return 0 }
+			assert.Equal(t, tt.minWait, p.wait(tt.attempt))
+
+			p.randN = func(n int64) int64 { return n - 1 }
+			assert.Equal(t, tt.maxWait, p.wait(tt.attempt))
+
+			p.randN = nil
+			for range 100 {
+				wait := p.wait(tt.attempt)
+				assert.GreaterOrEqual(t, wait, tt.minWait)
+				assert.LessOrEqual(t, wait, tt.maxWait)
+			}
+		})
+	}
+}
+
+func TestConnection_Reconnect(t *testing.T) {
+	var connections atomic.Int32
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = c.CloseNow() }()
+
+		if connections.Add(1) == 1 {
+			_ = c.Write(r.Context(), websocket.MessageText, []byte("first"))
+
+			// Drop the connection without a close handshake.
+			return
+		}
+
+		_ = c.Write(r.Context(), websocket.MessageText, []byte("second"))
+		_, _, _ = c.Read(r.Context())
+	}))
+	defer s.Close()
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), Options{
+		ReconnectAttempts: 3,
+		ReconnectDelay:    10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	received := make(chan string, 2)
+	statuses := &statusRecorder{}
+
+	conn.SetOnMessage(func(_ context.Context, data []byte) { received <- string(data) })
+	conn.SetOnStatusChange(statuses.record)
+
+	connErr := make(chan error, 1)
+
+	go func() {
+		connErr <- conn.Connect(context.Background())
+	}()
+
+	for _, expected := range []string{"first", "second"} {
+		select {
+		case msg := <-received:
+			assert.Equal(t, expected, msg)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timeout waiting for message %q", expected)
+		}
+	}
+
+	assert.Equal(t, []string{StatusConnected, StatusReconnecting, StatusConnected}, statuses.get())
+	assert.Equal(t, StatusConnected, conn.Status())
+	assert.NoError(t, conn.Send(context.Background(), "after reconnect"))
+
+	_ = conn.Close()
+
+	<-connErr
+
+	assert.Equal(t, []string{StatusConnected, StatusReconnecting, StatusConnected, StatusClosed}, statuses.get())
+}
+
+func TestConnection_Reconnect_NormalClosure(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		_ = c.Close(websocket.StatusNormalClosure, "")
+	}))
+	defer s.Close()
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), Options{
+		ReconnectAttempts: 3,
+		ReconnectDelay:    10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	statuses := &statusRecorder{}
+
+	conn.SetOnMessage(func(context.Context, []byte) {})
+	conn.SetOnStatusChange(statuses.record)
+
+	err = conn.Connect(context.Background())
+
+	assert.ErrorIs(t, err, ErrConnectionClosed)
+	assert.Equal(t, []string{StatusConnected, StatusClosed}, statuses.get())
+}
+
+func TestConnection_Reconnect_AttemptsExhausted(t *testing.T) {
+	var s *httptest.Server
+
+	s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		// Stop accepting new connections and drop the current one.
+		go s.Listener.Close()
+
+		_ = c.CloseNow()
+	}))
+	defer s.Close()
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), Options{
+		ReconnectAttempts: 2,
+		ReconnectDelay:    10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	statuses := &statusRecorder{}
+
+	conn.SetOnMessage(func(context.Context, []byte) {})
+	conn.SetOnStatusChange(statuses.record)
+
+	err = conn.Connect(context.Background())
+
+	assert.ErrorContains(t, err, "fail to reconnect after 2 attempts")
+	assert.Equal(t, []string{StatusConnected, StatusReconnecting, StatusClosed}, statuses.get())
+	assert.Equal(t, StatusClosed, conn.Status())
+}
+
+func TestConnection_Reconnect_TemplatedHeaders(t *testing.T) {
+	var connections atomic.Int32
+
+	authHeaders := make(chan string, 2)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeaders <- r.Header.Get("Authorization")
+
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = c.CloseNow() }()
+
+		if connections.Add(1) == 1 {
+			return
+		}
+
+		_, _, _ = c.Read(r.Context())
+	}))
+	defer s.Close()
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), Options{
+		Headers:           []string{"Authorization: Bearer ${token}"},
+		ReconnectAttempts: 3,
+		ReconnectDelay:    10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	var token atomic.Value
+
+	token.Store("first")
+
+	conn.SetHeaderExpander(func(value string) string {
+		return strings.ReplaceAll(value, "${token}", token.Load().(string))
+	})
+
+	reconnected := make(chan struct{})
+
+	conn.SetOnMessage(func(context.Context, []byte) {})
+	conn.SetOnStatusChange(func(_ context.Context, status string, _ error) {
+		switch status {
+		case StatusReconnecting:
+			token.Store("refreshed")
+		case StatusConnected:
+			if connections.Load() > 1 {
+				close(reconnected)
+			}
+		}
+	})
+
+	connErr := make(chan error, 1)
+
+	go func() {
+		connErr <- conn.Connect(context.Background())
+	}()
+
+	select {
+	case <-reconnected:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for reconnect")
+	}
+
+	assert.Equal(t, "Bearer first", <-authHeaders)
+	assert.Equal(t, "Bearer refreshed", <-authHeaders)
+	assert.Equal(t, []string{"Bearer ${token}"}, conn.opts.HTTPHeader.Values("Authorization"))
+
+	_ = conn.Close()
+
+	<-connErr
+}
+
+func TestConnection_Reconnect_Manual(t *testing.T) {
+	var connections atomic.Int32
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = c.CloseNow() }()
+
+		n := connections.Add(1)
+
+		for {
+			_, data, err := c.Read(r.Context())
+			if err != nil {
+				return
+			}
+
+			if err := c.Write(r.Context(), websocket.MessageText, []byte(string(data)+" "+strconv.Itoa(int(n)))); err != nil {
+				return
+			}
+		}
+	}))
+	defer s.Close()
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), Options{})
+	require.NoError(t, err)
+
+	received := make(chan string, 2)
+	statuses := &statusRecorder{}
+
+	conn.SetOnMessage(func(_ context.Context, data []byte) { received <- string(data) })
+	conn.SetOnStatusChange(statuses.record)
+
+	assert.EqualError(t, conn.Reconnect(context.Background()), "connection is not established")
+
+	connErr := make(chan error, 1)
+
+	go func() {
+		connErr <- conn.Connect(context.Background())
+	}()
+
+	<-conn.Ready()
+
+	for _, expected := range []string{"ping 1", "ping 2"} {
+		if expected == "ping 2" {
+			require.NoError(t, conn.Reconnect(context.Background()))
+		}
+
+		require.NoError(t, conn.Send(context.Background(), "ping"))
+
+		select {
+		case msg := <-received:
+			assert.Equal(t, expected, msg)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timeout waiting for message %q", expected)
+		}
+	}
+
+	assert.Equal(t, int32(2), connections.Load())
+	assert.Equal(t, StatusConnected, conn.Status())
+
+	_ = conn.Close()
+
+	assert.ErrorIs(t, <-connErr, ErrConnectionClosed)
+	assert.Equal(t, []string{StatusConnected, StatusConnected, StatusClosed}, statuses.get())
+	assert.ErrorIs(t, conn.Reconnect(context.Background()), ErrConnectionClosed)
+}
+
+func TestConnection_Reconnect_ManualFailure(t *testing.T) {
+	var connections atomic.Int32
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if connections.Add(1) > 1 {
+			http.Error(w, "deploying", http.StatusServiceUnavailable)
+			return
+		}
+
+		createEchoWSHandler()(w, r)
+	}))
+	defer s.Close()
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), Options{})
+	require.NoError(t, err)
+
+	received := make(chan string, 1)
+
+	conn.SetOnMessage(func(_ context.Context, data []byte) { received <- string(data) })
+
+	connErr := make(chan error, 1)
+
+	go func() {
+		connErr <- conn.Connect(context.Background())
+	}()
+
+	<-conn.Ready()
+
+	info := conn.Info()
+
+	err = conn.Reconnect(context.Background())
+	assert.ErrorContains(t, err, "503")
+	assert.Equal(t, info, conn.Info())
+	assert.Equal(t, StatusConnected, conn.Status())
+
+	require.NoError(t, conn.Send(context.Background(), "still here"))
+
+	select {
+	case msg := <-received:
+		assert.Equal(t, "still here", msg)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for the echo on the current connection")
+	}
+
+	_ = conn.Close()
+
+	<-connErr
+}
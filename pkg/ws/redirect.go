@@ -0,0 +1,96 @@
+package ws
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// DefaultMaxRedirects is the number of redirects followed during the handshake if the limit isn't provided,
+	// it's the limit of net/http, so the handshake follows redirects as it did before the limit was configurable.
+	DefaultMaxRedirects = 10
+
+	RedirectAuthCrossOrigin = "cross-origin"
+	RedirectAuthKeep        = "keep"
+	RedirectAuthDrop        = "drop"
+)
+
+// sensitiveHeaders are the headers carrying credentials that are not sent to another origin by default.
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "X-API-Key"}
+
+// newRedirectPolicy creates the redirect check of the handshake client.
+// It takes maxRedirects of type int, the number of redirects followed before the handshake fails,
+// 0 follows up to DefaultMaxRedirects and negative value disables following redirects, so the 3xx response fails the handshake,
+// and authPolicy of type string, which defines what happens to the sensitive headers on redirect:
+// cross-origin drops them when the redirect leads to another origin, drop always drops them
+// and keep sends them to every redirect target.
+// It returns the function for http.Client.CheckRedirect or an error if the auth policy is unknown.
+func newRedirectPolicy(maxRedirects int, authPolicy string) (func(*http.Request, []*http.Request) error, error) {
+	switch authPolicy {
+	case "", RedirectAuthCrossOrigin, RedirectAuthKeep, RedirectAuthDrop:
+	default:
+		return nil, fmt.Errorf("invalid redirect auth policy: %s, expected cross-origin, keep or drop", authPolicy)
+	}
+
+	if maxRedirects < 0 {
+		return func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }, nil
+	}
+
+	if maxRedirects == 0 {
+		maxRedirects = DefaultMaxRedirects
+	}
+
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) > maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+
+		first := via[0]
+
+		for _, name := range sensitiveHeaders {
+			switch {
+			case authPolicy == RedirectAuthDrop,
+				authPolicy != RedirectAuthKeep && !sameOrigin(first.URL, req.URL):
+				req.Header.Del(name)
+			case authPolicy == RedirectAuthKeep && first.Header.Get(name) != "":
+				// net/http drops credentials on redirects to another domain itself, so they are restored here.
+				req.Header[name] = first.Header.Values(name)
+			}
+		}
+
+		return nil
+	}, nil
+}
+
+// sameOrigin reports whether both URLs have the same scheme, host and port,
+// ws and wss schemes are treated as http and https.
+func sameOrigin(a, b *url.URL) bool {
+	return originScheme(a) == originScheme(b) && strings.EqualFold(originHost(a), originHost(b))
+}
+
+// originScheme returns the HTTP scheme of the URL.
+func originScheme(u *url.URL) string {
+	switch scheme := strings.ToLower(u.Scheme); scheme {
+	case "ws":
+		return "http"
+	case "wss":
+		return "https"
+	default:
+		return scheme
+	}
+}
+
+// originHost returns the host of the URL with the default port of its scheme if the port is omitted.
+func originHost(u *url.URL) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+
+	if originScheme(u) == "https" {
+		return u.Host + ":443"
+	}
+
+	return u.Host + ":80"
+}
@@ -0,0 +1,181 @@
+package ws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createRedirectWSServer starts a server redirecting /old to target, upgrading /ws
+// and closing the connection right away, the headers of the upgrade request are sent to upgraded.
+func createRedirectWSServer(t *testing.T, target func(s *httptest.Server) string, upgraded chan<- http.Header) *httptest.Server {
+	t.Helper()
+
+	var s *httptest.Server
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target(s), http.StatusFound)
+	})
+	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		upgraded <- r.Header.Clone()
+
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		_ = c.Close(websocket.StatusGoingAway, "bye")
+	})
+
+	s = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func TestConnection_Redirect(t *testing.T) {
+	tests := []struct {
+		name         string
+		authPolicy   string
+		wantAuth     string
+		crossOrigin  bool
+		maxRedirects int
+	}{
+		{name: "same origin keeps credentials", maxRedirects: 1, wantAuth: "Bearer secret"},
+		{name: "cross origin drops credentials", maxRedirects: 1, crossOrigin: true},
+		{name: "keep policy", maxRedirects: 1, crossOrigin: true, authPolicy: RedirectAuthKeep, wantAuth: "Bearer secret"},
+		{name: "drop policy", maxRedirects: 1, authPolicy: RedirectAuthDrop},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upgraded := make(chan http.Header, 1)
+
+			target := createRedirectWSServer(t, func(*httptest.Server) string { return "" }, upgraded)
+			s := createRedirectWSServer(t, func(s *httptest.Server) string {
+				if tt.crossOrigin {
+					return "ws://" + target.Listener.Addr().String() + "/ws"
+				}
+
+				return "/ws"
+			}, upgraded)
+
+			conn, err := New("ws://"+s.Listener.Addr().String()+"/old", Options{
+				Headers:      []string{"Authorization: Bearer secret", "X-Trace: 1"},
+				MaxRedirects: tt.maxRedirects,
+				RedirectAuth: tt.authPolicy,
+			})
+			require.NoError(t, err)
+
+			conn.SetOnMessage(func(context.Context, []byte) {})
+
+			err = conn.Connect(context.Background())
+			assert.EqualError(t, err, "connection closed: StatusGoingAway bye")
+
+			headers := <-upgraded
+			assert.Equal(t, tt.wantAuth, headers.Get("Authorization"))
+			assert.Equal(t, "1", headers.Get("X-Trace"))
+		})
+	}
+}
+
+func TestConnection_Redirect_Disabled(t *testing.T) {
+	upgraded := make(chan http.Header, 1)
+	s := createRedirectWSServer(t, func(*httptest.Server) string { return "/ws" }, upgraded)
+
+	conn, err := New("ws://"+s.Listener.Addr().String()+"/old", Options{MaxRedirects: -1})
+	require.NoError(t, err)
+
+	conn.SetOnMessage(func(context.Context, []byte) {})
+
+	err = conn.Connect(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "302")
+	assert.Empty(t, upgraded)
+}
+
+func TestConnection_Redirect_Default(t *testing.T) {
+	upgraded := make(chan http.Header, 1)
+
+	target := createRedirectWSServer(t, func(*httptest.Server) string { return "" }, upgraded)
+	s := createRedirectWSServer(t, func(*httptest.Server) string {
+		return "ws://" + target.Listener.Addr().String() + "/ws"
+	}, upgraded)
+
+	conn, err := New("ws://"+s.Listener.Addr().String()+"/old", Options{
+		Headers: []string{"Authorization: Bearer secret", "X-Trace: 1"},
+	})
+	require.NoError(t, err)
+
+	conn.SetOnMessage(func(context.Context, []byte) {})
+
+	err = conn.Connect(context.Background())
+	assert.EqualError(t, err, "connection closed: StatusGoingAway bye")
+
+	headers := <-upgraded
+	assert.Empty(t, headers.Get("Authorization"))
+	assert.Equal(t, "1", headers.Get("X-Trace"))
+}
+
+func TestConnection_Redirect_DefaultLimit(t *testing.T) {
+	s := createRedirectWSServer(t, func(*httptest.Server) string { return "/old" }, nil)
+
+	conn, err := New("ws://"+s.Listener.Addr().String()+"/old", Options{})
+	require.NoError(t, err)
+
+	conn.SetOnMessage(func(context.Context, []byte) {})
+
+	err = conn.Connect(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "stopped after 10 redirects")
+}
+
+func TestConnection_Redirect_Loop(t *testing.T) {
+	s := createRedirectWSServer(t, func(*httptest.Server) string { return "/old" }, nil)
+
+	conn, err := New("ws://"+s.Listener.Addr().String()+"/old", Options{MaxRedirects: 3})
+	require.NoError(t, err)
+
+	conn.SetOnMessage(func(context.Context, []byte) {})
+
+	err = conn.Connect(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "stopped after 3 redirects")
+}
+
+func TestNewRedirectPolicy_InvalidAuth(t *testing.T) {
+	_, err := New("ws://localhost", Options{MaxRedirects: 1, RedirectAuth: "always"})
+
+	assert.EqualError(t, err, "invalid redirect auth policy: always, expected cross-origin, keep or drop")
+}
+
+func TestSameOrigin(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{a: "ws://example.com/old", b: "http://example.com/ws", want: true},
+		{a: "wss://example.com", b: "https://example.com:443/ws", want: true},
+		{a: "ws://example.com", b: "http://EXAMPLE.com:80", want: true},
+		{a: "wss://example.com", b: "http://example.com"},
+		{a: "ws://example.com", b: "http://api.example.com"},
+		{a: "ws://example.com:8080", b: "http://example.com:8081"},
+	}
+
+	for _, tt := range tests {
+		a, err := url.Parse(tt.a)
+		require.NoError(t, err)
+
+		b, err := url.Parse(tt.b)
+		require.NoError(t, err)
+
+		assert.Equal(t, tt.want, sameOrigin(a, b), "%s %s", tt.a, tt.b)
+	}
+}
@@ -6,27 +6,71 @@ import (
 	"io"
 	"net/http"
 	"sort"
+	"sync"
 
 	"github.com/fatih/color"
 )
 
 type requestLogger struct {
-	transport *http.Transport
-	output    io.Writer
+	transport  *http.Transport
+	output     io.Writer
+	sessions   tls.ClientSessionCache
+	serverName string
+	mu         sync.Mutex
 }
 
 // newRequestLogger creates a new requestLogger for HTTP client request logging.
-// It takes an output of type io.Writer for logging and a skipSSLVerification of type bool to control SSL verification.
+// It takes an output of type io.Writer for logging, a skipSSLVerification of type bool to control SSL verification
+// and a serverName of type string, the SNI sent in the TLS handshake instead of the URL host, if it's not empty.
 // It returns a pointer to a requestLogger configured to log requests and responses without SSL verification if specified.
-func newRequestLogger(output io.Writer, skipSSLVerification bool) *requestLogger {
+func newRequestLogger(output io.Writer, skipSSLVerification bool, serverName string) *requestLogger {
 	return &requestLogger{
-		transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: skipSSLVerification}, //nolint:gosec // Skip SSL verification
+		transport:  newTransport(skipSSLVerification, serverName, nil),
+		output:     output,
+		serverName: serverName,
+	}
+}
+
+// newTransport creates the HTTP transport used for the handshake, with or without SSL verification.
+// The server name overrides the SNI and the name the server certificate is verified against, if it's not empty.
+// TLS sessions are resumed from the sessions cache, if it's not nil.
+func newTransport(skipSSLVerification bool, serverName string, sessions tls.ClientSessionCache) *http.Transport {
+	return &http.Transport{
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: skipSSLVerification, //nolint:gosec // Skip SSL verification
+			ServerName:         serverName,
+			ClientSessionCache: sessions,
 		},
-		output: output,
 	}
 }
 
+// enableSessionResumption replaces the transport with one keeping TLS session tickets in a cache,
+// so the following handshakes, e.g. on reconnect, resume the session instead of making a full handshake.
+func (rl *requestLogger) enableSessionResumption() {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	skip := rl.transport.TLSClientConfig.InsecureSkipVerify
+
+	rl.sessions = tls.NewLRUClientSessionCache(0)
+	rl.transport = newTransport(skip, rl.serverName, rl.sessions)
+}
+
+// setSkipSSLVerification replaces the transport, so the following requests are made with or without SSL verification.
+// Idle connections of the previous transport are closed, so they are not reused with the old setting.
+// The TLS session cache is replaced as well, so a session of a handshake with the old setting is never resumed.
+func (rl *requestLogger) setSkipSSLVerification(skip bool) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if rl.sessions != nil {
+		rl.sessions = tls.NewLRUClientSessionCache(0)
+	}
+
+	rl.transport.CloseIdleConnections()
+	rl.transport = newTransport(skip, rl.serverName, rl.sessions)
+}
+
 // RoundTrip executes a single HTTP transaction with logging.
 // It takes a parameter req of type *http.Request.
 // It returns an *http.Response and an error.
@@ -43,7 +87,11 @@ func (rl *requestLogger) RoundTrip(req *http.Request) (*http.Response, error) {
 		tx.UnsetWriter(rl.output)
 	}
 
-	resp, err := rl.transport.RoundTrip(req)
+	rl.mu.Lock()
+	transport := rl.transport
+	rl.mu.Unlock()
+
+	resp, err := transport.RoundTrip(req)
 
 	if err != nil {
 		return nil, err
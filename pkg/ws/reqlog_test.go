@@ -48,7 +48,7 @@ func TestNewRequestLogger(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			rl := newRequestLogger(tt.output, tt.skipSSLVerification)
+			rl := newRequestLogger(tt.output, tt.skipSSLVerification, "")
 
 			assert.NotNil(t, rl)
 			assert.Equal(t, tt.output, rl.output)
@@ -149,9 +149,9 @@ func TestRequestLogger_RoundTrip(t *testing.T) {
 
 			var rl *requestLogger
 			if tt.output == nil {
-				rl = newRequestLogger(nil, false)
+				rl = newRequestLogger(nil, false, "")
 			} else {
-				rl = newRequestLogger(tt.output, false)
+				rl = newRequestLogger(tt.output, false, "")
 			}
 
 			cl := http.Client{
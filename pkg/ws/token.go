@@ -0,0 +1,62 @@
+package ws
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+var ErrEmptyToken = errors.New("bearer token command returned empty output")
+
+// tokenCommand runs an external command printing a short-lived bearer token,
+// e.g. gcloud auth print-access-token, before every handshake.
+type tokenCommand struct {
+	run     func(ctx context.Context, command string) ([]byte, error)
+	command string
+}
+
+// newTokenCommand creates the bearer token command from the connection options.
+// It takes command of type string, the command line run with the system shell.
+// It returns nil if the command is empty.
+func newTokenCommand(command string) *tokenCommand {
+	if command == "" {
+		return nil
+	}
+
+	return &tokenCommand{command: command, run: runShell}
+}
+
+// token runs the command and returns its output with surrounding whitespace trimmed.
+// It returns an error if the command exits with a non-zero status, the error output is included if there is any,
+// or ErrEmptyToken if the command prints nothing.
+func (t *tokenCommand) token(ctx context.Context) (string, error) {
+	out, err := t.run(ctx, t.command)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(bytes.TrimSpace(exitErr.Stderr)) > 0 {
+			return "", fmt.Errorf("bearer token command failed: %w: %s", err, bytes.TrimSpace(exitErr.Stderr))
+		}
+
+		return "", fmt.Errorf("bearer token command failed: %w", err)
+	}
+
+	token := strings.TrimSpace(string(out))
+	if token == "" {
+		return "", ErrEmptyToken
+	}
+
+	return token, nil
+}
+
+// runShell runs the command line with the system shell and returns its standard output.
+func runShell(ctx context.Context, command string) ([]byte, error) {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command).Output()
+	}
+
+	return exec.CommandContext(ctx, "sh", "-c", command).Output()
+}
@@ -0,0 +1,162 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenCommand_Token(t *testing.T) {
+	tests := []struct {
+		runErr  error
+		name    string
+		out     string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "trims output",
+			out:  "  secret\n",
+			want: "secret",
+		},
+		{
+			name:    "empty output",
+			out:     " \n",
+			wantErr: ErrEmptyToken.Error(),
+		},
+		{
+			name:    "command fails",
+			runErr:  errors.New("exit status 1"),
+			wantErr: "bearer token command failed: exit status 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &tokenCommand{
+				command: "print-token",
+				run: func(_ context.Context, command string) ([]byte, error) {
+					assert.Equal(t, "print-token", command)
+					return []byte(tt.out), tt.runErr
+				},
+			}
+
+			got, err := cmd.token(context.Background())
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTokenCommand_Token_Shell(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test command requires a POSIX shell")
+	}
+
+	got, err := newTokenCommand("echo secret").token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "secret", got)
+
+	_, err = newTokenCommand("echo denied >&2; exit 3").token(context.Background())
+	assert.EqualError(t, err, "bearer token command failed: exit status 3: denied")
+
+	assert.Nil(t, newTokenCommand(""))
+}
+
+func TestConnection_BearerTokenCommand(t *testing.T) {
+	var connections atomic.Int32
+
+	authHeaders := make(chan string, 2)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeaders <- r.Header.Get("Authorization")
+
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = c.CloseNow() }()
+
+		if connections.Add(1) == 1 {
+			return
+		}
+
+		_, _, _ = c.Read(r.Context())
+	}))
+	defer s.Close()
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), Options{
+		Headers:            []string{"X-Client: wsget"},
+		BearerTokenCommand: "print-token",
+		ReconnectAttempts:  3,
+		ReconnectDelay:     10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	var runs atomic.Int32
+
+	conn.tokenCmd.run = func(context.Context, string) ([]byte, error) {
+		return []byte(fmt.Sprintf("token-%d\n", runs.Add(1))), nil
+	}
+
+	reconnected := make(chan struct{})
+
+	conn.SetOnMessage(func(context.Context, []byte) {})
+	conn.SetOnStatusChange(func(_ context.Context, status string, _ error) {
+		if status == StatusConnected && connections.Load() > 1 {
+			close(reconnected)
+		}
+	})
+
+	connErr := make(chan error, 1)
+
+	go func() {
+		connErr <- conn.Connect(context.Background())
+	}()
+
+	select {
+	case <-reconnected:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for reconnect")
+	}
+
+	assert.Equal(t, "Bearer token-1", <-authHeaders)
+	assert.Equal(t, "Bearer token-2", <-authHeaders)
+	assert.Empty(t, conn.opts.HTTPHeader.Values("Authorization"))
+
+	_ = conn.Close()
+
+	<-connErr
+}
+
+func TestConnection_BearerTokenCommand_Fails(t *testing.T) {
+	conn, err := New("ws://localhost:0", Options{BearerTokenCommand: "print-token"})
+	require.NoError(t, err)
+
+	conn.tokenCmd.run = func(context.Context, string) ([]byte, error) {
+		return nil, nil
+	}
+
+	conn.SetOnMessage(func(context.Context, []byte) {})
+
+	err = conn.Connect(context.Background())
+
+	assert.ErrorIs(t, err, ErrEmptyToken)
+}
@@ -2,18 +2,23 @@ package ws
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/coder/websocket"
+	"github.com/ksysoev/wsget/pkg/core"
 )
 
 const (
@@ -22,8 +27,18 @@ const (
 	DefaultMaxMessageSize = 1024 * 1024
 )
 
+const (
+	StatusConnecting   = "connecting"
+	StatusConnected    = "connected"
+	StatusReconnecting = "reconnecting"
+	StatusClosed       = "closed"
+)
+
 var (
 	ErrConnectionClosed = errors.New("connection closed")
+	ErrMessageTooBig    = errors.New("message is too big")
+	ErrWriteTimeout     = errors.New("write timeout")
+	ErrNotConnected     = errors.New("not connected")
 )
 
 type reader interface {
@@ -31,47 +46,130 @@ type reader interface {
 }
 
 type Connection struct {
-	url       *url.URL
-	ws        *websocket.Conn
-	onMessage func(context.Context, []byte)
-	opts      *websocket.DialOptions
-	ready     chan struct{}
-	l         sync.Mutex
-	msgSize   int64
+	framing        *LengthPrefixFraming
+	extensions     *extensionNegotiator
+	onMessage      func(context.Context, []byte)
+	onStatusChange func(ctx context.Context, status string, err error)
+	onFrameError   func(ctx context.Context, err error)
+	expandHeader   func(value string) string
+	tokenCmd       *tokenCommand
+	closeHook      *closeHook
+	reqLogger      *requestLogger
+	opts           *websocket.DialOptions
+	ready          chan struct{}
+	closed         chan struct{}
+	ws             *websocket.Conn
+	url            *url.URL
+	info           core.ConnectionInfo
+	pending        []pendingMessage
+	appHandshake   appHandshake
+	heartbeat      heartbeat
+	reconnect      reconnectPolicy
+	wg             sync.WaitGroup
+	msgSize        int64
+	writeTimeout   time.Duration
+	l              sync.Mutex
+	reconnecting   atomic.Bool
+	closing        atomic.Bool
 }
 
 type Options struct {
 	Output              io.Writer
+	Framing             *LengthPrefixFraming
+	HeartbeatMessage    string
+	ConnectMessage      string
+	ConnectAck          string
+	BearerTokenCommand  string
+	ExecOnClose         string
+	ServerName          string
+	RedirectAuth        string
+	ReconnectJitter     string
 	Headers             []string
-	SkipSSLVerification bool
+	HeaderPresets       []string
+	Extensions          []string
+	Subprotocols        []string
 	MaxMessageSize      int64
+	ReconnectAttempts   int
+	MaxRedirects        int
+	ReconnectDelay      time.Duration
+	HeartbeatInterval   time.Duration
+	WriteTimeout        time.Duration
+	ConnectAckTimeout   time.Duration
+	SkipSSLVerification bool
+	TLSResume           bool
 }
 
 // New initializes a new WebSocket connection configuration with specified URL and options.
 // It takes wsURL, a string representing the WebSocket URL, and opts, an instance of Options with custom settings.
-// It returns a pointer to a Connection and possible error if the URL is empty, poorly formatted, or headers are invalid.
+// Header presets from opts are expanded with ExpandHeaderPreset and sent after the headers.
+// If ServerName is set, it's sent as the SNI of wss connections and the server certificate is verified against it
+// instead of the URL host.
+// If BearerTokenCommand is set, it's run before every handshake, including reconnects,
+// and its trimmed output is sent as the bearer token in the Authorization header.
+// If ExecOnClose is set, it's run every time the connection is closed unexpectedly, see fireCloseHook.
+// If TLSResume is set, TLS session tickets are kept for the lifetime of the connection,
+// so the handshakes of reconnects resume the TLS session.
+// Redirects of the handshake are followed up to MaxRedirects times, DefaultMaxRedirects if it's 0,
+// negative MaxRedirects disables following them. RedirectAuth defines whether the credentials are sent
+// to the redirect target, see newRedirectPolicy.
+// It returns a pointer to a Connection and possible error if the URL is empty, poorly formatted, headers are invalid,
+// a header preset is unknown, the redirect auth policy or the reconnect jitter strategy is unknown
+// or the connect ack pattern is not a valid regular expression.
 func New(wsURL string, opts Options) (*Connection, error) {
 	if wsURL == "" {
 		return nil, errors.New("url is empty")
 	}
 
-	parsedURL, err := url.Parse(wsURL)
+	parsedURL, err := parseURL(wsURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		reqLogger                    = newRequestLogger(opts.Output, opts.SkipSSLVerification, opts.ServerName)
+		transport  http.RoundTripper = reqLogger
+		extensions *extensionNegotiator
+	)
+
+	if opts.TLSResume {
+		reqLogger.enableSessionResumption()
+	}
+
+	if len(opts.Extensions) > 0 {
+		extensions = newExtensionNegotiator(transport, opts.Extensions)
+		transport = extensions
+	}
+
+	checkRedirect, err := newRedirectPolicy(opts.MaxRedirects, opts.RedirectAuth)
 	if err != nil {
 		return nil, err
 	}
 
 	httpCli := &http.Client{
-		Transport: newRequestLogger(opts.Output, opts.SkipSSLVerification),
-		Timeout:   dialTimeout,
+		Transport:     transport,
+		Timeout:       dialTimeout,
+		CheckRedirect: checkRedirect,
 	}
 
 	wsOpts := &websocket.DialOptions{
-		HTTPClient: httpCli,
+		HTTPClient:   httpCli,
+		Subprotocols: opts.Subprotocols,
+	}
+
+	headers := opts.Headers
+
+	for _, preset := range opts.HeaderPresets {
+		header, err := ExpandHeaderPreset(preset)
+		if err != nil {
+			return nil, err
+		}
+
+		headers = append(slices.Clip(headers), header)
 	}
 
-	if len(opts.Headers) > 0 {
+	if len(headers) > 0 {
 		Headers := make(http.Header)
-		for _, headerInput := range opts.Headers {
+		for _, headerInput := range headers {
 			splited := strings.Split(headerInput, ":")
 			if len(splited) != headerPartsNumber {
 				return nil, fmt.Errorf("invalid header: %s", headerInput)
@@ -86,16 +184,71 @@ func New(wsURL string, opts Options) (*Connection, error) {
 		wsOpts.HTTPHeader = Headers
 	}
 
+	handshake, err := newAppHandshake(opts.ConnectMessage, opts.ConnectAck, opts.ConnectAckTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	reconnect, err := newReconnectPolicy(opts.ReconnectAttempts, opts.ReconnectDelay, opts.ReconnectJitter)
+	if err != nil {
+		return nil, err
+	}
+
 	var msgSize int64 = DefaultMaxMessageSize
+	if opts.MaxMessageSize > 0 {
+		msgSize = opts.MaxMessageSize
+	}
 
 	return &Connection{
-		url:     parsedURL,
-		opts:    wsOpts,
-		ready:   make(chan struct{}),
-		msgSize: msgSize,
+		url:          parsedURL,
+		opts:         wsOpts,
+		ready:        make(chan struct{}),
+		closed:       make(chan struct{}),
+		msgSize:      msgSize,
+		framing:      opts.Framing,
+		extensions:   extensions,
+		reqLogger:    reqLogger,
+		reconnect:    reconnect,
+		heartbeat:    heartbeat{message: opts.HeartbeatMessage, interval: opts.HeartbeatInterval},
+		writeTimeout: opts.WriteTimeout,
+		appHandshake: handshake,
+		tokenCmd:     newTokenCommand(opts.BearerTokenCommand),
+		closeHook:    newCloseHook(opts.ExecOnClose),
 	}, nil
 }
 
+// parseURL parses the WebSocket URL, accepting IPv6 literals with a zone identifier as they are usually written,
+// e.g. ws://[fe80::1%eth0]:8080/, RFC 6874 requires the percent sign of the zone to be escaped as %25.
+// It returns the parsed URL or an error if the URL is poorly formatted.
+func parseURL(wsURL string) (*url.URL, error) {
+	parsed, err := url.Parse(wsURL)
+	if err == nil {
+		return parsed, nil
+	}
+
+	start := strings.Index(wsURL, "[")
+	end := strings.Index(wsURL, "]")
+
+	if start < 0 || end < start {
+		return nil, err
+	}
+
+	host := wsURL[start:end]
+
+	zone := strings.Index(host, "%")
+	if zone < 0 || strings.HasPrefix(host[zone:], "%25") {
+		return nil, err
+	}
+
+	escaped := wsURL[:start] + host[:zone] + "%25" + host[zone+1:] + wsURL[end:]
+
+	if parsed, escErr := url.Parse(escaped); escErr == nil {
+		return parsed, nil
+	}
+
+	return nil, err
+}
+
 // SetOnMessage sets the callback function to handle incoming messages on the connection.
 // It takes onMessage, a function with parameters context.Context and a byte slice [], as input.
 // The method does not return any value and is thread-safe, locking access to the callback function.
@@ -106,22 +259,60 @@ func (c *Connection) SetOnMessage(onMessage func(context.Context, []byte)) {
 	c.onMessage = onMessage
 }
 
+// SetHeaderExpander sets the function used to resolve placeholders in header values before every handshake.
+// It takes expand, a function that receives the configured header value and returns the value to send,
+// so headers can carry values that change between reconnects, e.g. a refreshed token.
+// The method does not return any value and is thread-safe.
+func (c *Connection) SetHeaderExpander(expand func(value string) string) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	c.expandHeader = expand
+}
+
+// SetSkipSSLVerification enables or disables verification of the server certificate.
+// It takes skip of type bool, if true the certificate is not verified.
+// The setting is applied to the next handshake, e.g. when the connection is re-established,
+// the current connection is not affected.
+func (c *Connection) SetSkipSSLVerification(skip bool) {
+	c.reqLogger.setSkipSSLVerification(skip)
+}
+
+// SetOnStatusChange sets the callback function notified when the connection changes its state.
+// It takes onStatusChange, a function called with the new status and the error that caused the change, if any.
+// The method does not return any value and is thread-safe, locking access to the callback function.
+func (c *Connection) SetOnStatusChange(onStatusChange func(ctx context.Context, status string, err error)) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	c.onStatusChange = onStatusChange
+}
+
+// SetOnFrameError sets the callback function notified when a binary frame can't be split into messages.
+// It takes onFrameError, a function called with the error, the malformed frame is dropped and reading continues.
+// The method does not return any value and is thread-safe, locking access to the callback function.
+func (c *Connection) SetOnFrameError(onFrameError func(ctx context.Context, err error)) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	c.onFrameError = onFrameError
+}
+
 // Connect establishes a WebSocket connection using the specified context.
 // It returns an error if the onMessage callback is not set, the connection attempt fails,
 // or if a connection is already established.
 // The method locks the connection during setup to ensure thread safety and sets a default read limit on the WebSocket.
-func (c *Connection) Connect(ctx context.Context) error {
+// If reconnect attempts are configured, a connection dropped by an error is re-established with a growing delay,
+// and Connect returns only when reconnecting fails, the server closes the connection normally or the context is canceled.
+// A connection replaced with Reconnect is read from without interruption.
+func (c *Connection) Connect(ctx context.Context) (err error) {
 	if c.onMessage == nil {
 		return fmt.Errorf("onMessage callback is not set")
 	}
 
-	ws, resp, err := websocket.Dial(ctx, c.url.String(), c.opts)
-	if err != nil {
-		return handleError(err)
-	}
-
-	if resp.Body != nil {
-		_ = resp.Body.Close()
+	ws, err := c.dial(ctx)
+	if ws == nil {
+		return err
 	}
 
 	c.l.Lock()
@@ -135,9 +326,148 @@ func (c *Connection) Connect(ctx context.Context) error {
 
 	c.l.Unlock()
 
+	hbCtx, stopHeartbeat := context.WithCancel(ctx)
+
+	defer func() {
+		stopHeartbeat()
+		c.wg.Wait()
+		close(c.closed)
+		c.notifyStatus(ctx, StatusClosed, err)
+	}()
+
+	c.notifyStatus(ctx, StatusConnected, nil)
+	c.startHeartbeat(hbCtx)
+
+	for {
+		err = c.handleResponses(ctx, ws)
+
+		if next := c.conn(); next != ws && ctx.Err() == nil {
+			// The connection was replaced by Reconnect, which closes the old one.
+			ws = next
+			continue
+		}
+
+		c.fireCloseHook(ctx, err)
+
+		if !c.shouldReconnect(ctx, err) {
+			return handleError(err)
+		}
+
+		if ws, err = c.redial(ctx, handleError(err)); ws == nil {
+			return err
+		}
+	}
+}
+
+// dial opens a new WebSocket connection to the configured URL, applies the read limit to it
+// and performs the application level handshake, if it's configured.
+// It takes ctx of type context.Context to control the handshake.
+// It returns the established connection, or nil and an error if the bearer token command or the handshake fails.
+// It returns nil and nil if the context is canceled.
+func (c *Connection) dial(ctx context.Context) (*websocket.Conn, error) {
+	opts := *c.opts
+	opts.HTTPHeader = c.resolveHeaders()
+
+	if c.tokenCmd != nil {
+		token, err := c.tokenCmd.token(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if opts.HTTPHeader = opts.HTTPHeader.Clone(); opts.HTTPHeader == nil {
+			opts.HTTPHeader = make(http.Header)
+		}
+
+		opts.HTTPHeader.Set("Authorization", "Bearer "+token)
+	}
+
+	var remoteAddr string
+
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) { remoteAddr = info.Conn.RemoteAddr().String() },
+	}
+
+	ws, resp, err := websocket.Dial(httptrace.WithClientTrace(ctx, trace), c.url.String(), &opts)
+	if err != nil {
+		return nil, handleError(err)
+	}
+
+	info := core.ConnectionInfo{
+		URL:             c.url.String(),
+		RemoteAddr:      remoteAddr,
+		Subprotocols:    opts.Subprotocols,
+		Subprotocol:     ws.Subprotocol(),
+		ResponseHeaders: resp.Header.Clone(),
+	}
+
+	if resp.TLS != nil {
+		info.TLSVersion = tls.VersionName(resp.TLS.Version)
+		info.CipherSuite = tls.CipherSuiteName(resp.TLS.CipherSuite)
+		info.TLSResumed = resp.TLS.DidResume
+	}
+
+	c.l.Lock()
+	c.info = info
+	c.l.Unlock()
+
+	if resp.Body != nil {
+		_ = resp.Body.Close()
+	}
+
 	ws.SetReadLimit(c.msgSize)
 
-	return c.handleResponses(ctx, ws)
+	if err := c.handshake(ctx, ws); err != nil {
+		_ = ws.CloseNow()
+		return nil, err
+	}
+
+	return ws, nil
+}
+
+// resolveHeaders returns the handshake headers with values resolved by the header expander, if it is set.
+func (c *Connection) resolveHeaders() http.Header {
+	c.l.Lock()
+	expand := c.expandHeader
+	c.l.Unlock()
+
+	if expand == nil || c.opts.HTTPHeader == nil {
+		return c.opts.HTTPHeader
+	}
+
+	headers := make(http.Header, len(c.opts.HTTPHeader))
+
+	for name, values := range c.opts.HTTPHeader {
+		for _, value := range values {
+			headers.Add(name, expand(value))
+		}
+	}
+
+	return headers
+}
+
+// notifyStatus calls the status change callback if it is set.
+func (c *Connection) notifyStatus(ctx context.Context, status string, err error) {
+	c.l.Lock()
+	onStatusChange := c.onStatusChange
+	c.l.Unlock()
+
+	if onStatusChange != nil {
+		onStatusChange(ctx, status, err)
+	}
+}
+
+// Info returns the details of the last successful handshake, e.g. the remote address, the negotiated subprotocol
+// and the headers of the upgrade response.
+// Before the first handshake completes, only the URL and the offered subprotocols are set.
+func (c *Connection) Info() core.ConnectionInfo {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	if c.info.URL == "" {
+		return core.ConnectionInfo{URL: c.url.String(), Subprotocols: c.opts.Subprotocols}
+	}
+
+	return c.info
 }
 
 // Hostname retrieves the host name part of the URL stored in the Connection struct.
@@ -146,19 +476,61 @@ func (c *Connection) Hostname() string {
 	return c.url.Hostname()
 }
 
+// Status returns the current state of the connection.
+// It returns StatusConnecting before the handshake completes, StatusConnected while messages are being read,
+// StatusReconnecting while a dropped connection is being re-established and StatusClosed once the connection stopped reading messages.
+func (c *Connection) Status() string {
+	select {
+	case <-c.closed:
+		return StatusClosed
+	default:
+	}
+
+	if c.reconnecting.Load() {
+		return StatusReconnecting
+	}
+
+	select {
+	case <-c.ready:
+		return StatusConnected
+	default:
+		return StatusConnecting
+	}
+}
+
+// NegotiatedExtensions returns the WebSocket extensions accepted by the server during the handshake.
+// It returns nil if no extensions were configured in Options or the server didn't accept any of them.
+func (c *Connection) NegotiatedExtensions() []string {
+	if c.extensions == nil {
+		return nil
+	}
+
+	return c.extensions.Negotiated()
+}
+
 // handleResponses manages incoming messages on a WebSocket connection until the context is canceled.
 // It takes a context (ctx) for cancellation control and a websocket connection (ws) for message communication.
-// It returns an error if there is an issue reading from the WebSocket or if handling a message fails.
+// It returns the unprocessed error if there is an issue reading from the WebSocket or if handling a message fails,
+// so the caller can decide whether the connection should be re-established.
+// It returns ErrNotConnected if there is no underlying connection to read from.
 // The function terminates without error if the context is canceled.
 func (c *Connection) handleResponses(ctx context.Context, ws *websocket.Conn) error {
+	if ws == nil {
+		return ErrNotConnected
+	}
+
+	if err := c.flushPending(ctx); err != nil {
+		return err
+	}
+
 	for ctx.Err() == nil {
 		msgType, reader, err := ws.Reader(ctx)
 		if err != nil {
-			return handleError(err)
+			return err
 		}
 
 		if err := c.handleMessage(ctx, msgType, reader); err != nil {
-			return handleError(err)
+			return err
 		}
 	}
 
@@ -167,19 +539,44 @@ func (c *Connection) handleResponses(ctx context.Context, ws *websocket.Conn) er
 
 // handleMessage processes an incoming WebSocket message for the Connection.
 // It takes ctx of type context.Context, msgType of type websocket.MessageType, and msgReader of type reader.
-// It returns an error if the message type is binary or if reading from the reader fails.
+// It returns an error if reading from the reader fails.
+// Messages larger than the maximum message size are rejected with ErrMessageTooBig, the read limit of the connection
+// stops reading the frame and closes the connection with the message too big status, so the message is never fully buffered.
 // The function reads all data from msgReader and invokes the onMessage callback with the read data.
+// Binary frames are passed to the callback as is, so they can be shown with a binary content type, e.g. base64.
+// If framing is configured, they are split and the callback is invoked for every message in the frame,
+// a frame that can't be split is dropped and reported to the frame error callback, the connection is kept open.
 func (c *Connection) handleMessage(ctx context.Context, msgType websocket.MessageType, msgReader reader) error {
-	if msgType == websocket.MessageBinary {
-		return fmt.Errorf("unexpected binary message")
+	data, err := io.ReadAll(msgReader)
+
+	switch {
+	case err != nil && int64(len(data)) > c.msgSize:
+		return fmt.Errorf("%w: limit is %d bytes", ErrMessageTooBig, c.msgSize)
+	case err != nil:
+		return fmt.Errorf("fail to read message: %w", err)
 	}
 
-	data, err := io.ReadAll(msgReader)
+	if msgType != websocket.MessageBinary || c.framing == nil {
+		c.onMessage(ctx, data)
+		return nil
+	}
+
+	msgs, err := c.framing.Split(data)
 	if err != nil {
-		return fmt.Errorf("fail to read message: %w", err)
+		c.l.Lock()
+		onFrameError := c.onFrameError
+		c.l.Unlock()
+
+		if onFrameError != nil {
+			onFrameError(ctx, fmt.Errorf("fail to split binary frame: %w", err))
+		}
+
+		return nil
 	}
 
-	c.onMessage(ctx, data)
+	for _, msg := range msgs {
+		c.onMessage(ctx, msg)
+	}
 
 	return nil
 }
@@ -211,7 +608,8 @@ func handleError(err error) error {
 
 // Send transmits a message over an established WebSocket connection within a given context.
 // It takes ctx of type context.Context and msg of type string as parameters.
-// It returns an error if the context is canceled or if there is a failure writing to the WebSocket.
+// It returns an error if the context is canceled, if there is a failure writing to the WebSocket
+// or if the message can't be sent within the write timeout, and ErrNotConnected if there is no underlying connection.
 // The function waits for the connection to be ready before sending the message.
 func (c *Connection) Send(ctx context.Context, msg string) error {
 	select {
@@ -220,13 +618,54 @@ func (c *Connection) Send(ctx context.Context, msg string) error {
 		return ctx.Err()
 	}
 
-	err := c.ws.Write(ctx, websocket.MessageText, []byte(msg))
+	return c.write(ctx, websocket.MessageText, []byte(msg))
+}
+
+// SendBinary transmits data as a binary frame over an established WebSocket connection within a given context.
+// It takes ctx of type context.Context and data of type []byte as parameters.
+// It returns an error if the context is canceled or if there is a failure writing to the WebSocket,
+// and ErrNotConnected if there is no underlying connection.
+// The function waits for the connection to be ready before sending the data.
+func (c *Connection) SendBinary(ctx context.Context, data []byte) error {
+	select {
+	case <-c.ready:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return c.write(ctx, websocket.MessageBinary, data)
+}
+
+// write sends a frame over the current connection within the configured write timeout.
+// It takes ctx of type context.Context, msgType of type websocket.MessageType and data of type []byte.
+// It returns an error wrapping ErrWriteTimeout if the frame can't be flushed in time, the connection is closed in this case,
+// ErrNotConnected if there is no underlying connection, or the processed error if writing fails for another reason.
+func (c *Connection) write(ctx context.Context, msgType websocket.MessageType, data []byte) error {
+	ws := c.conn()
+	if ws == nil {
+		return ErrNotConnected
+	}
+
+	writeCtx := ctx
+
+	if c.writeTimeout > 0 {
+		var cancel context.CancelFunc
+		writeCtx, cancel = context.WithTimeout(ctx, c.writeTimeout)
+
+		defer cancel()
+	}
+
+	err := ws.Write(writeCtx, msgType, data)
+
+	if err != nil && ctx.Err() == nil && errors.Is(writeCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: frame was not sent in %s", ErrWriteTimeout, c.writeTimeout)
+	}
 
 	return handleError(err)
 }
 
 // Close shuts down an established WebSocket connection gracefully.
-// It returns an error if the connection is not yet established.
+// It returns an error if the connection is not yet established, or ErrNotConnected if there is no underlying connection.
 // The function ensures a normal closure status is sent to the WebSocket server.
 func (c *Connection) Close() error {
 	select {
@@ -235,7 +674,27 @@ func (c *Connection) Close() error {
 		return fmt.Errorf("connection is not established")
 	}
 
-	return c.ws.Close(websocket.StatusNormalClosure, "closing connection")
+	c.closing.Store(true)
+
+	ws := c.conn()
+	if ws == nil {
+		return ErrNotConnected
+	}
+
+	return ws.Close(websocket.StatusNormalClosure, "closing connection")
+}
+
+// conn returns the current underlying WebSocket connection, which is replaced on every reconnect.
+func (c *Connection) conn() *websocket.Conn {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	return c.ws
+}
+
+// Done returns a channel that is closed when the connection stops reading messages, e.g. after the server closed it.
+func (c *Connection) Done() <-chan struct{} {
+	return c.closed
 }
 
 // Ready returns a channel that is closed when the WebSocket connection is established.
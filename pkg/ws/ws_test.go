@@ -1,7 +1,10 @@
 package ws
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
@@ -15,8 +18,10 @@ import (
 	"time"
 
 	"github.com/coder/websocket"
+	"github.com/ksysoev/wsget/pkg/core"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func createEchoWSHandler() http.HandlerFunc {
@@ -64,9 +69,9 @@ func TestConnection_HandleMessage(t *testing.T) {
 		expectErr  bool
 	}{
 		{
-			name:      "Unexpected binary message",
+			name:      "Binary message without framing",
 			msgType:   websocket.MessageBinary,
-			expectErr: true,
+			expectErr: false,
 		},
 		{
 			name:       "Successful text message",
@@ -85,9 +90,9 @@ func TestConnection_HandleMessage(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			msgReader := NewMockreader(t)
 
-			if tt.msgType == websocket.MessageText && tt.expectErr {
+			if tt.expectErr {
 				msgReader.On("Read", mock.Anything).Return(0, assert.AnError)
-			} else if tt.msgType == websocket.MessageText {
+			} else {
 				msgReader.On("Read", mock.Anything).Return(0, io.EOF)
 			}
 
@@ -261,6 +266,50 @@ func TestConnection_Hostname(t *testing.T) {
 	}
 }
 
+func TestNew_IPv6(t *testing.T) {
+	tests := []struct {
+		name         string
+		url          string
+		expectedHost string
+		expectedURL  string
+	}{
+		{
+			name:         "IPv6 literal",
+			url:          "ws://[2001:db8::1]:8080/ws",
+			expectedHost: "2001:db8::1",
+			expectedURL:  "ws://[2001:db8::1]:8080/ws",
+		},
+		{
+			name:         "IPv6 literal with zone",
+			url:          "ws://[fe80::1%eth0]:8080/",
+			expectedHost: "fe80::1%eth0",
+			expectedURL:  "ws://[fe80::1%25eth0]:8080/",
+		},
+		{
+			name:         "IPv6 literal with escaped zone",
+			url:          "wss://[fe80::1%25en0]/",
+			expectedHost: "fe80::1%en0",
+			expectedURL:  "wss://[fe80::1%25en0]/",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := New(tt.url, Options{})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedHost, conn.Hostname())
+			assert.Equal(t, tt.expectedURL, conn.url.String())
+		})
+	}
+}
+
+func TestNew_InvalidIPv6Zone(t *testing.T) {
+	_, err := New("ws://[fe80::1%eth0:8080/", Options{})
+
+	assert.Error(t, err)
+}
+
 func TestConnection_HandleError(t *testing.T) {
 	tests := []struct {
 		err   error
@@ -448,3 +497,428 @@ func TestConnection_Close_NotConnected(t *testing.T) {
 	err = conn.Close()
 	assert.EqualError(t, err, "connection is not established")
 }
+
+func TestConnection_NoUnderlyingConnection(t *testing.T) {
+	conn, err := New("ws://localhost:0", Options{})
+	require.NoError(t, err)
+
+	// The connection is marked as ready, but the underlying socket is absent, e.g. after a failed dial.
+	close(conn.ready)
+
+	ctx := context.Background()
+
+	assert.ErrorIs(t, conn.Send(ctx, "test data"), ErrNotConnected)
+	assert.ErrorIs(t, conn.SendBinary(ctx, []byte{0x01}), ErrNotConnected)
+	assert.ErrorIs(t, conn.Close(), ErrNotConnected)
+	assert.ErrorIs(t, conn.handleResponses(ctx, nil), ErrNotConnected)
+}
+
+func TestConnection_Status(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		_ = c.Close(websocket.StatusNormalClosure, "")
+	}))
+	defer s.Close()
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), Options{})
+	assert.NoError(t, err)
+
+	conn.SetOnMessage(func(context.Context, []byte) {})
+
+	assert.Equal(t, StatusConnecting, conn.Status())
+
+	err = conn.Connect(context.Background())
+	assert.ErrorIs(t, err, ErrConnectionClosed)
+
+	assert.Equal(t, StatusClosed, conn.Status())
+}
+
+func TestConnection_Done(t *testing.T) {
+	tests := []struct {
+		name        string
+		closeServer bool
+	}{
+		{
+			name:        "Server closes after message",
+			closeServer: true,
+		},
+		{
+			name:        "Server stays open",
+			closeServer: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				c, err := websocket.Accept(w, r, nil)
+				if err != nil {
+					return
+				}
+
+				defer func() { _ = c.CloseNow() }()
+
+				if err := c.Write(r.Context(), websocket.MessageText, []byte("bye")); err != nil {
+					return
+				}
+
+				if tt.closeServer {
+					_ = c.Close(websocket.StatusNormalClosure, "")
+					return
+				}
+
+				_, _, _ = c.Read(r.Context())
+			}))
+			defer s.Close()
+
+			conn, err := New("ws://"+s.Listener.Addr().String(), Options{})
+			assert.NoError(t, err)
+
+			received := make(chan string, 1)
+
+			conn.SetOnMessage(func(_ context.Context, data []byte) {
+				received <- string(data)
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			connErr := make(chan error, 1)
+
+			go func() {
+				connErr <- conn.Connect(ctx)
+			}()
+
+			select {
+			case msg := <-received:
+				assert.Equal(t, "bye", msg)
+			case <-time.After(time.Second):
+				t.Fatal("timeout waiting for message")
+			}
+
+			select {
+			case <-conn.Done():
+				assert.True(t, tt.closeServer, "connection closed unexpectedly")
+				assert.ErrorIs(t, <-connErr, ErrConnectionClosed)
+			case <-time.After(100 * time.Millisecond):
+				assert.False(t, tt.closeServer, "timeout waiting for connection to close")
+
+				_ = conn.Close()
+
+				<-connErr
+			}
+		})
+	}
+}
+
+func TestConnection_SendBinary(t *testing.T) {
+	received := make(chan []byte, 1)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = c.CloseNow() }()
+
+		msgType, data, err := c.Read(r.Context())
+		if err != nil || msgType != websocket.MessageBinary {
+			return
+		}
+
+		received <- data
+
+		_, _, _ = c.Read(r.Context())
+	}))
+	defer s.Close()
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), Options{})
+	assert.NoError(t, err)
+
+	conn.SetOnMessage(func(context.Context, []byte) {})
+
+	connErr := make(chan error, 1)
+
+	go func() {
+		connErr <- conn.Connect(context.Background())
+	}()
+
+	var buf bytes.Buffer
+
+	zw := gzip.NewWriter(&buf)
+	_, err = zw.Write([]byte(`{"ping": 1}`))
+	assert.NoError(t, err)
+	assert.NoError(t, zw.Close())
+
+	assert.NoError(t, conn.SendBinary(context.Background(), buf.Bytes()))
+
+	select {
+	case data := <-received:
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		assert.NoError(t, err)
+
+		decompressed, err := io.ReadAll(zr)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"ping": 1}`, string(decompressed))
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for binary message")
+	}
+
+	_ = conn.Close()
+
+	<-connErr
+}
+
+func TestConnection_SendBinary_ContextCancelled(t *testing.T) {
+	conn, err := New("ws://localhost:0", Options{})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.ErrorIs(t, conn.SendBinary(ctx, []byte("data")), context.Canceled)
+}
+
+func TestNew_MaxMessageSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		size     int64
+		expected int64
+	}{
+		{name: "Configured", size: 512, expected: 512},
+		{name: "Zero uses default", size: 0, expected: DefaultMaxMessageSize},
+		{name: "Negative uses default", size: -1, expected: DefaultMaxMessageSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := New("ws://localhost:8080", Options{MaxMessageSize: tt.size})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, conn.msgSize)
+		})
+	}
+}
+
+func TestConnection_Connect_MessageTooBig(t *testing.T) {
+	const limit = 1024
+
+	closeStatus := make(chan websocket.StatusCode, 1)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = c.CloseNow() }()
+
+		if err := c.Write(r.Context(), websocket.MessageText, bytes.Repeat([]byte("a"), 64*limit)); err != nil {
+			return
+		}
+
+		_, _, err = c.Read(r.Context())
+		closeStatus <- websocket.CloseStatus(err)
+	}))
+	defer s.Close()
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), Options{MaxMessageSize: limit})
+	assert.NoError(t, err)
+
+	received := 0
+
+	conn.SetOnMessage(func(context.Context, []byte) { received++ })
+
+	err = conn.Connect(context.Background())
+
+	assert.ErrorIs(t, err, ErrMessageTooBig)
+	assert.ErrorContains(t, err, "limit is 1024 bytes")
+	assert.Zero(t, received)
+
+	select {
+	case status := <-closeStatus:
+		assert.Equal(t, websocket.StatusMessageTooBig, status)
+	case <-time.After(time.Second):
+		t.Fatal("server didn't receive close frame")
+	}
+}
+
+func TestConnection_Send_WriteTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = c.CloseNow() }()
+
+		// The server stops reading, so the client's writes block once the socket buffers are full.
+		<-release
+	}))
+	defer s.Close()
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), Options{WriteTimeout: 100 * time.Millisecond})
+	assert.NoError(t, err)
+
+	conn.SetOnMessage(func(context.Context, []byte) {})
+
+	go func() { _ = conn.Connect(context.Background()) }()
+
+	payload := string(bytes.Repeat([]byte("a"), 1024*1024))
+
+	for i := 0; i < 256; i++ {
+		start := time.Now()
+
+		err = conn.Send(context.Background(), payload)
+		if err == nil {
+			continue
+		}
+
+		assert.ErrorIs(t, err, ErrWriteTimeout)
+		assert.Less(t, time.Since(start), time.Second)
+
+		return
+	}
+
+	t.Fatal("send didn't time out on a stalled connection")
+}
+
+func TestConnection_SetSkipSSLVerification(t *testing.T) {
+	s := httptest.NewTLSServer(createEchoWSHandler())
+	defer s.Close()
+
+	conn, err := New("wss://"+s.Listener.Addr().String(), Options{})
+	require.NoError(t, err)
+
+	_, err = conn.dial(context.Background())
+	assert.ErrorContains(t, err, "certificate")
+
+	conn.SetSkipSSLVerification(true)
+
+	ws, err := conn.dial(context.Background())
+	require.NoError(t, err)
+
+	_ = ws.CloseNow()
+
+	conn.SetSkipSSLVerification(false)
+
+	_, err = conn.dial(context.Background())
+	assert.ErrorContains(t, err, "certificate")
+}
+
+func TestConnection_ServerName(t *testing.T) {
+	serverNames := make(chan string, 1)
+
+	s := httptest.NewUnstartedServer(createEchoWSHandler())
+	s.TLS = &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			serverNames <- hello.ServerName
+			return nil, nil
+		},
+	}
+
+	s.StartTLS()
+	defer s.Close()
+
+	conn, err := New("wss://"+s.Listener.Addr().String(), Options{ServerName: "api.example.com", SkipSSLVerification: true})
+	require.NoError(t, err)
+
+	ws, err := conn.dial(context.Background())
+	require.NoError(t, err)
+
+	_ = ws.CloseNow()
+
+	assert.Equal(t, "api.example.com", <-serverNames)
+
+	conn.SetSkipSSLVerification(false)
+
+	_, err = conn.dial(context.Background())
+	assert.ErrorContains(t, err, "certificate")
+	assert.Equal(t, "api.example.com", <-serverNames, "SNI should be kept when the transport is replaced")
+}
+
+func TestConnection_TLSResume(t *testing.T) {
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		_ = c.Close(websocket.StatusNormalClosure, "")
+	}))
+	defer s.Close()
+
+	wsURL := "wss://" + s.Listener.Addr().String()
+
+	for _, resume := range []bool{true, false} {
+		conn, err := New(wsURL, Options{SkipSSLVerification: true, TLSResume: resume})
+		require.NoError(t, err)
+
+		for i, wantResumed := range []bool{false, resume} {
+			ws, err := conn.dial(context.Background())
+			require.NoError(t, err)
+
+			_ = ws.CloseNow()
+
+			assert.Equal(t, wantResumed, conn.Info().TLSResumed, "resume %t, handshake %d", resume, i+1)
+		}
+	}
+}
+
+func TestConnection_TLSResume_InsecureChange(t *testing.T) {
+	conn, err := New("wss://localhost", Options{TLSResume: true})
+	require.NoError(t, err)
+
+	sessions := conn.reqLogger.sessions
+	require.NotNil(t, sessions)
+
+	conn.SetSkipSSLVerification(true)
+
+	assert.NotSame(t, sessions, conn.reqLogger.sessions)
+	assert.Same(t, conn.reqLogger.sessions, conn.reqLogger.transport.TLSClientConfig.ClientSessionCache)
+	assert.True(t, conn.reqLogger.transport.TLSClientConfig.InsecureSkipVerify)
+}
+
+func TestConnection_Info(t *testing.T) {
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Session-Id", "abc123")
+
+		c, err := websocket.Accept(w, r, &websocket.AcceptOptions{Subprotocols: []string{"json"}})
+		if err != nil {
+			return
+		}
+
+		_ = c.Close(websocket.StatusNormalClosure, "")
+	}))
+	defer s.Close()
+
+	wsURL := "wss://" + s.Listener.Addr().String()
+
+	conn, err := New(wsURL, Options{SkipSSLVerification: true, Subprotocols: []string{"msgpack", "json"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, core.ConnectionInfo{URL: wsURL, Subprotocols: []string{"msgpack", "json"}}, conn.Info())
+
+	ws, err := conn.dial(context.Background())
+	require.NoError(t, err)
+
+	_ = ws.CloseNow()
+
+	info := conn.Info()
+	assert.Equal(t, wsURL, info.URL)
+	assert.Equal(t, s.Listener.Addr().String(), info.RemoteAddr)
+	assert.Equal(t, "TLS 1.3", info.TLSVersion)
+	assert.NotEmpty(t, info.CipherSuite)
+	assert.Equal(t, []string{"msgpack", "json"}, info.Subprotocols)
+	assert.Equal(t, "json", info.Subprotocol)
+	assert.Equal(t, []string{"abc123"}, info.ResponseHeaders["X-Session-Id"])
+	assert.Equal(t, []string{"websocket"}, info.ResponseHeaders["Upgrade"])
+}